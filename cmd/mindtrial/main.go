@@ -9,13 +9,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -38,6 +41,7 @@ const (
 	exitCodeFinishedWithErrors = 3
 	defaultConfigFile          = "config.yaml"
 	msgInteractiveExited       = "Interactive session exited by user."
+	artifactsDirSuffix         = "-artifacts"
 )
 
 var (
@@ -50,7 +54,7 @@ var (
 )
 
 var (
-	csvFormatter        = formatters.NewCSVFormatter()
+	csvCodec            = formatters.NewCSVCodec()
 	htmlFormatter       = formatters.NewHTMLFormatter()
 	jsonCodec           = formatters.NewJSONCodec()
 	logFormatter        = formatters.NewLogFormatter()
@@ -69,9 +73,26 @@ var (
 	verbose            *bool
 	debug              *bool
 	interactive        *bool
+	deadline           *time.Duration
+	gracePeriod        *time.Duration
+	anonymize          *bool
+	anonymizeKey       *string
+	anonymizeMapping   *string
 )
 
-var inputFiles stringSliceFlag
+var (
+	inputFiles       stringSliceFlag
+	includeProviders stringSliceFlag
+	excludeProviders stringSliceFlag
+	includeRuns      stringSliceFlag
+	excludeRuns      stringSliceFlag
+	includeTasks     stringSliceFlag
+	excludeTasks     stringSliceFlag
+	includeKinds     stringSliceFlag
+	excludeKinds     stringSliceFlag
+	renameProviders  stringSliceFlag
+	renameRuns       stringSliceFlag
+)
 
 // stringSliceFlag implements flag.Value for collecting multiple string flag values.
 type stringSliceFlag []string
@@ -114,13 +135,28 @@ func registerFlags() {
 	outputFileDir = flag.String("output-dir", unsetFlagValue, "results output directory")
 	outputFileBasename = flag.String("output-basename", unsetFlagValue, "base filename for results; replace if exists; blank = stdout")
 	formatHTML = formatFlag(htmlFormatter, true)
-	formatCSV = formatFlag(csvFormatter, false)
+	formatCSV = formatFlag(csvCodec, false)
 	formatJSON = formatFlag(jsonCodec, false)
 	logFilePath = flag.String("log", unsetFlagValue, "log file path; append if exists; blank = stdout")
 	verbose = flag.Bool("verbose", false, "enable detailed logging")
 	debug = flag.Bool("debug", false, "enable low-level debug logging")
 	interactive = flag.Bool("interactive", false, "enable interactive interface for run configuration, and real-time progress monitoring")
+	deadline = flag.Duration("deadline", 0, "maximum duration of the whole trial; tasks still running are stopped and reported as timed out; 0 = no deadline")
+	gracePeriod = flag.Duration("grace-period", 30*time.Second, "time running tasks may take to finish after an interrupt signal before they are stopped; a second signal stops them immediately")
 	flag.Var(&inputFiles, "input", "input result file path for merge-results; can be specified multiple times")
+	flag.Var(&includeProviders, "include-provider", "merge-results: keep only results of this provider; can be specified multiple times")
+	flag.Var(&excludeProviders, "exclude-provider", "merge-results: drop results of this provider; can be specified multiple times")
+	flag.Var(&includeRuns, "include-run", "merge-results: keep only results of this run; can be specified multiple times")
+	flag.Var(&excludeRuns, "exclude-run", "merge-results: drop results of this run; can be specified multiple times")
+	flag.Var(&includeTasks, "include-task", "merge-results: keep only results of this task; can be specified multiple times")
+	flag.Var(&excludeTasks, "exclude-task", "merge-results: drop results of this task; can be specified multiple times")
+	flag.Var(&includeKinds, "include-kind", "merge-results: keep only results with this status (Passed, Failed, Error, Skipped, Cancelled, Unavailable, TimedOut, ExtractionFailed); can be specified multiple times")
+	flag.Var(&excludeKinds, "exclude-kind", "merge-results: drop results with this status (Passed, Failed, Error, Skipped, Cancelled, Unavailable, TimedOut, ExtractionFailed); can be specified multiple times")
+	flag.Var(&renameProviders, "rename-provider", "merge-results: rename a provider, given as OLD=NEW; can be specified multiple times")
+	flag.Var(&renameRuns, "rename-run", "merge-results: rename a run of any provider, given as OLD=NEW; can be specified multiple times")
+	anonymize = flag.Bool("anonymize", false, "merge-results: replace provider and run names with stable pseudonyms")
+	anonymizeKey = flag.String("anonymize-key", unsetFlagValue, "merge-results: secret key from which pseudonyms are derived; required with --anonymize")
+	anonymizeMapping = flag.String("anonymize-mapping", unsetFlagValue, "merge-results: file path for the pseudonym mapping; blank = output base filename with .pseudonyms.json suffix")
 
 	flag.Usage = func() {
 		w := flag.CommandLine.Output()
@@ -178,7 +214,7 @@ func main() {
 func run(ctx context.Context) (ok bool, err error) {
 	if err = validateFlags(runCommandName,
 		"config", "tasks", "output-dir", "output-basename",
-		"html", "csv", "json", "log", "verbose", "debug", "interactive",
+		"html", "csv", "json", "log", "verbose", "debug", "interactive", "deadline", "grace-period",
 	); err != nil {
 		return
 	}
@@ -198,6 +234,13 @@ func run(ctx context.Context) (ok bool, err error) {
 		return
 	}
 
+	// Set the base path for each tool image build context to the location of the configuration file.
+	for _, tool := range cfg.Config.Tools {
+		if tool.Build != nil {
+			tool.Build.Context = config.MakeAbs(configDir, tool.Build.Context)
+		}
+	}
+
 	// Load tasks.
 	tasksFile := config.CleanIfNotBlank(getFlagValueIfSet(tasksFilePath, config.MakeAbs(configDir, cfg.Config.TaskSource)))
 	fmt.Printf("Loading tasks from file: %s\n", tasksFile)
@@ -258,6 +301,7 @@ func run(ctx context.Context) (ok bool, err error) {
 
 	// Create output files.
 	var outputWriters []outputTarget
+	var artifactsBaseDir, artifactsDirName string
 	for _, formatter := range enabledFormatters() {
 		out := os.Stdout // default
 		if fileName := getFlagValueIfSet(outputFileBasename, cfg.Config.OutputBaseName); config.IsNotBlank(fileName) {
@@ -269,6 +313,9 @@ func run(ctx context.Context) (ok bool, err error) {
 				defer fp.Close()
 				fmt.Printf("Results in %s format will be saved to: %s\n", strings.ToUpper(formatter.FileExt()), outputPath)
 				out = fp
+				// Store tool artifacts next to the result files, named after them.
+				artifactsBaseDir = filepath.Dir(outputPath)
+				artifactsDirName = strings.TrimSuffix(filepath.Base(outputPath), "."+formatter.FileExt()) + artifactsDirSuffix
 			}
 		}
 		outputWriters = append(outputWriters, outputTarget{formatter: formatter, writer: out})
@@ -313,10 +360,19 @@ func run(ctx context.Context) (ok bool, err error) {
 	}
 	defer exec.Close(ctx)
 
-	var runResult runners.ResultSet
+	// Stop all tasks that are still running when the trial deadline is exceeded.
+	runCtx := ctx
+	if deadline != nil && *deadline > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, *deadline, runners.ErrTrialDeadline)
+		defer cancel()
+		fmt.Printf("Trial deadline: %s\n", *deadline)
+	}
+
+	var runResult runners.AsyncResultSet
 	if isEnabled(interactive) {
 		var userAction tui.UserInputEvent
-		if userAction, runResult, err = tui.NewTaskMonitor(exec, consoleBuffer.(*tui.ConsoleBuffer)).Run(ctx, targetTasks); err != nil { // blocking call
+		if userAction, runResult, err = tui.NewTaskMonitor(exec, consoleBuffer.(*tui.ConsoleBuffer)).Run(runCtx, targetTasks); err != nil { // blocking call
 			return ok, err
 		} else if userAction == tui.Exit {
 			fmt.Println(msgInteractiveExited)
@@ -325,27 +381,66 @@ func run(ctx context.Context) (ok bool, err error) {
 			fmt.Println("Interactive UI closed: tasks will continue running in the background.")
 		}
 	} else {
-		if runResult, err = exec.Run(ctx, targetTasks); err != nil { // blocking call
+		if runResult, err = exec.Start(runCtx, targetTasks); err != nil {
 			return
 		}
+		stopSignalHandler := cancelOnSignal(runResult, logger, *gracePeriod)
+		defer stopSignalHandler()
 	}
 
-	// If this was an async run that is still in progress, the call will block until it is finished.
-	results := runResult.GetResults()
+	// If the run is still in progress, the call will block until it is finished.
+	results, err := runResult.GetResults()
+	if err != nil {
+		return ok, err
+	}
 
 	// Print and save the results.
 	ok = !logResults(results, logFile)
+	ok = !storeArtifacts(results, artifactsBaseDir, artifactsDirName) && ok
 	ok = ok && !saveResults(results, outputWriters)
 
 	return
 }
 
+// cancelOnSignal cancels the run gracefully when an interrupt or termination signal is received,
+// so that the partial results can still be saved. Tasks that have not started yet are cancelled
+// and running tasks may finish within the grace period. A second signal stops them immediately.
+// The returned function stops listening for signals and waits until the handler has exited.
+func cancelOnSignal(result runners.AsyncResultSet, logger zerolog.Logger, grace time.Duration) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case sig := <-signals:
+				if grace > 0 {
+					logger.Warn().Msgf("received %s: cancelling tasks that have not started yet, running tasks may finish within %s (repeat to stop them now)...", sig, grace)
+				} else {
+					logger.Warn().Msgf("received %s: stopping all tasks...", sig)
+				}
+				result.Cancel(grace)
+				grace = 0
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		<-exited
+	}
+}
+
 func enabledFormatters() (enabled []formatters.Formatter) {
 	if isEnabled(formatHTML) {
 		enabled = append(enabled, htmlFormatter)
 	}
 	if isEnabled(formatCSV) {
-		enabled = append(enabled, csvFormatter)
+		enabled = append(enabled, csvCodec)
 	}
 	if isEnabled(formatJSON) {
 		enabled = append(enabled, jsonCodec)
@@ -427,6 +522,22 @@ func logResults(results runners.Results, out io.Writer) (finishedWithErrors bool
 	return
 }
 
+// storeArtifacts writes the tool artifacts collected in the results to the named directory
+// within baseDir, so that the result files written there can link to them. Artifacts are
+// not stored when no result file is written (baseDir is empty).
+func storeArtifacts(results runners.Results, baseDir string, dirName string) (finishedWithErrors bool) {
+	if baseDir == "" {
+		return
+	}
+	if stored, err := results.StoreArtifacts(baseDir, dirName); err != nil {
+		stderr.Warn().Err(err).Msg("failed to store tool artifacts")
+		finishedWithErrors = true
+	} else if stored > 0 {
+		fmt.Printf("Tool artifacts were saved to: %s\n", filepath.Join(baseDir, dirName))
+	}
+	return
+}
+
 func saveResults(results runners.Results, outputWriters []outputTarget) (finishedWithErrors bool) {
 	for _, ow := range outputWriters {
 		if err := ow.formatter.Write(results, ow.writer); err != nil {
@@ -437,7 +548,106 @@ func saveResults(results runners.Results, outputWriters []outputTarget) (finishe
 	return
 }
 
-var errUnsupportedFlag = errors.New("unsupported flag for command")
+var (
+	errUnsupportedFlag      = errors.New("unsupported flag for command")
+	errInvalidFlagValue     = errors.New("invalid flag value")
+	errMissingMappingOutput = errors.New("missing pseudonym mapping output")
+	errMissingAnonymizeKey  = errors.New("missing anonymization key")
+)
+
+// mergeResultFilter builds the result filter from the merge-results filter flags.
+func mergeResultFilter() (filter runners.ResultFilter, err error) {
+	filter = runners.ResultFilter{
+		IncludeProviders: includeProviders,
+		ExcludeProviders: excludeProviders,
+		IncludeRuns:      includeRuns,
+		ExcludeRuns:      excludeRuns,
+		IncludeTasks:     includeTasks,
+		ExcludeTasks:     excludeTasks,
+	}
+	if filter.IncludeKinds, err = parseResultKinds("include-kind", includeKinds); err != nil {
+		return
+	}
+	filter.ExcludeKinds, err = parseResultKinds("exclude-kind", excludeKinds)
+	return
+}
+
+func parseResultKinds(flagName string, statuses []string) ([]runners.ResultKind, error) {
+	kinds := make([]runners.ResultKind, 0, len(statuses))
+	for _, status := range statuses {
+		kind, ok := formatters.ParseStatus(status)
+		if !ok {
+			return nil, fmt.Errorf("%w: --%s=%q is not one of %s, %s, %s, %s, %s, %s, %s, %s", errInvalidFlagValue, flagName, status,
+				formatters.Passed, formatters.Failed, formatters.Error, formatters.Skipped, formatters.Cancelled, formatters.Unavailable, formatters.TimedOut, formatters.ExtractionFailed)
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// mergeRenameMap builds the rename map from the merge-results rename flags.
+func mergeRenameMap() (renames runners.RenameMap, err error) {
+	if renames.Providers, err = parseRenames("rename-provider", renameProviders); err != nil {
+		return
+	}
+	renames.Runs, err = parseRenames("rename-run", renameRuns)
+	return
+}
+
+func parseRenames(flagName string, values []string) (map[string]string, error) {
+	renames := make(map[string]string, len(values))
+	for _, value := range values {
+		oldName, newName, ok := strings.Cut(value, "=")
+		if !ok || !config.IsNotBlank(oldName) || !config.IsNotBlank(newName) {
+			return nil, fmt.Errorf("%w: --%s=%q must have the form OLD=NEW", errInvalidFlagValue, flagName, value)
+		}
+		renames[oldName] = newName
+	}
+	return renames, nil
+}
+
+// anonymizeMappingPath returns the path of the file the pseudonym mapping is written to.
+func anonymizeMappingPath() (string, error) {
+	if mappingPath := getFlagValueIfSet(anonymizeMapping, ""); config.IsNotBlank(mappingPath) {
+		return mappingPath, nil
+	}
+	fileName := getFlagValueIfSet(outputFileBasename, "")
+	if !config.IsNotBlank(fileName) {
+		return "", fmt.Errorf("%w: --anonymize requires --anonymize-mapping or --output-basename", errMissingMappingOutput)
+	}
+	fileName += ".pseudonyms.json"
+	if outputDir := getFlagValueIfSet(outputFileDir, ""); config.IsNotBlank(outputDir) {
+		fileName = filepath.Join(outputDir, fileName)
+	}
+	return fileName, nil
+}
+
+// anonymizationKey returns the key pseudonyms are derived from.
+// The key is required, so that the same results always get the same pseudonyms.
+func anonymizationKey() ([]byte, error) {
+	key := getFlagValueIfSet(anonymizeKey, "")
+	if !config.IsNotBlank(key) {
+		return nil, fmt.Errorf("%w: --anonymize requires --anonymize-key", errMissingAnonymizeKey)
+	}
+	return []byte(key), nil
+}
+
+func saveAnonymizationMapping(mapping []runners.PseudonymMapping, mappingPath string, timeRef time.Time) error {
+	fp, outputPath, err := createOutputFile(mappingPath, timeRef, false)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	data, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err = fp.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	fmt.Printf("Pseudonym mapping saved to: %s\n", outputPath)
+	return nil
+}
 
 func validateFlags(command string, supported ...string) error {
 	allowed := make(map[string]bool, len(supported))
@@ -459,10 +669,32 @@ func validateFlags(command string, supported ...string) error {
 func mergeResults(_ context.Context) (ok bool, err error) {
 	if err = validateFlags(mergeResultsCommandName,
 		"input", "output-dir", "output-basename", "html", "csv", "json", "verbose",
+		"include-provider", "exclude-provider", "include-run", "exclude-run",
+		"include-task", "exclude-task", "include-kind", "exclude-kind",
+		"rename-provider", "rename-run", "anonymize", "anonymize-key", "anonymize-mapping",
 	); err != nil {
 		return
 	}
 
+	filter, err := mergeResultFilter()
+	if err != nil {
+		return
+	}
+	renames, err := mergeRenameMap()
+	if err != nil {
+		return
+	}
+	var mappingPath string
+	var key []byte
+	if isEnabled(anonymize) {
+		if key, err = anonymizationKey(); err != nil {
+			return
+		}
+		if mappingPath, err = anonymizeMappingPath(); err != nil {
+			return
+		}
+	}
+
 	if len(inputFiles) < 1 {
 		fmt.Println("Nothing to merge: no input files provided.")
 		return true, nil
@@ -476,7 +708,8 @@ func mergeResults(_ context.Context) (ok bool, err error) {
 		if readErr != nil {
 			return false, readErr
 		}
-		resultSets = append(resultSets, rs)
+		// Filter before renaming, so that filters always refer to the original names.
+		resultSets = append(resultSets, renames.Apply(filter.Apply(rs)))
 	}
 
 	// Merge results.
@@ -485,6 +718,17 @@ func mergeResults(_ context.Context) (ok bool, err error) {
 	// Time to be used to resolve name patterns.
 	timeRef := time.Now()
 
+	// Anonymize the merged results.
+	if isEnabled(anonymize) {
+		var mapping []runners.PseudonymMapping
+		if results, stats, mapping, err = runners.Anonymize(results, stats, key); err != nil {
+			return
+		}
+		if err = saveAnonymizationMapping(mapping, mappingPath, timeRef); err != nil {
+			return
+		}
+	}
+
 	// Create output files.
 	var outputWriters []outputTarget
 	for _, formatter := range enabledFormatters() {
@@ -8,6 +8,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -20,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/runners"
 	"github.com/petmal/mindtrial/version"
 )
 
@@ -149,6 +151,11 @@ var (
 func resetFlags() {
 	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	inputFiles = nil
+	includeProviders, excludeProviders = nil, nil
+	includeRuns, excludeRuns = nil, nil
+	includeTasks, excludeTasks = nil, nil
+	includeKinds, excludeKinds = nil, nil
+	renameProviders, renameRuns = nil, nil
 	registerFlags()
 }
 
@@ -698,6 +705,43 @@ func TestMergeResults(t *testing.T) {
 		assert.NoFileExists(t, filepath.Join(outBasePath, "merged.csv"))
 	})
 
+	t.Run("merge JSON and CSV files", func(t *testing.T) {
+		resetFlags()
+		inputFile1 := testutils.CreateMockFile(t, "*.json", []byte(fixture1))
+		inputFile2 := testutils.CreateMockFile(t, "*.csv", []byte(
+			"TraceID,Provider,Run,Task,Status,DurationMS,Answer,Details,Suite,Category,Difficulty,Tags,Got,Want\n"+
+				`t3,ProviderB,run2,task-beta,Failed,2000,answer-b2,{},,,,,"""answer-b2""","""expected-b2"""`+"\n"))
+		outBasePath := filepath.Join(os.TempDir(), uuid.NewString())
+
+		require.NoError(t, flag.Set("input", inputFile1))
+		require.NoError(t, flag.Set("input", inputFile2))
+		require.NoError(t, flag.Set("output-dir", outBasePath))
+		require.NoError(t, flag.Set("output-basename", "merged"))
+		require.NoError(t, flag.Set("html", "false"))
+		require.NoError(t, flag.Set("csv", "false"))
+		require.NoError(t, flag.Set("json", "true"))
+		require.NoError(t, flag.Set("verbose", "true"))
+
+		sout := testutils.CaptureStdout(t, func() { testutils.WithArgs(t, main, "merge-results") })
+		testutils.AssertContainsAll(t, sout, []string{
+			fmt.Sprintf("Loading results from file: %s", inputFile1),
+			fmt.Sprintf("Loading results from file: %s", inputFile2),
+			"Merged results:",
+			"ProviderA:",
+			"run1: 1 total",
+			"ProviderB:",
+			"run2: 1 total",
+		})
+
+		jsonOutputPath := filepath.Join(outBasePath, "merged.json")
+		require.FileExists(t, jsonOutputPath)
+		testutils.AssertFileContains(t, jsonOutputPath, []string{
+			"task-alpha",
+			"task-beta",
+			"expected-b2",
+		}, nil)
+	})
+
 	t.Run("merge with overlapping results last-in wins", func(t *testing.T) {
 		resetFlags()
 		inputFile1 := testutils.CreateMockFile(t, "*.json", []byte(fixture1))
@@ -773,6 +817,137 @@ func TestMergeResults(t *testing.T) {
 		})
 	})
 
+	t.Run("merge with filters and renames", func(t *testing.T) {
+		resetFlags()
+		inputFile1 := testutils.CreateMockFile(t, "*.json", []byte(fixture1))
+		inputFile2 := testutils.CreateMockFile(t, "*.json", []byte(fixture2))
+		outBasePath := filepath.Join(os.TempDir(), uuid.NewString())
+
+		require.NoError(t, flag.Set("input", inputFile1))
+		require.NoError(t, flag.Set("input", inputFile2))
+		require.NoError(t, flag.Set("output-dir", outBasePath))
+		require.NoError(t, flag.Set("output-basename", "merged"))
+		require.NoError(t, flag.Set("html", "false"))
+		require.NoError(t, flag.Set("csv", "false"))
+		require.NoError(t, flag.Set("json", "true"))
+		require.NoError(t, flag.Set("exclude-provider", "ProviderB"))
+		require.NoError(t, flag.Set("include-kind", "passed"))
+		require.NoError(t, flag.Set("rename-provider", "ProviderA=Public Provider"))
+		require.NoError(t, flag.Set("rename-run", "run1=Public Model"))
+
+		sout := testutils.CaptureStdout(t, func() { testutils.WithArgs(t, main, "merge-results") })
+		testutils.AssertContainsAll(t, sout, []string{
+			"Merged results:",
+			"Public Provider:",
+			"Public Model: 1 total",
+		})
+		testutils.AssertContainsNone(t, sout, []string{
+			"ProviderB:",
+			"run1:",
+		})
+
+		jsonOutputPath := filepath.Join(outBasePath, "merged.json")
+		require.FileExists(t, jsonOutputPath)
+		testutils.AssertFileContains(t, jsonOutputPath, []string{
+			"Public Provider",
+			"Public Model",
+			"task-alpha",
+		}, []string{
+			"ProviderA",
+			"ProviderB",
+			"task-beta",
+		})
+	})
+
+	t.Run("merge with anonymization", func(t *testing.T) {
+		resetFlags()
+		inputFile1 := testutils.CreateMockFile(t, "*.json", []byte(fixture1))
+		inputFile2 := testutils.CreateMockFile(t, "*.json", []byte(fixture2))
+		outBasePath := filepath.Join(os.TempDir(), uuid.NewString())
+
+		require.NoError(t, flag.Set("input", inputFile1))
+		require.NoError(t, flag.Set("input", inputFile2))
+		require.NoError(t, flag.Set("output-dir", outBasePath))
+		require.NoError(t, flag.Set("output-basename", "merged"))
+		require.NoError(t, flag.Set("html", "false"))
+		require.NoError(t, flag.Set("csv", "false"))
+		require.NoError(t, flag.Set("json", "true"))
+		require.NoError(t, flag.Set("anonymize", "true"))
+		require.NoError(t, flag.Set("anonymize-key", "secret"))
+
+		mappingPath := filepath.Join(outBasePath, "merged.pseudonyms.json")
+		sout := testutils.CaptureStdout(t, func() { testutils.WithArgs(t, main, "merge-results") })
+		testutils.AssertContainsAll(t, sout, []string{
+			fmt.Sprintf("Pseudonym mapping saved to: %s", mappingPath),
+			"Merged results:",
+			"provider-",
+			"run-",
+		})
+		testutils.AssertContainsNone(t, sout, []string{
+			"ProviderA:",
+			"ProviderB:",
+		})
+
+		jsonOutputPath := filepath.Join(outBasePath, "merged.json")
+		require.FileExists(t, jsonOutputPath)
+		testutils.AssertFileContains(t, jsonOutputPath, []string{
+			"task-alpha",
+			"task-beta",
+		}, []string{
+			"ProviderA",
+			"ProviderB",
+			"run1",
+			"run2",
+		})
+
+		var mapping []runners.PseudonymMapping
+		require.NoError(t, json.Unmarshal(testutils.ReadFile(t, mappingPath), &mapping))
+		require.Len(t, mapping, 2)
+		originals := []runners.Identity{mapping[0].Original, mapping[1].Original}
+		assert.ElementsMatch(t, []runners.Identity{
+			{Provider: "ProviderA", Run: "run1"},
+			{Provider: "ProviderB", Run: "run2"},
+		}, originals)
+	})
+
+	t.Run("anonymize without mapping output", func(t *testing.T) {
+		resetFlags()
+		inputFiles = append(stringSliceFlag(nil), testutils.CreateMockFile(t, "*.json", []byte(fixture1)))
+		require.NoError(t, flag.Set("anonymize", "true"))
+		require.NoError(t, flag.Set("anonymize-key", "secret"))
+
+		_, err := mergeResults(context.Background())
+		require.ErrorIs(t, err, errMissingMappingOutput)
+	})
+
+	t.Run("anonymize without key", func(t *testing.T) {
+		resetFlags()
+		inputFiles = append(stringSliceFlag(nil), testutils.CreateMockFile(t, "*.json", []byte(fixture1)))
+		require.NoError(t, flag.Set("anonymize", "true"))
+		require.NoError(t, flag.Set("anonymize-mapping", filepath.Join(t.TempDir(), "mapping.json")))
+
+		_, err := mergeResults(context.Background())
+		require.ErrorIs(t, err, errMissingAnonymizeKey)
+	})
+
+	t.Run("invalid filter and rename values", func(t *testing.T) {
+		tests := map[string]string{
+			"include-kind":    "Unknown",
+			"exclude-kind":    "",
+			"rename-provider": "ProviderA",
+			"rename-run":      "=run2",
+		}
+		for name, value := range tests {
+			t.Run(name, func(t *testing.T) {
+				resetFlags()
+				require.NoError(t, flag.Set(name, value))
+
+				_, err := mergeResults(context.Background())
+				require.ErrorIs(t, err, errInvalidFlagValue)
+			})
+		}
+	})
+
 	t.Run("no input files", func(t *testing.T) {
 		resetFlags()
 		outBasePath := filepath.Join(os.TempDir(), uuid.NewString())
@@ -67,7 +67,7 @@ func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyPressMsg:
 		switch msg.String() {
 		case "ctrl+c":
-			m.resultManager.Cancel()
+			m.resultManager.Cancel(0)
 			m.action = Exit
 			return m, tea.Quit
 		case "q", "esc":
@@ -13,8 +13,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"path"
+	"strings"
 	"time"
 
+	"github.com/petmal/mindtrial/pkg/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -118,18 +122,34 @@ type ProviderConfig struct {
 	// and limits the aggregate number of API requests per minute across all runs.
 	// When set to a value greater than 0, runs execute concurrently with a shared rate limiter.
 	// Value of 0 (default) means runs execute sequentially.
-	MaxParallelRequestsPerMinute int `yaml:"max-parallel-requests-per-minute" validate:"omitempty,numeric,min=0"`
+	MaxParallelRequestsPerMinute int `yaml:"max-parallel-requests-per-minute" validate:"required_if=AdaptiveRateLimit true,numeric,min=0"`
+
+	// AdaptiveRateLimit lets the aggregate rate limit of parallel runs adapt to the provider's
+	// rate limit responses. The rate is lowered after 429 responses or when the remaining quota
+	// reported in response headers runs low, and raised back up to MaxParallelRequestsPerMinute
+	// while the quota recovers. Requires MaxParallelRequestsPerMinute to be set.
+	AdaptiveRateLimit bool `yaml:"adaptive-rate-limit" validate:"omitempty"`
+
+	// MaxConcurrentTasks specifies the default number of tasks executed concurrently within each run.
+	// Requests of concurrent tasks are still subject to the configured rate limits.
+	// Value of 0 (default) or 1 means tasks execute sequentially.
+	MaxConcurrentTasks int `yaml:"max-concurrent-tasks" validate:"omitempty,numeric,min=0"`
 
 	// Disabled indicates if all runs should be disabled by default.
 	Disabled bool `yaml:"disabled" validate:"omitempty"`
 
 	// RetryPolicy specifies default retry behavior for all runs in this provider.
 	RetryPolicy RetryPolicy `yaml:"retry-policy" validate:"omitempty"`
+
+	// CircuitBreaker pauses all runs of this provider after repeated transient errors.
+	// If not set, failing requests are retried independently according to the retry policy.
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit-breaker" validate:"omitempty"`
 }
 
-// GetRunsResolved returns runs with retry policies and disabled flags resolved.
+// GetRunsResolved returns runs with retry policies, disabled flags and task concurrency resolved.
 // If RunConfig.RetryPolicy is nil, the parent ProviderConfig.RetryPolicy value is used instead.
 // If RunConfig.Disabled is nil, the parent ProviderConfig.Disabled value is used instead.
+// If RunConfig.MaxConcurrentTasks is 0, the parent ProviderConfig.MaxConcurrentTasks value is used instead.
 func (pc ProviderConfig) GetRunsResolved() []RunConfig {
 	resolved := make([]RunConfig, 0, len(pc.Runs))
 	for _, run := range pc.Runs {
@@ -139,6 +159,9 @@ func (pc ProviderConfig) GetRunsResolved() []RunConfig {
 		if run.Disabled == nil {
 			run.Disabled = &pc.Disabled
 		}
+		if run.MaxConcurrentTasks == 0 {
+			run.MaxConcurrentTasks = pc.MaxConcurrentTasks
+		}
 		resolved = append(resolved, run)
 	}
 	return resolved
@@ -254,12 +277,47 @@ func (c MoonshotAIClientConfig) GetEndpoint() string {
 	return c.Endpoint
 }
 
+const (
+	// ToolTypeDocker identifies a tool that runs each call in a new Docker container, or in
+	// a pre-started one if it has a pool, or in a container kept for the whole task if it
+	// has a session.
+	ToolTypeDocker = "docker"
+	// ToolTypeSubprocess identifies a tool that runs each call as a sandboxed local process,
+	// for environments where Docker is not available.
+	ToolTypeSubprocess = "subprocess"
+	// ToolTypeMCP identifies a Model Context Protocol (MCP) server whose tools are
+	// discovered at the start of each task.
+	ToolTypeMCP = "mcp"
+	// ToolTypeMock identifies a tool that returns canned responses defined in its
+	// configuration instead of running anything, for deterministic function calling tests.
+	ToolTypeMock = "mock"
+	// ToolTypeHTTP identifies a tool that forwards each call to an HTTP service by
+	// POSTing its JSON arguments to a configured URL.
+	ToolTypeHTTP = "http"
+)
+
+const (
+	// MCPTransportStdio communicates with an MCP server launched as a subprocess over its
+	// standard input and output.
+	MCPTransportStdio = "stdio"
+	// MCPTransportStreamableHTTP communicates with an MCP server over the streamable HTTP transport.
+	MCPTransportStreamableHTTP = "streamable-http"
+)
+
 // ToolConfig represents the configuration for a tool.
 type ToolConfig struct {
 	// Name is the unique identifier for the tool.
 	Name string `yaml:"name" validate:"required"`
+	// Type selects how the tool is executed, e.g. ToolTypeSubprocess, ToolTypeMCP, ToolTypeMock
+	// or ToolTypeHTTP. If empty, the tool is a Docker tool.
+	Type string `yaml:"type,omitempty" validate:"omitempty,oneof=docker subprocess mcp mock http"`
 	// Image is the name of the Docker image to use for the tool.
-	Image string `yaml:"image" validate:"required"`
+	// It is required for Docker tools and ignored by subprocess tools.
+	// If the tool has a build, it is the name the built image is tagged with.
+	Image string `yaml:"image,omitempty"`
+	// Build builds the Docker image of the tool from a Dockerfile before the trial starts,
+	// unless the image already exists. It is only allowed for Docker tools.
+	Build *ToolBuildConfig `yaml:"build,omitempty" validate:"omitempty"`
 	// Description describes what the tool does. For optimal LLM understanding and tool selection,
 	// provide extremely detailed descriptions including:
 	// - What the tool does and its primary purpose
@@ -269,7 +327,8 @@ type ToolConfig struct {
 	// - Examples of usage if helpful
 	// Aim for 3-4 sentences per tool description. Be specific and avoid ambiguity
 	// to help the LLM choose the correct tool and provide appropriate parameters.
-	Description string `yaml:"description" validate:"required"`
+	// It is required for all but MCP tools. MCP servers describe their own tools.
+	Description string `yaml:"description,omitempty"`
 	// Parameters is the JSON schema for the tool's input parameters. Follow these best practices
 	// to improve LLM parameter generation accuracy:
 	// - Use standard JSON Schema format with detailed "description" fields for each parameter
@@ -279,7 +338,8 @@ type ToolConfig struct {
 	// - Clearly mark all required parameters in the "required" array
 	// - Use "additionalProperties": false for objects to prevent unexpected parameters
 	// - Provide comprehensive descriptions that explain parameter purpose and format
-	Parameters map[string]interface{} `yaml:"parameters" validate:"required"`
+	// It is required for all but MCP tools. MCP servers define the parameters of their own tools.
+	Parameters map[string]interface{} `yaml:"parameters,omitempty"`
 	// ParameterFiles maps parameter field names to file paths where argument values should be written.
 	// This allows passing large or complex data to tools via files instead of inline JSON.
 	// The tool's command should read these files as needed.
@@ -295,9 +355,431 @@ type ToolConfig struct {
 	// will be removed when the task completes.
 	SharedDir string `yaml:"shared-dir,omitempty"`
 	// Command specifies the command to execute as a list of its components.
+	// It is required for subprocess tools. For MCP tools, it is the command that launches the MCP server as a local process.
 	Command []string `yaml:"command,omitempty"`
 	// Env specifies additional environment variables to set.
 	Env map[string]string `yaml:"env,omitempty"`
+	// MCP holds the MCP server connection settings. It is required for MCP tools.
+	MCP *MCPServerConfig `yaml:"mcp,omitempty" validate:"omitempty"`
+	// Mock holds the canned responses of the tool. It is required for mock tools.
+	Mock *MockToolConfig `yaml:"mock,omitempty" validate:"omitempty"`
+	// HTTP holds the settings of the HTTP service the calls are forwarded to. It is required for HTTP tools.
+	HTTP *HTTPToolConfig `yaml:"http,omitempty" validate:"omitempty"`
+	// Session makes all calls of the tool within a task share a persistent sandbox.
+	// It is only allowed for Docker and subprocess tools.
+	Session *ToolSessionConfig `yaml:"session,omitempty" validate:"omitempty"`
+	// Pool keeps pre-started containers ready for the calls of the tool to cut the
+	// container startup latency. It is only allowed for Docker tools without a session
+	// or a shared directory.
+	Pool *ToolPoolConfig `yaml:"pool,omitempty" validate:"omitempty"`
+	// Artifacts collects files written by the tool in its sandbox after each call, so that
+	// they are stored alongside the results. It is only allowed for Docker and subprocess tools.
+	Artifacts *ToolArtifactsConfig `yaml:"artifacts,omitempty" validate:"omitempty"`
+}
+
+// ToolBuildConfig defines how to build the Docker image of a tool.
+type ToolBuildConfig struct {
+	// Context is the path of the build context directory. A relative path is resolved
+	// against the directory of the configuration file.
+	Context string `yaml:"context" validate:"required"`
+	// Dockerfile is the slash-separated path of the Dockerfile within the build context.
+	// If not set, it defaults to `Dockerfile`.
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+	// Args specifies the values of the build arguments declared in the Dockerfile.
+	Args map[string]string `yaml:"args,omitempty"`
+	// ContentHashTag tags the image with a hash of the build context contents, the Dockerfile
+	// path and the build arguments instead of the tag in the image name, so that an existing
+	// image is reused while none of them changes. Otherwise, the image is built every time,
+	// relying on the build cache of Docker for unchanged builds.
+	ContentHashTag bool `yaml:"content-hash-tag,omitempty"`
+}
+
+// GetDockerfile returns the path of the Dockerfile within the build context,
+// defaulting to `Dockerfile`.
+func (b ToolBuildConfig) GetDockerfile() string {
+	if b.Dockerfile == "" {
+		return "Dockerfile"
+	}
+	return b.Dockerfile
+}
+
+// ToolArtifactsConfig defines the files collected from the sandbox of a tool after each call.
+// Files are collected whenever the tool process has run to completion, regardless of its exit code.
+type ToolArtifactsConfig struct {
+	// Paths lists the absolute sandbox paths of the files or directories to collect.
+	// Paths that do not exist after a call are ignored. For subprocess tools, the paths
+	// must be within the session workspace directory or the shared directory.
+	Paths []string `yaml:"paths" validate:"required,min=1,dive,required"`
+	// MaxBytes limits the total size of the file contents collected per call. Files that
+	// would exceed the limit are listed without their content.
+	// If not set, it defaults to DefaultMaxArtifactBytes.
+	MaxBytes *int64 `yaml:"max-bytes,omitempty" validate:"omitempty,gt=0"`
+}
+
+// DefaultMaxArtifactBytes is the default limit on the total size of the file contents
+// collected as artifacts of a tool call.
+const DefaultMaxArtifactBytes int64 = 10 * 1024 * 1024
+
+// GetMaxBytes returns the limit on the total size of the collected file contents,
+// defaulting to DefaultMaxArtifactBytes.
+func (a ToolArtifactsConfig) GetMaxBytes() int64 {
+	if a.MaxBytes == nil {
+		return DefaultMaxArtifactBytes
+	}
+	return *a.MaxBytes
+}
+
+// ToolPoolConfig defines a pool of pre-started containers for a Docker tool. Each call takes
+// a running container from the pool and the container is discarded after the call, so that
+// no state is carried over to other calls or tasks. The pool is shared by all tools running
+// the same image with the same resource limits and kept until the run completes.
+type ToolPoolConfig struct {
+	// Size is the number of idle containers to keep ready.
+	Size int `yaml:"size" validate:"gt=0"`
+	// KeepAliveCommand is the command that keeps a pooled container running until it is
+	// used. If not set, it defaults to `sleep infinity`.
+	KeepAliveCommand []string `yaml:"keep-alive-command,omitempty"`
+	// HealthCheckCommand is an optional command run in a pooled container before it is used.
+	// The container is discarded if the command does not exit with code 0.
+	HealthCheckCommand []string `yaml:"health-check-command,omitempty"`
+}
+
+// GetKeepAliveCommand returns the command that keeps a pooled container running,
+// defaulting to `sleep infinity`.
+func (p ToolPoolConfig) GetKeepAliveCommand() []string {
+	if len(p.KeepAliveCommand) == 0 {
+		return []string{"sleep", "infinity"}
+	}
+	return p.KeepAliveCommand
+}
+
+// ToolSessionConfig defines a persistent sandbox session of a tool. The session is created
+// on the first call of the tool within a task, reused for all subsequent calls of the tool
+// within the same task, and torn down when the task completes.
+type ToolSessionConfig struct {
+	// WorkspaceDir is the working directory of the tool in the sandbox. Files written to it
+	// persist across the calls of the session. For Docker tools, the whole container
+	// file system persists.
+	WorkspaceDir string `yaml:"workspace-dir" validate:"required"`
+	// KeepAliveCommand is the command that keeps the session container of a Docker tool
+	// running between calls. If not set, it defaults to `sleep infinity`.
+	KeepAliveCommand []string `yaml:"keep-alive-command,omitempty"`
+	// CaptureWorkspace enables recording the files left in the workspace directory at the
+	// end of the task in the results.
+	CaptureWorkspace bool `yaml:"capture-workspace,omitempty"`
+	// MaxCaptureBytes limits the total size of the file contents recorded when capturing
+	// the workspace. If not set, it defaults to DefaultMaxCaptureBytes.
+	MaxCaptureBytes *int64 `yaml:"max-capture-bytes,omitempty" validate:"omitempty,gt=0"`
+}
+
+// DefaultMaxCaptureBytes is the default limit on the total size of the file contents
+// recorded when capturing the workspace of a tool session.
+const DefaultMaxCaptureBytes int64 = 1024 * 1024
+
+// GetKeepAliveCommand returns the command that keeps the session container running,
+// defaulting to `sleep infinity`.
+func (s ToolSessionConfig) GetKeepAliveCommand() []string {
+	if len(s.KeepAliveCommand) == 0 {
+		return []string{"sleep", "infinity"}
+	}
+	return s.KeepAliveCommand
+}
+
+// GetMaxCaptureBytes returns the limit on the total size of the captured file contents,
+// defaulting to DefaultMaxCaptureBytes.
+func (s ToolSessionConfig) GetMaxCaptureBytes() int64 {
+	if s.MaxCaptureBytes == nil {
+		return DefaultMaxCaptureBytes
+	}
+	return *s.MaxCaptureBytes
+}
+
+// GetType returns the type of the tool, defaulting to ToolTypeDocker.
+func (t ToolConfig) GetType() string {
+	if t.Type == "" {
+		return ToolTypeDocker
+	}
+	return t.Type
+}
+
+// Validate checks that the settings required by the tool type are present.
+func (t ToolConfig) Validate() error {
+	switch t.GetType() {
+	case ToolTypeMCP:
+		if t.MCP == nil {
+			return fmt.Errorf("%w: mcp settings are required for mcp tools", ErrInvalidConfigProperty)
+		}
+		switch t.MCP.Transport {
+		case MCPTransportStdio:
+			if len(t.Command) == 0 {
+				return fmt.Errorf("%w: command is required for mcp tools using the %s transport", ErrInvalidConfigProperty, MCPTransportStdio)
+			}
+		case MCPTransportStreamableHTTP:
+			if t.MCP.URL == "" {
+				return fmt.Errorf("%w: mcp url is required for mcp tools using the %s transport", ErrInvalidConfigProperty, MCPTransportStreamableHTTP)
+			}
+		}
+		if t.Session != nil {
+			return fmt.Errorf("%w: session is not allowed for mcp tools", ErrInvalidConfigProperty)
+		}
+		if t.Pool != nil {
+			return fmt.Errorf("%w: pool is only allowed for docker tools", ErrInvalidConfigProperty)
+		}
+		if t.Artifacts != nil {
+			return fmt.Errorf("%w: artifacts are not allowed for mcp tools", ErrInvalidConfigProperty)
+		}
+		if t.Mock != nil {
+			return fmt.Errorf("%w: mock settings are only allowed for mock tools", ErrInvalidConfigProperty)
+		}
+		if t.HTTP != nil {
+			return fmt.Errorf("%w: http settings are only allowed for http tools", ErrInvalidConfigProperty)
+		}
+		if t.Build != nil {
+			return fmt.Errorf("%w: build is only allowed for docker tools", ErrInvalidConfigProperty)
+		}
+	case ToolTypeMock, ToolTypeHTTP:
+		if t.GetType() == ToolTypeMock {
+			if t.Mock == nil {
+				return fmt.Errorf("%w: mock settings are required for mock tools", ErrInvalidConfigProperty)
+			}
+			if err := t.Mock.Validate(); err != nil {
+				return err
+			}
+		} else {
+			if t.HTTP == nil {
+				return fmt.Errorf("%w: http settings are required for http tools", ErrInvalidConfigProperty)
+			}
+			if err := t.HTTP.Validate(); err != nil {
+				return err
+			}
+		}
+		if t.Description == "" {
+			return fmt.Errorf("%w: description is required for %s tools", ErrInvalidConfigProperty, t.GetType())
+		}
+		if t.Parameters == nil {
+			return fmt.Errorf("%w: parameters are required for %s tools", ErrInvalidConfigProperty, t.GetType())
+		}
+		switch {
+		case t.MCP != nil:
+			return fmt.Errorf("%w: mcp settings are only allowed for mcp tools", ErrInvalidConfigProperty)
+		case t.Mock != nil && t.GetType() != ToolTypeMock:
+			return fmt.Errorf("%w: mock settings are only allowed for mock tools", ErrInvalidConfigProperty)
+		case t.HTTP != nil && t.GetType() != ToolTypeHTTP:
+			return fmt.Errorf("%w: http settings are only allowed for http tools", ErrInvalidConfigProperty)
+		case t.Session != nil:
+			return fmt.Errorf("%w: session is not allowed for %s tools", ErrInvalidConfigProperty, t.GetType())
+		case t.Pool != nil:
+			return fmt.Errorf("%w: pool is only allowed for docker tools", ErrInvalidConfigProperty)
+		case t.Build != nil:
+			return fmt.Errorf("%w: build is only allowed for docker tools", ErrInvalidConfigProperty)
+		case t.Artifacts != nil:
+			return fmt.Errorf("%w: artifacts are not allowed for %s tools", ErrInvalidConfigProperty, t.GetType())
+		}
+	default:
+		if t.GetType() == ToolTypeDocker && t.Image == "" {
+			return fmt.Errorf("%w: image is required for docker tools", ErrInvalidConfigProperty)
+		}
+		if t.GetType() == ToolTypeSubprocess && len(t.Command) == 0 {
+			return fmt.Errorf("%w: command is required for subprocess tools", ErrInvalidConfigProperty)
+		}
+		if t.Description == "" {
+			return fmt.Errorf("%w: description is required for %s tools", ErrInvalidConfigProperty, t.GetType())
+		}
+		if t.Parameters == nil {
+			return fmt.Errorf("%w: parameters are required for %s tools", ErrInvalidConfigProperty, t.GetType())
+		}
+		if t.MCP != nil {
+			return fmt.Errorf("%w: mcp settings are only allowed for mcp tools", ErrInvalidConfigProperty)
+		}
+		if t.Mock != nil {
+			return fmt.Errorf("%w: mock settings are only allowed for mock tools", ErrInvalidConfigProperty)
+		}
+		if t.HTTP != nil {
+			return fmt.Errorf("%w: http settings are only allowed for http tools", ErrInvalidConfigProperty)
+		}
+		if t.Build != nil {
+			if t.GetType() != ToolTypeDocker {
+				return fmt.Errorf("%w: build is only allowed for docker tools", ErrInvalidConfigProperty)
+			}
+			if dockerfile := path.Clean(t.Build.GetDockerfile()); path.IsAbs(dockerfile) || dockerfile == ".." || strings.HasPrefix(dockerfile, "../") {
+				return fmt.Errorf("%w: dockerfile %q must be a relative path within the build context", ErrInvalidConfigProperty, t.Build.Dockerfile)
+			}
+		}
+		if t.Pool != nil {
+			switch {
+			case t.GetType() != ToolTypeDocker:
+				return fmt.Errorf("%w: pool is only allowed for docker tools", ErrInvalidConfigProperty)
+			case t.Session != nil:
+				return fmt.Errorf("%w: pool cannot be combined with session", ErrInvalidConfigProperty)
+			case t.SharedDir != "":
+				return fmt.Errorf("%w: pool cannot be combined with shared-dir", ErrInvalidConfigProperty)
+			}
+		}
+		if t.Artifacts != nil {
+			if err := t.validateArtifactPaths(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateArtifactPaths checks that the artifact paths are absolute and, for subprocess tools,
+// within a directory that is kept on the host after the call.
+func (t ToolConfig) validateArtifactPaths() error {
+	for _, artifactPath := range t.Artifacts.Paths {
+		if !path.IsAbs(artifactPath) {
+			return fmt.Errorf("%w: artifact path %q must be absolute", ErrInvalidConfigProperty, artifactPath)
+		}
+		if t.GetType() != ToolTypeSubprocess {
+			continue
+		}
+		if !(t.Session != nil && isWithinDir(artifactPath, t.Session.WorkspaceDir)) && !(t.SharedDir != "" && isWithinDir(artifactPath, t.SharedDir)) {
+			return fmt.Errorf("%w: artifact path %q of a subprocess tool must be within the session workspace-dir or the shared-dir", ErrInvalidConfigProperty, artifactPath)
+		}
+	}
+	return nil
+}
+
+// isWithinDir reports whether the slash-separated path equals or is nested in the directory.
+func isWithinDir(p string, dir string) bool {
+	p, dir = path.Clean(p), path.Clean(dir)
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// MockToolConfig defines the canned responses of a mock tool. Exactly one of Output, Lookup
+// and Sequence must be set. A response that is a string is returned to the model verbatim,
+// while any other value is returned in its JSON encoding.
+type MockToolConfig struct {
+	// Output is the response to every call of the tool.
+	Output interface{} `yaml:"output,omitempty"`
+	// Lookup selects the response to each call by the value of one of its arguments.
+	Lookup *MockLookupConfig `yaml:"lookup,omitempty" validate:"omitempty"`
+	// Sequence lists the responses to the calls of the tool within a task, in order.
+	// Once all responses have been used, the last one is repeated.
+	Sequence []interface{} `yaml:"sequence,omitempty"`
+}
+
+// MockLookupConfig defines a lookup table of the responses of a mock tool keyed by
+// argument values.
+type MockLookupConfig struct {
+	// Argument is the JSONPath expression selecting the argument value to look up,
+	// e.g. `$.city`. If it selects several values, the first one is looked up.
+	Argument utils.JSONPath `yaml:"argument"`
+	// Responses maps argument values to responses. Argument values other than strings
+	// are looked up by their JSON encoding, e.g. `42` or `true`.
+	Responses map[string]interface{} `yaml:"responses" validate:"required,min=1"`
+	// Default is the response to calls whose argument value is not in Responses.
+	// If not set, such calls fail as called with invalid arguments.
+	Default interface{} `yaml:"default,omitempty"`
+}
+
+// Validate checks that exactly one kind of response is defined.
+func (m MockToolConfig) Validate() error {
+	defined := 0
+	if m.Output != nil {
+		defined++
+	}
+	if m.Lookup != nil {
+		defined++
+		if m.Lookup.Argument.String() == "" {
+			return fmt.Errorf("%w: mock lookup argument is required", ErrInvalidConfigProperty)
+		}
+	}
+	if len(m.Sequence) > 0 {
+		defined++
+	}
+	if defined != 1 {
+		return fmt.Errorf("%w: exactly one of mock output, lookup or sequence must be set", ErrInvalidConfigProperty)
+	}
+	return nil
+}
+
+// HTTPToolConfig defines the HTTP service an HTTP tool forwards its calls to. Each call POSTs
+// the JSON arguments to the URL and the response body becomes the result of the call.
+type HTTPToolConfig struct {
+	// URL is the endpoint the arguments of each call are sent to.
+	URL string `yaml:"url" validate:"required,url"`
+	// Headers specifies additional HTTP headers to send with each request, e.g. for authorization.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// AllowedHosts lists the host names or IP addresses the tool may send requests to,
+	// including when following redirects. The host of the URL must be listed.
+	// If empty, only the loopback hosts in DefaultHTTPToolAllowedHosts are allowed.
+	AllowedHosts []string `yaml:"allowed-hosts,omitempty" validate:"omitempty,dive,required"`
+	// MaxResponseBytes limits the size of the response body. Calls with a larger response fail.
+	// If not set, it defaults to DefaultMaxHTTPToolResponseBytes.
+	MaxResponseBytes *int64 `yaml:"max-response-bytes,omitempty" validate:"omitempty,gt=0"`
+}
+
+// DefaultHTTPToolAllowedHosts lists the hosts HTTP tools may send requests to if they
+// do not list their own.
+var DefaultHTTPToolAllowedHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// DefaultMaxHTTPToolResponseBytes is the default limit on the size of the response body
+// of an HTTP tool call.
+const DefaultMaxHTTPToolResponseBytes int64 = 1024 * 1024
+
+// GetAllowedHosts returns the hosts the tool may send requests to, defaulting to
+// DefaultHTTPToolAllowedHosts.
+func (h HTTPToolConfig) GetAllowedHosts() []string {
+	if len(h.AllowedHosts) == 0 {
+		return DefaultHTTPToolAllowedHosts
+	}
+	return h.AllowedHosts
+}
+
+// IsHostAllowed reports whether the tool may send requests to the host, given without a port.
+func (h HTTPToolConfig) IsHostAllowed(host string) bool {
+	for _, allowed := range h.GetAllowedHosts() {
+		if strings.EqualFold(strings.Trim(allowed, "[]"), host) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMaxResponseBytes returns the limit on the size of the response body,
+// defaulting to DefaultMaxHTTPToolResponseBytes.
+func (h HTTPToolConfig) GetMaxResponseBytes() int64 {
+	if h.MaxResponseBytes == nil {
+		return DefaultMaxHTTPToolResponseBytes
+	}
+	return *h.MaxResponseBytes
+}
+
+// Validate checks that the URL uses HTTP and that its host is allowed.
+func (h HTTPToolConfig) Validate() error {
+	endpoint, err := url.Parse(h.URL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid http url %q: %v", ErrInvalidConfigProperty, h.URL, err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return fmt.Errorf("%w: http url %q must use the http or https scheme", ErrInvalidConfigProperty, h.URL)
+	}
+	if !h.IsHostAllowed(endpoint.Hostname()) {
+		return fmt.Errorf("%w: host of http url %q is not in allowed-hosts", ErrInvalidConfigProperty, h.URL)
+	}
+	return nil
+}
+
+// MCPServerConfig defines how to connect to a Model Context Protocol (MCP) server.
+type MCPServerConfig struct {
+	// Transport selects how to communicate with the server: MCPTransportStdio
+	// or MCPTransportStreamableHTTP.
+	Transport string `yaml:"transport" validate:"required,oneof=stdio streamable-http"`
+	// URL is the endpoint of a server using the streamable HTTP transport.
+	// If the tool also defines a command, the server is launched as a local process
+	// and its endpoint is awaited until it responds.
+	URL string `yaml:"url,omitempty" validate:"omitempty,url"`
+	// Headers specifies additional HTTP headers to send to a server using
+	// the streamable HTTP transport.
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// StartupTimeout limits how long to wait for the server to start and list its tools.
+	// If nil, a default of 30 seconds is used.
+	StartupTimeout *time.Duration `yaml:"startup-timeout,omitempty" validate:"omitempty,gt=0"`
+	// Tools restricts the tools exposed from the server to those with the given names.
+	// If empty, all tools listed by the server are exposed.
+	Tools []string `yaml:"tools,omitempty" validate:"omitempty,unique"`
 }
 
 // RunConfig defines settings for a single run configuration.
@@ -312,6 +794,15 @@ type RunConfig struct {
 	// Value of 0 means no rate limiting will be applied.
 	MaxRequestsPerMinute int `yaml:"max-requests-per-minute" validate:"omitempty,numeric,min=0"`
 
+	// MaxConcurrentTasks specifies the number of tasks executed concurrently in this run.
+	// If set, overrides the parent ProviderConfig.MaxConcurrentTasks value.
+	MaxConcurrentTasks int `yaml:"max-concurrent-tasks" validate:"omitempty,numeric,min=0"`
+
+	// Timeout limits how long a single task may run in this run configuration.
+	// If the task has a shorter timeout of its own, that one applies instead.
+	// If nil, tasks of this run are only limited by their own timeout.
+	Timeout *time.Duration `yaml:"timeout" validate:"omitempty,gt=0"`
+
 	// Disabled indicates if this run configuration should be skipped.
 	// If set, overrides the parent ProviderConfig.Disabled value.
 	Disabled *bool `yaml:"disabled" validate:"omitempty"`
@@ -330,12 +821,55 @@ type RunConfig struct {
 	// - Cannot be used with judge configurations.
 	DisableStructuredOutput bool `yaml:"disable-structured-output" validate:"omitempty"`
 
+	// RecordReasoning stores the reasoning text or summaries returned by the model in the results.
+	// Reasoning can be long, so it is not stored by default. Whether any reasoning is returned
+	// depends on the model and its parameters (e.g. reasoning-summary for OpenAI).
+	RecordReasoning bool `yaml:"record-reasoning" validate:"omitempty"`
+
 	// ModelParams holds any model-specific configuration parameters.
 	ModelParams ModelParams `yaml:"model-parameters" validate:"omitempty"`
 
 	// RetryPolicy specifies retry behavior on transient errors.
 	// If set, overrides the parent ProviderConfig.RetryPolicy value.
 	RetryPolicy *RetryPolicy `yaml:"retry-policy" validate:"omitempty"`
+
+	// ResponseRecovery specifies how responses that could not be parsed are recovered.
+	// If nil, such responses fail the task with a parsing error.
+	ResponseRecovery *ResponseRecoveryPolicy `yaml:"response-recovery" validate:"omitempty"`
+
+	// ExecutionMode selects how tasks are sent to the provider: ExecutionModeSync (default) or ExecutionModeBatch.
+	// In batch mode, single-turn tasks are submitted together through the provider's batch API at a lower
+	// price and higher latency. Tasks that need a tool loop and providers without batch support fall back
+	// to synchronous execution.
+	ExecutionMode string `yaml:"execution-mode" validate:"omitempty,oneof=sync batch"`
+}
+
+// ResponseRecoveryPolicy defines how structured responses that could not be parsed are recovered.
+// The enabled steps are tried in order: Repair, Coerce and finally corrective turns.
+type ResponseRecoveryPolicy struct {
+	// Repair fixes common syntax issues of the response locally, e.g. JSON wrapped in a Markdown
+	// code block, trailing commas or unquoted keys.
+	Repair bool `yaml:"repair" validate:"omitempty"`
+
+	// Coerce fits the response to the result schema of the task, e.g. by wrapping a bare answer,
+	// renaming misspelled fields or converting values to the expected types.
+	Coerce bool `yaml:"coerce" validate:"omitempty"`
+
+	// MaxCorrectiveTurns specifies how many times the model is asked to correct its response,
+	// quoting the error the response failed with. Value of 0 means the model is not asked.
+	MaxCorrectiveTurns int `yaml:"max-corrective-turns" validate:"omitempty,min=0"`
+}
+
+const (
+	// ExecutionModeSync sends each task request to the provider synchronously.
+	ExecutionModeSync = "sync"
+	// ExecutionModeBatch submits task requests through the provider's batch API.
+	ExecutionModeBatch = "batch"
+)
+
+// IsBatch reports whether the run submits tasks through the provider's batch API.
+func (rc RunConfig) IsBatch() bool {
+	return rc.ExecutionMode == ExecutionModeBatch
 }
 
 // RetryPolicy defines retry behavior on transient errors.
@@ -346,6 +880,59 @@ type RetryPolicy struct {
 
 	// InitialDelaySeconds specifies the initial delay in seconds before the first retry attempt.
 	InitialDelaySeconds int `yaml:"initial-delay-seconds" validate:"omitempty,gt=0"`
+
+	// MaxDelaySeconds caps the delay in seconds between retry attempts.
+	// Value of 0 means the delay grows without limit.
+	MaxDelaySeconds int `yaml:"max-delay-seconds" validate:"omitempty,gt=0"`
+
+	// Jitter randomizes retry delays so that parallel runs do not retry in lockstep:
+	// RetryJitterNone (default), RetryJitterFull or RetryJitterDecorrelated.
+	Jitter string `yaml:"jitter" validate:"omitempty,oneof=none full decorrelated"`
+
+	// MaxTotalSeconds limits the total time in seconds spent on all attempts of a task,
+	// after which no further retries are made. Value of 0 means no limit.
+	MaxTotalSeconds int `yaml:"max-total-seconds" validate:"omitempty,gt=0"`
+
+	// RetryOn lists the classes of errors that are retried, e.g. ErrorClassRateLimit.
+	// If empty, all errors that the provider reports as transient are retried.
+	RetryOn []string `yaml:"retry-on" validate:"omitempty,unique,dive,oneof=rate-limit server-error timeout parse-error no-content"`
+}
+
+const (
+	// RetryJitterNone uses the exponential backoff delays as they are.
+	RetryJitterNone = "none"
+	// RetryJitterFull picks each delay at random between zero and the exponential backoff delay.
+	RetryJitterFull = "full"
+	// RetryJitterDecorrelated picks each delay at random between the initial delay and three times the previous delay.
+	RetryJitterDecorrelated = "decorrelated"
+)
+
+const (
+	// ErrorClassRateLimit classifies responses rejected because of rate limiting (HTTP 429).
+	ErrorClassRateLimit = "rate-limit"
+	// ErrorClassServerError classifies server errors (HTTP 5xx) and interrupted response streams.
+	ErrorClassServerError = "server-error"
+	// ErrorClassTimeout classifies requests that timed out.
+	ErrorClassTimeout = "timeout"
+	// ErrorClassParseError classifies responses that could not be parsed.
+	ErrorClassParseError = "parse-error"
+	// ErrorClassNoContent classifies responses without any actionable content.
+	ErrorClassNoContent = "no-content"
+)
+
+// CircuitBreakerConfig defines when requests to a failing provider are paused or given up.
+type CircuitBreakerConfig struct {
+	// FailureThreshold specifies the number of consecutive transient errors after which
+	// all requests to the provider are paused.
+	FailureThreshold int `yaml:"failure-threshold" validate:"required,gt=0"`
+
+	// ProbeIntervalSeconds specifies the delay in seconds between probe requests while requests are paused.
+	ProbeIntervalSeconds int `yaml:"probe-interval-seconds" validate:"required,gt=0"`
+
+	// MaxProbeAttempts specifies the number of failed probes after which the provider is considered
+	// unavailable and the remaining tasks fail without being sent.
+	// Value of 0 means the provider is probed until it recovers.
+	MaxProbeAttempts int `yaml:"max-probe-attempts" validate:"omitempty,min=0"`
 }
 
 // ModelParams is a marker interface for model-specific parameters.
@@ -370,6 +957,11 @@ type OpenAIModelParams struct {
 	// matters. Supported on GPT-5.6 and later models.
 	ReasoningMode *string `yaml:"reasoning-mode" validate:"omitempty,oneof=pro"`
 
+	// ReasoningSummary requests a summary of the model's reasoning.
+	// Valid values are: "auto", "concise", "detailed".
+	// Summaries are only stored in the results if the run enables record-reasoning.
+	ReasoningSummary *string `yaml:"reasoning-summary" validate:"omitempty,oneof=auto concise detailed"`
+
 	// Verbosity determines how many output tokens are generated.
 	// Valid values are: "low", "medium", "high".
 	// Note: May not be supported by legacy models.
@@ -558,6 +1150,10 @@ type GoogleAIModelParams struct {
 	// - "high": Maximizes reasoning depth, the model may take longer but output is more carefully reasoned
 	ThinkingLevel *string `yaml:"thinking-level" validate:"omitempty,oneof=minimal low medium high"`
 
+	// IncludeThoughts requests summaries of the model's thoughts in the response.
+	// Summaries are only stored in the results if the run enables record-reasoning.
+	IncludeThoughts bool `yaml:"include-thoughts" validate:"omitempty"`
+
 	// MediaResolution controls the maximum number of tokens allocated per input image or video frame.
 	// Valid values: "low", "medium", "high". Higher resolutions improve fine text reading and small detail
 	// identification but increase token usage and latency.
@@ -991,12 +1587,15 @@ func (jc JudgeConfig) Validate() error {
 // It handles provider-specific client configuration based on provider name.
 func (pc *ProviderConfig) UnmarshalYAML(value *yaml.Node) error {
 	var temp struct {
-		Name                         string      `yaml:"name"`
-		ClientConfig                 yaml.Node   `yaml:"client-config"`
-		Runs                         yaml.Node   `yaml:"runs"`
-		MaxParallelRequestsPerMinute int         `yaml:"max-parallel-requests-per-minute"`
-		Disabled                     bool        `yaml:"disabled"`
-		RetryPolicy                  RetryPolicy `yaml:"retry-policy"`
+		Name                         string                `yaml:"name"`
+		ClientConfig                 yaml.Node             `yaml:"client-config"`
+		Runs                         yaml.Node             `yaml:"runs"`
+		MaxParallelRequestsPerMinute int                   `yaml:"max-parallel-requests-per-minute"`
+		AdaptiveRateLimit            bool                  `yaml:"adaptive-rate-limit"`
+		MaxConcurrentTasks           int                   `yaml:"max-concurrent-tasks"`
+		Disabled                     bool                  `yaml:"disabled"`
+		RetryPolicy                  RetryPolicy           `yaml:"retry-policy"`
+		CircuitBreaker               *CircuitBreakerConfig `yaml:"circuit-breaker"`
 	}
 
 	if err := value.Decode(&temp); err != nil {
@@ -1005,8 +1604,11 @@ func (pc *ProviderConfig) UnmarshalYAML(value *yaml.Node) error {
 
 	pc.Name = temp.Name
 	pc.MaxParallelRequestsPerMinute = temp.MaxParallelRequestsPerMinute
+	pc.AdaptiveRateLimit = temp.AdaptiveRateLimit
+	pc.MaxConcurrentTasks = temp.MaxConcurrentTasks
 	pc.Disabled = temp.Disabled
 	pc.RetryPolicy = temp.RetryPolicy
+	pc.CircuitBreaker = temp.CircuitBreaker
 
 	if err := decodeRuns(temp.Name, &temp.Runs, &pc.Runs); err != nil {
 		return err
@@ -1076,14 +1678,19 @@ func (pc *ProviderConfig) UnmarshalYAML(value *yaml.Node) error {
 
 func decodeRuns(provider string, value *yaml.Node, out *[]RunConfig) error {
 	var temp []struct {
-		Name                    string       `yaml:"name"`
-		Model                   string       `yaml:"model"`
-		MaxRequestsPerMinute    int          `yaml:"max-requests-per-minute"`
-		Disabled                *bool        `yaml:"disabled"`
-		TextOnly                bool         `yaml:"text-only"`
-		DisableStructuredOutput bool         `yaml:"disable-structured-output"`
-		ModelParams             yaml.Node    `yaml:"model-parameters"`
-		RetryPolicy             *RetryPolicy `yaml:"retry-policy"`
+		Name                    string                  `yaml:"name"`
+		Model                   string                  `yaml:"model"`
+		MaxRequestsPerMinute    int                     `yaml:"max-requests-per-minute"`
+		MaxConcurrentTasks      int                     `yaml:"max-concurrent-tasks"`
+		Timeout                 *time.Duration          `yaml:"timeout"`
+		Disabled                *bool                   `yaml:"disabled"`
+		TextOnly                bool                    `yaml:"text-only"`
+		DisableStructuredOutput bool                    `yaml:"disable-structured-output"`
+		RecordReasoning         bool                    `yaml:"record-reasoning"`
+		ModelParams             yaml.Node               `yaml:"model-parameters"`
+		RetryPolicy             *RetryPolicy            `yaml:"retry-policy"`
+		ResponseRecovery        *ResponseRecoveryPolicy `yaml:"response-recovery"`
+		ExecutionMode           string                  `yaml:"execution-mode"`
 	}
 
 	if err := value.Decode(&temp); err != nil {
@@ -1095,10 +1702,15 @@ func decodeRuns(provider string, value *yaml.Node, out *[]RunConfig) error {
 		(*out)[i].Name = temp[i].Name
 		(*out)[i].Model = temp[i].Model
 		(*out)[i].MaxRequestsPerMinute = temp[i].MaxRequestsPerMinute
+		(*out)[i].MaxConcurrentTasks = temp[i].MaxConcurrentTasks
+		(*out)[i].Timeout = temp[i].Timeout
 		(*out)[i].Disabled = temp[i].Disabled
 		(*out)[i].TextOnly = temp[i].TextOnly
 		(*out)[i].DisableStructuredOutput = temp[i].DisableStructuredOutput
+		(*out)[i].RecordReasoning = temp[i].RecordReasoning
 		(*out)[i].RetryPolicy = temp[i].RetryPolicy
+		(*out)[i].ResponseRecovery = temp[i].ResponseRecovery
+		(*out)[i].ExecutionMode = temp[i].ExecutionMode
 
 		if !temp[i].ModelParams.IsZero() {
 			switch provider {
@@ -18,7 +18,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -294,6 +296,15 @@ type TaskConfig struct {
 	// allowed per task. This acts as a safety net to prevent infinite conversation loops.
 	// Value of 0 means no limit is enforced. Individual tasks can override this setting.
 	MaxTurns int `yaml:"max-turns" validate:"omitempty,min=0"`
+
+	// Timeout sets the default maximum time a single task may run.
+	// If nil, there is no limit. Individual tasks can override this setting.
+	Timeout *time.Duration `yaml:"timeout" validate:"omitempty,gt=0"`
+
+	// AnswerExtractor sets the default rule for extracting the final answer from responses
+	// of runs with disabled structured output. If nil, the whole response is the answer.
+	// Individual tasks can override this setting.
+	AnswerExtractor *AnswerExtractor `yaml:"answer-extractor" validate:"omitempty"`
 }
 
 // GetEnabledTasks returns a filtered list of tasks that are not disabled.
@@ -322,6 +333,31 @@ func (o TaskConfig) Validate() error {
 func (o TaskConfig) validateTask(task Task) error {
 	resolvedValidationRules := o.ValidationRules.MergeWith(task.ValidationRules)
 
+	// Validate resolved file options.
+	for _, file := range task.Files {
+		if err := o.FileOptions.MergeWith(file.Options).Validate(); err != nil {
+			return fmt.Errorf("invalid options for file '%s': %w", file.Name, err)
+		}
+	}
+
+	// Validate resolved answer extractor pattern.
+	extractor := task.AnswerExtractor
+	if extractor == nil {
+		extractor = o.AnswerExtractor
+	}
+	if extractor != nil && extractor.Type == AnswerExtractorRegex {
+		if _, err := extractor.CompilePattern(); err != nil {
+			return fmt.Errorf("%w: invalid answer-extractor pattern: %v", ErrInvalidTaskProperty, err)
+		}
+	}
+
+	// Validate tool expectations.
+	if task.ToolExpectations != nil {
+		if err := task.ToolExpectations.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validate task response format and expected results.
 	if err := validateFormatAndExpectedResults(task.ResponseResultFormat, task.ExpectedResult, resolvedValidationRules.UseJudge(), "response-result-format", "expected-result"); err != nil {
 		return err
@@ -664,6 +700,19 @@ type Task struct {
 	// Value of 0 means no limit is enforced.
 	MaxTurns *int `yaml:"max-turns" validate:"omitempty,min=0"`
 
+	// Timeout sets the maximum time this specific task may run.
+	// If set, overrides the global TaskConfig.Timeout value.
+	Timeout *time.Duration `yaml:"timeout" validate:"omitempty,gt=0"`
+
+	// AnswerExtractor sets the rule for extracting the final answer from responses of runs
+	// with disabled structured output. If set, overrides the global TaskConfig.AnswerExtractor value.
+	AnswerExtractor *AnswerExtractor `yaml:"answer-extractor" validate:"omitempty"`
+
+	// ToolExpectations sets assertions on the tool calls made while producing the answer.
+	// They are checked in addition to the validation of the answer, and the task fails
+	// if any of them is not met.
+	ToolExpectations *ToolExpectations `yaml:"tool-expectations,omitempty" validate:"omitempty"`
+
 	// Suite is an optional grouping label for organizing related tasks (e.g. a benchmark suite name).
 	Suite string `yaml:"suite,omitempty" validate:"omitempty"`
 
@@ -687,6 +736,12 @@ type Task struct {
 
 	// resolvedMaxTurns is the resolved maximum conversation turns for this task.
 	resolvedMaxTurns int
+
+	// resolvedTimeout is the resolved maximum time this task may run.
+	resolvedTimeout time.Duration
+
+	// resolvedAnswerExtractor is the resolved answer extraction rule for this task.
+	resolvedAnswerExtractor *AnswerExtractor
 }
 
 // GetResolvedSystemPrompt returns the resolved system prompt template for this task and true if it is not blank.
@@ -783,6 +838,45 @@ func (t Task) GetResolvedMaxTurns() int {
 	return t.resolvedMaxTurns
 }
 
+// ResolveTimeout resolves the maximum time this task may run.
+// If the task has its own value set, it takes precedence over the default.
+// The resolved value can be retrieved using GetResolvedTimeout().
+func (t *Task) ResolveTimeout(defaultValue *time.Duration) {
+	if t.Timeout != nil {
+		t.resolvedTimeout = *t.Timeout
+	} else if defaultValue != nil {
+		t.resolvedTimeout = *defaultValue
+	} else {
+		t.resolvedTimeout = 0
+	}
+}
+
+// GetResolvedTimeout returns the resolved maximum time this task may run.
+// Value of 0 means no limit is enforced.
+func (t Task) GetResolvedTimeout() time.Duration {
+	return t.resolvedTimeout
+}
+
+// ResolveAnswerExtractor resolves the answer extraction rule for this task.
+// If the task has its own rule set, it takes precedence over the default.
+// The resolved rule can be retrieved using GetResolvedAnswerExtractor().
+func (t *Task) ResolveAnswerExtractor(defaultValue *AnswerExtractor) {
+	if t.AnswerExtractor != nil {
+		t.resolvedAnswerExtractor = t.AnswerExtractor
+	} else {
+		t.resolvedAnswerExtractor = defaultValue
+	}
+}
+
+// GetResolvedAnswerExtractor returns the resolved answer extraction rule for this task
+// and whether any rule is set.
+func (t Task) GetResolvedAnswerExtractor() (extractor AnswerExtractor, ok bool) {
+	if t.resolvedAnswerExtractor == nil {
+		return AnswerExtractor{}, false
+	}
+	return *t.resolvedAnswerExtractor, true
+}
+
 // shouldResolveSystemPrompt determines if system prompt should be resolved for this task
 // based on the SystemPrompt configuration.
 func (t Task) shouldResolveSystemPrompt(configuration SystemPrompt) bool {
@@ -934,6 +1028,15 @@ const (
 type FileOptions struct {
 	// ImageDetail controls the fidelity level for image files.
 	ImageDetail *ImageDetail `yaml:"image-detail" validate:"omitempty,oneof=auto low medium high original"`
+
+	// Pages restricts which pages of a paged document (e.g. PDF) the model should consider.
+	// The value is a comma-separated list of 1-based page numbers and inclusive ranges, e.g. "1-3,5".
+	// The whole document is always attached; the selection is passed to the model as an instruction.
+	Pages *string `yaml:"pages" validate:"omitempty"`
+
+	// Upload sends the file through the provider's Files API instead of inlining it into the request.
+	// Providers without a Files API, or that cannot reference uploaded files of this type, inline the file.
+	Upload *bool `yaml:"upload" validate:"omitempty"`
 }
 
 // MergeWith merges these file options with other options and returns the result.
@@ -943,11 +1046,71 @@ func (these FileOptions) MergeWith(other *FileOptions) FileOptions {
 
 	if other != nil {
 		setIfNotNil(&resolved.ImageDetail, other.ImageDetail)
+		setIfNotNil(&resolved.Pages, other.Pages)
+		setIfNotNil(&resolved.Upload, other.Upload)
 	}
 
 	return resolved
 }
 
+// UseUpload returns whether the file should be uploaded through the provider's Files API.
+func (these FileOptions) UseUpload() bool {
+	return these.Upload != nil && *these.Upload
+}
+
+// Validate checks that the file options are internally consistent.
+func (these FileOptions) Validate() error {
+	if these.Pages != nil {
+		if _, err := ParsePageRanges(*these.Pages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PageRange is an inclusive range of 1-based document page numbers.
+type PageRange struct {
+	// First is the first page in the range.
+	First int
+	// Last is the last page in the range.
+	Last int
+}
+
+// ParsePageRanges parses a page selection such as "1-3,5" into a list of page ranges.
+// Returns an error if the selection is empty, malformed, or contains a descending range.
+func ParsePageRanges(pages string) ([]PageRange, error) {
+	if strings.TrimSpace(pages) == "" {
+		return nil, fmt.Errorf("%w: pages must not be empty", ErrInvalidTaskProperty)
+	}
+
+	parsePage := func(value string) (int, error) {
+		page, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || page < 1 {
+			return 0, fmt.Errorf("%w: invalid page number '%s' in pages '%s'", ErrInvalidTaskProperty, value, pages)
+		}
+		return page, nil
+	}
+
+	var ranges []PageRange
+	for item := range strings.SplitSeq(pages, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		firstPage, err := parsePage(first)
+		if err != nil {
+			return nil, err
+		}
+		lastPage := firstPage
+		if isRange {
+			if lastPage, err = parsePage(last); err != nil {
+				return nil, err
+			} else if lastPage < firstPage {
+				return nil, fmt.Errorf("%w: descending page range '%s' in pages '%s'", ErrInvalidTaskProperty, item, pages)
+			}
+		}
+		ranges = append(ranges, PageRange{First: firstPage, Last: lastPage})
+	}
+	return ranges, nil
+}
+
 // SetBaseFilePath sets the base path for all local files in the task.
 // The resolved paths are validated to ensure they are accessible.
 func (t *Task) SetBaseFilePath(basePath string) error {
@@ -1044,6 +1207,134 @@ func (these JudgePrompt) MergeWith(other JudgePrompt) JudgePrompt {
 	return resolved
 }
 
+// DefaultAnswerMarker is the text that precedes the answer if the marker extractor sets no marker.
+const DefaultAnswerMarker = "Answer:"
+
+const (
+	// AnswerExtractorLastLine extracts the last non-blank line of the response.
+	AnswerExtractorLastLine = "last-line"
+	// AnswerExtractorBoxed extracts the content of the last \boxed{} expression in the response.
+	AnswerExtractorBoxed = "boxed"
+	// AnswerExtractorRegex extracts the first capture group of the last match of a regular expression,
+	// or the whole match if the expression has no groups.
+	AnswerExtractorRegex = "regex"
+	// AnswerExtractorMarker extracts the text that follows the last answer marker on the same line.
+	AnswerExtractorMarker = "marker"
+	// AnswerExtractorCodeBlock extracts the content of the last fenced code block in the response.
+	AnswerExtractorCodeBlock = "code-block"
+	// AnswerExtractorJSON extracts the last JSON object or array in the response.
+	AnswerExtractorJSON = "json"
+)
+
+// AnswerExtractor defines how the final answer is extracted from a response in unstructured
+// output mode, e.g. to ignore the reasoning that precedes the answer.
+type AnswerExtractor struct {
+	// Type selects the extraction rule, e.g. AnswerExtractorLastLine.
+	Type string `yaml:"type" validate:"required,oneof=last-line boxed regex marker code-block json"`
+
+	// Pattern is the regular expression used by the regex extractor.
+	Pattern string `yaml:"pattern,omitempty" validate:"required_if=Type regex"`
+
+	// Marker is the text that precedes the answer for the marker extractor.
+	// If empty, DefaultAnswerMarker is used.
+	Marker string `yaml:"marker,omitempty" validate:"omitempty"`
+}
+
+// CompilePattern compiles the regular expression of the regex extractor.
+func (e AnswerExtractor) CompilePattern() (*regexp.Regexp, error) {
+	return regexp.Compile(e.Pattern)
+}
+
+// GetMarker returns the answer marker of the marker extractor.
+func (e AnswerExtractor) GetMarker() string {
+	if e.Marker != "" {
+		return e.Marker
+	}
+	return DefaultAnswerMarker
+}
+
+// ToolExpectations defines assertions on the tool calls made while producing the answer
+// to a task. Tools are referenced by the names they are exposed to the model with, and
+// every recorded call attempt counts, including failed ones.
+type ToolExpectations struct {
+	// Required lists the tools that must be called at least once.
+	Required []string `yaml:"required,omitempty" validate:"omitempty,dive,required"`
+
+	// Forbidden lists the tools that must not be called.
+	Forbidden []string `yaml:"forbidden,omitempty" validate:"omitempty,dive,required"`
+
+	// Calls limits the number of calls of specific tools.
+	Calls []ToolCallCountExpectation `yaml:"calls,omitempty" validate:"omitempty,dive"`
+
+	// Arguments constrains the arguments passed to specific tools.
+	Arguments []ToolArgumentExpectation `yaml:"arguments,omitempty" validate:"omitempty,dive"`
+
+	// Order lists tools that must be called in the given order, not necessarily consecutively.
+	// A tool may be listed more than once to require repeated calls.
+	Order []string `yaml:"order,omitempty" validate:"omitempty,dive,required"`
+}
+
+// ToolCallCountExpectation limits the number of calls of a tool.
+type ToolCallCountExpectation struct {
+	// Tool is the name of the tool.
+	Tool string `yaml:"tool" validate:"required"`
+
+	// MinCalls is the minimum number of calls of the tool. If nil, there is no minimum.
+	MinCalls *int `yaml:"min-calls,omitempty" validate:"omitempty,min=0"`
+
+	// MaxCalls is the maximum number of calls of the tool. If nil, there is no maximum.
+	MaxCalls *int `yaml:"max-calls,omitempty" validate:"omitempty,min=0"`
+}
+
+// ToolArgumentExpectation constrains the arguments passed to a tool. The path selects values
+// from the JSON arguments of each call of the tool, and a call satisfies the expectation if
+// any selected value matches. If neither Equals nor Pattern is set, the path must select a value.
+type ToolArgumentExpectation struct {
+	// Tool is the name of the tool.
+	Tool string `yaml:"tool" validate:"required"`
+
+	// Path is the JSONPath expression selecting values from the arguments of a call,
+	// e.g. `$.expression` or `$.queries[*]`.
+	Path utils.JSONPath `yaml:"path"`
+
+	// Equals requires a selected value to be equal to this value.
+	Equals interface{} `yaml:"equals,omitempty"`
+
+	// Pattern requires a selected value to match this regular expression. Values other
+	// than strings are matched in their JSON encoding.
+	Pattern string `yaml:"pattern,omitempty"`
+
+	// All requires every call of the tool to satisfy the expectation, rather than at least one.
+	// The tool must be called at least once either way.
+	All bool `yaml:"all,omitempty"`
+}
+
+// CompilePattern compiles the regular expression of the expectation, or returns nil if it has none.
+func (e ToolArgumentExpectation) CompilePattern() (*regexp.Regexp, error) {
+	if e.Pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(e.Pattern)
+}
+
+// Validate checks that the expectations are consistent.
+func (e ToolExpectations) Validate() error {
+	for _, calls := range e.Calls {
+		if calls.MinCalls != nil && calls.MaxCalls != nil && *calls.MinCalls > *calls.MaxCalls {
+			return fmt.Errorf("%w: tool-expectations min-calls of tool '%s' exceed its max-calls", ErrInvalidTaskProperty, calls.Tool)
+		}
+	}
+	for _, argument := range e.Arguments {
+		if argument.Path.String() == "" {
+			return fmt.Errorf("%w: tool-expectations argument path of tool '%s' is required", ErrInvalidTaskProperty, argument.Tool)
+		}
+		if _, err := argument.CompilePattern(); err != nil {
+			return fmt.Errorf("%w: invalid tool-expectations argument pattern of tool '%s': %v", ErrInvalidTaskProperty, argument.Tool, err)
+		}
+	}
+	return nil
+}
+
 // ToolSelection represents the selection and configuration of a tool for a task.
 type ToolSelection struct {
 	// Name of the tool to select.
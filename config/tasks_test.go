@@ -1947,6 +1947,91 @@ func TestTask_ResolveMaxTurns(t *testing.T) {
 	}
 }
 
+func TestTask_ResolveTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		task         Task
+		defaultValue *time.Duration
+		want         time.Duration
+	}{
+		{
+			name:         "uses default when task override is nil",
+			task:         Task{},
+			defaultValue: testutils.Ptr(time.Minute),
+			want:         time.Minute,
+		},
+		{
+			name:         "task override takes precedence",
+			task:         Task{Timeout: testutils.Ptr(30 * time.Second)},
+			defaultValue: testutils.Ptr(time.Minute),
+			want:         30 * time.Second,
+		},
+		{
+			name: "no timeout by default",
+			task: Task{},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, time.Duration(0), tt.task.GetResolvedTimeout())
+
+			tt.task.ResolveTimeout(tt.defaultValue)
+
+			assert.Equal(t, tt.want, tt.task.GetResolvedTimeout())
+		})
+	}
+}
+
+func TestTask_ResolveAnswerExtractor(t *testing.T) {
+	marker := &AnswerExtractor{Type: AnswerExtractorMarker}
+	boxed := &AnswerExtractor{Type: AnswerExtractorBoxed}
+	tests := []struct {
+		name         string
+		task         Task
+		defaultValue *AnswerExtractor
+		want         *AnswerExtractor
+	}{
+		{
+			name:         "uses default when task override is nil",
+			task:         Task{},
+			defaultValue: marker,
+			want:         marker,
+		},
+		{
+			name:         "task override takes precedence",
+			task:         Task{AnswerExtractor: boxed},
+			defaultValue: marker,
+			want:         boxed,
+		},
+		{
+			name: "no extraction by default",
+			task: Task{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := tt.task.GetResolvedAnswerExtractor()
+			assert.False(t, ok)
+
+			tt.task.ResolveAnswerExtractor(tt.defaultValue)
+
+			got, ok := tt.task.GetResolvedAnswerExtractor()
+			if tt.want == nil {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, *tt.want, got)
+		})
+	}
+}
+
+func TestAnswerExtractor_GetMarker(t *testing.T) {
+	assert.Equal(t, DefaultAnswerMarker, AnswerExtractor{Type: AnswerExtractorMarker}.GetMarker())
+	assert.Equal(t, "Result:", AnswerExtractor{Type: AnswerExtractorMarker, Marker: "Result:"}.GetMarker())
+}
+
 func TestSystemPrompt_GetEnableFor(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -2135,6 +2220,90 @@ func TestValidateTaskConfiguration(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("invalid - file pages", func(t *testing.T) {
+		task := Task{
+			Name:                 "test",
+			Prompt:               "Summarize the report.",
+			ResponseResultFormat: NewResponseFormat("Summary"),
+			ExpectedResult:       utils.NewValueSet("summary"),
+			Files: []TaskFile{
+				{Name: "report", Options: &FileOptions{Pages: testutils.Ptr("3-1")}},
+			},
+		}
+
+		taskConfig := TaskConfig{
+			Tasks:       []Task{task},
+			FileOptions: FileOptions{Pages: testutils.Ptr("1")},
+		}
+		err := taskConfig.Validate()
+		require.ErrorIs(t, err, ErrInvalidTaskProperty)
+		assert.Contains(t, err.Error(), "invalid options for file 'report'")
+
+		taskConfig.Tasks[0].Files[0].Options = nil
+		assert.NoError(t, taskConfig.Validate())
+	})
+
+	t.Run("invalid - answer extractor pattern", func(t *testing.T) {
+		task := Task{
+			Name:                 "test",
+			Prompt:               "What is 2+2?",
+			ResponseResultFormat: NewResponseFormat("Number"),
+			ExpectedResult:       utils.NewValueSet("4"),
+			AnswerExtractor:      &AnswerExtractor{Type: AnswerExtractorRegex, Pattern: "ANSWER: ("},
+		}
+
+		taskConfig := TaskConfig{Tasks: []Task{task}}
+		err := taskConfig.Validate()
+		require.ErrorIs(t, err, ErrInvalidTaskProperty)
+		assert.Contains(t, err.Error(), "invalid answer-extractor pattern")
+
+		taskConfig.Tasks[0].AnswerExtractor = nil
+		taskConfig.AnswerExtractor = &AnswerExtractor{Type: AnswerExtractorRegex, Pattern: "ANSWER: ("}
+		require.ErrorIs(t, taskConfig.Validate(), ErrInvalidTaskProperty)
+
+		taskConfig.AnswerExtractor.Pattern = `ANSWER: (\d+)`
+		assert.NoError(t, taskConfig.Validate())
+	})
+
+	t.Run("invalid - tool expectations", func(t *testing.T) {
+		path := mustParseJSONPath(t, "$.expression")
+		task := Task{
+			Name:                 "test",
+			Prompt:               "What is 2+2?",
+			ResponseResultFormat: NewResponseFormat("Number"),
+			ExpectedResult:       utils.NewValueSet("4"),
+			ToolExpectations: &ToolExpectations{
+				Calls: []ToolCallCountExpectation{{Tool: "calculator", MinCalls: testutils.Ptr(3), MaxCalls: testutils.Ptr(2)}},
+			},
+		}
+
+		taskConfig := TaskConfig{Tasks: []Task{task}}
+		err := taskConfig.Validate()
+		require.ErrorIs(t, err, ErrInvalidTaskProperty)
+		assert.Contains(t, err.Error(), "min-calls of tool 'calculator' exceed its max-calls")
+
+		taskConfig.Tasks[0].ToolExpectations = &ToolExpectations{
+			Arguments: []ToolArgumentExpectation{{Tool: "calculator"}},
+		}
+		err = taskConfig.Validate()
+		require.ErrorIs(t, err, ErrInvalidTaskProperty)
+		assert.Contains(t, err.Error(), "argument path of tool 'calculator' is required")
+
+		taskConfig.Tasks[0].ToolExpectations = &ToolExpectations{
+			Arguments: []ToolArgumentExpectation{{Tool: "calculator", Path: path, Pattern: "2+("}},
+		}
+		err = taskConfig.Validate()
+		require.ErrorIs(t, err, ErrInvalidTaskProperty)
+		assert.Contains(t, err.Error(), "invalid tool-expectations argument pattern of tool 'calculator'")
+
+		taskConfig.Tasks[0].ToolExpectations = &ToolExpectations{
+			Required:  []string{"calculator"},
+			Calls:     []ToolCallCountExpectation{{Tool: "calculator", MinCalls: testutils.Ptr(1), MaxCalls: testutils.Ptr(1)}},
+			Arguments: []ToolArgumentExpectation{{Tool: "calculator", Path: path, Pattern: `^\d+\+\d+$`}},
+		}
+		assert.NoError(t, taskConfig.Validate())
+	})
+
 	t.Run("invalid - string format with object expected results", func(t *testing.T) {
 		task := Task{
 			Name:                 "test",
@@ -2475,6 +2644,18 @@ func TestFileOptions_MergeWith(t *testing.T) {
 			other:    &FileOptions{ImageDetail: testutils.Ptr(ImageDetailMedium)},
 			expected: FileOptions{ImageDetail: testutils.Ptr(ImageDetailMedium)},
 		},
+		{
+			name:     "pages are merged independently",
+			base:     FileOptions{ImageDetail: testutils.Ptr(ImageDetailLow), Pages: testutils.Ptr("1-2")},
+			other:    &FileOptions{Pages: testutils.Ptr("3")},
+			expected: FileOptions{ImageDetail: testutils.Ptr(ImageDetailLow), Pages: testutils.Ptr("3")},
+		},
+		{
+			name:     "other disables upload",
+			base:     FileOptions{Upload: testutils.Ptr(true)},
+			other:    &FileOptions{Upload: testutils.Ptr(false)},
+			expected: FileOptions{Upload: testutils.Ptr(false)},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2485,6 +2666,70 @@ func TestFileOptions_MergeWith(t *testing.T) {
 	}
 }
 
+func TestFileOptions_UseUpload(t *testing.T) {
+	assert.False(t, FileOptions{}.UseUpload())
+	assert.False(t, FileOptions{Upload: testutils.Ptr(false)}.UseUpload())
+	assert.True(t, FileOptions{Upload: testutils.Ptr(true)}.UseUpload())
+}
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    string
+		expected []PageRange
+		wantErr  string
+	}{
+		{
+			name:     "single page",
+			pages:    "4",
+			expected: []PageRange{{First: 4, Last: 4}},
+		},
+		{
+			name:     "ranges and pages",
+			pages:    "1-3, 5,7-7",
+			expected: []PageRange{{First: 1, Last: 3}, {First: 5, Last: 5}, {First: 7, Last: 7}},
+		},
+		{
+			name:    "empty",
+			pages:   " ",
+			wantErr: "pages must not be empty",
+		},
+		{
+			name:    "zero page",
+			pages:   "0-2",
+			wantErr: "invalid page number '0'",
+		},
+		{
+			name:    "not a number",
+			pages:   "1,x",
+			wantErr: "invalid page number 'x'",
+		},
+		{
+			name:    "open range",
+			pages:   "2-",
+			wantErr: "invalid page number ''",
+		},
+		{
+			name:    "descending range",
+			pages:   "5-2",
+			wantErr: "descending page range '5-2'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePageRanges(tt.pages)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrInvalidTaskProperty)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
 func TestTaskFile_ResolveFileOptions(t *testing.T) {
 	tests := []struct {
 		name           string
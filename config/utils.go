@@ -49,8 +49,11 @@ func LoadConfigFromFile(ctx context.Context, path string) (*Config, error) {
 		return cfg, fmt.Errorf("invalid configuration definition: %w", err)
 	}
 
-	// Validate tool parameters schemas.
+	// Validate tool settings and parameters schemas.
 	for _, tool := range cfg.Config.Tools {
+		if err := tool.Validate(); err != nil {
+			return cfg, fmt.Errorf("invalid tool configuration: invalid settings for tool '%s': %w", tool.Name, err)
+		}
 		if err := validateToolParameters(tool.Parameters); err != nil {
 			return cfg, fmt.Errorf("invalid tool configuration: invalid parameters for tool '%s': %w", tool.Name, err)
 		}
@@ -111,6 +114,8 @@ func LoadTasksFromFile(ctx context.Context, path string) (*Tasks, error) {
 		}
 		cfg.TaskConfig.Tasks[i].ResolveToolSelector(cfg.TaskConfig.ToolSelector)
 		cfg.TaskConfig.Tasks[i].ResolveMaxTurns(cfg.TaskConfig.MaxTurns)
+		cfg.TaskConfig.Tasks[i].ResolveTimeout(cfg.TaskConfig.Timeout)
+		cfg.TaskConfig.Tasks[i].ResolveAnswerExtractor(cfg.TaskConfig.AnswerExtractor)
 		for j := range cfg.TaskConfig.Tasks[i].Files {
 			cfg.TaskConfig.Tasks[i].Files[j].ResolveFileOptions(cfg.TaskConfig.FileOptions)
 		}
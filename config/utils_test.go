@@ -211,6 +211,73 @@ func TestLoadConfigFromFile(t *testing.T) {
           runs:
               - name: "Cape"
                 model: "Baby"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "adaptive rate limit without parallel requests",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          adaptive-rate-limit: true
+          client-config:
+              api-key: "a8b159e5-ee58-47c6-93d2-f31dcf068e8a"
+          runs:
+              - name: "Cape"
+                model: "Baby"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "circuit breaker without failure threshold",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          circuit-breaker:
+              probe-interval-seconds: 30
+          client-config:
+              api-key: "a8b159e5-ee58-47c6-93d2-f31dcf068e8a"
+          runs:
+              - name: "Cape"
+                model: "Baby"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "retry policy with unknown error class",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          retry-policy:
+              max-retry-attempts: 3
+              initial-delay-seconds: 1
+              retry-on: [rate-limit, bad-request]
+          client-config:
+              api-key: "a8b159e5-ee58-47c6-93d2-f31dcf068e8a"
+          runs:
+              - name: "Cape"
+                model: "Baby"
 `)),
 			},
 			wantErr: true,
@@ -271,6 +338,7 @@ func TestLoadConfigFromFile(t *testing.T) {
       runs:
           - name: "innovative"
             model: "Nevada"
+            execution-mode: batch
     - name: deepseek
       client-config:
           api-key: "b8d40c7c-b169-49a9-9a5c-291741e86daa"
@@ -364,6 +432,7 @@ func TestLoadConfigFromFile(t *testing.T) {
 									Name:                 "innovative",
 									Model:                "Nevada",
 									MaxRequestsPerMinute: 0,
+									ExecutionMode:        ExecutionModeBatch,
 								},
 							},
 							Disabled: false,
@@ -467,6 +536,7 @@ func TestLoadConfigFromFile(t *testing.T) {
                 disabled: true
                 model: "directional"
                 max-requests-per-minute: 3
+                max-concurrent-tasks: 4
                 disable-structured-output: true
                 model-parameters:
                     reasoning-effort: max
@@ -557,10 +627,12 @@ func TestLoadConfigFromFile(t *testing.T) {
           runs:
               - name: "Gemini"
                 model: "gemini-pro"
+                record-reasoning: true
                 model-parameters:
                     text-response-format: true
                     text-response-format-with-tools: true
                     thinking-level: high
+                    include-thoughts: true
                     media-resolution: medium
                     temperature: 0.7
                     top-p: 0.95
@@ -575,6 +647,12 @@ func TestLoadConfigFromFile(t *testing.T) {
               max-retry-attempts: 5
               initial-delay-seconds: 10
           max-parallel-requests-per-minute: 30
+          adaptive-rate-limit: true
+          max-concurrent-tasks: 8
+          circuit-breaker:
+              failure-threshold: 5
+              probe-interval-seconds: 60
+              max-probe-attempts: 3
           runs:
               - name: "Mistral"
                 model: "mistral-large"
@@ -588,9 +666,18 @@ func TestLoadConfigFromFile(t *testing.T) {
                     prompt-mode: reasoning
                     reasoning-effort: xhigh
                     safe-prompt: true
+                timeout: 15m
                 retry-policy:
                     max-retry-attempts: 3
                     initial-delay-seconds: 1
+                    max-delay-seconds: 60
+                    jitter: decorrelated
+                    max-total-seconds: 600
+                    retry-on: [rate-limit, server-error, parse-error]
+                response-recovery:
+                    repair: true
+                    coerce: true
+                    max-corrective-turns: 2
         - name: xai
           client-config:
               api-key: "b990bc70-169c-4de8-8dd1-fd4253527046"
@@ -677,6 +764,7 @@ func TestLoadConfigFromFile(t *testing.T) {
 									Name:                    "Sports",
 									Model:                   "directional",
 									MaxRequestsPerMinute:    3,
+									MaxConcurrentTasks:      4,
 									Disabled:                testutils.Ptr(true),
 									DisableStructuredOutput: true,
 									ModelParams: OpenAIModelParams{
@@ -820,10 +908,12 @@ func TestLoadConfigFromFile(t *testing.T) {
 									Name:                 "Gemini",
 									Model:                "gemini-pro",
 									MaxRequestsPerMinute: 0,
+									RecordReasoning:      true,
 									ModelParams: GoogleAIModelParams{
 										TextResponseFormat:          true,
 										TextResponseFormatWithTools: true,
 										ThinkingLevel:               testutils.Ptr("high"),
+										IncludeThoughts:             true,
 										MediaResolution:             testutils.Ptr("medium"),
 										Temperature:                 testutils.Ptr(float32(0.7)),
 										TopP:                        testutils.Ptr(float32(0.95)),
@@ -846,6 +936,13 @@ func TestLoadConfigFromFile(t *testing.T) {
 								InitialDelaySeconds: 10,
 							},
 							MaxParallelRequestsPerMinute: 30,
+							AdaptiveRateLimit:            true,
+							MaxConcurrentTasks:           8,
+							CircuitBreaker: &CircuitBreakerConfig{
+								FailureThreshold:     5,
+								ProbeIntervalSeconds: 60,
+								MaxProbeAttempts:     3,
+							},
 							Runs: []RunConfig{
 								{
 									Name:                 "Mistral",
@@ -862,9 +959,19 @@ func TestLoadConfigFromFile(t *testing.T) {
 										ReasoningEffort:  testutils.Ptr("xhigh"),
 										SafePrompt:       testutils.Ptr(true),
 									},
+									Timeout: testutils.Ptr(15 * time.Minute),
 									RetryPolicy: &RetryPolicy{
 										MaxRetryAttempts:    3,
 										InitialDelaySeconds: 1,
+										MaxDelaySeconds:     60,
+										Jitter:              RetryJitterDecorrelated,
+										MaxTotalSeconds:     600,
+										RetryOn:             []string{ErrorClassRateLimit, ErrorClassServerError, ErrorClassParseError},
+									},
+									ResponseRecovery: &ResponseRecoveryPolicy{
+										Repair:             true,
+										Coerce:             true,
+										MaxCorrectiveTurns: 2,
 									},
 								},
 							},
@@ -936,49 +1043,1140 @@ func TestLoadConfigFromFile(t *testing.T) {
 							Disabled: false,
 						},
 						{
-							Name: "moonshotai",
-							ClientConfig: MoonshotAIClientConfig{
-								APIKey:   "sk-moonshot-test-key",
-								Endpoint: "https://api.moonshot.6ee41fa3-1279-4f8c-a348-c9a00c6f5b06.ai/v1",
+							Name: "moonshotai",
+							ClientConfig: MoonshotAIClientConfig{
+								APIKey:   "sk-moonshot-test-key",
+								Endpoint: "https://api.moonshot.6ee41fa3-1279-4f8c-a348-c9a00c6f5b06.ai/v1",
+							},
+							MaxParallelRequestsPerMinute: 0,
+							Runs: []RunConfig{
+								{
+									Name:                 "Kimi K2",
+									Model:                "kimi-k2",
+									MaxRequestsPerMinute: 0,
+									TextOnly:             true,
+									ModelParams: MoonshotAIModelParams{
+										Temperature:      testutils.Ptr(float32(0.6)),
+										TopP:             testutils.Ptr(float32(0.9)),
+										MaxTokens:        testutils.Ptr(int32(2048)),
+										PresencePenalty:  testutils.Ptr(float32(0.1)),
+										FrequencyPenalty: testutils.Ptr(float32(0.2)),
+									},
+								},
+								{
+									Name:                 "Kimi K2.6",
+									Model:                "kimi-k2.6",
+									MaxRequestsPerMinute: 0,
+									ModelParams: MoonshotAIModelParams{
+										MaxTokens:        testutils.Ptr(int32(32000)),
+										Thinking:         testutils.Ptr("enabled"),
+										PreserveThinking: testutils.Ptr("all"),
+									},
+								},
+								{
+									Name:                 "Kimi K3",
+									Model:                "kimi-k3",
+									MaxRequestsPerMinute: 0,
+									ModelParams: MoonshotAIModelParams{
+										ReasoningEffort:     testutils.Ptr("max"),
+										MaxCompletionTokens: testutils.Ptr(int32(65536)),
+										ResponseFormat:      testutils.Ptr(ModelResponseFormatText),
+										Stream:              true,
+									},
+								},
+							},
+							Disabled: false,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with tool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python-code-executor
+          image: python:3.9-slim
+          description: "Executes Python code in a container"
+          parameters:
+            code:
+              type: string
+              description: "Python code to execute"
+          command: ["python", "-c"]
+          parameter-files:
+            code: "/tmp/code.py"
+          auxiliary-dir: "/tmp/data"
+          shared-dir: "/tmp/shared"
+          env:
+            PYTHONPATH: "/usr/local/lib/python3.9"
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							MaxParallelRequestsPerMinute: 0,
+							Runs: []RunConfig{
+								{
+									Name:                 "test-run",
+									Model:                "gpt-4",
+									MaxRequestsPerMinute: 0,
+								},
+							},
+							Disabled: false,
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "python-code-executor",
+							Image:       "python:3.9-slim",
+							Description: "Executes Python code in a container",
+							Parameters: map[string]interface{}{
+								"code": map[string]interface{}{
+									"type":        "string",
+									"description": "Python code to execute",
+								},
+							},
+							Command:        []string{"python", "-c"},
+							ParameterFiles: map[string]string{"code": "/tmp/code.py"},
+							AuxiliaryDir:   "/tmp/data",
+							SharedDir:      "/tmp/shared",
+							Env:            map[string]string{"PYTHONPATH": "/usr/local/lib/python3.9"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with invalid tool parameter schema",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: invalid-tool
+          image: python:3.9-slim
+          description: "Invalid tool"
+          parameters:
+            code:
+              type: string
+              description: "Python code to execute"
+            required: "code"
+          command: ["python", "-c"]
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with duplicate tool names",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: duplicate-tool
+          image: python:3.9-slim
+          description: "First tool"
+          parameters:
+            code:
+              type: string
+        - name: duplicate-tool
+          image: python:3.9-slim
+          description: "Second tool"
+          parameters:
+            code:
+              type: string
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with mcp tool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: filesystem
+          type: mcp
+          command: ["npx", "-y", "@modelcontextprotocol/server-filesystem", "/data"]
+          mcp:
+            transport: stdio
+            startup-timeout: 1m
+            tools: ["read_file", "list_directory"]
+        - name: search
+          type: mcp
+          mcp:
+            transport: streamable-http
+            url: "http://localhost:8080/mcp"
+            headers:
+              Authorization: "Bearer token"
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:    "filesystem",
+							Type:    ToolTypeMCP,
+							Command: []string{"npx", "-y", "@modelcontextprotocol/server-filesystem", "/data"},
+							MCP: &MCPServerConfig{
+								Transport:      MCPTransportStdio,
+								StartupTimeout: testutils.Ptr(1 * time.Minute),
+								Tools:          []string{"read_file", "list_directory"},
+							},
+						},
+						{
+							Name: "search",
+							Type: ToolTypeMCP,
+							MCP: &MCPServerConfig{
+								Transport: MCPTransportStreamableHTTP,
+								URL:       "http://localhost:8080/mcp",
+								Headers:   map[string]string{"Authorization": "Bearer token"},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with mcp tool without mcp settings",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: filesystem
+          type: mcp
+          command: ["mcp-server"]
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with mock tool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: weather
+          type: mock
+          description: "Returns the current weather in a city"
+          parameters:
+            city:
+              type: string
+          mock:
+            lookup:
+              argument: $.city
+              responses:
+                Paris: {"temperature": 21}
+                Oslo: "unavailable"
+              default: {"temperature": 15}
+        - name: clock
+          type: mock
+          description: "Returns the current time"
+          parameters: {}
+          mock:
+            sequence: ["10:00", "10:05"]
+        - name: echo
+          type: mock
+          description: "Returns a fixed greeting"
+          parameters: {}
+          mock:
+            output: "hello"
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "weather",
+							Type:        ToolTypeMock,
+							Description: "Returns the current weather in a city",
+							Parameters: map[string]interface{}{
+								"city": map[string]interface{}{
+									"type": "string",
+								},
+							},
+							Mock: &MockToolConfig{
+								Lookup: &MockLookupConfig{
+									Argument: mustParseJSONPath(t, "$.city"),
+									Responses: map[string]interface{}{
+										"Paris": map[string]interface{}{"temperature": 21},
+										"Oslo":  "unavailable",
+									},
+									Default: map[string]interface{}{"temperature": 15},
+								},
+							},
+						},
+						{
+							Name:        "clock",
+							Type:        ToolTypeMock,
+							Description: "Returns the current time",
+							Parameters:  map[string]interface{}{},
+							Mock: &MockToolConfig{
+								Sequence: []interface{}{"10:00", "10:05"},
+							},
+						},
+						{
+							Name:        "echo",
+							Type:        ToolTypeMock,
+							Description: "Returns a fixed greeting",
+							Parameters:  map[string]interface{}{},
+							Mock: &MockToolConfig{
+								Output: "hello",
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with mock tool with several kinds of responses",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: echo
+          type: mock
+          description: "Returns a fixed greeting"
+          parameters: {}
+          mock:
+            output: "hello"
+            sequence: ["hi"]
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with mock tool without mock settings",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: echo
+          type: mock
+          description: "Returns a fixed greeting"
+          parameters: {}
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with http tool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: weather
+          type: http
+          description: "Returns the current weather in a city"
+          parameters:
+            city:
+              type: string
+          http:
+            url: "https://weather.internal.example.com/v1/current"
+            headers:
+              Authorization: "Bearer token"
+            allowed-hosts: ["weather.internal.example.com"]
+            max-response-bytes: 4096
+        - name: geocode
+          type: http
+          description: "Returns the coordinates of a city"
+          parameters:
+            city:
+              type: string
+          http:
+            url: "http://localhost:8080/geocode"
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "weather",
+							Type:        ToolTypeHTTP,
+							Description: "Returns the current weather in a city",
+							Parameters: map[string]interface{}{
+								"city": map[string]interface{}{
+									"type": "string",
+								},
+							},
+							HTTP: &HTTPToolConfig{
+								URL:              "https://weather.internal.example.com/v1/current",
+								Headers:          map[string]string{"Authorization": "Bearer token"},
+								AllowedHosts:     []string{"weather.internal.example.com"},
+								MaxResponseBytes: testutils.Ptr(int64(4096)),
+							},
+						},
+						{
+							Name:        "geocode",
+							Type:        ToolTypeHTTP,
+							Description: "Returns the coordinates of a city",
+							Parameters: map[string]interface{}{
+								"city": map[string]interface{}{
+									"type": "string",
+								},
+							},
+							HTTP: &HTTPToolConfig{
+								URL: "http://localhost:8080/geocode",
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with http tool host not allowed",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: weather
+          type: http
+          description: "Returns the current weather in a city"
+          parameters: {}
+          http:
+            url: "https://weather.example.com/v1/current"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with http tool settings on mock tool",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: weather
+          type: mock
+          description: "Returns the current weather in a city"
+          parameters: {}
+          mock:
+            output: "sunny"
+          http:
+            url: "http://localhost:8080/weather"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool image build",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: analyzer
+          image: "mindtrial/analyzer:latest"
+          description: "Analyzes data"
+          parameters: {}
+          command: ["analyze"]
+          build:
+            context: "tools/analyzer"
+            dockerfile: "docker/Dockerfile"
+            args:
+              BASE_IMAGE: "python:3.12-slim"
+            content-hash-tag: true
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "analyzer",
+							Image:       "mindtrial/analyzer:latest",
+							Description: "Analyzes data",
+							Parameters:  map[string]interface{}{},
+							Command:     []string{"analyze"},
+							Build: &ToolBuildConfig{
+								Context:        "tools/analyzer",
+								Dockerfile:     "docker/Dockerfile",
+								Args:           map[string]string{"BASE_IMAGE": "python:3.12-slim"},
+								ContentHashTag: true,
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with tool image build on subprocess tool",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: analyzer
+          type: subprocess
+          description: "Analyzes data"
+          parameters: {}
+          command: ["analyze"]
+          build:
+            context: "tools/analyzer"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool image build dockerfile outside context",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: analyzer
+          image: "mindtrial/analyzer:latest"
+          description: "Analyzes data"
+          parameters: {}
+          command: ["analyze"]
+          build:
+            context: "tools/analyzer"
+            dockerfile: "../Dockerfile"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool image build without context",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: analyzer
+          image: "mindtrial/analyzer:latest"
+          description: "Analyzes data"
+          parameters: {}
+          command: ["analyze"]
+          build:
+            dockerfile: "Dockerfile"
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with stdio mcp tool without command",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: filesystem
+          type: mcp
+          mcp:
+            transport: stdio
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with streamable http mcp tool without url",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: search
+          type: mcp
+          mcp:
+            transport: streamable-http
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with mcp tool with unknown transport",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: search
+          type: mcp
+          command: ["mcp-server"]
+          mcp:
+            transport: sse
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with docker tool without image",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python-code-executor
+          description: "Executes Python code in a container"
+          parameters:
+            code:
+              type: string
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with docker tool with mcp settings",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python-code-executor
+          image: python:3.9-slim
+          description: "Executes Python code in a container"
+          parameters:
+            code:
+              type: string
+          mcp:
+            transport: stdio
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with subprocess tool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: shell
+          type: subprocess
+          description: "Runs a shell script"
+          parameters:
+            script:
+              type: string
+          parameter-files:
+            script: /sandbox/script.sh
+          command: ["sh", "/sandbox/script.sh"]
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "shell",
+							Type:        ToolTypeSubprocess,
+							Description: "Runs a shell script",
+							Parameters: map[string]interface{}{
+								"script": map[string]interface{}{
+									"type": "string",
+								},
+							},
+							ParameterFiles: map[string]string{"script": "/sandbox/script.sh"},
+							Command:        []string{"sh", "/sandbox/script.sh"},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with subprocess tool without command",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: shell
+          type: subprocess
+          description: "Runs a shell script"
+          parameters:
+            script:
+              type: string
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool session config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: shell
+          image: alpine:latest
+          description: "Runs a shell command in a persistent workspace"
+          parameters:
+            command:
+              type: string
+          parameter-files:
+            command: /tmp/command.sh
+          command: ["sh", "/tmp/command.sh"]
+          session:
+            workspace-dir: /workspace
+            keep-alive-command: ["tail", "-f", "/dev/null"]
+            capture-workspace: true
+            max-capture-bytes: 4096
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
+							},
+							Runs: []RunConfig{
+								{
+									Name:  "test-run",
+									Model: "gpt-4",
+								},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "shell",
+							Image:       "alpine:latest",
+							Description: "Runs a shell command in a persistent workspace",
+							Parameters: map[string]interface{}{
+								"command": map[string]interface{}{
+									"type": "string",
+								},
+							},
+							ParameterFiles: map[string]string{"command": "/tmp/command.sh"},
+							Command:        []string{"sh", "/tmp/command.sh"},
+							Session: &ToolSessionConfig{
+								WorkspaceDir:     "/workspace",
+								KeepAliveCommand: []string{"tail", "-f", "/dev/null"},
+								CaptureWorkspace: true,
+								MaxCaptureBytes:  testutils.Ptr(int64(4096)),
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "config with mcp tool session",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: search
+          type: mcp
+          command: ["mcp-search"]
+          mcp:
+            transport: stdio
+          session:
+            workspace-dir: /workspace
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool pool config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
+          parameters:
+            code:
+              type: string
+          command: ["python", "/app/main.py"]
+          pool:
+            size: 2
+            health-check-command: ["python", "--version"]
+`)),
+			},
+			want: &Config{
+				Config: AppConfig{
+					TaskSource: "tasks.yaml",
+					OutputDir:  ".",
+					Providers: []ProviderConfig{
+						{
+							Name: "openai",
+							ClientConfig: OpenAIClientConfig{
+								APIKey: "test-key",
 							},
-							MaxParallelRequestsPerMinute: 0,
 							Runs: []RunConfig{
 								{
-									Name:                 "Kimi K2",
-									Model:                "kimi-k2",
-									MaxRequestsPerMinute: 0,
-									TextOnly:             true,
-									ModelParams: MoonshotAIModelParams{
-										Temperature:      testutils.Ptr(float32(0.6)),
-										TopP:             testutils.Ptr(float32(0.9)),
-										MaxTokens:        testutils.Ptr(int32(2048)),
-										PresencePenalty:  testutils.Ptr(float32(0.1)),
-										FrequencyPenalty: testutils.Ptr(float32(0.2)),
-									},
-								},
-								{
-									Name:                 "Kimi K2.6",
-									Model:                "kimi-k2.6",
-									MaxRequestsPerMinute: 0,
-									ModelParams: MoonshotAIModelParams{
-										MaxTokens:        testutils.Ptr(int32(32000)),
-										Thinking:         testutils.Ptr("enabled"),
-										PreserveThinking: testutils.Ptr("all"),
-									},
+									Name:  "test-run",
+									Model: "gpt-4",
 								},
-								{
-									Name:                 "Kimi K3",
-									Model:                "kimi-k3",
-									MaxRequestsPerMinute: 0,
-									ModelParams: MoonshotAIModelParams{
-										ReasoningEffort:     testutils.Ptr("max"),
-										MaxCompletionTokens: testutils.Ptr(int32(65536)),
-										ResponseFormat:      testutils.Ptr(ModelResponseFormatText),
-										Stream:              true,
-									},
+							},
+						},
+					},
+					Tools: []ToolConfig{
+						{
+							Name:        "python",
+							Image:       "python:latest",
+							Description: "Runs Python code",
+							Parameters: map[string]interface{}{
+								"code": map[string]interface{}{
+									"type": "string",
 								},
 							},
-							Disabled: false,
+							Command: []string{"python", "/app/main.py"},
+							Pool: &ToolPoolConfig{
+								Size:               2,
+								HealthCheckCommand: []string{"python", "--version"},
+							},
 						},
 					},
 				},
@@ -986,7 +2184,7 @@ func TestLoadConfigFromFile(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "config with tool config",
+			name: "config with tool pool without size",
 			args: args{
 				ctx: context.Background(),
 				path: createMockFile(t,
@@ -1002,20 +2200,109 @@ func TestLoadConfigFromFile(t *testing.T) {
               - name: "test-run"
                 model: "gpt-4"
     tools:
-        - name: python-code-executor
-          image: python:3.9-slim
-          description: "Executes Python code in a container"
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
           parameters:
             code:
               type: string
-              description: "Python code to execute"
-          command: ["python", "-c"]
-          parameter-files:
-            code: "/tmp/code.py"
-          auxiliary-dir: "/tmp/data"
-          shared-dir: "/tmp/shared"
-          env:
-            PYTHONPATH: "/usr/local/lib/python3.9"
+          command: ["python", "/app/main.py"]
+          pool:
+            health-check-command: ["python", "--version"]
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool pool and session",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
+          parameters:
+            code:
+              type: string
+          command: ["python", "/app/main.py"]
+          pool:
+            size: 2
+          session:
+            workspace-dir: /workspace
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool pool and shared directory",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
+          parameters:
+            code:
+              type: string
+          command: ["python", "/app/main.py"]
+          shared-dir: /app/shared
+          pool:
+            size: 2
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with tool artifacts config",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
+          parameters:
+            code:
+              type: string
+          command: ["python", "/app/main.py"]
+          artifacts:
+            paths: ["/app/out", "/tmp/chart.png"]
+            max-bytes: 1048576
 `)),
 			},
 			want: &Config{
@@ -1028,33 +2315,29 @@ func TestLoadConfigFromFile(t *testing.T) {
 							ClientConfig: OpenAIClientConfig{
 								APIKey: "test-key",
 							},
-							MaxParallelRequestsPerMinute: 0,
 							Runs: []RunConfig{
 								{
-									Name:                 "test-run",
-									Model:                "gpt-4",
-									MaxRequestsPerMinute: 0,
+									Name:  "test-run",
+									Model: "gpt-4",
 								},
 							},
-							Disabled: false,
 						},
 					},
 					Tools: []ToolConfig{
 						{
-							Name:        "python-code-executor",
-							Image:       "python:3.9-slim",
-							Description: "Executes Python code in a container",
+							Name:        "python",
+							Image:       "python:latest",
+							Description: "Runs Python code",
 							Parameters: map[string]interface{}{
 								"code": map[string]interface{}{
-									"type":        "string",
-									"description": "Python code to execute",
+									"type": "string",
 								},
 							},
-							Command:        []string{"python", "-c"},
-							ParameterFiles: map[string]string{"code": "/tmp/code.py"},
-							AuxiliaryDir:   "/tmp/data",
-							SharedDir:      "/tmp/shared",
-							Env:            map[string]string{"PYTHONPATH": "/usr/local/lib/python3.9"},
+							Command: []string{"python", "/app/main.py"},
+							Artifacts: &ToolArtifactsConfig{
+								Paths:    []string{"/app/out", "/tmp/chart.png"},
+								MaxBytes: testutils.Ptr(int64(1048576)),
+							},
 						},
 					},
 				},
@@ -1062,7 +2345,7 @@ func TestLoadConfigFromFile(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "config with invalid tool parameter schema",
+			name: "config with relative tool artifact path",
 			args: args{
 				ctx: context.Background(),
 				path: createMockFile(t,
@@ -1078,21 +2361,21 @@ func TestLoadConfigFromFile(t *testing.T) {
               - name: "test-run"
                 model: "gpt-4"
     tools:
-        - name: invalid-tool
-          image: python:3.9-slim
-          description: "Invalid tool"
+        - name: python
+          image: python:latest
+          description: "Runs Python code"
           parameters:
             code:
               type: string
-              description: "Python code to execute"
-            required: "code"
-          command: ["python", "-c"]
+          command: ["python", "/app/main.py"]
+          artifacts:
+            paths: ["out/chart.png"]
 `)),
 			},
 			wantErr: true,
 		},
 		{
-			name: "config with duplicate tool names",
+			name: "config with subprocess tool artifact path outside of kept directories",
 			args: args{
 				ctx: context.Background(),
 				path: createMockFile(t,
@@ -1108,18 +2391,44 @@ func TestLoadConfigFromFile(t *testing.T) {
               - name: "test-run"
                 model: "gpt-4"
     tools:
-        - name: duplicate-tool
-          image: python:3.9-slim
-          description: "First tool"
-          parameters:
-            code:
-              type: string
-        - name: duplicate-tool
-          image: python:3.9-slim
-          description: "Second tool"
+        - name: python
+          type: subprocess
+          description: "Runs Python code"
           parameters:
             code:
               type: string
+          command: ["python3", "-c", "print(1)"]
+          shared-dir: /shared
+          artifacts:
+            paths: ["/shared/out", "/tmp/chart.png"]
+`)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "config with mcp tool artifacts",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`config:
+    task-source: "tasks.yaml"
+    output-dir: "."
+    providers:
+        - name: openai
+          client-config:
+              api-key: "test-key"
+          runs:
+              - name: "test-run"
+                model: "gpt-4"
+    tools:
+        - name: filesystem
+          type: mcp
+          command: ["npx", "server-filesystem"]
+          mcp:
+            transport: stdio
+          artifacts:
+            paths: ["/tmp/out"]
 `)),
 			},
 			wantErr: true,
@@ -1454,12 +2763,20 @@ func TestLoadTasksFromFile(t *testing.T) {
 						`task-config:
     disabled: true
     max-turns: 50
+    timeout: 10m
+    answer-extractor:
+        type: marker
     file-options:
         image-detail: high
+        upload: true
     tasks:
         - name: "Books neural Automotive"
           disabled: false
           max-turns: 150
+          timeout: 90s
+          answer-extractor:
+              type: regex
+              pattern: 'ANSWER: (\w+)'
           suite: "core-suite"
           category: "reasoning"
           difficulty: "hard"
@@ -1484,6 +2801,7 @@ func TestLoadTasksFromFile(t *testing.T) {
               type: "text"
               options:
                   image-detail: original
+                  pages: "2-4"
             - name: "remote-file"
               uri: "http://example.com/file.txt"
               type: "text"`)),
@@ -1492,8 +2810,13 @@ func TestLoadTasksFromFile(t *testing.T) {
 				TaskConfig: TaskConfig{
 					Disabled: true,
 					MaxTurns: 50,
+					Timeout:  testutils.Ptr(10 * time.Minute),
+					AnswerExtractor: &AnswerExtractor{
+						Type: AnswerExtractorMarker,
+					},
 					FileOptions: FileOptions{
 						ImageDetail: testutils.Ptr(ImageDetailHigh),
+						Upload:      testutils.Ptr(true),
 					},
 					Tasks: []Task{
 						{
@@ -1506,13 +2829,17 @@ func TestLoadTasksFromFile(t *testing.T) {
 							Difficulty:           "hard",
 							Tags:                 []string{"nightly", "regression"},
 							Files: []TaskFile{
-								mockTaskFileWithOptions(t, "local-file", "path/to/file.txt", "text", &FileOptions{ImageDetail: testutils.Ptr(ImageDetailOriginal)}, FileOptions{ImageDetail: testutils.Ptr(ImageDetailHigh)}),
-								mockTaskFileWithOptions(t, "remote-file", "http://example.com/file.txt", "text", nil, FileOptions{ImageDetail: testutils.Ptr(ImageDetailHigh)}),
+								mockTaskFileWithOptions(t, "local-file", "path/to/file.txt", "text", &FileOptions{ImageDetail: testutils.Ptr(ImageDetailOriginal), Pages: testutils.Ptr("2-4")}, FileOptions{ImageDetail: testutils.Ptr(ImageDetailHigh), Upload: testutils.Ptr(true)}),
+								mockTaskFileWithOptions(t, "remote-file", "http://example.com/file.txt", "text", nil, FileOptions{ImageDetail: testutils.Ptr(ImageDetailHigh), Upload: testutils.Ptr(true)}),
 							},
-							Disabled:             testutils.Ptr(false),
-							MaxTurns:             testutils.Ptr(150),
-							resolvedSystemPrompt: "Provide the final answer in exactly this format: Sed unde non.\nVoluptatem quia voluptate id ipsum est rerum quisquam modi pariatur.",
-							resolvedMaxTurns:     150,
+							Disabled:                testutils.Ptr(false),
+							MaxTurns:                testutils.Ptr(150),
+							Timeout:                 testutils.Ptr(90 * time.Second),
+							AnswerExtractor:         &AnswerExtractor{Type: AnswerExtractorRegex, Pattern: `ANSWER: (\w+)`},
+							resolvedSystemPrompt:    "Provide the final answer in exactly this format: Sed unde non.\nVoluptatem quia voluptate id ipsum est rerum quisquam modi pariatur.",
+							resolvedMaxTurns:        150,
+							resolvedTimeout:         90 * time.Second,
+							resolvedAnswerExtractor: &AnswerExtractor{Type: AnswerExtractorRegex, Pattern: `ANSWER: (\w+)`},
 						},
 					},
 				},
@@ -1821,6 +3148,79 @@ func TestLoadTasksFromFile(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid file with tool expectations",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`task-config:
+    tasks:
+        - name: "Task with tool expectations"
+          prompt: "Calculate 2 + 2"
+          response-result-format: "Result"
+          expected-result: "4"
+          tool-expectations:
+              required: [calculator]
+              forbidden: [python-code-executor]
+              calls:
+                  - tool: calculator
+                    min-calls: 1
+                    max-calls: 2
+              arguments:
+                  - tool: calculator
+                    path: $.expression
+                    equals: "2+2"
+                  - tool: calculator
+                    path: $.precision
+                    pattern: '^\d+$'
+                    all: true
+              order: [search, calculator]`)),
+			},
+			want: &Tasks{
+				TaskConfig: TaskConfig{
+					Tasks: []Task{
+						{
+							Name:                 "Task with tool expectations",
+							Prompt:               "Calculate 2 + 2",
+							ResponseResultFormat: NewResponseFormat("Result"),
+							ExpectedResult:       utils.NewValueSet("4"),
+							ToolExpectations: &ToolExpectations{
+								Required:  []string{"calculator"},
+								Forbidden: []string{"python-code-executor"},
+								Calls:     []ToolCallCountExpectation{{Tool: "calculator", MinCalls: testutils.Ptr(1), MaxCalls: testutils.Ptr(2)}},
+								Arguments: []ToolArgumentExpectation{
+									{Tool: "calculator", Path: mustParseJSONPath(t, "$.expression"), Equals: "2+2"},
+									{Tool: "calculator", Path: mustParseJSONPath(t, "$.precision"), Pattern: `^\d+$`, All: true},
+								},
+								Order: []string{"search", "calculator"},
+							},
+							resolvedSystemPrompt: "Provide the final answer in exactly this format: Result",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "task with invalid tool expectations path",
+			args: args{
+				ctx: context.Background(),
+				path: createMockFile(t,
+					[]byte(
+						`task-config:
+    tasks:
+        - name: "Task with tool expectations"
+          prompt: "Calculate 2 + 2"
+          response-result-format: "Result"
+          expected-result: "4"
+          tool-expectations:
+              arguments:
+                  - tool: calculator
+                    path: expression`)),
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2753,6 +4153,40 @@ func TestGetRunsResolved(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "inherited and explicit task concurrency",
+			pc: ProviderConfig{
+				Name:               "test-provider",
+				MaxConcurrentTasks: 8,
+				Runs: []RunConfig{
+					{
+						Name:  "run1",
+						Model: "model1",
+					},
+					{
+						Name:               "run2",
+						Model:              "model2",
+						MaxConcurrentTasks: 2,
+					},
+				},
+			},
+			want: []RunConfig{
+				{
+					Name:               "run1",
+					Model:              "model1",
+					Disabled:           testutils.Ptr(false),
+					RetryPolicy:        &RetryPolicy{},
+					MaxConcurrentTasks: 8,
+				},
+				{
+					Name:               "run2",
+					Model:              "model2",
+					Disabled:           testutils.Ptr(false),
+					RetryPolicy:        &RetryPolicy{},
+					MaxConcurrentTasks: 2,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2799,3 +4233,23 @@ func TestAlibabaClientConfig_GetEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func mustParseJSONPath(t *testing.T, expr string) utils.JSONPath {
+	path, err := utils.ParseJSONPath(expr)
+	require.NoError(t, err)
+	return path
+}
+
+func TestHTTPToolConfig_IsHostAllowed(t *testing.T) {
+	defaults := HTTPToolConfig{URL: "http://localhost:8080"}
+	assert.True(t, defaults.IsHostAllowed("localhost"))
+	assert.True(t, defaults.IsHostAllowed("127.0.0.1"))
+	assert.True(t, defaults.IsHostAllowed("::1"))
+	assert.False(t, defaults.IsHostAllowed("example.com"))
+
+	custom := HTTPToolConfig{URL: "http://example.com", AllowedHosts: []string{"Example.com", "[fd00::1]"}}
+	assert.True(t, custom.IsHostAllowed("example.com"))
+	assert.True(t, custom.IsHostAllowed("fd00::1"))
+	assert.False(t, custom.IsHostAllowed("localhost"))
+	assert.False(t, custom.IsHostAllowed("api.example.com"))
+}
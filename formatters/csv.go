@@ -7,39 +7,95 @@
 package formatters
 
 import (
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/petmal/mindtrial/pkg/utils"
 	"github.com/petmal/mindtrial/runners"
 )
 
-// NewCSVFormatter creates a new formatter that outputs results in CSV format.
-func NewCSVFormatter() Formatter {
-	return &csvFormatter{}
+var (
+	// errLegacyCSVLayout indicates a CSV document written by an older version whose columns
+	// do not carry enough information to restore the results.
+	errLegacyCSVLayout = errors.New("CSV was produced by an older version with an unsupported column layout")
+	// errUnexpectedCSVHeader indicates a CSV document whose header is not a results header.
+	errUnexpectedCSVHeader = errors.New("unexpected CSV header")
+)
+
+// csvHeaders lists the CSV columns in the order they are written.
+// New columns must only ever be appended, so that older documents can be recognized
+// by their header being a prefix of this one.
+var csvHeaders = []string{"TraceID", "Provider", "Run", "Task", "Status", "DurationMS", "Answer", "Details", "Suite", "Category", "Difficulty", "Tags", "Got", "Want", "TimeToFirstTokenMS", "OutputTokensPerSecond", "ToolTimeMS", "Timing"}
+
+// Column indices of the values needed to restore results from a CSV document.
+const (
+	csvColTraceID = iota
+	csvColProvider
+	csvColRun
+	csvColTask
+	csvColStatus
+	csvColDurationMS
+	_ // Answer is derived from Got and Details.
+	csvColDetails
+	csvColSuite
+	csvColCategory
+	csvColDifficulty
+	csvColTags
+	csvColGot
+	csvColWant
+	_ // TimeToFirstTokenMS is derived from Timing.
+	_ // OutputTokensPerSecond is derived from Timing.
+	_ // ToolTimeMS is derived from Timing.
+	csvColTiming
+)
+
+// csvMinColumns is the number of leading columns a CSV document must have to be read.
+// Documents written before the timing columns were added lack only optional values.
+const csvMinColumns = csvColWant + 1
+
+// NewCSVCodec creates a new codec that reads and writes results in CSV format.
+// Durations are stored with millisecond precision.
+func NewCSVCodec() Codec {
+	return &csvCodec{}
 }
 
-type csvFormatter struct{}
+type csvCodec struct{}
 
-func (f csvFormatter) FileExt() string {
+func (c csvCodec) FileExt() string {
 	return "csv"
 }
 
-func (f csvFormatter) Write(results runners.Results, out io.Writer) error {
+func (c csvCodec) Write(results runners.Results, out io.Writer) error {
 	writer := csv.NewWriter(out)
 	defer writer.Flush()
 
-	headers := []string{"TraceID", "Provider", "Run", "Task", "Status", "DurationMS", "Answer", "Details", "Suite", "Category", "Difficulty", "Tags"}
-	if err := writer.Write(headers); err != nil {
+	if err := writer.Write(csvHeaders); err != nil {
 		return fmt.Errorf("%w: %v", ErrPrintResults, err)
 	}
 
 	return ForEachOrdered(results, func(_ string, runResults []runners.RunResult) error {
 		for _, result := range runResults {
-			row := []string{result.TraceID, result.Provider, result.Run, result.Task, ToStatus(result.Kind), strconv.FormatInt(RoundToMS(result.Duration).Milliseconds(), 10), formatAnswerText(result), utils.ToString(newDetailsView(result.Details)), result.TaskMetadata.Suite, result.TaskMetadata.Category, result.TaskMetadata.Difficulty, strings.Join(result.TaskMetadata.Tags, ",")}
+			got, err := json.Marshal(result.Got)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrPrintResults, err)
+			}
+			want, err := json.Marshal(result.Want)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrPrintResults, err)
+			}
+			timing, err := csvTimingColumns(result.Timing)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrPrintResults, err)
+			}
+			row := append([]string{result.TraceID, result.Provider, result.Run, result.Task, ToStatus(result.Kind), strconv.FormatInt(RoundToMS(result.Duration).Milliseconds(), 10), formatAnswerText(result), utils.ToString(newDetailsView(result.Details)), result.TaskMetadata.Suite, result.TaskMetadata.Category, result.TaskMetadata.Difficulty, strings.Join(result.TaskMetadata.Tags, ","), string(got), string(want)}, timing...)
 			if err := writer.Write(row); err != nil {
 				return fmt.Errorf("%w: %v", ErrPrintResults, err)
 			}
@@ -47,3 +103,130 @@ func (f csvFormatter) Write(results runners.Results, out io.Writer) error {
 		return nil
 	})
 }
+
+func (c csvCodec) Read(in io.Reader) (runners.Results, error) {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1 // the header is validated explicitly below
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReadResults, err)
+	}
+	if err := validateCSVHeader(header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReadResults, err)
+	}
+
+	results := runners.Results{}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrReadResults, err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("%w: line %d: expected %d columns but got %d", ErrReadResults, line, len(header), len(row))
+		}
+		result, err := fromCSVRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrReadResults, line, err)
+		}
+		results[result.Provider] = append(results[result.Provider], result)
+	}
+	return results, nil
+}
+
+// validateCSVHeader checks that the header matches the current column layout.
+// Since columns are only ever appended, a header that is a proper prefix of the
+// current one was written by an older version. Such a header is accepted as long
+// as it includes all columns needed to restore the results.
+func validateCSVHeader(header []string) error {
+	switch {
+	case len(header) > len(csvHeaders) || !slices.Equal(header, csvHeaders[:len(header)]):
+		return fmt.Errorf("%w: %q", errUnexpectedCSVHeader, strings.Join(header, ","))
+	case len(header) < csvMinColumns:
+		return fmt.Errorf("%w: missing columns %s; re-create it from the JSON results or by re-running the trials",
+			errLegacyCSVLayout, strings.Join(csvHeaders[len(header):csvMinColumns], ", "))
+	default:
+		return nil
+	}
+}
+
+func fromCSVRow(row []string) (runners.RunResult, error) {
+	kind, ok := stringToResultKind[row[csvColStatus]]
+	if !ok {
+		return runners.RunResult{}, fmt.Errorf("%w: %q", errUnknownResultKind, row[csvColStatus])
+	}
+	durationMS, err := strconv.ParseInt(row[csvColDurationMS], 10, 64)
+	if err != nil {
+		return runners.RunResult{}, fmt.Errorf("invalid DurationMS: %v", err)
+	}
+	var details detailsView
+	if err := json.Unmarshal([]byte(row[csvColDetails]), &details); err != nil {
+		return runners.RunResult{}, fmt.Errorf("invalid Details: %v", err)
+	}
+	var got interface{}
+	if err := decodeJSONColumn(row[csvColGot], &got); err != nil {
+		return runners.RunResult{}, fmt.Errorf("invalid Got: %v", err)
+	}
+	var want utils.ValueSet
+	if err := decodeJSONColumn(row[csvColWant], &want); err != nil {
+		return runners.RunResult{}, fmt.Errorf("invalid Want: %v", err)
+	}
+	result := runners.RunResult{
+		TraceID:  row[csvColTraceID],
+		Kind:     kind,
+		Task:     row[csvColTask],
+		Provider: row[csvColProvider],
+		Run:      row[csvColRun],
+		Got:      got,
+		Want:     want,
+		TaskMetadata: runners.TaskMetadata{
+			Suite:      row[csvColSuite],
+			Category:   row[csvColCategory],
+			Difficulty: row[csvColDifficulty],
+		},
+		Details:  fromDetailsView(details),
+		Duration: time.Duration(durationMS) * time.Millisecond,
+	}
+	if tags := row[csvColTags]; tags != "" {
+		result.TaskMetadata.Tags = strings.Split(tags, ",")
+	}
+	if len(row) > csvColTiming && row[csvColTiming] != "" {
+		var timing timingView
+		if err := json.Unmarshal([]byte(row[csvColTiming]), &timing); err != nil {
+			return runners.RunResult{}, fmt.Errorf("invalid Timing: %v", err)
+		}
+		result.Timing = fromTimingView(&timing)
+	}
+	return result, nil
+}
+
+// csvTimingColumns returns the values of the timing columns of a result. The derived
+// columns are left empty for metrics that were not recorded.
+func csvTimingColumns(timing *runners.Timing) ([]string, error) {
+	if timing == nil {
+		return []string{"", "", "", ""}, nil
+	}
+	var ttft, tokenRate string
+	if value := timing.TimeToFirstToken(); value != nil {
+		ttft = strconv.FormatInt(RoundToMS(*value).Milliseconds(), 10)
+	}
+	if value := timing.OutputTokensPerSecond(); value != nil {
+		tokenRate = strconv.FormatFloat(*value, 'f', 2, 64)
+	}
+	data, err := json.Marshal(newTimingView(timing))
+	if err != nil {
+		return nil, err
+	}
+	return []string{ttft, tokenRate, strconv.FormatInt(RoundToMS(timing.ToolTime).Milliseconds(), 10), string(data)}, nil
+}
+
+// decodeJSONColumn decodes a JSON encoded column value, preserving numeric precision
+// the same way the JSON codec does.
+func decodeJSONColumn(value string, target interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader([]byte(value)))
+	dec.UseNumber()
+	return dec.Decode(target)
+}
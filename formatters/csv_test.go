@@ -7,9 +7,12 @@
 package formatters
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +25,9 @@ import (
 
 var updateGolden = flag.Bool("update-golden", false, "update golden test files")
 
+// csvTestHeader is the header of documents written before the timing columns were added.
+const csvTestHeader = "TraceID,Provider,Run,Task,Status,DurationMS,Answer,Details,Suite,Category,Difficulty,Tags,Got,Want\n"
+
 type goldenFileTestCase struct {
 	path    string
 	results runners.Results
@@ -43,6 +49,27 @@ func updateGoldenFiles(t *testing.T, formatter Formatter, cases []goldenFileTest
 	}
 }
 
+var mockTaskSnapshot = runners.TaskSnapshot{
+	Hash:                 "sha256:3b9f0d",
+	Prompt:               "Quis autem vel eum iure reprehenderit?",
+	SystemPrompt:         "Provide the final answer in exactly this format: a single sentence",
+	ResponseResultFormat: "a single sentence",
+	ExpectedResult:       utils.NewValueSet("Quos aut rerum quaerat qui ad culpa."),
+	ValidationRules: map[string]interface{}{
+		"case-sensitive": false,
+	},
+	Tools: map[string]interface{}{
+		"calculator": map[string]interface{}{
+			"max-calls": 5,
+		},
+	},
+	Files: []runners.TaskFileSnapshot{
+		{Name: "diagram", URI: "taskdata/diagram.png", Type: "image/png"},
+	},
+	MaxTurns: 10,
+	Timeout:  2 * time.Minute,
+}
+
 var mockResults = runners.Results{
 	"provider-name": []runners.RunResult{
 		{
@@ -52,8 +79,15 @@ var mockResults = runners.Results{
 			Run:      "run-success",
 			Kind:     runners.Success,
 			Duration: 95 * time.Second,
-			Want:     utils.NewValueSet("Quos aut rerum quaerat qui ad culpa."),
-			Got:      "Quos aut rerum quaerat qui ad culpa.",
+			Timing: &runners.Timing{
+				Requests: []runners.RequestTiming{
+					{Duration: 60 * time.Second, TimeToFirstToken: testutils.Ptr(1200 * time.Millisecond), OutputTokens: testutils.Ptr(int64(1176))},
+					{Duration: 35 * time.Second, TimeToFirstToken: testutils.Ptr(800 * time.Millisecond), OutputTokens: testutils.Ptr(int64(684))},
+				},
+				ToolTime: 250 * time.Millisecond,
+			},
+			Want: utils.NewValueSet("Quos aut rerum quaerat qui ad culpa."),
+			Got:  "Quos aut rerum quaerat qui ad culpa.",
 			TaskMetadata: runners.TaskMetadata{
 				Suite:      "core-suite",
 				Category:   "reasoning",
@@ -66,17 +100,25 @@ var mockResults = runners.Results{
 					Explanation:    []string{"Quis ea voluptatem non aperiam dolor est.", "Alias odit enim fugiat vitae aliquam dolor quo ratione."},
 					ActualAnswer:   []string{"Quos aut rerum quaerat qui ad culpa."},
 					ExpectedAnswer: [][]string{{"Quos aut rerum quaerat qui ad culpa."}},
+					Reasoning: []string{
+						"Sed ut perspiciatis unde omnis iste natus.\n\nNemo enim ipsam voluptatem quia voluptas.",
+						"Neque porro quisquam est qui dolorem ipsum.",
+					},
 					Usage: runners.TokenUsage{
 						InputTokens:           testutils.Ptr(int64(9876543210)),
 						InputCacheWriteTokens: testutils.Ptr(int64(9757309999902428)),
 						InputCacheReadTokens:  testutils.Ptr(int64(91835)),
 						InputTokenAccounting:  runners.InputTokenAccountingCacheTokensSeparate,
 						OutputTokens:          testutils.Ptr(int64(1234567890)),
+						ReasoningTokens:       testutils.Ptr(int64(987654321)),
 					},
 					ToolUsage: map[string]runners.ToolUsage{
 						"calculator": {
 							CallCount:     testutils.Ptr(int64(127)),
 							TotalDuration: testutils.Ptr(45 * time.Second),
+							PoolHits:      testutils.Ptr(int64(120)),
+							PoolMisses:    testutils.Ptr(int64(7)),
+							PoolSaved:     testutils.Ptr(96 * time.Second),
 						},
 						"web_search": {
 							CallCount:     testutils.Ptr(int64(1)),
@@ -103,6 +145,10 @@ var mockResults = runners.Results{
 							ExitCode:         testutils.Ptr(int64(0)),
 							Status:           "success",
 							Stdout:           &runners.ToolCallOutput{Bytes: 12},
+							Artifacts: []runners.ToolArtifact{
+								{Path: "/workspace/out/chart.png", Bytes: 2048, MediaType: "image/png", File: "results-artifacts/a1b2c3d4/call_9f8c2e1a4b3d/workspace/out/chart.png"},
+								{Path: "/workspace/out/dump.bin", Bytes: 52428800, MediaType: "application/octet-stream"},
+							},
 						},
 						{
 							Tool:             "calculator",
@@ -151,6 +197,34 @@ var mockResults = runners.Results{
 					},
 				},
 				Error: runners.ErrorDetails{},
+				Events: []runners.ExecutionEvent{
+					{Time: time.Date(2025, 6, 1, 11, 59, 58, 0, time.UTC), Source: "rate-limit", Message: "waited 1.5s for the provider rate limit", Delay: 1500 * time.Millisecond},
+					{Time: time.Date(2025, 6, 1, 11, 59, 59, 0, time.UTC), Source: "rate-limit", Message: "lowered the rate limit of provider-name from 60.0 to 30.0 requests/min after a rate limited response"},
+				},
+			},
+			Provenance: &runners.Provenance{
+				ConfigHash: "sha256:0f6c1a",
+				Run: runners.RunSnapshot{
+					Model:                "model-success",
+					MaxRequestsPerMinute: 60,
+					ModelParams: map[string]interface{}{
+						"temperature": 0.7,
+						"api-key":     "[REDACTED]",
+					},
+					RetryPolicy: &runners.RetryPolicySnapshot{
+						MaxRetryAttempts:    3,
+						InitialDelaySeconds: 30,
+					},
+				},
+				Judge: &runners.JudgeSnapshot{
+					Name:     "judge-name",
+					Variant:  "judge-variant",
+					Provider: "judge-provider",
+					Run: runners.RunSnapshot{
+						Model: "judge-model",
+					},
+				},
+				Task: mockTaskSnapshot,
 			},
 		},
 		{
@@ -168,6 +242,7 @@ var mockResults = runners.Results{
 					Explanation:    []string{"Ut eos eius modi nihil voluptatem error.", "Veniam omnis at possimus aliquid tempore.", "Ut voluptatem ullam et ea non beatae eos adipisci incidunt.", "Consequatur hic sint laboriosam maiores unde vero ipsum magnam."},
 					ActualAnswer:   []string{"Ipsam ea et optio explicabo eius et."},
 					ExpectedAnswer: [][]string{{"Nihil reprehenderit enim voluptatum dolore nisi neque quia aut qui."}},
+					Recovery:       "repair",
 					Usage: runners.TokenUsage{
 						InputTokens:           testutils.Ptr(int64(200)),
 						InputCacheWriteTokens: testutils.Ptr(int64(100)),
@@ -185,6 +260,15 @@ var mockResults = runners.Results{
 				},
 				Error: runners.ErrorDetails{},
 			},
+			Provenance: &runners.Provenance{
+				ConfigHash: "sha256:7d2e94",
+				Run: runners.RunSnapshot{
+					Model:                   "model-failure",
+					TextOnly:                true,
+					DisableStructuredOutput: true,
+				},
+				Task: mockTaskSnapshot,
+			},
 		},
 		{
 			TraceID:  "01JEDE7Z8X0000000000000003",
@@ -201,6 +285,7 @@ var mockResults = runners.Results{
 					Explanation:    []string{"Quis ea voluptatem non aperiam.", "Dolor est alias odit enim fugiat vitae aliquam dolore ratione."},
 					ActualAnswer:   []string{"Quos aut rerum quaerat qui ad culpa."},
 					ExpectedAnswer: [][]string{{"Deserunt quo sint minus eos officiis et."}, {"Quos aut rerum quaerat qui ad culpa."}},
+					RawAnswer:      []string{"Quis ea voluptatem non aperiam.", "", "Answer: Quos aut rerum quaerat qui ad culpa."},
 				},
 				Validation: runners.ValidationDetails{
 					Title: "Selectio Validata",
@@ -397,6 +482,11 @@ var mockResults = runners.Results{
 			Run:      "run-structured-success",
 			Kind:     runners.Success,
 			Duration: 42 * time.Second,
+			Timing: &runners.Timing{
+				Requests: []runners.RequestTiming{
+					{Duration: 42 * time.Second, OutputTokens: testutils.Ptr(int64(210))},
+				},
+			},
 			Want: utils.NewValueSet(
 				[]interface{}{
 					map[string]interface{}{
@@ -578,13 +668,13 @@ var mockResults = runners.Results{
 }
 
 func TestUpdateGoldenCSV(t *testing.T) {
-	updateGoldenFiles(t, NewCSVFormatter(), []goldenFileTestCase{
+	updateGoldenFiles(t, NewCSVCodec(), []goldenFileTestCase{
 		{"testdata/empty.csv", runners.Results{}},
 		{"testdata/results.csv", mockResults},
 	})
 }
 
-func TestCSVFormatterWrite(t *testing.T) {
+func TestCSVCodecWrite(t *testing.T) {
 	tests := []struct {
 		name    string
 		results runners.Results
@@ -603,7 +693,7 @@ func TestCSVFormatterWrite(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			formatter := NewCSVFormatter()
+			formatter := NewCSVCodec()
 			assertFormatterOutputFromFile(t, formatter, tt.results, tt.want)
 		})
 	}
@@ -619,7 +709,142 @@ func assertFormatterOutputFromFile(t *testing.T, formatter Formatter, results ru
 	testutils.AssertFileContentsSameAs(t, expectedContentsFilePath, gotFilePath)
 }
 
-func TestCSVFormatterFileExt(t *testing.T) {
-	formatter := NewCSVFormatter()
-	assert.Equal(t, "csv", formatter.FileExt())
+func TestCSVCodecRead(t *testing.T) {
+	codec := NewCSVCodec()
+
+	t.Run("round-trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, codec.Write(mockResults, &buf))
+
+		got, err := codec.Read(&buf)
+		require.NoError(t, err)
+
+		expected := testutils.ReadFile(t, "testdata/results.csv")
+
+		var actual bytes.Buffer
+		require.NoError(t, codec.Write(got, &actual))
+
+		assert.Equal(t, string(expected), actual.String())
+	})
+
+	t.Run("empty results round-trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, codec.Write(runners.Results{}, &buf))
+
+		got, err := codec.Read(&buf)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("restores metadata and tags", func(t *testing.T) {
+		data := csvTestHeader +
+			`t1,ProviderX,run1,task1,Failed,1500,b,"{""Error"":{""Message"":""m""}}",suite,math,hard,"a,b","{""x"":1}","[""a"",""c""]"` + "\n"
+		got, err := codec.Read(strings.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, runners.Results{
+			"ProviderX": {
+				{
+					TraceID:  "t1",
+					Kind:     runners.Failure,
+					Task:     "task1",
+					Provider: "ProviderX",
+					Run:      "run1",
+					Got:      map[string]interface{}{"x": json.Number("1")},
+					Want:     utils.NewValueSet("a", "c"),
+					TaskMetadata: runners.TaskMetadata{
+						Suite:      "suite",
+						Category:   "math",
+						Difficulty: "hard",
+						Tags:       []string{"a", "b"},
+					},
+					Details: runners.Details{
+						Error: runners.ErrorDetails{Message: "m"},
+					},
+					Duration: 1500 * time.Millisecond,
+				},
+			},
+		}, got)
+	})
+
+	t.Run("restores timing", func(t *testing.T) {
+		data := strings.Join(csvHeaders, ",") + "\n" +
+			`t1,ProviderX,run1,task1,Passed,1500,a,{},,,,,"""a""","""a""",500,1000.00,20,"{""Requests"":[{""DurationNS"":1500000000,""TimeToFirstTokenNS"":500000000,""OutputTokens"":1000}],""ToolTimeNS"":20000000}"` + "\n" +
+			`t2,ProviderX,run1,task2,Passed,1000,a,{},,,,,"""a""","""a""",,,,` + "\n"
+		got, err := codec.Read(strings.NewReader(data))
+		require.NoError(t, err)
+		require.Len(t, got["ProviderX"], 2)
+		assert.Equal(t, &runners.Timing{
+			Requests: []runners.RequestTiming{
+				{Duration: 1500 * time.Millisecond, TimeToFirstToken: testutils.Ptr(500 * time.Millisecond), OutputTokens: testutils.Ptr(int64(1000))},
+			},
+			ToolTime: 20 * time.Millisecond,
+		}, got["ProviderX"][0].Timing)
+		assert.Nil(t, got["ProviderX"][1].Timing)
+	})
+
+	t.Run("malformed Timing", func(t *testing.T) {
+		data := strings.Join(csvHeaders, ",") + "\n" +
+			`t1,ProviderX,run1,task1,Passed,1000,a,{},,,,,"""a""","""a""",,,,{invalid}` + "\n"
+		_, err := codec.Read(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), "invalid Timing")
+	})
+
+	t.Run("older column layout", func(t *testing.T) {
+		data := "TraceID,Provider,Run,Task,Status,DurationMS,Answer,Details,Suite,Category,Difficulty,Tags\n" +
+			"t1,ProviderX,run1,task1,Passed,1000,a,{},,,,\n"
+		_, err := codec.Read(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), "older version")
+		assert.Contains(t, err.Error(), "missing columns Got, Want")
+	})
+
+	t.Run("unexpected header", func(t *testing.T) {
+		_, err := codec.Read(strings.NewReader("Name,Value\na,b\n"))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), "unexpected CSV header")
+	})
+
+	t.Run("empty document", func(t *testing.T) {
+		_, err := codec.Read(strings.NewReader(""))
+		require.ErrorIs(t, err, ErrReadResults)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		data := csvTestHeader + `t1,ProviderX,run1,task1,Maybe,1000,a,{},,,,,"""a""","""a"""` + "\n"
+		_, err := codec.Read(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), `line 2: unknown result kind: "Maybe"`)
+	})
+
+	t.Run("malformed Details", func(t *testing.T) {
+		data := csvTestHeader + `t1,ProviderX,run1,task1,Passed,1000,a,{invalid},,,,,"""a""","""a"""` + "\n"
+		_, err := codec.Read(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), "invalid Details")
+	})
+
+	t.Run("missing columns in row", func(t *testing.T) {
+		data := csvTestHeader + "t1,ProviderX,run1\n"
+		_, err := codec.Read(strings.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), "expected 14 columns but got 3")
+	})
+}
+
+func TestCSVCodecReadFromFile(t *testing.T) {
+	got, err := ReadResultsFromFile("testdata/results.csv")
+	require.NoError(t, err)
+
+	expected := testutils.ReadFile(t, "testdata/results.csv")
+
+	var actual bytes.Buffer
+	require.NoError(t, NewCSVCodec().Write(got, &actual))
+
+	assert.Equal(t, string(expected), actual.String())
+}
+
+func TestCSVCodecFileExt(t *testing.T) {
+	codec := NewCSVCodec()
+	assert.Equal(t, "csv", codec.FileExt())
 }
@@ -6,7 +6,7 @@
 
 // Package formatters provides output formatting functionality for MindTrial results.
 // It supports multiple output formats including HTML, CSV, JSON, and text logs.
-// The JSON format implements the Codec interface, enabling bidirectional serialization
+// The JSON and CSV formats implement the Codec interface, enabling bidirectional serialization
 // for result persistence and merging across separate runs.
 package formatters
 
@@ -47,7 +47,7 @@ type Codec interface {
 }
 
 // codecs is the registry of all available codecs.
-var codecs = []Codec{NewJSONCodec()}
+var codecs = []Codec{NewJSONCodec(), NewCSVCodec()}
 
 // ReadResultsFromFile reads results from a file, selecting the appropriate codec based on file extension.
 func ReadResultsFromFile(path string) (runners.Results, error) {
@@ -43,19 +43,26 @@ type VersionData struct {
 // NewHTMLFormatter creates a new formatter that outputs results as an HTML document.
 func NewHTMLFormatter() Formatter {
 	templ := template.Must(template.New(filepath.Base(templateFile)).Funcs(template.FuncMap{
-		"ToStatus":                ToStatus,
-		"FormatAnswer":            FormatAnswer,
-		"SortResultsByProvider":   utils.SortedKeys[string, []runners.RunResult],
-		"SortResultsByRunAndKind": utils.SortedKeys[string, map[runners.ResultKind][]runners.RunResult],
-		"SortToolsByName":         utils.SortedKeys[string, runners.ToolUsage],
-		"CountByKind":             CountByKind,
-		"TotalDuration":           TotalDuration,
-		"RoundToMS":               RoundToMS,
-		"PassRate":                PassRate,
-		"AccuracyRate":            AccuracyRate,
-		"ErrorRate":               ErrorRate,
-		"Percent":                 Percent,
-		"Timestamp":               Timestamp,
+		"ToStatus":                  ToStatus,
+		"FormatAnswer":              FormatAnswer,
+		"SortResultsByProvider":     utils.SortedKeys[string, []runners.RunResult],
+		"SortResultsByRunAndKind":   utils.SortedKeys[string, map[runners.ResultKind][]runners.RunResult],
+		"SortToolsByName":           utils.SortedKeys[string, runners.ToolUsage],
+		"CountByKind":               CountByKind,
+		"TotalDuration":             TotalDuration,
+		"RoundToMS":                 RoundToMS,
+		"PassRate":                  PassRate,
+		"AccuracyRate":              AccuracyRate,
+		"ErrorRate":                 ErrorRate,
+		"Percent":                   Percent,
+		"RunLatency":                RunLatency,
+		"FormatDurationPercentiles": FormatDurationPercentiles,
+		"FormatRatePercentiles":     FormatRatePercentiles,
+		"FormatTiming": func(timing *runners.Timing) string {
+			ttft, tokenRate, toolTime := formatTiming(timing)
+			return fmt.Sprintf("TTFT %s · %s tok/s · tools %s", ttft, tokenRate, toolTime)
+		},
+		"Timestamp": Timestamp,
 		"SafeHTML": func(s string) template.HTML {
 			return template.HTML(s) //nolint:gosec
 		},
@@ -68,7 +75,10 @@ func NewHTMLFormatter() Formatter {
 		"UniqueDifficulties": UniqueDifficulties,
 		"UniqueTags":         UniqueTags,
 		"GroupParagraphs":    GroupParagraphs,
+		"SplitLines":         utils.SplitLines,
 		"ErrorCategory":      ToErrorCategory,
+		"HasArtifacts":       HasArtifacts,
+		"IsImage":            IsImage,
 	}).ParseFS(templatesFS, templateFile))
 	return &htmlFormatter{
 		templ: templ,
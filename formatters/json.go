@@ -14,13 +14,17 @@ import (
 	"github.com/petmal/mindtrial/runners"
 )
 
-const currentFormatVersion = 1
+// currentFormatVersion 2 adds the Configurations and Tasks provenance sections to the document.
+const currentFormatVersion = 2
+
+// minFormatVersion is the oldest document version the JSON codec can read.
+const minFormatVersion = 1
 
 // resultsSchemaURL is the public URL of the JSON schema describing this format version's
 // document structure (see resultsJSONSchema in json_schema.go and the checked-in
-// schema/results-v1.schema.json). Every generated document includes it via the "$schema"
+// schema/results-v2.schema.json). Every generated document includes it via the "$schema"
 // field so tooling/LLM consumers can locate the schema without guessing.
-const resultsSchemaURL = "https://raw.githubusercontent.com/petmal/mindtrial/main/schema/results-v1.schema.json"
+const resultsSchemaURL = "https://raw.githubusercontent.com/petmal/mindtrial/main/schema/results-v2.schema.json"
 
 // NewJSONCodec creates a new codec that reads and writes results in JSON format.
 func NewJSONCodec() Codec {
@@ -30,12 +34,15 @@ func NewJSONCodec() Codec {
 type jsonCodec struct{}
 
 type jsonDocument struct {
-	Schema        string      `json:"$schema,omitempty" jsonschema:"title=Schema" jsonschema_description:"The URL of the JSON schema describing this document's structure."`
-	FormatVersion int         `json:"FormatVersion" jsonschema:"title=Format Version,enum=1" jsonschema_description:"The version of this JSON document's structure. Readers should reject documents with an unrecognized version rather than guessing at compatibility."`
-	AppName       string      `json:"AppName,omitempty" jsonschema:"title=Application Name" jsonschema_description:"The name of the application that produced this document."`
-	AppVersion    string      `json:"AppVersion,omitempty" jsonschema:"title=Application Version" jsonschema_description:"The version of the application that produced this document."`
-	CreatedAt     string      `json:"CreatedAt,omitempty" jsonschema:"title=Created At" jsonschema_description:"The timestamp at which this document was generated."`
-	Results       resultsView `json:"Results" jsonschema:"title=Results" jsonschema_description:"Task results, keyed by provider name."`
+	Schema         string                               `json:"$schema,omitempty" jsonschema:"title=Schema" jsonschema_description:"The URL of the JSON schema describing this document's structure."`
+	FormatVersion  int                                  `json:"FormatVersion" jsonschema:"title=Format Version,enum=2" jsonschema_description:"The version of this JSON document's structure. Readers should reject documents with an unrecognized version rather than guessing at compatibility."`
+	AppName        string                               `json:"AppName,omitempty" jsonschema:"title=Application Name" jsonschema_description:"The name of the application that produced this document."`
+	AppVersion     string                               `json:"AppVersion,omitempty" jsonschema:"title=Application Version" jsonschema_description:"The version of the application that produced this document."`
+	CreatedAt      string                               `json:"CreatedAt,omitempty" jsonschema:"title=Created At" jsonschema_description:"The timestamp at which this document was generated."`
+	Configurations map[string]configurationView         `json:"Configurations,omitempty" jsonschema:"title=Configurations" jsonschema_description:"Redacted snapshots of the run and judge configurations that produced the results, keyed by the content hash each result refers to via its ConfigHash field."`
+	Tasks          map[string]taskSnapshotView          `json:"Tasks,omitempty" jsonschema:"title=Task Definitions" jsonschema_description:"Snapshots of the task definitions that produced the results, keyed by the content hash each result refers to via its TaskHash field."`
+	RunLatency     map[string]map[string]runLatencyView `json:"RunLatency,omitempty" jsonschema:"title=Run Latency" jsonschema_description:"Latency percentiles of each run, keyed by provider name and then run name. Computed over the Passed, Failed and Error results; derived from the results and ignored when the document is read."`
+	Results        resultsView                          `json:"Results" jsonschema:"title=Results" jsonschema_description:"Task results, keyed by provider name."`
 }
 
 func (c jsonCodec) FileExt() string {
@@ -43,13 +50,17 @@ func (c jsonCodec) FileExt() string {
 }
 
 func (c jsonCodec) Write(results runners.Results, out io.Writer) error {
+	resultViews, provenance := toResultsView(results)
 	doc := jsonDocument{
-		Schema:        resultsSchemaURL,
-		FormatVersion: currentFormatVersion,
-		AppName:       currentVersionData.Name,
-		AppVersion:    currentVersionData.Version,
-		CreatedAt:     Timestamp(),
-		Results:       toResultsView(results),
+		Schema:         resultsSchemaURL,
+		FormatVersion:  currentFormatVersion,
+		AppName:        currentVersionData.Name,
+		AppVersion:     currentVersionData.Version,
+		CreatedAt:      Timestamp(),
+		Configurations: provenance.configurations,
+		Tasks:          provenance.tasks,
+		RunLatency:     newRunLatencyViews(results),
+		Results:        resultViews,
 	}
 	data, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
@@ -73,10 +84,13 @@ func (c jsonCodec) Read(in io.Reader) (runners.Results, error) {
 	if dec.More() {
 		return nil, fmt.Errorf("%w: unexpected trailing data after JSON document", ErrReadResults)
 	}
-	if doc.FormatVersion != currentFormatVersion {
-		return nil, fmt.Errorf("%w: unsupported format version %d (expected %d)", ErrReadResults, doc.FormatVersion, currentFormatVersion)
+	if doc.FormatVersion < minFormatVersion || doc.FormatVersion > currentFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d (expected %d to %d)", ErrReadResults, doc.FormatVersion, minFormatVersion, currentFormatVersion)
 	}
-	results, err := fromResultsView(doc.Results)
+	results, err := fromResultsView(doc.Results, provenanceViews{
+		configurations: doc.Configurations,
+		tasks:          doc.Tasks,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrReadResults, err)
 	}
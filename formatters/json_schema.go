@@ -14,9 +14,10 @@ import (
 // JSON results document (see jsonDocument and the view types in json_view.go). It is
 // intended for external tooling/LLM consumers of the JSON results output, not for the
 // codec's own read/write path, which relies on Go's encoding/json instead. The generated
-// schema is checked into schema/results-v1.schema.json; regenerate it with
+// schema is checked into schema/results-v2.schema.json; regenerate it with
 // `go test -tags=test ./formatters/... -run TestUpdateGoldenResultsSchema -update-golden`
-// after a deliberate change to jsonDocument or its view types.
+// after a deliberate change to jsonDocument or its view types. Schemas of earlier format
+// versions stay checked in next to it for documents written by older releases.
 func resultsJSONSchema() *jsonschema.Schema {
 	reflector := jsonschema.Reflector{
 		AllowAdditionalProperties: false,
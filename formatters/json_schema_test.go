@@ -17,7 +17,7 @@ import (
 
 // resultsSchemaPath is the checked-in JSON schema artifact for the JSON results
 // document. Run TestUpdateGoldenResultsSchema with -update-golden to regenerate it.
-const resultsSchemaPath = "../schema/results-v1.schema.json"
+const resultsSchemaPath = "../schema/results-v2.schema.json"
 
 func marshalResultsJSONSchema(t *testing.T) []byte {
 	t.Helper()
@@ -34,7 +34,7 @@ func TestUpdateGoldenResultsSchema(t *testing.T) {
 	t.Logf("Updated %s", resultsSchemaPath)
 }
 
-// TestResultsJSONSchemaUpToDate ensures the checked-in schema/results-v1.schema.json
+// TestResultsJSONSchemaUpToDate ensures the checked-in schema/results-v2.schema.json
 // artifact stays in sync with the jsonschema tags declared on jsonDocument and its view
 // types; run with -update-golden to regenerate it after a deliberate change.
 func TestResultsJSONSchemaUpToDate(t *testing.T) {
@@ -130,6 +130,85 @@ func TestJSONCodecRead(t *testing.T) {
 		assert.Contains(t, err.Error(), "unexpected trailing data")
 	})
 
+	t.Run("format version 1 without provenance", func(t *testing.T) {
+		data := []byte(`{
+  "FormatVersion": 1,
+  "Results": {
+    "ProviderX": [
+      {
+        "TraceID": "t1",
+        "Kind": "Passed",
+        "Task": "task1",
+        "Provider": "ProviderX",
+        "Run": "run1",
+        "Got": "a",
+        "Want": "a",
+        "Details": {},
+        "DurationNS": 1000000000
+      }
+    ]
+  }
+}`)
+		got, err := codec.Read(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Len(t, got["ProviderX"], 1)
+		assert.Nil(t, got["ProviderX"][0].Provenance)
+	})
+
+	t.Run("unknown configuration hash", func(t *testing.T) {
+		data := []byte(`{
+  "FormatVersion": 2,
+  "Tasks": {"sha256:t1": {"Prompt": "p"}},
+  "Results": {
+    "ProviderX": [
+      {
+        "TraceID": "t1",
+        "Kind": "Passed",
+        "Task": "task1",
+        "Provider": "ProviderX",
+        "Run": "run1",
+        "Got": "a",
+        "Want": "a",
+        "Details": {},
+        "DurationNS": 1000000000,
+        "ConfigHash": "sha256:c1",
+        "TaskHash": "sha256:t1"
+      }
+    ]
+  }
+}`)
+		_, err := codec.Read(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), `unknown configuration hash: "sha256:c1"`)
+	})
+
+	t.Run("unknown task hash", func(t *testing.T) {
+		data := []byte(`{
+  "FormatVersion": 2,
+  "Configurations": {"sha256:c1": {"Run": {"Model": "m"}}},
+  "Results": {
+    "ProviderX": [
+      {
+        "TraceID": "t1",
+        "Kind": "Passed",
+        "Task": "task1",
+        "Provider": "ProviderX",
+        "Run": "run1",
+        "Got": "a",
+        "Want": "a",
+        "Details": {},
+        "DurationNS": 1000000000,
+        "ConfigHash": "sha256:c1",
+        "TaskHash": "sha256:t1"
+      }
+    ]
+  }
+}`)
+		_, err := codec.Read(bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrReadResults)
+		assert.Contains(t, err.Error(), `unknown task definition hash: "sha256:t1"`)
+	})
+
 	t.Run("provider key mismatch", func(t *testing.T) {
 		data := []byte(`{
   "FormatVersion": 1,
@@ -229,7 +308,7 @@ func TestJSONCodecCrossFormatConsistency(t *testing.T) {
 		},
 		{
 			name:      "JSON to CSV",
-			formatter: NewCSVFormatter(),
+			formatter: NewCSVCodec(),
 			want:      "testdata/results.csv",
 		},
 	}
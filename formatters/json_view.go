@@ -15,15 +15,25 @@ import (
 	"github.com/petmal/mindtrial/runners"
 )
 
-// errUnknownResultKind indicates an unrecognized result kind string during deserialization.
-var errUnknownResultKind = errors.New("unknown result kind")
+var (
+	// errUnknownResultKind indicates an unrecognized result kind string during deserialization.
+	errUnknownResultKind = errors.New("unknown result kind")
+	// errUnknownConfigHash indicates a result referencing a configuration missing from the document.
+	errUnknownConfigHash = errors.New("unknown configuration hash")
+	// errUnknownTaskHash indicates a result referencing a task definition missing from the document.
+	errUnknownTaskHash = errors.New("unknown task definition hash")
+)
 
 // stringToResultKind maps status strings (as produced by ToStatus) back to ResultKind values.
 var stringToResultKind = map[string]runners.ResultKind{
-	Passed:  runners.Success,
-	Failed:  runners.Failure,
-	Error:   runners.Error,
-	Skipped: runners.NotSupported,
+	Passed:           runners.Success,
+	Failed:           runners.Failure,
+	Error:            runners.Error,
+	Skipped:          runners.NotSupported,
+	Cancelled:        runners.Cancelled,
+	Unavailable:      runners.ProviderUnavailable,
+	TimedOut:         runners.TimedOut,
+	ExtractionFailed: runners.ExtractionFailed,
 }
 
 // resultsView is the view model for runners.Results used in JSON serialization.
@@ -31,16 +41,139 @@ type resultsView map[string][]resultView
 
 // resultView is the view model for runners.RunResult.
 type resultView struct {
-	TraceID      string            `json:"TraceID" jsonschema:"title=Trace ID" jsonschema_description:"A globally unique identifier for this specific task result, used for tracing and correlation."`
-	Kind         string            `json:"Kind" jsonschema:"title=Result Kind" jsonschema_description:"The result status: Passed (answer accepted), Failed (answer rejected), Error (task execution failed), or Skipped (task not supported/attempted). An \"Unknown (n)\" fallback is possible but not expected in practice."`
-	Task         string            `json:"Task" jsonschema:"title=Task Name" jsonschema_description:"The name of the executed task."`
-	Provider     string            `json:"Provider" jsonschema:"title=Provider Name" jsonschema_description:"The name of the AI provider that executed the task."`
-	Run          string            `json:"Run" jsonschema:"title=Run Name" jsonschema_description:"The name of the provider's run configuration used."`
-	Got          interface{}       `json:"Got" jsonschema:"title=Actual Answer" jsonschema_description:"The actual answer received from the AI model. For plain text response format, a string that follows the format instruction precisely. For structured schema-based response format, any object that conforms to the task's response schema."`
-	Want         utils.ValueSet    `json:"Want" jsonschema:"title=Expected Answer(s)" jsonschema_description:"The accepted valid answer(s) for the task, as a single value or an array of values. For plain text response format: string values that should follow the format instruction precisely. For structured schema-based response format: object values that conform to the task's response schema."`
-	TaskMetadata *taskMetadataView `json:"TaskMetadata,omitempty" jsonschema:"title=Task Metadata" jsonschema_description:"Optional descriptive labels copied from the originating task."`
-	Details      detailsView       `json:"Details" jsonschema:"title=Details" jsonschema_description:"Comprehensive information about the generated response and validation assessment."`
-	DurationNS   int64             `json:"DurationNS" jsonschema:"title=Duration (ns)" jsonschema_description:"The cumulative time the AI model itself spent generating a response, in nanoseconds, summed across every conversation turn's model request (network + inference). Excludes local tool execution time (see ToolCalls/ToolUsage) and any subsequent validation time, so this is not the total wall-clock time spent processing the task."`
+	TraceID      string              `json:"TraceID" jsonschema:"title=Trace ID" jsonschema_description:"A globally unique identifier for this specific task result, used for tracing and correlation."`
+	Kind         string              `json:"Kind" jsonschema:"title=Result Kind" jsonschema_description:"The result status: Passed (answer accepted), Failed (answer rejected), Error (task execution failed), Skipped (task not supported/attempted), Cancelled (task not started or not finished because the run was canceled), Unavailable (task not sent because the circuit breaker gave up on the provider), TimedOut (task stopped because it exceeded its timeout or the trial deadline), or ExtractionFailed (no answer found in the response by the answer extractor configured for the task). An \"Unknown (n)\" fallback is possible but not expected in practice."`
+	Task         string              `json:"Task" jsonschema:"title=Task Name" jsonschema_description:"The name of the executed task."`
+	Provider     string              `json:"Provider" jsonschema:"title=Provider Name" jsonschema_description:"The name of the AI provider that executed the task."`
+	Run          string              `json:"Run" jsonschema:"title=Run Name" jsonschema_description:"The name of the provider's run configuration used."`
+	Got          interface{}         `json:"Got" jsonschema:"title=Actual Answer" jsonschema_description:"The actual answer received from the AI model. For plain text response format, a string that follows the format instruction precisely. For structured schema-based response format, any object that conforms to the task's response schema."`
+	Want         utils.ValueSet      `json:"Want" jsonschema:"title=Expected Answer(s)" jsonschema_description:"The accepted valid answer(s) for the task, as a single value or an array of values. For plain text response format: string values that should follow the format instruction precisely. For structured schema-based response format: object values that conform to the task's response schema."`
+	TaskMetadata *taskMetadataView   `json:"TaskMetadata,omitempty" jsonschema:"title=Task Metadata" jsonschema_description:"Optional descriptive labels copied from the originating task."`
+	Details      detailsView         `json:"Details" jsonschema:"title=Details" jsonschema_description:"Comprehensive information about the generated response and validation assessment."`
+	DurationNS   int64               `json:"DurationNS" jsonschema:"title=Duration (ns)" jsonschema_description:"The cumulative time the AI model itself spent generating a response, in nanoseconds, summed across every conversation turn's model request (network + inference). Excludes local tool execution time (see ToolCalls/ToolUsage) and any subsequent validation time, so this is not the total wall-clock time spent processing the task."`
+	Timing       *timingView         `json:"Timing,omitempty" jsonschema:"title=Timing" jsonschema_description:"The latency of the individual model requests and the time spent executing tools. Absent if no timing information was recorded."`
+	ConfigHash   string              `json:"ConfigHash,omitempty" jsonschema:"title=Configuration Hash" jsonschema_description:"The key of the entry in the document's Configurations section describing the run (and judge) configuration that produced this result. Absent for results without recorded provenance."`
+	TaskHash     string              `json:"TaskHash,omitempty" jsonschema:"title=Task Definition Hash" jsonschema_description:"The key of the entry in the document's Tasks section describing the task definition that produced this result. Absent for results without recorded provenance."`
+	Workspaces   []toolWorkspaceView `json:"Workspaces,omitempty" jsonschema:"title=Tool Workspaces" jsonschema_description:"The files left in the workspaces of the tool sessions configured to capture them, in order of tool names. Absent if no workspace was captured."`
+}
+
+// toolWorkspaceView is the view model for runners.ToolWorkspace.
+type toolWorkspaceView struct {
+	Tool      string                  `json:"Tool" jsonschema:"title=Tool Name" jsonschema_description:"The name of the tool that owned the session."`
+	Dir       string                  `json:"Dir" jsonschema:"title=Workspace Directory" jsonschema_description:"The path of the workspace directory in the sandbox."`
+	Files     []toolWorkspaceFileView `json:"Files,omitempty" jsonschema:"title=Files" jsonschema_description:"The regular files found in the workspace, in lexical order of their paths."`
+	Truncated bool                    `json:"Truncated,omitempty" jsonschema:"title=Truncated" jsonschema_description:"Whether the content of some files was omitted because the capture size limit was reached."`
+	Error     string                  `json:"Error,omitempty" jsonschema:"title=Error" jsonschema_description:"Why the workspace could not be captured completely, or absent if it was."`
+}
+
+// toolWorkspaceFileView is the view model for runners.ToolWorkspaceFile.
+type toolWorkspaceFileView struct {
+	Path    string  `json:"Path" jsonschema:"title=Path" jsonschema_description:"The slash-separated path of the file relative to the workspace directory."`
+	Bytes   int64   `json:"Bytes" jsonschema:"title=Bytes" jsonschema_description:"The size of the file, in bytes."`
+	Content *string `json:"Content,omitempty" jsonschema:"title=Content" jsonschema_description:"The content of the file, or absent if it is not valid UTF-8 text or would exceed the capture size limit."`
+}
+
+// timingView is the view model for runners.Timing.
+type timingView struct {
+	Requests   []requestTimingView `json:"Requests,omitempty" jsonschema:"title=Requests" jsonschema_description:"The timing of each model request in the order the requests were made."`
+	ToolTimeNS int64               `json:"ToolTimeNS" jsonschema:"title=Tool Time (ns)" jsonschema_description:"The cumulative wall-clock time spent executing tool calls, in nanoseconds."`
+}
+
+// requestTimingView is the view model for runners.RequestTiming.
+type requestTimingView struct {
+	DurationNS         int64  `json:"DurationNS" jsonschema:"title=Duration (ns)" jsonschema_description:"The time from sending the request until the complete response was received, in nanoseconds."`
+	TimeToFirstTokenNS *int64 `json:"TimeToFirstTokenNS,omitempty" jsonschema:"title=Time to First Token (ns)" jsonschema_description:"The time from sending the request until the first output token was received, in nanoseconds. Absent if the response was not streamed."`
+	OutputTokens       *int64 `json:"OutputTokens,omitempty" jsonschema:"title=Output Tokens" jsonschema_description:"The number of tokens generated in response to the request, if known."`
+}
+
+// runLatencyView is the view model for LatencyStats.
+type runLatencyView struct {
+	DurationNS            *durationPercentilesView `json:"DurationNS,omitempty" jsonschema:"title=Duration (ns)" jsonschema_description:"Percentiles of the time the model spent generating each task's response, in nanoseconds."`
+	TimeToFirstTokenNS    *durationPercentilesView `json:"TimeToFirstTokenNS,omitempty" jsonschema:"title=Time to First Token (ns)" jsonschema_description:"Percentiles of the time to first token of each task's first model request, in nanoseconds. Absent if no response was streamed."`
+	OutputTokensPerSecond *ratePercentilesView     `json:"OutputTokensPerSecond,omitempty" jsonschema:"title=Output Tokens per Second" jsonschema_description:"Percentiles of the output token throughput of each task, excluding the time to first token of streamed requests."`
+	ToolTimeNS            *durationPercentilesView `json:"ToolTimeNS,omitempty" jsonschema:"title=Tool Time (ns)" jsonschema_description:"Percentiles of the time spent executing tools for each task, in nanoseconds."`
+}
+
+// durationPercentilesView is the view model for Percentiles of durations.
+type durationPercentilesView struct {
+	P50 int64 `json:"P50" jsonschema:"title=50th Percentile"`
+	P90 int64 `json:"P90" jsonschema:"title=90th Percentile"`
+	P99 int64 `json:"P99" jsonschema:"title=99th Percentile"`
+}
+
+// ratePercentilesView is the view model for Percentiles of rates.
+type ratePercentilesView struct {
+	P50 float64 `json:"P50" jsonschema:"title=50th Percentile"`
+	P90 float64 `json:"P90" jsonschema:"title=90th Percentile"`
+	P99 float64 `json:"P99" jsonschema:"title=99th Percentile"`
+}
+
+// configurationView is the view model for the configuration part of runners.Provenance.
+type configurationView struct {
+	Run   runSnapshotView    `json:"Run" jsonschema:"title=Run Configuration" jsonschema_description:"A redacted snapshot of the run configuration used."`
+	Judge *judgeSnapshotView `json:"Judge,omitempty" jsonschema:"title=Judge Configuration" jsonschema_description:"A redacted snapshot of the judge used to validate answers, or absent if answers were validated by value matching."`
+}
+
+// runSnapshotView is the view model for runners.RunSnapshot.
+type runSnapshotView struct {
+	Model                   string                 `json:"Model" jsonschema:"title=Model" jsonschema_description:"The target model's identifier."`
+	MaxRequestsPerMinute    int                    `json:"MaxRequestsPerMinute,omitempty" jsonschema:"title=Max Requests Per Minute" jsonschema_description:"The per-run request rate limit, or absent if not limited."`
+	MaxConcurrentTasks      int                    `json:"MaxConcurrentTasks,omitempty" jsonschema:"title=Max Concurrent Tasks" jsonschema_description:"The maximum number of tasks run concurrently, or absent if the tasks were run one at a time."`
+	TimeoutNS               int64                  `json:"TimeoutNS,omitempty" jsonschema:"title=Timeout (ns)" jsonschema_description:"The maximum time each task could run, in nanoseconds, or absent if not limited."`
+	ExecutionMode           string                 `json:"ExecutionMode,omitempty" jsonschema:"title=Execution Mode,enum=batch" jsonschema_description:"The execution mode of the run, or absent if the tasks were executed synchronously."`
+	TextOnly                bool                   `json:"TextOnly,omitempty" jsonschema:"title=Text Only" jsonschema_description:"Whether tasks with file attachments were skipped."`
+	DisableStructuredOutput bool                   `json:"DisableStructuredOutput,omitempty" jsonschema:"title=Disable Structured Output" jsonschema_description:"Whether structured output was disabled."`
+	RecordReasoning         bool                   `json:"RecordReasoning,omitempty" jsonschema:"title=Record Reasoning" jsonschema_description:"Whether the reasoning of the model was recorded."`
+	ModelParams             map[string]interface{} `json:"ModelParams,omitempty" jsonschema:"title=Model Parameters" jsonschema_description:"The model-specific parameters keyed by their configuration file property names. Values of properties that look like secrets are redacted."`
+	RetryPolicy             *retryPolicyView       `json:"RetryPolicy,omitempty" jsonschema:"title=Retry Policy" jsonschema_description:"The resolved retry policy, or absent if unknown."`
+	ResponseRecovery        *responseRecoveryView  `json:"ResponseRecovery,omitempty" jsonschema:"title=Response Recovery" jsonschema_description:"The policy for recovering responses that could not be parsed, or absent if no recovery was configured."`
+}
+
+// retryPolicyView is the view model for runners.RetryPolicySnapshot.
+type retryPolicyView struct {
+	MaxRetryAttempts    uint     `json:"MaxRetryAttempts" jsonschema:"title=Max Retry Attempts" jsonschema_description:"The maximum number of retry attempts."`
+	InitialDelaySeconds int      `json:"InitialDelaySeconds,omitempty" jsonschema:"title=Initial Delay (s)" jsonschema_description:"The initial delay in seconds before the first retry attempt."`
+	MaxDelaySeconds     int      `json:"MaxDelaySeconds,omitempty" jsonschema:"title=Max Delay (s)" jsonschema_description:"The maximum delay in seconds between retry attempts, or absent if the delay was not capped."`
+	Jitter              string   `json:"Jitter,omitempty" jsonschema:"title=Jitter,enum=none,enum=full,enum=decorrelated" jsonschema_description:"The randomization applied to retry delays, or absent if none."`
+	MaxTotalSeconds     int      `json:"MaxTotalSeconds,omitempty" jsonschema:"title=Max Total Time (s)" jsonschema_description:"The limit in seconds on the total time spent on all attempts of a task, or absent if not limited."`
+	RetryOn             []string `json:"RetryOn,omitempty" jsonschema:"title=Retry On" jsonschema_description:"The classes of errors that were retried (rate-limit, server-error, timeout, parse-error, no-content), or absent if all errors reported as transient by the provider were retried."`
+}
+
+// responseRecoveryView is the view model for runners.ResponseRecoverySnapshot.
+type responseRecoveryView struct {
+	Repair             bool `json:"Repair,omitempty" jsonschema:"title=Repair" jsonschema_description:"Whether the syntax of responses was repaired locally."`
+	Coerce             bool `json:"Coerce,omitempty" jsonschema:"title=Coerce" jsonschema_description:"Whether responses were coerced to the result schema."`
+	MaxCorrectiveTurns int  `json:"MaxCorrectiveTurns,omitempty" jsonschema:"title=Max Corrective Turns" jsonschema_description:"The number of times the model could be asked to correct its response, or absent if it was not asked."`
+}
+
+// judgeSnapshotView is the view model for runners.JudgeSnapshot.
+type judgeSnapshotView struct {
+	Name     string          `json:"Name" jsonschema:"title=Judge Name" jsonschema_description:"The name of the judge configuration."`
+	Variant  string          `json:"Variant" jsonschema:"title=Judge Variant" jsonschema_description:"The name of the judge's run variant."`
+	Provider string          `json:"Provider" jsonschema:"title=Judge Provider" jsonschema_description:"The name of the judge's AI provider."`
+	Run      runSnapshotView `json:"Run" jsonschema:"title=Judge Run Configuration" jsonschema_description:"A redacted snapshot of the judge's run variant configuration."`
+}
+
+// taskSnapshotView is the view model for runners.TaskSnapshot.
+type taskSnapshotView struct {
+	Prompt               string                 `json:"Prompt" jsonschema:"title=Prompt" jsonschema_description:"The prompt sent to the AI model."`
+	SystemPrompt         string                 `json:"SystemPrompt,omitempty" jsonschema:"title=System Prompt" jsonschema_description:"The resolved system prompt, or absent if none was used."`
+	ResponseResultFormat interface{}            `json:"ResponseResultFormat,omitempty" jsonschema:"title=Response Result Format" jsonschema_description:"The plain text instruction or JSON schema object describing the expected answer format."`
+	ExpectedResult       utils.ValueSet         `json:"ExpectedResult" jsonschema:"title=Expected Result" jsonschema_description:"The accepted valid answer(s) as defined by the task, before canonicalization, as a single value or an array of values."`
+	ValidationRules      map[string]interface{} `json:"ValidationRules,omitempty" jsonschema:"title=Validation Rules" jsonschema_description:"The resolved validation rules keyed by their configuration file property names."`
+	Tools                map[string]interface{} `json:"Tools,omitempty" jsonschema:"title=Tools" jsonschema_description:"The resolved settings of each enabled tool, keyed by tool name."`
+	Files                []taskFileView         `json:"Files,omitempty" jsonschema:"title=Files" jsonschema_description:"The files attached to the prompt."`
+	MaxTurns             int                    `json:"MaxTurns,omitempty" jsonschema:"title=Max Turns" jsonschema_description:"The resolved maximum number of conversation turns, or absent if unlimited."`
+	TimeoutNS            int64                  `json:"TimeoutNS,omitempty" jsonschema:"title=Timeout (ns)" jsonschema_description:"The resolved maximum time the task could run, in nanoseconds, or absent if not limited."`
+	AnswerExtractor      map[string]interface{} `json:"AnswerExtractor,omitempty" jsonschema:"title=Answer Extractor" jsonschema_description:"The resolved rule for extracting the final answer from unstructured responses keyed by its configuration file property names, or absent if none was set."`
+	ToolExpectations     map[string]interface{} `json:"ToolExpectations,omitempty" jsonschema:"title=Tool Expectations" jsonschema_description:"The assertions on the tool calls made while producing the answer keyed by their configuration file property names, or absent if none were set."`
+}
+
+// taskFileView is the view model for runners.TaskFileSnapshot.
+type taskFileView struct {
+	Name string `json:"Name" jsonschema:"title=File Name" jsonschema_description:"The file's name as referenced in the prompt."`
+	URI  string `json:"URI" jsonschema:"title=File URI" jsonschema_description:"The path or URL the file was loaded from."`
+	Type string `json:"Type,omitempty" jsonschema:"title=MIME Type" jsonschema_description:"The configured MIME type of the file, or absent if it was inferred."`
 }
 
 // taskMetadataView is the view model for runners.TaskMetadata.
@@ -56,6 +189,15 @@ type detailsView struct {
 	Answer     *answerDetailsView     `json:"Answer,omitempty" jsonschema:"title=Answer Details" jsonschema_description:"Details about the AI model's response and reasoning process."`
 	Validation *validationDetailsView `json:"Validation,omitempty" jsonschema:"title=Validation Details" jsonschema_description:"Details about the answer verification and assessment."`
 	Error      *errorDetailsView      `json:"Error,omitempty" jsonschema:"title=Error Details" jsonschema_description:"Details about any errors that occurred during task execution."`
+	Events     []executionEventView   `json:"Events,omitempty" jsonschema:"title=Execution Events" jsonschema_description:"What delayed or otherwise affected the execution of the task in the order it occurred, e.g. waits for rate limits or delays requested by the server."`
+}
+
+// executionEventView is the view model for runners.ExecutionEvent.
+type executionEventView struct {
+	Time    time.Time `json:"Time" jsonschema:"title=Time" jsonschema_description:"When the event occurred."`
+	Source  string    `json:"Source" jsonschema:"title=Source,enum=rate-limit,enum=retry,enum=circuit-breaker" jsonschema_description:"The component that produced the event."`
+	Message string    `json:"Message" jsonschema:"title=Message" jsonschema_description:"Describes the event."`
+	DelayNS int64     `json:"DelayNS,omitempty" jsonschema:"title=Delay (ns)" jsonschema_description:"How long the event delayed the task, in nanoseconds, or absent if it did not delay it directly."`
 }
 
 // answerDetailsView is the view model for runners.AnswerDetails.
@@ -67,6 +209,9 @@ type answerDetailsView struct {
 	Usage          *runners.TokenUsage      `json:"Usage,omitempty" jsonschema:"title=Token Usage" jsonschema_description:"Token usage statistics for generating the answer."`
 	ToolUsage      map[string]toolUsageView `json:"ToolUsage,omitempty" jsonschema:"title=Tool Usage" jsonschema_description:"Aggregated execution statistics, keyed by tool name, for any tools invoked while producing the answer."`
 	ToolCalls      []toolCallSummaryView    `json:"ToolCalls,omitempty" jsonschema:"title=Tool Calls" jsonschema_description:"A log of every individual invocation attempt made while producing the answer, including attempts that never actually ran. Tracked separately from ToolUsage, which only reflects invocations that actually ran."`
+	Reasoning      []string                 `json:"Reasoning,omitempty" jsonschema:"title=Reasoning" jsonschema_description:"The reasoning text or summaries produced by the target AI model, one entry per model response. Only present if recording reasoning was enabled for the run."`
+	Recovery       string                   `json:"Recovery,omitempty" jsonschema:"title=Recovery,enum=repair,enum=coercion,enum=corrective-turn" jsonschema_description:"The step that recovered a response that could not be parsed: a local syntax repair, a coercion to the result schema, or a corrective turn in which the model was asked to fix the response. Absent if the response was parsed on the first try."`
+	RawAnswer      []string                 `json:"RawAnswer,omitempty" jsonschema:"title=Raw Answer Lines" jsonschema_description:"The complete unstructured response split into lines. Only present if the actual answer was extracted from it by the answer extractor configured for the task."`
 }
 
 // validationDetailsView is the view model for runners.ValidationDetails.
@@ -87,12 +232,24 @@ type errorDetailsView struct {
 	ToolUsage map[string]toolUsageView `json:"ToolUsage,omitempty" jsonschema:"title=Tool Usage" jsonschema_description:"Aggregated execution statistics, keyed by tool name, for any tools invoked prior to the error."`
 	ToolCalls []toolCallSummaryView    `json:"ToolCalls,omitempty" jsonschema:"title=Tool Calls" jsonschema_description:"A log of every individual invocation attempt made prior to the error, including attempts that never actually ran. Tracked separately from ToolUsage, which only reflects invocations that actually ran."`
 	Transient *bool                    `json:"Transient,omitempty" jsonschema:"title=Transient" jsonschema_description:"Whether the error appears temporary/external (true), appears permanent/hard (false), or is unknown (field absent). A best-effort classification, not a complete error taxonomy."`
+	Attempts  []attemptView            `json:"Attempts,omitempty" jsonschema:"title=Attempts" jsonschema_description:"Every attempt to execute the task in the order they were made. Only present if a retry policy was configured."`
+}
+
+// attemptView is the view model for runners.AttemptDetails.
+type attemptView struct {
+	StartedAt  time.Time `json:"StartedAt" jsonschema:"title=Started At" jsonschema_description:"When the attempt started."`
+	DurationNS int64     `json:"DurationNS" jsonschema:"title=Duration (ns)" jsonschema_description:"How long the attempt took, in nanoseconds."`
+	Error      string    `json:"Error,omitempty" jsonschema:"title=Error" jsonschema_description:"The error the attempt failed with, or absent if it succeeded."`
+	ErrorClass string    `json:"ErrorClass,omitempty" jsonschema:"title=Error Class,enum=rate-limit,enum=server-error,enum=timeout,enum=parse-error,enum=no-content" jsonschema_description:"The class of the error used by retry rules, or absent if the error matches no class."`
 }
 
 // toolUsageView is the view model for runners.ToolUsage.
 type toolUsageView struct {
 	CallCount       *int64 `json:"CallCount,omitempty" jsonschema:"title=Call Count" jsonschema_description:"The number of times the tool's underlying process actually ran."`
 	TotalDurationNS *int64 `json:"TotalDurationNS,omitempty" jsonschema:"title=Total Duration (ns)" jsonschema_description:"The cumulative execution time for the tool's underlying process, in nanoseconds."`
+	PoolHits        *int64 `json:"PoolHits,omitempty" jsonschema:"title=Pool Hits" jsonschema_description:"The number of calls that used a pre-started container from the pool of the tool. Only present for tools with a container pool."`
+	PoolMisses      *int64 `json:"PoolMisses,omitempty" jsonschema:"title=Pool Misses" jsonschema_description:"The number of calls that found no pre-started container ready in the pool of the tool. Only present for tools with a container pool."`
+	PoolSavedNS     *int64 `json:"PoolSavedNS,omitempty" jsonschema:"title=Pool Saved Time (ns)" jsonschema_description:"The estimated latency saved by the pool hits, in nanoseconds, measured as the startup time of the pre-started containers they used. Only present for tools with a container pool."`
 }
 
 // toolCallSummaryView is the view model for runners.ToolCallSummary.
@@ -106,10 +263,19 @@ type toolCallSummaryView struct {
 	WallTimeNS       int64               `json:"WallTimeNS" jsonschema:"title=Wall Time (ns)" jsonschema_description:"The wall-clock duration of the entire call attempt, in nanoseconds, from setup through output retrieval - i.e. DurationNS plus setup/teardown overhead. Unlike DurationNS, this is always set, even for calls whose underlying process never ran."`
 	ExitCode         *int64              `json:"ExitCode,omitempty" jsonschema:"title=Exit Code" jsonschema_description:"The underlying process's exit code, or absent if no exit code is known."`
 	TimedOut         bool                `json:"TimedOut,omitempty" jsonschema:"title=Timed Out" jsonschema_description:"Whether the call was aborted due to exceeding its configured timeout."`
-	Status           string              `json:"Status,omitempty" jsonschema:"title=Status,enum=success,enum=nonzero_exit,enum=empty_output,enum=timeout,enum=invalid_arguments,enum=infrastructure_error" jsonschema_description:"The outcome of this call."`
+	Status           string              `json:"Status,omitempty" jsonschema:"title=Status,enum=success,enum=nonzero_exit,enum=empty_output,enum=timeout,enum=invalid_arguments,enum=infrastructure_error,enum=tool_error" jsonschema_description:"The outcome of this call."`
 	Stdout           *toolCallOutputView `json:"Stdout,omitempty" jsonschema:"title=Standard Output" jsonschema_description:"A size-limited capture of the call's standard output, or absent if no output was ever captured."`
 	Stderr           *toolCallOutputView `json:"Stderr,omitempty" jsonschema:"title=Standard Error" jsonschema_description:"A size-limited capture of the call's standard error, or absent if no output was ever captured."`
 	ErrorMessage     string              `json:"ErrorMessage,omitempty" jsonschema:"title=Error Message" jsonschema_description:"A short explanation of the failure when Status is not \"success\"."`
+	Artifacts        []toolArtifactView  `json:"Artifacts,omitempty" jsonschema:"title=Artifacts" jsonschema_description:"The files collected from the sandbox after the call, in lexical order of their paths, if the tool declares artifact paths."`
+}
+
+// toolArtifactView is the view model for runners.ToolArtifact.
+type toolArtifactView struct {
+	Path      string `json:"Path" jsonschema:"title=Path" jsonschema_description:"The absolute path of the file in the sandbox."`
+	Bytes     int64  `json:"Bytes" jsonschema:"title=Bytes" jsonschema_description:"The size of the file, in bytes."`
+	MediaType string `json:"MediaType,omitempty" jsonschema:"title=Media Type" jsonschema_description:"The media type of the file."`
+	File      string `json:"File,omitempty" jsonschema:"title=File" jsonschema_description:"The path of the stored file relative to the results, or absent if the file exceeded the size limit or was not stored."`
 }
 
 // toolCallOutputView is the view model for runners.ToolCallOutput.
@@ -119,20 +285,35 @@ type toolCallOutputView struct {
 	Truncated bool    `json:"Truncated,omitempty" jsonschema:"title=Truncated" jsonschema_description:"Whether Preview was cut short of the full output."`
 }
 
-func toResultsView(results runners.Results) resultsView {
+// provenanceViews holds the deduplicated provenance sections of a results document,
+// keyed by the hashes the individual results refer to.
+type provenanceViews struct {
+	configurations map[string]configurationView
+	tasks          map[string]taskSnapshotView
+}
+
+func toResultsView(results runners.Results) (resultsView, provenanceViews) {
 	rv := make(resultsView, len(results))
+	pv := provenanceViews{
+		configurations: make(map[string]configurationView),
+		tasks:          make(map[string]taskSnapshotView),
+	}
 	for provider, runResults := range results {
 		views := make([]resultView, len(runResults))
 		for i, r := range runResults {
 			views[i] = newResultView(r)
+			if r.Provenance != nil {
+				pv.configurations[r.Provenance.ConfigHash] = newConfigurationView(*r.Provenance)
+				pv.tasks[r.Provenance.Task.Hash] = newTaskSnapshotView(r.Provenance.Task)
+			}
 		}
 		rv[provider] = views
 	}
-	return rv
+	return rv, pv
 }
 
 func newResultView(r runners.RunResult) resultView {
-	return resultView{
+	v := resultView{
 		TraceID:      r.TraceID,
 		Kind:         ToStatus(r.Kind),
 		Task:         r.Task,
@@ -143,7 +324,150 @@ func newResultView(r runners.RunResult) resultView {
 		TaskMetadata: newTaskMetadataView(r.TaskMetadata),
 		Details:      newDetailsView(r.Details),
 		DurationNS:   r.Duration.Nanoseconds(),
+		Timing:       newTimingView(r.Timing),
+		Workspaces:   newToolWorkspaceViews(r.Workspaces),
+	}
+	if r.Provenance != nil {
+		v.ConfigHash = r.Provenance.ConfigHash
+		v.TaskHash = r.Provenance.Task.Hash
+	}
+	return v
+}
+
+func newTimingView(t *runners.Timing) *timingView {
+	if t == nil {
+		return nil
+	}
+	v := &timingView{ToolTimeNS: t.ToolTime.Nanoseconds()}
+	for _, r := range t.Requests {
+		v.Requests = append(v.Requests, requestTimingView{
+			DurationNS:         r.Duration.Nanoseconds(),
+			TimeToFirstTokenNS: durationToNsPtr(r.TimeToFirstToken),
+			OutputTokens:       r.OutputTokens,
+		})
+	}
+	return v
+}
+
+func newToolWorkspaceViews(workspaces []runners.ToolWorkspace) []toolWorkspaceView {
+	if len(workspaces) == 0 {
+		return nil
+	}
+	views := make([]toolWorkspaceView, len(workspaces))
+	for i, w := range workspaces {
+		views[i] = toolWorkspaceView{Tool: w.Tool, Dir: w.Dir, Truncated: w.Truncated, Error: w.Error}
+		for _, f := range w.Files {
+			views[i].Files = append(views[i].Files, toolWorkspaceFileView{Path: f.Path, Bytes: f.Bytes, Content: f.Content})
+		}
+	}
+	return views
+}
+
+// newRunLatencyViews computes the latency statistics of every run, keyed by provider and run name.
+// Returns nil if there are no results.
+func newRunLatencyViews(results runners.Results) map[string]map[string]runLatencyView {
+	if len(results) == 0 {
+		return nil
+	}
+	views := make(map[string]map[string]runLatencyView, len(results))
+	for provider := range results {
+		runs := make(map[string]runLatencyView)
+		for run, resultsByKind := range results.ProviderResultsByRunAndKind(provider) {
+			latency := RunLatency(resultsByKind)
+			runs[run] = runLatencyView{
+				DurationNS:            newDurationPercentilesView(latency.Duration),
+				TimeToFirstTokenNS:    newDurationPercentilesView(latency.TimeToFirstToken),
+				OutputTokensPerSecond: newRatePercentilesView(latency.OutputTokensPerSecond),
+				ToolTimeNS:            newDurationPercentilesView(latency.ToolTime),
+			}
+		}
+		views[provider] = runs
+	}
+	return views
+}
+
+func newDurationPercentilesView(p *Percentiles[time.Duration]) *durationPercentilesView {
+	if p == nil {
+		return nil
+	}
+	return &durationPercentilesView{P50: p.P50.Nanoseconds(), P90: p.P90.Nanoseconds(), P99: p.P99.Nanoseconds()}
+}
+
+func newRatePercentilesView(p *Percentiles[float64]) *ratePercentilesView {
+	if p == nil {
+		return nil
+	}
+	return &ratePercentilesView{P50: p.P50, P90: p.P90, P99: p.P99}
+}
+
+func newConfigurationView(p runners.Provenance) configurationView {
+	v := configurationView{
+		Run: newRunSnapshotView(p.Run),
+	}
+	if p.Judge != nil {
+		v.Judge = &judgeSnapshotView{
+			Name:     p.Judge.Name,
+			Variant:  p.Judge.Variant,
+			Provider: p.Judge.Provider,
+			Run:      newRunSnapshotView(p.Judge.Run),
+		}
+	}
+	return v
+}
+
+func newRunSnapshotView(s runners.RunSnapshot) runSnapshotView {
+	v := runSnapshotView{
+		Model:                   s.Model,
+		MaxRequestsPerMinute:    s.MaxRequestsPerMinute,
+		MaxConcurrentTasks:      s.MaxConcurrentTasks,
+		TimeoutNS:               s.Timeout.Nanoseconds(),
+		ExecutionMode:           s.ExecutionMode,
+		TextOnly:                s.TextOnly,
+		DisableStructuredOutput: s.DisableStructuredOutput,
+		RecordReasoning:         s.RecordReasoning,
+		ModelParams:             s.ModelParams,
+	}
+	if s.RetryPolicy != nil {
+		v.RetryPolicy = &retryPolicyView{
+			MaxRetryAttempts:    s.RetryPolicy.MaxRetryAttempts,
+			InitialDelaySeconds: s.RetryPolicy.InitialDelaySeconds,
+			MaxDelaySeconds:     s.RetryPolicy.MaxDelaySeconds,
+			Jitter:              s.RetryPolicy.Jitter,
+			MaxTotalSeconds:     s.RetryPolicy.MaxTotalSeconds,
+			RetryOn:             s.RetryPolicy.RetryOn,
+		}
+	}
+	if s.ResponseRecovery != nil {
+		v.ResponseRecovery = &responseRecoveryView{
+			Repair:             s.ResponseRecovery.Repair,
+			Coerce:             s.ResponseRecovery.Coerce,
+			MaxCorrectiveTurns: s.ResponseRecovery.MaxCorrectiveTurns,
+		}
+	}
+	return v
+}
+
+func newTaskSnapshotView(s runners.TaskSnapshot) taskSnapshotView {
+	v := taskSnapshotView{
+		Prompt:               s.Prompt,
+		SystemPrompt:         s.SystemPrompt,
+		ResponseResultFormat: s.ResponseResultFormat,
+		ExpectedResult:       s.ExpectedResult,
+		ValidationRules:      s.ValidationRules,
+		Tools:                s.Tools,
+		MaxTurns:             s.MaxTurns,
+		TimeoutNS:            s.Timeout.Nanoseconds(),
+		AnswerExtractor:      s.AnswerExtractor,
+		ToolExpectations:     s.ToolExpectations,
+	}
+	for _, f := range s.Files {
+		v.Files = append(v.Files, taskFileView{
+			Name: f.Name,
+			URI:  f.URI,
+			Type: f.Type,
+		})
 	}
+	return v
 }
 
 // newTaskMetadataView converts runners.TaskMetadata to its view model.
@@ -166,9 +490,28 @@ func newDetailsView(d runners.Details) detailsView {
 		Answer:     newAnswerDetailsView(d.Answer),
 		Validation: newValidationDetailsView(d.Validation),
 		Error:      newErrorDetailsView(d.Error),
+		Events:     newExecutionEventViews(d.Events),
 	}
 }
 
+// newExecutionEventViews converts runners.ExecutionEvent values to their view model.
+// Returns nil for an empty input so the field is omitted entirely.
+func newExecutionEventViews(events []runners.ExecutionEvent) []executionEventView {
+	if len(events) == 0 {
+		return nil
+	}
+	views := make([]executionEventView, len(events))
+	for i, e := range events {
+		views[i] = executionEventView{
+			Time:    e.Time,
+			Source:  e.Source,
+			Message: e.Message,
+			DelayNS: e.Delay.Nanoseconds(),
+		}
+	}
+	return views
+}
+
 func newAnswerDetailsView(a runners.AnswerDetails) *answerDetailsView {
 	v := answerDetailsView{
 		Title:          a.Title,
@@ -178,9 +521,12 @@ func newAnswerDetailsView(a runners.AnswerDetails) *answerDetailsView {
 		Usage:          tokenUsageToPtr(a.Usage),
 		ToolUsage:      newToolUsageMapView(a.ToolUsage),
 		ToolCalls:      newToolCallSummaryViews(a.ToolCalls),
+		Reasoning:      a.Reasoning,
+		Recovery:       a.Recovery,
+		RawAnswer:      a.RawAnswer,
 	}
 	if v.Title == "" && len(v.Explanation) == 0 && len(v.ActualAnswer) == 0 &&
-		len(v.ExpectedAnswer) == 0 && v.Usage == nil && len(v.ToolUsage) == 0 && len(v.ToolCalls) == 0 {
+		len(v.ExpectedAnswer) == 0 && v.Usage == nil && len(v.ToolUsage) == 0 && len(v.ToolCalls) == 0 && len(v.Reasoning) == 0 && v.Recovery == "" && len(v.RawAnswer) == 0 {
 		return nil
 	}
 	return &v
@@ -209,8 +555,9 @@ func newErrorDetailsView(e runners.ErrorDetails) *errorDetailsView {
 		ToolUsage: newToolUsageMapView(e.ToolUsage),
 		ToolCalls: newToolCallSummaryViews(e.ToolCalls),
 		Transient: e.Transient,
+		Attempts:  newAttemptViews(e.Attempts),
 	}
-	if v.Title == "" && v.Message == "" && len(v.Details) == 0 && v.Usage == nil && len(v.ToolUsage) == 0 && len(v.ToolCalls) == 0 && v.Transient == nil {
+	if v.Title == "" && v.Message == "" && len(v.Details) == 0 && v.Usage == nil && len(v.ToolUsage) == 0 && len(v.ToolCalls) == 0 && v.Transient == nil && len(v.Attempts) == 0 {
 		return nil
 	}
 	return &v
@@ -231,9 +578,30 @@ func newToolUsageView(u runners.ToolUsage) toolUsageView {
 	return toolUsageView{
 		CallCount:       u.CallCount,
 		TotalDurationNS: durationToNsPtr(u.TotalDuration),
+		PoolHits:        u.PoolHits,
+		PoolMisses:      u.PoolMisses,
+		PoolSavedNS:     durationToNsPtr(u.PoolSaved),
 	}
 }
 
+// newAttemptViews converts runners.AttemptDetails values to their view model.
+// Returns nil for an empty input so the field is omitted entirely.
+func newAttemptViews(attempts []runners.AttemptDetails) []attemptView {
+	if len(attempts) == 0 {
+		return nil
+	}
+	views := make([]attemptView, len(attempts))
+	for i, a := range attempts {
+		views[i] = attemptView{
+			StartedAt:  a.StartedAt,
+			DurationNS: a.Duration.Nanoseconds(),
+			Error:      a.Error,
+			ErrorClass: a.ErrorClass,
+		}
+	}
+	return views
+}
+
 // newToolCallSummaryViews converts runners.ToolCallSummary values to their view model.
 // Returns nil for an empty input so the field is omitted entirely, consistent with the
 // other optional-field conventions in this file.
@@ -257,6 +625,25 @@ func newToolCallSummaryViews(calls []runners.ToolCallSummary) []toolCallSummaryV
 			Stdout:           newToolCallOutputView(c.Stdout),
 			Stderr:           newToolCallOutputView(c.Stderr),
 			ErrorMessage:     c.ErrorMessage,
+			Artifacts:        newToolArtifactViews(c.Artifacts),
+		}
+	}
+	return views
+}
+
+// newToolArtifactViews converts runners.ToolArtifact values to their view models. The
+// content of artifacts is not included; only stored artifacts can be linked via File.
+func newToolArtifactViews(artifacts []runners.ToolArtifact) []toolArtifactView {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	views := make([]toolArtifactView, len(artifacts))
+	for i, a := range artifacts {
+		views[i] = toolArtifactView{
+			Path:      a.Path,
+			Bytes:     a.Bytes,
+			MediaType: a.MediaType,
+			File:      a.File,
 		}
 	}
 	return views
@@ -286,6 +673,7 @@ func durationToNsPtr(d *time.Duration) *int64 {
 func tokenUsageToPtr(u runners.TokenUsage) *runners.TokenUsage {
 	if u.InputTokens == nil &&
 		u.OutputTokens == nil &&
+		u.ReasoningTokens == nil &&
 		u.InputCacheWriteTokens == nil &&
 		u.InputCacheReadTokens == nil &&
 		u.InputTokenAccounting == "" {
@@ -301,8 +689,9 @@ func tokenUsageFromPtr(u *runners.TokenUsage) runners.TokenUsage {
 	return *u
 }
 
-// fromResultsView converts a resultsView back to runners.Results.
-func fromResultsView(rv resultsView) (runners.Results, error) {
+// fromResultsView converts a resultsView back to runners.Results, restoring each result's
+// provenance from the given provenance sections.
+func fromResultsView(rv resultsView, pv provenanceViews) (runners.Results, error) {
 	results := make(runners.Results, len(rv))
 	for provider, views := range rv {
 		runResults := make([]runners.RunResult, len(views))
@@ -311,6 +700,9 @@ func fromResultsView(rv resultsView) (runners.Results, error) {
 			if err != nil {
 				return nil, err
 			}
+			if r.Provenance, err = fromProvenanceViews(v.ConfigHash, v.TaskHash, pv); err != nil {
+				return nil, err
+			}
 			if r.Provider != provider {
 				return nil, fmt.Errorf("%w: provider key %q does not match entry provider %q", ErrReadResults, provider, r.Provider)
 			}
@@ -337,9 +729,122 @@ func fromResultView(v resultView) (runners.RunResult, error) {
 		TaskMetadata: fromTaskMetadataView(v.TaskMetadata),
 		Details:      fromDetailsView(v.Details),
 		Duration:     time.Duration(v.DurationNS),
+		Timing:       fromTimingView(v.Timing),
+		Workspaces:   fromToolWorkspaceViews(v.Workspaces),
 	}, nil
 }
 
+func fromToolWorkspaceViews(views []toolWorkspaceView) []runners.ToolWorkspace {
+	if len(views) == 0 {
+		return nil
+	}
+	workspaces := make([]runners.ToolWorkspace, len(views))
+	for i, v := range views {
+		workspaces[i] = runners.ToolWorkspace{Tool: v.Tool, Dir: v.Dir, Truncated: v.Truncated, Error: v.Error}
+		for _, f := range v.Files {
+			workspaces[i].Files = append(workspaces[i].Files, runners.ToolWorkspaceFile{Path: f.Path, Bytes: f.Bytes, Content: f.Content})
+		}
+	}
+	return workspaces
+}
+
+func fromTimingView(v *timingView) *runners.Timing {
+	if v == nil {
+		return nil
+	}
+	t := &runners.Timing{ToolTime: time.Duration(v.ToolTimeNS)}
+	for _, r := range v.Requests {
+		t.Requests = append(t.Requests, runners.RequestTiming{
+			Duration:         time.Duration(r.DurationNS),
+			TimeToFirstToken: nsToDurationPtr(r.TimeToFirstTokenNS),
+			OutputTokens:     r.OutputTokens,
+		})
+	}
+	return t
+}
+
+// fromProvenanceViews looks up the provenance sections referenced by a result.
+// Returns nil when the result does not reference any provenance (e.g. in a format
+// version 1 document).
+func fromProvenanceViews(configHash string, taskHash string, pv provenanceViews) (*runners.Provenance, error) {
+	if configHash == "" && taskHash == "" {
+		return nil, nil
+	}
+	configuration, ok := pv.configurations[configHash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownConfigHash, configHash)
+	}
+	task, ok := pv.tasks[taskHash]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownTaskHash, taskHash)
+	}
+	p := &runners.Provenance{
+		ConfigHash: configHash,
+		Run:        fromRunSnapshotView(configuration.Run),
+		Task: runners.TaskSnapshot{
+			Hash:                 taskHash,
+			Prompt:               task.Prompt,
+			SystemPrompt:         task.SystemPrompt,
+			ResponseResultFormat: task.ResponseResultFormat,
+			ExpectedResult:       task.ExpectedResult,
+			ValidationRules:      task.ValidationRules,
+			Tools:                task.Tools,
+			MaxTurns:             task.MaxTurns,
+			Timeout:              time.Duration(task.TimeoutNS),
+			AnswerExtractor:      task.AnswerExtractor,
+			ToolExpectations:     task.ToolExpectations,
+		},
+	}
+	for _, f := range task.Files {
+		p.Task.Files = append(p.Task.Files, runners.TaskFileSnapshot{
+			Name: f.Name,
+			URI:  f.URI,
+			Type: f.Type,
+		})
+	}
+	if configuration.Judge != nil {
+		p.Judge = &runners.JudgeSnapshot{
+			Name:     configuration.Judge.Name,
+			Variant:  configuration.Judge.Variant,
+			Provider: configuration.Judge.Provider,
+			Run:      fromRunSnapshotView(configuration.Judge.Run),
+		}
+	}
+	return p, nil
+}
+
+func fromRunSnapshotView(v runSnapshotView) runners.RunSnapshot {
+	s := runners.RunSnapshot{
+		Model:                   v.Model,
+		MaxRequestsPerMinute:    v.MaxRequestsPerMinute,
+		MaxConcurrentTasks:      v.MaxConcurrentTasks,
+		Timeout:                 time.Duration(v.TimeoutNS),
+		ExecutionMode:           v.ExecutionMode,
+		TextOnly:                v.TextOnly,
+		DisableStructuredOutput: v.DisableStructuredOutput,
+		RecordReasoning:         v.RecordReasoning,
+		ModelParams:             v.ModelParams,
+	}
+	if v.RetryPolicy != nil {
+		s.RetryPolicy = &runners.RetryPolicySnapshot{
+			MaxRetryAttempts:    v.RetryPolicy.MaxRetryAttempts,
+			InitialDelaySeconds: v.RetryPolicy.InitialDelaySeconds,
+			MaxDelaySeconds:     v.RetryPolicy.MaxDelaySeconds,
+			Jitter:              v.RetryPolicy.Jitter,
+			MaxTotalSeconds:     v.RetryPolicy.MaxTotalSeconds,
+			RetryOn:             v.RetryPolicy.RetryOn,
+		}
+	}
+	if v.ResponseRecovery != nil {
+		s.ResponseRecovery = &runners.ResponseRecoverySnapshot{
+			Repair:             v.ResponseRecovery.Repair,
+			Coerce:             v.ResponseRecovery.Coerce,
+			MaxCorrectiveTurns: v.ResponseRecovery.MaxCorrectiveTurns,
+		}
+	}
+	return s
+}
+
 // fromTaskMetadataView converts a taskMetadataView back to runners.TaskMetadata.
 // A nil view produces a zero-value TaskMetadata.
 func fromTaskMetadataView(v *taskMetadataView) runners.TaskMetadata {
@@ -365,6 +870,9 @@ func fromDetailsView(d detailsView) runners.Details {
 			Usage:          tokenUsageFromPtr(d.Answer.Usage),
 			ToolUsage:      fromToolUsageMapView(d.Answer.ToolUsage),
 			ToolCalls:      fromToolCallSummaryViews(d.Answer.ToolCalls),
+			Reasoning:      d.Answer.Reasoning,
+			Recovery:       d.Answer.Recovery,
+			RawAnswer:      d.Answer.RawAnswer,
 		}
 	}
 	if d.Validation != nil {
@@ -385,11 +893,31 @@ func fromDetailsView(d detailsView) runners.Details {
 			ToolUsage: fromToolUsageMapView(d.Error.ToolUsage),
 			ToolCalls: fromToolCallSummaryViews(d.Error.ToolCalls),
 			Transient: d.Error.Transient,
+			Attempts:  fromAttemptViews(d.Error.Attempts),
 		}
 	}
+	result.Events = fromExecutionEventViews(d.Events)
 	return result
 }
 
+// fromExecutionEventViews converts view models back to runners.ExecutionEvent.
+// Returns nil for an empty input, matching newExecutionEventViews.
+func fromExecutionEventViews(views []executionEventView) []runners.ExecutionEvent {
+	if len(views) == 0 {
+		return nil
+	}
+	events := make([]runners.ExecutionEvent, len(views))
+	for i, v := range views {
+		events[i] = runners.ExecutionEvent{
+			Time:    v.Time,
+			Source:  v.Source,
+			Message: v.Message,
+			Delay:   time.Duration(v.DelayNS),
+		}
+	}
+	return events
+}
+
 func fromToolUsageMapView(m map[string]toolUsageView) map[string]runners.ToolUsage {
 	if m == nil {
 		return nil
@@ -405,7 +933,28 @@ func fromToolUsageView(v toolUsageView) runners.ToolUsage {
 	return runners.ToolUsage{
 		CallCount:     v.CallCount,
 		TotalDuration: nsToDurationPtr(v.TotalDurationNS),
+		PoolHits:      v.PoolHits,
+		PoolMisses:    v.PoolMisses,
+		PoolSaved:     nsToDurationPtr(v.PoolSavedNS),
+	}
+}
+
+// fromAttemptViews converts view models back to runners.AttemptDetails.
+// Returns nil for an empty input, matching newAttemptViews.
+func fromAttemptViews(views []attemptView) []runners.AttemptDetails {
+	if len(views) == 0 {
+		return nil
+	}
+	attempts := make([]runners.AttemptDetails, len(views))
+	for i, v := range views {
+		attempts[i] = runners.AttemptDetails{
+			StartedAt:  v.StartedAt,
+			Duration:   time.Duration(v.DurationNS),
+			Error:      v.Error,
+			ErrorClass: v.ErrorClass,
+		}
 	}
+	return attempts
 }
 
 // fromToolCallSummaryViews converts view models back to runners.ToolCallSummary.
@@ -431,11 +980,29 @@ func fromToolCallSummaryViews(views []toolCallSummaryView) []runners.ToolCallSum
 			Stdout:           fromToolCallOutputView(v.Stdout),
 			Stderr:           fromToolCallOutputView(v.Stderr),
 			ErrorMessage:     v.ErrorMessage,
+			Artifacts:        fromToolArtifactViews(v.Artifacts),
 		}
 	}
 	return calls
 }
 
+// fromToolArtifactViews converts toolArtifactView values back to runners.ToolArtifact.
+func fromToolArtifactViews(views []toolArtifactView) []runners.ToolArtifact {
+	if len(views) == 0 {
+		return nil
+	}
+	artifacts := make([]runners.ToolArtifact, len(views))
+	for i, v := range views {
+		artifacts[i] = runners.ToolArtifact{
+			Path:      v.Path,
+			Bytes:     v.Bytes,
+			MediaType: v.MediaType,
+			File:      v.File,
+		}
+	}
+	return artifacts
+}
+
 // fromToolCallOutputView converts a toolCallOutputView back to runners.ToolCallOutput.
 // Returns nil when v is nil (the stream was never captured for this call).
 func fromToolCallOutputView(v *toolCallOutputView) *runners.ToolCallOutput {
@@ -22,6 +22,10 @@ func TestResultKindMappingConsistency(t *testing.T) {
 		runners.Failure,
 		runners.Error,
 		runners.NotSupported,
+		runners.Cancelled,
+		runners.ProviderUnavailable,
+		runners.TimedOut,
+		runners.ExtractionFailed,
 	}
 
 	t.Run("every ResultKind has a stringToResultKind entry", func(t *testing.T) {
@@ -210,6 +214,38 @@ func TestToolCallSummaryViewsRoundTrip(t *testing.T) {
 	})
 }
 
+func TestToolWorkspaceViewsRoundTrip(t *testing.T) {
+	t.Run("empty input maps to nil view", func(t *testing.T) {
+		view := newToolWorkspaceViews([]runners.ToolWorkspace{})
+		assert.Nil(t, view)
+		assert.Nil(t, fromToolWorkspaceViews(view))
+	})
+
+	t.Run("workspaces round-trip including omitted content", func(t *testing.T) {
+		workspaces := []runners.ToolWorkspace{
+			{
+				Tool: "python",
+				Dir:  "/workspace",
+				Files: []runners.ToolWorkspaceFile{
+					{Path: "main.py", Bytes: 13, Content: testutils.Ptr("print('done')")},
+					{Path: "out/data.bin", Bytes: 4096},
+				},
+				Truncated: true,
+			},
+			{
+				Tool:  "shell",
+				Dir:   "/work",
+				Error: "failed to copy workspace from tool container",
+			},
+		}
+		view := newToolWorkspaceViews(workspaces)
+		require.Len(t, view, 2)
+		assert.Nil(t, view[0].Files[1].Content)
+		assert.Empty(t, view[1].Files)
+		assert.Equal(t, workspaces, fromToolWorkspaceViews(view))
+	})
+}
+
 func TestErrorDetailsViewTransientRoundTrip(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -242,3 +278,39 @@ func TestErrorDetailsViewTransientRoundTrip(t *testing.T) {
 		assert.Equal(t, details, fromDetailsView(view))
 	})
 }
+
+func TestErrorDetailsViewAttemptsRoundTrip(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	details := runners.Details{
+		Error: runners.ErrorDetails{
+			Attempts: []runners.AttemptDetails{
+				{StartedAt: startedAt, Duration: 2 * time.Second, Error: "too many requests", ErrorClass: "rate-limit"},
+				{StartedAt: startedAt.Add(5 * time.Second), Duration: time.Second, Error: "bad gateway"},
+			},
+		},
+	}
+	view := newDetailsView(details)
+	require.NotNil(t, view.Error, "attempts alone are not treated as an empty error")
+	require.Len(t, view.Error.Attempts, 2)
+	assert.Equal(t, int64(2*time.Second), view.Error.Attempts[0].DurationNS)
+	assert.Empty(t, view.Error.Attempts[1].ErrorClass)
+	assert.Equal(t, details, fromDetailsView(view))
+}
+
+func TestDetailsViewEventsRoundTrip(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	details := runners.Details{
+		Events: []runners.ExecutionEvent{
+			{Time: at, Source: "rate-limit", Message: "waited 2s for the run rate limit", Delay: 2 * time.Second},
+			{Time: at.Add(time.Second), Source: "rate-limit", Message: "lowered the rate limit"},
+		},
+	}
+	view := newDetailsView(details)
+	require.Len(t, view.Events, 2)
+	assert.Equal(t, int64(2*time.Second), view.Events[0].DelayNS)
+	assert.Zero(t, view.Events[1].DelayNS)
+	assert.Nil(t, view.Error)
+	assert.Equal(t, details, fromDetailsView(view))
+
+	assert.Nil(t, newDetailsView(runners.Details{}).Events)
+}
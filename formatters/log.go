@@ -28,16 +28,34 @@ func (f logFormatter) FileExt() string {
 func (f logFormatter) Write(results runners.Results, out io.Writer) error {
 	tab := tabwriter.NewWriter(out, 0, 0, 1, ' ', tabwriter.Debug)
 	defer tab.Flush()
-	if _, err := fmt.Fprintln(tab, "TraceID\tProvider\tRun\tTask\tStatus\tDuration\tAnswer\t"); err != nil {
+	if _, err := fmt.Fprintln(tab, "TraceID\tProvider\tRun\tTask\tStatus\tDuration\tTTFT\tOutput Tokens/s\tTool Time\tAnswer\t"); err != nil {
 		return fmt.Errorf("%w: %v", ErrPrintResults, err)
 	}
 
 	return ForEachOrdered(results, func(_ string, runResults []runners.RunResult) error {
 		for _, result := range runResults {
-			if _, err := fmt.Fprintf(tab, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", result.TraceID, result.Provider, result.Run, result.Task, ToStatus(result.Kind), RoundToMS(result.Duration), formatAnswerText(result)); err != nil {
+			ttft, tokenRate, toolTime := formatTiming(result.Timing)
+			if _, err := fmt.Fprintf(tab, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", result.TraceID, result.Provider, result.Run, result.Task, ToStatus(result.Kind), RoundToMS(result.Duration), ttft, tokenRate, toolTime, formatAnswerText(result)); err != nil {
 				return fmt.Errorf("%w: %v", ErrPrintResults, err)
 			}
 		}
 		return nil
 	})
 }
+
+// formatTiming formats the time to first token, output token throughput and tool time
+// of a single result, using "-" for metrics that were not recorded.
+func formatTiming(timing *runners.Timing) (ttft string, tokenRate string, toolTime string) {
+	ttft, tokenRate, toolTime = "-", "-", "-"
+	if timing == nil {
+		return
+	}
+	if value := timing.TimeToFirstToken(); value != nil {
+		ttft = RoundToMS(*value).String()
+	}
+	if value := timing.OutputTokensPerSecond(); value != nil {
+		tokenRate = fmt.Sprintf("%.1f", *value)
+	}
+	toolTime = RoundToMS(timing.ToolTime).String()
+	return
+}
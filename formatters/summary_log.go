@@ -28,22 +28,31 @@ func (f summaryLogFormatter) FileExt() string {
 func (f summaryLogFormatter) Write(results runners.Results, out io.Writer) error {
 	tab := tabwriter.NewWriter(out, 0, 0, 1, ' ', tabwriter.Debug)
 	defer tab.Flush()
-	if _, err := fmt.Fprintf(tab, "Provider\tRun\t%s\t%s\t%s\t%s\tPass Rate (%%)\tAccuracy (%%)\tError Rate (%%)\tTotal Duration\t\n", Passed, Failed, Error, Skipped); err != nil {
+	if _, err := fmt.Fprintf(tab, "Provider\tRun\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\tPass Rate (%%)\tAccuracy (%%)\tError Rate (%%)\tTotal Duration\tLatency p50/p90/p99\tTTFT p50/p90/p99\tOutput Tokens/s p50/p90/p99\tTool Time p50/p90/p99\t\n", Passed, Failed, Error, Skipped, Cancelled, Unavailable, TimedOut, ExtractionFailed); err != nil {
 		return fmt.Errorf("%w: %v", ErrPrintResults, err)
 	}
 	return ForEachOrdered(results, func(provider string, _ []runners.RunResult) error {
 		resultsByRunAndKind := results.ProviderResultsByRunAndKind(provider)
 		return ForEachOrdered(resultsByRunAndKind, func(run string, resultsByKind map[runners.ResultKind][]runners.RunResult) error {
-			if _, err := fmt.Fprintf(tab, "%s\t%s\t%d\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t%s\t\n",
+			latency := RunLatency(resultsByKind)
+			if _, err := fmt.Fprintf(tab, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t%s\t%s\t%s\t%s\t%s\t\n",
 				provider, run,
 				CountByKind(resultsByKind, runners.Success),
 				CountByKind(resultsByKind, runners.Failure),
 				CountByKind(resultsByKind, runners.Error),
 				CountByKind(resultsByKind, runners.NotSupported),
+				CountByKind(resultsByKind, runners.Cancelled),
+				CountByKind(resultsByKind, runners.ProviderUnavailable),
+				CountByKind(resultsByKind, runners.TimedOut),
+				CountByKind(resultsByKind, runners.ExtractionFailed),
 				Percent(PassRate(resultsByKind)),
 				Percent(AccuracyRate(resultsByKind)),
 				Percent(ErrorRate(resultsByKind)),
-				RoundToMS(TotalDuration(resultsByKind, runners.Success, runners.Failure, runners.Error, runners.NotSupported))); err != nil {
+				RoundToMS(TotalDuration(resultsByKind, runners.Success, runners.Failure, runners.Error, runners.NotSupported, runners.Cancelled, runners.ProviderUnavailable, runners.TimedOut, runners.ExtractionFailed)),
+				FormatDurationPercentiles(latency.Duration),
+				FormatDurationPercentiles(latency.TimeToFirstToken),
+				FormatRatePercentiles(latency.OutputTokensPerSecond),
+				FormatDurationPercentiles(latency.ToolTime)); err != nil {
 				return fmt.Errorf("%w: %v", ErrPrintResults, err)
 			}
 			return nil
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
@@ -31,6 +32,14 @@ const (
 	Error = "Error"
 	// Skipped indicates that the task was skipped by the provider.
 	Skipped = "Skipped"
+	// Cancelled indicates that the task was not started or did not finish because the run was canceled.
+	Cancelled = "Cancelled"
+	// Unavailable indicates that the task was not sent because the circuit breaker gave up on the provider.
+	Unavailable = "Unavailable"
+	// TimedOut indicates that the task was stopped because it exceeded its timeout or the trial deadline.
+	TimedOut = "TimedOut"
+	// ExtractionFailed indicates that the answer extractor configured for the task found no answer in the response.
+	ExtractionFailed = "ExtractionFailed"
 
 	// Transient identifies an error category: the error appears temporary/external and a
 	// retry may succeed. See ToErrorCategory.
@@ -60,10 +69,29 @@ func ToStatus(kind runners.ResultKind) string {
 		return Error
 	case runners.NotSupported:
 		return Skipped
+	case runners.Cancelled:
+		return Cancelled
+	case runners.ProviderUnavailable:
+		return Unavailable
+	case runners.TimedOut:
+		return TimedOut
+	case runners.ExtractionFailed:
+		return ExtractionFailed
 	}
 	return fmt.Sprintf("%s (%d)", Unknown, kind)
 }
 
+// ParseStatus converts a human-readable status string, as returned by ToStatus, back to its
+// runners.ResultKind value. The comparison is case-insensitive.
+func ParseStatus(status string) (runners.ResultKind, bool) {
+	for name, kind := range stringToResultKind {
+		if strings.EqualFold(name, status) {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
 // CountByKind returns the number of run results of a given kind.
 func CountByKind(resultsByKind map[runners.ResultKind][]runners.RunResult, kind runners.ResultKind) int {
 	return len(resultsByKind[kind])
@@ -79,18 +107,26 @@ func TotalDuration(resultsByKind map[runners.ResultKind][]runners.RunResult, inc
 	return
 }
 
+// erroredKinds lists the kinds of attempted tasks that did not produce an answer.
+// They count as errors in the rate calculations.
+var erroredKinds = []runners.ResultKind{runners.Error, runners.ProviderUnavailable, runners.TimedOut, runners.ExtractionFailed}
+
+// attemptedKinds lists the kinds of tasks that were attempted: passed, failed and errored tasks.
+var attemptedKinds = append([]runners.ResultKind{runners.Success, runners.Failure}, erroredKinds...)
+
 // PassRate returns the fraction of tasks that passed out of all attempted tasks
-// (passed, failed, error). Skipped tasks are excluded.
+// (passed, failed, error, unavailable, timed out, extraction failed). Skipped and cancelled
+// tasks are excluded.
 func PassRate(resultsByKind map[runners.ResultKind][]runners.RunResult) float64 {
 	return rate(
 		resultsByKind,
 		[]runners.ResultKind{runners.Success},
-		[]runners.ResultKind{runners.Success, runners.Failure, runners.Error},
+		attemptedKinds,
 	)
 }
 
 // AccuracyRate returns the fraction of correct results (passed) among completed tasks
-// (passed or failed). Errors and skipped tasks are excluded.
+// (passed or failed). Errors, skipped and cancelled tasks are excluded.
 func AccuracyRate(resultsByKind map[runners.ResultKind][]runners.RunResult) float64 {
 	return rate(
 		resultsByKind,
@@ -99,13 +135,14 @@ func AccuracyRate(resultsByKind map[runners.ResultKind][]runners.RunResult) floa
 	)
 }
 
-// ErrorRate returns the fraction of tasks that errored among attempted tasks
-// (passed, failed, error). Skipped tasks are excluded.
+// ErrorRate returns the fraction of tasks that errored, found the provider unavailable, timed out
+// or had no answer extracted among attempted tasks (passed, failed, error, unavailable, timed out,
+// extraction failed). Skipped and cancelled tasks are excluded.
 func ErrorRate(resultsByKind map[runners.ResultKind][]runners.RunResult) float64 {
 	return rate(
 		resultsByKind,
-		[]runners.ResultKind{runners.Error},
-		[]runners.ResultKind{runners.Success, runners.Failure, runners.Error},
+		erroredKinds,
+		attemptedKinds,
 	)
 }
 
@@ -124,6 +161,86 @@ func rate(resultsByKind map[runners.ResultKind][]runners.RunResult, numeratorKin
 	return float64(numerator) / float64(denominator)
 }
 
+// Percentiles holds the 50th, 90th and 99th percentiles of a set of values.
+type Percentiles[T time.Duration | float64] struct {
+	P50 T
+	P90 T
+	P99 T
+}
+
+// LatencyStats summarizes the latency and throughput of the tasks in a run.
+// A nil field means that the metric was not recorded for any task.
+type LatencyStats struct {
+	// Duration summarizes the time the model spent generating each task's response.
+	Duration *Percentiles[time.Duration]
+	// TimeToFirstToken summarizes the time to first token of each task's first model request.
+	TimeToFirstToken *Percentiles[time.Duration]
+	// OutputTokensPerSecond summarizes the output token throughput of each task.
+	OutputTokensPerSecond *Percentiles[float64]
+	// ToolTime summarizes the time spent executing tools for each task.
+	ToolTime *Percentiles[time.Duration]
+}
+
+// RunLatency computes the latency statistics of the runs of a single run configuration.
+// Only tasks that were sent to the provider and ran until they finished or timed out are included.
+func RunLatency(resultsByKind map[runners.ResultKind][]runners.RunResult) LatencyStats {
+	var durations, ttfts, toolTimes []time.Duration
+	var tokenRates []float64
+	for _, kind := range []runners.ResultKind{runners.Success, runners.Failure, runners.Error, runners.TimedOut, runners.ExtractionFailed} {
+		for _, result := range resultsByKind[kind] {
+			durations = append(durations, result.Duration)
+			if result.Timing == nil {
+				continue
+			}
+			if ttft := result.Timing.TimeToFirstToken(); ttft != nil {
+				ttfts = append(ttfts, *ttft)
+			}
+			if tokenRate := result.Timing.OutputTokensPerSecond(); tokenRate != nil {
+				tokenRates = append(tokenRates, *tokenRate)
+			}
+			toolTimes = append(toolTimes, result.Timing.ToolTime)
+		}
+	}
+	return LatencyStats{
+		Duration:              percentiles(durations),
+		TimeToFirstToken:      percentiles(ttfts),
+		OutputTokensPerSecond: percentiles(tokenRates),
+		ToolTime:              percentiles(toolTimes),
+	}
+}
+
+// percentiles computes the nearest-rank percentiles of the given values.
+// Returns nil if there are no values.
+func percentiles[T time.Duration | float64](values []T) *Percentiles[T] {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := func(p float64) T {
+		return sorted[int(math.Ceil(p/100*float64(len(sorted))))-1]
+	}
+	return &Percentiles[T]{P50: rank(50), P90: rank(90), P99: rank(99)}
+}
+
+// FormatDurationPercentiles formats duration percentiles rounded to milliseconds as "p50 / p90 / p99".
+// Returns "-" if p is nil.
+func FormatDurationPercentiles(p *Percentiles[time.Duration]) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s / %s", RoundToMS(p.P50), RoundToMS(p.P90), RoundToMS(p.P99))
+}
+
+// FormatRatePercentiles formats rate percentiles with one decimal place as "p50 / p90 / p99".
+// Returns "-" if p is nil.
+func FormatRatePercentiles(p *Percentiles[float64]) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f / %.1f / %.1f", p.P50, p.P90, p.P99)
+}
+
 // Percent converts a fraction (0..1) to a percentage (0..100) rounded to 2 decimals.
 func Percent(rate float64) float64 {
 	percent := rate * 100
@@ -137,7 +254,7 @@ func FormatAnswer(result runners.RunResult, useHTML bool) (answers []string) {
 	gotStr := utils.ToString(result.Got)
 
 	switch result.Kind {
-	case runners.Success, runners.Error, runners.NotSupported:
+	case runners.Success, runners.Error, runners.NotSupported, runners.Cancelled, runners.ProviderUnavailable, runners.TimedOut, runners.ExtractionFailed:
 		if useHTML {
 			gotStr = "<pre>" + gotStr + "</pre>"
 		}
@@ -295,3 +412,18 @@ func ToErrorCategory(details runners.ErrorDetails) string {
 	}
 	return Permanent
 }
+
+// HasArtifacts reports whether any of the tool calls collected artifacts.
+func HasArtifacts(calls []runners.ToolCallSummary) bool {
+	for _, call := range calls {
+		if len(call.Artifacts) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsImage reports whether the media type denotes an image that can be displayed inline.
+func IsImage(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "image/")
+}
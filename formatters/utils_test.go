@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/petmal/mindtrial/pkg/testutils"
 	"github.com/petmal/mindtrial/pkg/utils"
 	"github.com/petmal/mindtrial/runners"
 	"github.com/stretchr/testify/assert"
@@ -44,6 +45,26 @@ func TestToStatus(t *testing.T) {
 			kind: runners.NotSupported,
 			want: Skipped,
 		},
+		{
+			name: "Cancelled",
+			kind: runners.Cancelled,
+			want: Cancelled,
+		},
+		{
+			name: "ProviderUnavailable",
+			kind: runners.ProviderUnavailable,
+			want: Unavailable,
+		},
+		{
+			name: "TimedOut",
+			kind: runners.TimedOut,
+			want: TimedOut,
+		},
+		{
+			name: "ExtractionFailed",
+			kind: runners.ExtractionFailed,
+			want: ExtractionFailed,
+		},
 		{
 			name: "Unknown",
 			kind: runners.ResultKind(999),
@@ -57,6 +78,21 @@ func TestToStatus(t *testing.T) {
 	}
 }
 
+func TestParseStatus(t *testing.T) {
+	for _, kind := range []runners.ResultKind{runners.Success, runners.Failure, runners.Error, runners.NotSupported, runners.Cancelled, runners.ProviderUnavailable, runners.TimedOut, runners.ExtractionFailed} {
+		got, ok := ParseStatus(ToStatus(kind))
+		require.True(t, ok)
+		assert.Equal(t, kind, got)
+	}
+
+	got, ok := ParseStatus("skipped")
+	require.True(t, ok)
+	assert.Equal(t, runners.NotSupported, got)
+
+	_, ok = ParseStatus("Unknown (999)")
+	assert.False(t, ok)
+}
+
 func TestCountByKind(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -214,6 +250,17 @@ func TestPassRate(t *testing.T) {
 			// passed/(passed+failed+error) = 1/(1+1+2) = 0.25 (skipped excluded)
 			want: 0.25,
 		},
+		{
+			name: "unavailable counts as attempted",
+			resultsByKind: map[runners.ResultKind][]runners.RunResult{
+				runners.Success:             {{}},
+				runners.Failure:             {{}},
+				runners.ProviderUnavailable: {{}, {}},
+				runners.Cancelled:           {{}},
+			},
+			// passed/(passed+failed+error+unavailable) = 1/(1+1+0+2) = 0.25 (cancelled excluded)
+			want: 0.25,
+		},
 	}
 
 	for _, tt := range tests {
@@ -290,6 +337,34 @@ func TestErrorRate(t *testing.T) {
 			// errors/(passed+failed+error) = 2/(3+1+2)=2/6=0.333...
 			want: 2.0 / 6.0,
 		},
+		{
+			name: "unavailable counts as errored",
+			resultsByKind: map[runners.ResultKind][]runners.RunResult{
+				runners.Success:             {{}, {}},
+				runners.Error:               {{}},
+				runners.ProviderUnavailable: {{}},
+			},
+			// (error+unavailable)/(passed+failed+error+unavailable) = (1+1)/(2+0+1+1) = 0.5
+			want: 0.5,
+		},
+		{
+			name: "timed out counts as errored",
+			resultsByKind: map[runners.ResultKind][]runners.RunResult{
+				runners.Success:  {{}, {}, {}},
+				runners.TimedOut: {{}},
+			},
+			// timed out/(passed+timed out) = 1/(3+1) = 0.25
+			want: 0.25,
+		},
+		{
+			name: "extraction failed counts as errored",
+			resultsByKind: map[runners.ResultKind][]runners.RunResult{
+				runners.Failure:          {{}},
+				runners.ExtractionFailed: {{}},
+			},
+			// extraction failed/(failed+extraction failed) = 1/(1+1) = 0.5
+			want: 0.5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -318,6 +393,75 @@ func TestPercent(t *testing.T) {
 	}
 }
 
+func TestPercentiles(t *testing.T) {
+	var hundred []float64
+	for i := 100; i > 0; i-- {
+		hundred = append(hundred, float64(i))
+	}
+	tests := []struct {
+		name   string
+		values []float64
+		want   *Percentiles[float64]
+	}{
+		{name: "no values", values: nil, want: nil},
+		{name: "single value", values: []float64{7}, want: &Percentiles[float64]{P50: 7, P90: 7, P99: 7}},
+		{name: "nearest rank", values: []float64{5, 1, 4, 2, 3}, want: &Percentiles[float64]{P50: 3, P90: 5, P99: 5}},
+		{
+			name:   "hundred values",
+			values: hundred,
+			want:   &Percentiles[float64]{P50: 50, P90: 90, P99: 99},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, percentiles(tt.values))
+		})
+	}
+}
+
+func TestRunLatency(t *testing.T) {
+	ttft := 500 * time.Millisecond
+	resultsByKind := map[runners.ResultKind][]runners.RunResult{
+		runners.Success: {
+			{Duration: 3 * time.Second, Timing: &runners.Timing{
+				Requests: []runners.RequestTiming{{Duration: 3 * time.Second, TimeToFirstToken: &ttft, OutputTokens: testutils.Ptr(int64(100))}},
+				ToolTime: time.Second,
+			}},
+			{Duration: time.Second},
+		},
+		runners.Error: {
+			{Duration: 2 * time.Second, Timing: &runners.Timing{
+				Requests: []runners.RequestTiming{{Duration: 2 * time.Second}},
+			}},
+		},
+		runners.TimedOut: {
+			{Duration: 4 * time.Second},
+		},
+		runners.NotSupported: {
+			{Duration: time.Minute},
+		},
+		runners.ProviderUnavailable: {
+			{Duration: time.Minute},
+		},
+	}
+
+	assert.Equal(t, LatencyStats{
+		Duration:              &Percentiles[time.Duration]{P50: 2 * time.Second, P90: 4 * time.Second, P99: 4 * time.Second},
+		TimeToFirstToken:      &Percentiles[time.Duration]{P50: ttft, P90: ttft, P99: ttft},
+		OutputTokensPerSecond: &Percentiles[float64]{P50: 40, P90: 40, P99: 40},
+		ToolTime:              &Percentiles[time.Duration]{P50: 0, P90: time.Second, P99: time.Second},
+	}, RunLatency(resultsByKind))
+
+	assert.Equal(t, LatencyStats{}, RunLatency(map[runners.ResultKind][]runners.RunResult{}))
+}
+
+func TestFormatPercentiles(t *testing.T) {
+	assert.Equal(t, "-", FormatDurationPercentiles(nil))
+	assert.Equal(t, "1.5s / 2s / 10ms", FormatDurationPercentiles(&Percentiles[time.Duration]{P50: 1500 * time.Millisecond, P90: 2 * time.Second, P99: 10*time.Millisecond + 400*time.Microsecond}))
+	assert.Equal(t, "-", FormatRatePercentiles(nil))
+	assert.Equal(t, "12.3 / 45.7 / 100.0", FormatRatePercentiles(&Percentiles[float64]{P50: 12.34, P90: 45.67, P99: 100}))
+}
+
 func TestFormatAnswer(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -822,3 +966,16 @@ func TestToErrorCategory(t *testing.T) {
 		})
 	}
 }
+
+func TestHasArtifacts(t *testing.T) {
+	assert.False(t, HasArtifacts(nil))
+	assert.False(t, HasArtifacts([]runners.ToolCallSummary{{Tool: "python"}}))
+	assert.True(t, HasArtifacts([]runners.ToolCallSummary{{Tool: "python"}, {Tool: "plot", Artifacts: []runners.ToolArtifact{{Path: "/out/chart.png"}}}}))
+}
+
+func TestIsImage(t *testing.T) {
+	assert.True(t, IsImage("image/png"))
+	assert.True(t, IsImage("image/svg+xml"))
+	assert.False(t, IsImage("text/plain; charset=utf-8"))
+	assert.False(t, IsImage(""))
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidJSONPath indicates a malformed or unsupported JSONPath expression.
+var ErrInvalidJSONPath = errors.New("invalid JSONPath expression")
+
+// JSONPath is a parsed JSONPath expression selecting values from decoded JSON data.
+// It supports the root `$` followed by member names (`.name` or `['name']`), array
+// indices (`[0]`, with negative indices counting from the end) and wildcards (`.*` or `[*]`).
+type JSONPath struct {
+	expr     string
+	segments []jsonPathSegment
+}
+
+// jsonPathSegment is a single step of a JSONPath expression.
+type jsonPathSegment struct {
+	name     string // member name, if not an index or wildcard
+	index    int    // array index, if isIndex
+	isIndex  bool
+	wildcard bool
+}
+
+// ParseJSONPath parses a JSONPath expression.
+func ParseJSONPath(expr string) (JSONPath, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "$")
+	if !ok {
+		return JSONPath{}, fmt.Errorf("%w: %q must start with $", ErrInvalidJSONPath, expr)
+	}
+	path := JSONPath{expr: expr}
+	for rest != "" {
+		var segment jsonPathSegment
+		switch {
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			if name == "" {
+				return JSONPath{}, fmt.Errorf("%w: %q has an empty member name", ErrInvalidJSONPath, expr)
+			}
+			segment = jsonPathSegment{name: name, wildcard: name == "*"}
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return JSONPath{}, fmt.Errorf("%w: %q has an unclosed bracket", ErrInvalidJSONPath, expr)
+			}
+			selector := strings.TrimSpace(rest[1:end])
+			switch {
+			case selector == "*":
+				segment = jsonPathSegment{wildcard: true}
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				segment = jsonPathSegment{name: selector[1 : len(selector)-1]}
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return JSONPath{}, fmt.Errorf("%w: %q has an invalid selector [%s]", ErrInvalidJSONPath, expr, selector)
+				}
+				segment = jsonPathSegment{index: index, isIndex: true}
+			}
+			rest = rest[end+1:]
+		default:
+			return JSONPath{}, fmt.Errorf("%w: %q has an unexpected character at %q", ErrInvalidJSONPath, expr, rest)
+		}
+		path.segments = append(path.segments, segment)
+	}
+	return path, nil
+}
+
+// String returns the source expression of the path.
+func (p JSONPath) String() string {
+	return p.expr
+}
+
+// Select returns the values matched by the path in the decoded JSON value, in document
+// order. Object members matched by a wildcard are ordered by name.
+func (p JSONPath) Select(value interface{}) []interface{} {
+	current := []interface{}{value}
+	for _, segment := range p.segments {
+		var next []interface{}
+		for _, v := range current {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				if segment.wildcard {
+					for _, key := range SortedKeys(node) {
+						next = append(next, node[key])
+					}
+				} else if member, ok := node[segment.name]; ok && !segment.isIndex {
+					next = append(next, member)
+				}
+			case []interface{}:
+				if segment.wildcard {
+					next = append(next, node...)
+				} else if segment.isIndex {
+					index := segment.index
+					if index < 0 {
+						index += len(node)
+					}
+					if index >= 0 && index < len(node) {
+						next = append(next, node[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// UnmarshalYAML parses the JSONPath expression from a YAML string.
+func (p *JSONPath) UnmarshalYAML(value *yaml.Node) error {
+	var expr string
+	if err := value.Decode(&expr); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSONPath, err)
+	}
+	parsed, err := ParseJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// MarshalYAML implements YAML marshaling for JSONPath.
+func (p JSONPath) MarshalYAML() (interface{}, error) {
+	return p.expr, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONPathSelect(t *testing.T) {
+	var document interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"expression": "2+2",
+		"options": {"precision": 2, "mode": "exact"},
+		"queries": [{"text": "first"}, {"text": "second"}],
+		"odd key": true
+	}`), &document))
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{expr: "$", want: []interface{}{document}},
+		{expr: "$.expression", want: []interface{}{"2+2"}},
+		{expr: "$.options.precision", want: []interface{}{float64(2)}},
+		{expr: "$['odd key']", want: []interface{}{true}},
+		{expr: "$.queries[0].text", want: []interface{}{"first"}},
+		{expr: "$.queries[-1].text", want: []interface{}{"second"}},
+		{expr: "$.queries[*].text", want: []interface{}{"first", "second"}},
+		{expr: "$.options.*", want: []interface{}{"exact", float64(2)}},
+		{expr: "$.queries[5]"},
+		{expr: "$.missing.text"},
+		{expr: "$.expression[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := ParseJSONPath(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expr, path.String())
+			assert.Equal(t, tt.want, path.Select(document))
+		})
+	}
+}
+
+func TestParseJSONPath_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "expression", "$..expression", "$.queries[", "$.queries[first]", "$expression"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseJSONPath(expr)
+			require.ErrorIs(t, err, ErrInvalidJSONPath)
+		})
+	}
+}
+
+func TestJSONPathYAML(t *testing.T) {
+	var value struct {
+		Path JSONPath `yaml:"path"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(`path: $.queries[*].text`), &value))
+	assert.Equal(t, []interface{}{"a"}, value.Path.Select(map[string]interface{}{"queries": []interface{}{map[string]interface{}{"text": "a"}}}))
+
+	data, err := yaml.Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, "path: $.queries[*].text\n", string(data))
+
+	require.ErrorIs(t, yaml.Unmarshal([]byte(`path: queries`), &value), ErrInvalidJSONPath)
+}
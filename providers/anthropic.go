@@ -7,6 +7,7 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -27,13 +28,16 @@ const submitResponseToolName = "submit_response"
 
 // NewAnthropic creates a new Anthropic provider instance with the given configuration.
 func NewAnthropic(cfg config.AnthropicClientConfig, availableTools []config.ToolConfig) *Anthropic {
-	opts := []anthropicoption.RequestOption{anthropicoption.WithAPIKey(cfg.APIKey)}
+	batches := newBatchTransport("/messages")
+	opts := []anthropicoption.RequestOption{anthropicoption.WithAPIKey(cfg.APIKey), anthropicoption.WithHTTPClient(batches.client())}
 	if cfg.RequestTimeout != nil {
 		opts = append(opts, anthropicoption.WithRequestTimeout(*cfg.RequestTimeout))
 	}
 	return &Anthropic{
 		client:         anthropic.NewClient(opts...),
 		availableTools: availableTools,
+		uploads:        newFileUploads(),
+		batches:        batches,
 	}
 }
 
@@ -41,6 +45,8 @@ func NewAnthropic(cfg config.AnthropicClientConfig, availableTools []config.Tool
 type Anthropic struct {
 	client         anthropic.Client
 	availableTools []config.ToolConfig
+	uploads        *fileUploads
+	batches        *batchTransport
 }
 
 func (o Anthropic) Name() string {
@@ -54,24 +60,18 @@ func (o *Anthropic) Run(ctx context.Context, logger logging.Logger, cfg config.R
 	}
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	var lastCacheableLocalToolIndex = -1
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			// Find the tool config from available tools.
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			toolInputSchema, err := MapToJSONSchema(toolCfg.Parameters)
 			if err != nil {
 				return result, fmt.Errorf("%w: %v", ErrToolSetup, err)
@@ -213,6 +213,17 @@ func (o *Anthropic) Run(ctx context.Context, logger logging.Logger, cfg config.R
 
 	o.configurePromptCaching(&request, lastCacheableLocalToolIndex, promptCacheTTL)
 
+	// Referencing uploaded files requires the Files API beta.
+	var requestOpts []anthropicoption.RequestOption
+	if slices.ContainsFunc(task.Files, func(file config.TaskFile) bool { return file.GetResolvedFileOptions().UseUpload() }) {
+		requestOpts = append(requestOpts, anthropicoption.WithHeaderAdd("anthropic-beta", string(anthropic.AnthropicBetaFilesAPI2025_04_14)))
+	}
+	if o.batches != nil && o.batches.isBatchContext(ctx) {
+		// The request waits for the batch output, which also lifts the SDK limit on
+		// non-streaming requests with large token budgets.
+		requestOpts = append(requestOpts, anthropicoption.WithRequestTimeout(batchRequestTimeout))
+	}
+
 	// Conversation loop to handle tool calls.
 	var turn int
 	for {
@@ -221,15 +232,14 @@ func (o *Anthropic) Run(ctx context.Context, logger logging.Logger, cfg config.R
 			return result, err
 		}
 
-		resp, err := timed(func() (*anthropic.Message, error) {
-			response, err := o.handleRequest(ctx, request, useStreaming)
+		resp, err := timedRequest(&result, func(onFirstToken func()) (*anthropic.Message, error) {
+			response, err := o.handleRequest(ctx, request, useStreaming, onFirstToken, requestOpts...)
 			if err != nil && o.isTransientResponse(err) {
 				return response, WrapErrRetryable(err)
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -237,6 +247,9 @@ func (o *Anthropic) Run(ctx context.Context, logger logging.Logger, cfg config.R
 		}
 
 		recordUsage(InputTokenAccountingCacheTokensSeparate, &resp.Usage.InputTokens, &resp.Usage.OutputTokens, &resp.Usage.CacheCreationInputTokens, &resp.Usage.CacheReadInputTokens, &result.usage)
+		recordRequestOutputTokens(&resp.Usage.OutputTokens, &result)
+		// The API does not report thinking tokens separately from output tokens.
+		result.recordReasoning(thinkingText(resp.Content))
 		isTerminal := o.isTerminalStopReason(resp.StopReason)
 		logFinishReason(ctx, logger, string(resp.StopReason), isTerminal)
 
@@ -319,6 +332,18 @@ func (o *Anthropic) Run(ctx context.Context, logger logging.Logger, cfg config.R
 	} // move to the next conversation turn
 }
 
+// thinkingText joins the text of the thinking blocks in a response. Redacted thinking
+// blocks are encrypted and skipped.
+func thinkingText(content []anthropic.ContentBlockUnion) string {
+	var parts []string
+	for _, block := range content {
+		if thinking, ok := block.AsAny().(anthropic.ThinkingBlock); ok && thinking.Thinking != "" {
+			parts = append(parts, thinking.Thinking)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
 // sanitizeAssistantMessage removes empty text blocks from a MessageParam.
 // This works around a known SDK bug where resp.ToParam() preserves empty text
 // blocks that the API rejects with 400 "text content blocks must be non-empty"
@@ -346,24 +371,29 @@ func (o *Anthropic) isTerminalStopReason(stopReason anthropic.StopReason) bool {
 }
 
 // handleRequest dispatches the request to the appropriate handler based on streaming mode.
-func (o *Anthropic) handleRequest(ctx context.Context, request anthropic.MessageNewParams, stream bool) (*anthropic.Message, error) {
+func (o *Anthropic) handleRequest(ctx context.Context, request anthropic.MessageNewParams, stream bool, onFirstToken func(), opts ...anthropicoption.RequestOption) (*anthropic.Message, error) {
 	if stream {
-		return o.handleStreamingRequest(ctx, request)
+		return o.handleStreamingRequest(ctx, request, onFirstToken, opts...)
 	}
-	return o.client.Messages.New(ctx, request)
+	return o.client.Messages.New(ctx, request, opts...)
 }
 
 // handleStreamingRequest executes a streaming message request, buffering all events
 // into a single [anthropic.Message] via the SDK's Accumulate method.
 // Streaming is recommended for requests with large MaxTokens values, especially
 // when extended thinking is enabled, to prevent HTTP timeouts on long-running requests.
-func (o *Anthropic) handleStreamingRequest(ctx context.Context, request anthropic.MessageNewParams) (*anthropic.Message, error) {
-	stream := o.client.Messages.NewStreaming(ctx, request)
+// The onFirstToken callback is invoked for every content delta received.
+func (o *Anthropic) handleStreamingRequest(ctx context.Context, request anthropic.MessageNewParams, onFirstToken func(), opts ...anthropicoption.RequestOption) (*anthropic.Message, error) {
+	stream := o.client.Messages.NewStreaming(ctx, request, opts...)
 	defer stream.Close()
 
 	message := anthropic.Message{}
 	for stream.Next() {
-		if err := message.Accumulate(stream.Current()); err != nil {
+		event := stream.Current()
+		if event.Type == "content_block_delta" {
+			onFirstToken()
+		}
+		if err := message.Accumulate(event); err != nil {
 			return nil, ErrStreamResponse
 		}
 	}
@@ -394,18 +424,41 @@ func (o *Anthropic) createPromptMessageParts(ctx context.Context, promptText str
 		fileType, err := file.TypeValue(ctx)
 		if err != nil {
 			return nil, err
-		} else if !isSupportedImageType(fileType) {
-			return nil, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
 		}
 
-		base64Data, err := file.Base64(ctx)
-		if err != nil {
-			return nil, err
+		var filePart anthropic.ContentBlockParamUnion
+		switch classifyFile(fileType) {
+		case fileKindImage, fileKindPDF:
+			if o.uploads != nil && file.GetResolvedFileOptions().UseUpload() {
+				uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, o.uploadFile)
+				if err != nil {
+					return nil, err
+				}
+				filePart = newAnthropicUploadedFileBlock(fileType, uploaded.ID)
+				break
+			}
+			base64Data, err := file.Base64(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if isSupportedImageType(fileType) {
+				filePart = anthropic.NewImageBlockBase64(fileType, base64Data)
+			} else {
+				filePart = anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{Data: base64Data})
+			}
+		case fileKindText:
+			text, err := taskFileText(ctx, file)
+			if err != nil {
+				return nil, err
+			}
+			filePart = anthropic.NewDocumentBlock(anthropic.PlainTextSourceParam{Data: text})
+		default:
+			return nil, unsupportedFileError(file, fileType)
 		}
 
-		// Attach file name as a text block before the image.
+		// Attach file name as a text block before the file content.
 		parts = append(parts, anthropic.NewTextBlock(result.recordPrompt(DefaultTaskFileNameInstruction(file))))
-		parts = append(parts, anthropic.NewImageBlockBase64(fileType, base64Data))
+		parts = append(parts, filePart)
 	}
 
 	parts = append(parts, anthropic.NewTextBlock(result.recordPrompt(promptText))) // append the prompt text after the file data for improved context integrity
@@ -413,8 +466,127 @@ func (o *Anthropic) createPromptMessageParts(ctx context.Context, promptText str
 	return parts, nil
 }
 
+// CanBatch reports whether the task can be executed through the Message Batches API.
+// Tasks that use tools need a conversation loop and streaming responses cannot be batched,
+// so these are executed synchronously.
+func (o *Anthropic) CanBatch(cfg config.RunConfig, task config.Task) bool {
+	if modelParams, ok := cfg.ModelParams.(config.AnthropicModelParams); ok && modelParams.Stream {
+		return false
+	}
+	return !taskUsesTools(task)
+}
+
+// NewBatch creates a batch executed through the Message Batches API.
+func (o *Anthropic) NewBatch(ctx context.Context, logger logging.Logger, _ config.RunConfig, size int) *Batch {
+	return newBatch(ctx, logger, o.batches, size, o.submitBatch)
+}
+
+// submitBatch executes the requests through the Message Batches API and returns their results.
+// Errored results keep the API error body; canceled and expired results are reported as
+// transient server errors.
+func (o *Anthropic) submitBatch(ctx context.Context, logger logging.Logger, requests []*batchRequest) (map[string]batchResponse, error) {
+	type batchItem struct {
+		CustomID string          `json:"custom_id"`
+		Params   json.RawMessage `json:"params"`
+	}
+	items := make([]batchItem, len(requests))
+	var betas []string
+	for i, request := range requests {
+		items[i] = batchItem{CustomID: request.CustomID, Params: request.Body}
+		for _, beta := range request.Header.Values("anthropic-beta") {
+			if !slices.Contains(betas, beta) {
+				betas = append(betas, beta)
+			}
+		}
+	}
+	body, err := json.Marshal(map[string]any{"requests": items})
+	if err != nil {
+		return nil, err
+	}
+
+	// Beta features enabled for individual requests, such as the Files API, must be enabled for the whole batch.
+	opts := []anthropicoption.RequestOption{anthropicoption.WithRequestBody("application/json", body)}
+	for _, beta := range betas {
+		opts = append(opts, anthropicoption.WithHeaderAdd("anthropic-beta", beta))
+	}
+	batch, err := o.client.Messages.Batches.New(ctx, anthropic.MessageBatchNewParams{}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	logger.Message(ctx, logging.LevelInfo, "batch '%s' created, waiting for completion...", batch.ID)
+
+	batchID := batch.ID
+	if err := pollBatch(ctx, logger, func(ctx context.Context) (bool, error) {
+		batch, err = o.client.Messages.Batches.Get(ctx, batchID)
+		if err != nil {
+			return false, err
+		}
+		return batch.ProcessingStatus == anthropic.MessageBatchProcessingStatusEnded, nil
+	}); err != nil {
+		if _, cancelErr := o.client.Messages.Batches.Cancel(context.WithoutCancel(ctx), batchID); cancelErr != nil {
+			logger.Error(ctx, logging.LevelWarn, cancelErr, "failed to cancel batch")
+		}
+		return nil, err
+	}
+
+	responses := make(map[string]batchResponse, len(requests))
+	stream := o.client.Messages.Batches.ResultsStreaming(ctx, batchID)
+	defer stream.Close()
+	for stream.Next() {
+		item := stream.Current()
+		switch item.Result.Type {
+		case "succeeded":
+			responses[item.CustomID] = batchResponse{StatusCode: http.StatusOK, Body: json.RawMessage(item.Result.Message.RawJSON())}
+		case "errored":
+			statusCode := http.StatusInternalServerError
+			if item.Result.Error.Error.Type == "invalid_request_error" {
+				statusCode = http.StatusBadRequest
+			}
+			responses[item.CustomID] = batchResponse{StatusCode: statusCode, Body: json.RawMessage(item.Result.Error.RawJSON())}
+		default:
+			responses[item.CustomID] = newBatchErrorResponse(http.StatusInternalServerError, item.Result.Type, fmt.Sprintf("batch request %s", item.Result.Type))
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch results: %w", err)
+	}
+	return responses, nil
+}
+
 func (o *Anthropic) Close(ctx context.Context) error {
-	return nil
+	return o.uploads.deleteAll(ctx, func(ctx context.Context, file uploadedFile) error {
+		_, err := o.client.Beta.Files.Delete(ctx, file.ID, anthropic.BetaFileDeleteParams{
+			Betas: []anthropic.AnthropicBeta{anthropic.AnthropicBetaFilesAPI2025_04_14},
+		})
+		return err
+	})
+}
+
+// uploadFile uploads a task file to the Files API.
+func (o *Anthropic) uploadFile(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error) {
+	uploaded, err := o.client.Beta.Files.Upload(ctx, anthropic.BetaFileUploadParams{
+		File:  anthropic.File(bytes.NewReader(content), uploadFileName(file, mimeType), mimeType),
+		Betas: []anthropic.AnthropicBeta{anthropic.AnthropicBetaFilesAPI2025_04_14},
+	})
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	return uploadedFile{ID: uploaded.ID}, nil
+}
+
+// newAnthropicUploadedFileBlock creates an image or document block that references a file uploaded
+// to the Files API. The Messages API params have no file source variant outside the beta API,
+// so the source is set as a raw JSON field overriding the placeholder source.
+func newAnthropicUploadedFileBlock(mimeType string, fileID string) anthropic.ContentBlockParamUnion {
+	source := map[string]any{"type": "file", "file_id": fileID}
+	if isSupportedImageType(mimeType) {
+		block := anthropic.NewImageBlockBase64(mimeType, "")
+		block.OfImage.SetExtraFields(map[string]any{"source": source})
+		return block
+	}
+	block := anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{})
+	block.OfDocument.SetExtraFields(map[string]any{"source": source})
+	return block
 }
 
 // configurePromptCaching enables top-level automatic caching and places
@@ -8,9 +8,16 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	anthropic "github.com/anthropics/anthropic-sdk-go"
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/testutils"
 	"github.com/stretchr/testify/assert"
@@ -24,12 +31,67 @@ func TestAnthropic_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "claude"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
 
+func TestAnthropic_CreatePromptMessageParts_Documents(t *testing.T) {
+	ctx := context.Background()
+	pdfFile := mockTaskFile(t, "report", testutils.CreateMockFile(t, "report-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	textFile := mockTaskFile(t, "notes", testutils.CreateMockFile(t, "notes-*.md", []byte("# Notes")), "text/markdown")
+
+	result := &Result{}
+	parts, err := (&Anthropic{}).createPromptMessageParts(ctx, "Summarize.", []config.TaskFile{pdfFile, textFile}, result)
+	require.NoError(t, err)
+	require.Len(t, parts, 5)
+
+	require.NotNil(t, parts[1].OfDocument)
+	require.NotNil(t, parts[1].OfDocument.Source.OfBase64)
+	assert.Equal(t, "JVBERi0xLjc=", parts[1].OfDocument.Source.OfBase64.Data)
+
+	require.NotNil(t, parts[3].OfDocument)
+	require.NotNil(t, parts[3].OfDocument.Source.OfText)
+	assert.Equal(t, "# Notes", parts[3].OfDocument.Source.OfText.Data)
+
+	assert.Equal(t, []string{"[file: report]", "[file: notes]", "Summarize."}, result.prompts)
+}
+
+func TestAnthropic_AudioNotSupported(t *testing.T) {
+	audioFile := mockTaskFile(t, "clip", "file://clip.wav", "audio/wav")
+	_, err := (&Anthropic{}).createPromptMessageParts(context.Background(), "Transcribe.", []config.TaskFile{audioFile}, &Result{})
+	require.ErrorIs(t, err, ErrFileNotSupported)
+	assert.Contains(t, err.Error(), "(audio)")
+}
+
+func TestNewAnthropicUploadedFileBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		mimeType string
+		expected string
+	}{
+		{
+			name:     "image",
+			mimeType: "image/png",
+			expected: `{"source":{"file_id":"file-1","type":"file"},"type":"image"}`,
+		},
+		{
+			name:     "PDF document",
+			mimeType: "application/pdf",
+			expected: `{"source":{"file_id":"file-1","type":"file"},"type":"document"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(newAnthropicUploadedFileBlock(tt.mimeType, "file-1"))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
 func TestSanitizeAssistantMessage(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -119,6 +181,18 @@ func TestSanitizeAssistantMessage(t *testing.T) {
 	}
 }
 
+func TestThinkingText(t *testing.T) {
+	var message anthropic.Message
+	require.NoError(t, json.Unmarshal([]byte(`{"role": "assistant", "content": [
+		{"type": "thinking", "thinking": "first", "signature": "sig"},
+		{"type": "redacted_thinking", "data": "blob"},
+		{"type": "text", "text": "answer"},
+		{"type": "thinking", "thinking": "second", "signature": "sig"}
+	]}`), &message))
+	assert.Equal(t, "first\n\nsecond", thinkingText(message.Content))
+	assert.Empty(t, thinkingText(nil))
+}
+
 func TestAnthropic_Run_IncompatibleThinking(t *testing.T) {
 	logger := testutils.NewTestLogger(t)
 	p := &Anthropic{}
@@ -236,3 +310,148 @@ func TestAnthropic_ConfigurePromptCaching(t *testing.T) {
 		assert.Zero(t, req.CacheControl.TTL)
 	})
 }
+
+func TestAnthropic_RunBatch(t *testing.T) {
+	useFastBatchPolling(t)
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	var mu sync.Mutex
+	var submitted []struct {
+		CustomID string          `json:"custom_id"`
+		Params   json.RawMessage `json:"params"`
+	}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches":
+			var body struct {
+				Requests []struct {
+					CustomID string          `json:"custom_id"`
+					Params   json.RawMessage `json:"params"`
+				} `json:"requests"`
+			}
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+				return
+			}
+			submitted = append(submitted, body.Requests...)
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			polls++
+			status := "in_progress"
+			if polls > 1 {
+				status = "ended"
+			}
+			fmt.Fprintf(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":%q}`, status)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			w.Header().Set("Content-Type", "application/x-jsonl")
+			for _, request := range submitted {
+				var params struct {
+					Messages []struct {
+						Content []struct {
+							Text string `json:"text"`
+						} `json:"content"`
+					} `json:"messages"`
+				}
+				require.NoError(t, json.Unmarshal(request.Params, &params))
+				prompt := params.Messages[0].Content[0].Text
+				if strings.Contains(prompt, "invalid") {
+					fmt.Fprintf(w, `{"custom_id":%q,"result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"prompt is invalid"}}}}`+"\n", request.CustomID)
+					continue
+				}
+				answer, _ := json.Marshal(map[string]string{"title": "Answer", "explanation": "Echo", "final_answer": prompt})
+				message, _ := json.Marshal(map[string]any{
+					"id": "msg_" + request.CustomID, "type": "message", "role": "assistant", "model": "claude-test",
+					"content":     []map[string]any{{"type": "text", "text": string(answer)}},
+					"stop_reason": "end_turn",
+					"usage":       map[string]any{"input_tokens": 10, "output_tokens": 5},
+				})
+				fmt.Fprintf(w, `{"custom_id":%q,"result":{"type":"succeeded","message":%s}}`+"\n", request.CustomID, message)
+			}
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	batches := newBatchTransport("/messages")
+	p := &Anthropic{
+		client:  anthropic.NewClient(anthropicoption.WithBaseURL(server.URL), anthropicoption.WithAPIKey("test"), anthropicoption.WithHTTPClient(batches.client()), anthropicoption.WithMaxRetries(0)),
+		uploads: newFileUploads(),
+		batches: batches,
+	}
+	runCfg := config.RunConfig{Name: "batch-run", Model: "claude-test", ExecutionMode: config.ExecutionModeBatch}
+	prompts := []string{"first", "second", "invalid"}
+	for _, prompt := range prompts {
+		require.True(t, p.CanBatch(runCfg, config.Task{Name: prompt, Prompt: prompt}))
+	}
+
+	batch := p.NewBatch(ctx, logger, runCfg, len(prompts))
+	results := make([]Result, len(prompts))
+	errs := make([]error, len(prompts))
+	var wg sync.WaitGroup
+	for i, prompt := range prompts {
+		wg.Go(func() {
+			defer batch.Done()
+			task := config.Task{Name: prompt, Prompt: prompt, ResponseResultFormat: config.NewResponseFormat("text")}
+			results[i], errs[i] = p.Run(batch.Context(ctx), logger, runCfg, task)
+		})
+	}
+	wg.Wait()
+
+	assert.Len(t, submitted, len(prompts))
+	for i, prompt := range prompts[:2] {
+		require.NoError(t, errs[i])
+		assert.Equal(t, prompt, results[i].GetFinalAnswerContent())
+		assert.Equal(t, "Answer", results[i].Title)
+		assert.EqualValues(t, 10, *results[i].GetUsage().InputTokens)
+	}
+	require.Error(t, errs[2])
+	assert.Contains(t, errs[2].Error(), "prompt is invalid")
+}
+
+func TestAnthropic_SubmitBatchCarriesBetaHeaders(t *testing.T) {
+	useFastBatchPolling(t)
+	var betas []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches":
+			betas = r.Header.Values("anthropic-beta")
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1":
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"ended"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/messages/batches/msgbatch_1/results":
+			w.Header().Set("Content-Type", "application/x-jsonl")
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &Anthropic{
+		client: anthropic.NewClient(anthropicoption.WithBaseURL(server.URL), anthropicoption.WithAPIKey("test"), anthropicoption.WithMaxRetries(0)),
+	}
+	filesAPI := string(anthropic.AnthropicBetaFilesAPI2025_04_14)
+	requests := []*batchRequest{
+		{CustomID: "request-1", Body: json.RawMessage(`{}`), Header: http.Header{"Anthropic-Beta": []string{filesAPI}}},
+		{CustomID: "request-2", Body: json.RawMessage(`{}`), Header: http.Header{}},
+		{CustomID: "request-3", Body: json.RawMessage(`{}`), Header: http.Header{"Anthropic-Beta": []string{filesAPI}}},
+	}
+
+	_, err := p.submitBatch(context.Background(), testutils.NewTestLogger(t), requests)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filesAPI}, betas)
+}
+
+func TestAnthropic_CanBatch(t *testing.T) {
+	p := &Anthropic{}
+	task := config.Task{Name: "task"}
+	assert.True(t, p.CanBatch(config.RunConfig{}, task))
+	assert.False(t, p.CanBatch(config.RunConfig{ModelParams: config.AnthropicModelParams{Stream: true}}, task))
+}
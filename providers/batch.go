@@ -0,0 +1,362 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/sethvargo/go-retry"
+)
+
+// ErrBatchExecution is returned when a batch of requests cannot be executed through the provider's batch API.
+var ErrBatchExecution = errors.New("batch execution failed")
+
+// maxBatchPollErrors is the number of consecutive polling failures after which a batch is abandoned.
+const maxBatchPollErrors = 5
+
+var (
+	// batchPollInterval is the initial delay between batch status checks.
+	batchPollInterval = 10 * time.Second
+	// batchMaxPollInterval caps the exponentially growing delay between batch status checks.
+	batchMaxPollInterval = 5 * time.Minute
+)
+
+// batchRequestTimeout bounds the time a request waits for the output of its batch.
+// It is slightly longer than the 24-hour completion window of provider batch APIs.
+const batchRequestTimeout = 25 * time.Hour
+
+var errBatchInProgress = errors.New("batch is still in progress")
+
+// BatchProvider is implemented by providers that can execute tasks through an asynchronous
+// batch API, trading latency for a lower price.
+//
+// Batching is transparent to Provider.Run: a task is executed as usual with a context returned
+// by Batch.Context, and its model request is held back until every task in the batch has either
+// made its request or finished. All held requests are then submitted as a single batch and each
+// call returns the batch output as if it had been answered directly, so the response goes through
+// the same parsing path. Any further request, such as a retry or a follow-up turn, is sent
+// synchronously.
+type BatchProvider interface {
+	Provider
+	// CanBatch reports whether the task can be executed in a batch using the given run configuration.
+	// Tasks that need a tool loop or a streaming response cannot be batched.
+	CanBatch(cfg config.RunConfig, task config.Task) bool
+	// NewBatch creates a batch for the given number of tasks using the given run configuration.
+	// The context is used to submit and poll the batch.
+	NewBatch(ctx context.Context, logger logging.Logger, cfg config.RunConfig, size int) *Batch
+}
+
+// batchRequest is a model request held back for submission in a batch.
+type batchRequest struct {
+	// CustomID identifies the request within the batch.
+	CustomID string
+	// Path is the URL path of the original request, e.g. "/v1/chat/completions".
+	Path string
+	// Body is the JSON body of the original request.
+	Body json.RawMessage
+	// Header is the header of the original request, e.g. to carry beta feature flags.
+	Header http.Header
+
+	done     chan struct{}
+	response batchResponse
+	err      error
+}
+
+// batchResponse is the output of a single request in a batch.
+type batchResponse struct {
+	// StatusCode is the HTTP status code the request would have been answered with.
+	StatusCode int
+	// Body is the JSON body the request would have been answered with.
+	Body json.RawMessage
+}
+
+// batchSubmitFunc submits the requests to a provider's batch API, waits for the batch to finish
+// and returns the outputs by request CustomID.
+type batchSubmitFunc func(ctx context.Context, logger logging.Logger, requests []*batchRequest) (map[string]batchResponse, error)
+
+// Batch collects model requests of concurrently running tasks and submits them together
+// through a provider's batch API. It is safe for concurrent use.
+type Batch struct {
+	ctx       context.Context
+	logger    logging.Logger
+	transport *batchTransport
+	submit    batchSubmitFunc
+
+	mu        sync.Mutex
+	running   int
+	requests  []*batchRequest
+	submitted bool
+}
+
+type batchContextKey struct{}
+
+func newBatch(ctx context.Context, logger logging.Logger, transport *batchTransport, size int, submit batchSubmitFunc) *Batch {
+	return &Batch{
+		ctx:       ctx,
+		logger:    logger,
+		transport: transport,
+		submit:    submit,
+		running:   size,
+	}
+}
+
+// Context returns a copy of ctx that makes provider requests part of the batch.
+func (b *Batch) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchContextKey{}, b)
+}
+
+// Done marks one of the tasks in the batch as finished. It must be called exactly once
+// for each task, whether or not the task made a request.
+func (b *Batch) Done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.running--
+	b.submitIfReadyLocked()
+}
+
+// hold adds the request to the batch and waits for its output. It returns false if the
+// request cannot be batched, in which case it should be sent synchronously.
+func (b *Batch) hold(req *http.Request) (*http.Response, bool, error) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return nil, false, nil
+	}
+
+	b.mu.Lock()
+	if b.submitted {
+		b.mu.Unlock()
+		return nil, false, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		b.mu.Unlock()
+		return nil, true, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if isStreamingRequest(body) {
+		b.mu.Unlock()
+		return nil, false, nil
+	}
+
+	request := &batchRequest{
+		CustomID: fmt.Sprintf("request-%d", len(b.requests)+1),
+		Path:     req.URL.Path,
+		Body:     body,
+		Header:   req.Header.Clone(),
+		done:     make(chan struct{}),
+	}
+	b.requests = append(b.requests, request)
+	b.submitIfReadyLocked()
+	b.mu.Unlock()
+
+	select {
+	case <-request.done:
+	case <-req.Context().Done():
+		return nil, true, req.Context().Err()
+	}
+	if request.err != nil {
+		return nil, true, request.err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", request.response.StatusCode, http.StatusText(request.response.StatusCode)),
+		StatusCode:    request.response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(request.response.Body)),
+		ContentLength: int64(len(request.response.Body)),
+		Request:       req,
+	}, true, nil
+}
+
+// submitIfReadyLocked submits the batch once every task that is still running has made its request.
+func (b *Batch) submitIfReadyLocked() {
+	if b.submitted || len(b.requests) == 0 || len(b.requests) < b.running {
+		return
+	}
+	b.submitted = true
+	go b.execute(b.requests)
+}
+
+func (b *Batch) execute(requests []*batchRequest) {
+	b.logger.Message(b.ctx, logging.LevelInfo, "submitting batch of %d requests...", len(requests))
+	start := time.Now()
+	responses, err := b.submit(b.ctx, b.logger, requests)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrBatchExecution, err)
+		b.logger.Error(b.ctx, logging.LevelError, err, "batch failed")
+	} else {
+		b.logger.Message(b.ctx, logging.LevelInfo, "batch has finished in %s.", time.Since(start))
+	}
+
+	for _, request := range requests {
+		if err != nil {
+			request.err = err
+		} else if response, ok := responses[request.CustomID]; ok {
+			request.response = response
+		} else {
+			// Requests left unprocessed, e.g. when the batch expired, are reported as transient server errors.
+			request.response = newBatchErrorResponse(http.StatusInternalServerError, "batch_incomplete", "batch finished without processing the request")
+		}
+		close(request.done)
+	}
+}
+
+// batchTransport is an http.RoundTripper that holds back requests made with a batch context
+// created by the same transport. All other requests are passed to the underlying transport.
+type batchTransport struct {
+	base http.RoundTripper
+	// endpoints are the URL path suffixes of the model requests that can be batched.
+	endpoints []string
+}
+
+func newBatchTransport(endpoints ...string) *batchTransport {
+	return &batchTransport{
+		base:      &rateLimitTransport{base: http.DefaultTransport},
+		endpoints: endpoints,
+	}
+}
+
+// client returns an HTTP client that uses the transport.
+func (t *batchTransport) client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *batchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if batch, ok := req.Context().Value(batchContextKey{}).(*Batch); ok && batch.transport == t && t.isBatchEndpoint(req.URL.Path) {
+		if resp, held, err := batch.hold(req); held {
+			return resp, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// isBatchContext reports whether requests made with ctx can be held back for a batch
+// created by this transport that has not been submitted yet.
+func (t *batchTransport) isBatchContext(ctx context.Context) bool {
+	batch, ok := ctx.Value(batchContextKey{}).(*Batch)
+	if !ok || batch.transport != t {
+		return false
+	}
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return !batch.submitted
+}
+
+func (t *batchTransport) isBatchEndpoint(path string) bool {
+	for _, endpoint := range t.endpoints {
+		if strings.HasSuffix(path, endpoint) {
+			return true
+		}
+	}
+	return false
+}
+
+// pollBatch calls check with exponentially growing delays until it reports that the batch
+// has finished. Transient check failures are tolerated up to maxBatchPollErrors in a row.
+func pollBatch(ctx context.Context, logger logging.Logger, check func(ctx context.Context) (done bool, err error)) error {
+	backoff := retry.WithCappedDuration(batchMaxPollInterval, retry.NewExponential(batchPollInterval))
+	failures := 0
+	return retry.Do(ctx, backoff, func(ctx context.Context) error {
+		done, err := check(ctx)
+		if err != nil {
+			failures++
+			if failures >= maxBatchPollErrors {
+				return err
+			}
+			logger.Error(ctx, logging.LevelWarn, err, "failed to check batch status")
+			return retry.RetryableError(err)
+		}
+		failures = 0
+		if !done {
+			return retry.RetryableError(errBatchInProgress)
+		}
+		return nil
+	})
+}
+
+// batchOutputLine is a line of the JSONL batch output format shared by OpenAI and Mistral AI.
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// toBatchResponse converts the output line to a batch response. Request-level errors
+// are reported as server errors, so they are treated as transient by the provider.
+func (l batchOutputLine) toBatchResponse() batchResponse {
+	if l.Response != nil && len(l.Response.Body) > 0 {
+		return batchResponse{StatusCode: l.Response.StatusCode, Body: l.Response.Body}
+	}
+	message := "batch request produced no response"
+	code := ""
+	if l.Error != nil {
+		message, code = l.Error.Message, l.Error.Code
+	}
+	return newBatchErrorResponse(http.StatusInternalServerError, code, message)
+}
+
+// readBatchOutput reads JSONL batch output lines into responses by custom ID.
+func readBatchOutput(r io.Reader, responses map[string]batchResponse) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var line batchOutputLine
+		if err := decoder.Decode(&line); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read batch output: %w", err)
+		}
+		if line.CustomID != "" {
+			responses[line.CustomID] = line.toBatchResponse()
+		}
+	}
+}
+
+// newBatchErrorResponse creates a response in the common `{"error": {...}}` error format.
+func newBatchErrorResponse(statusCode int, code string, message string) batchResponse {
+	body, _ := json.Marshal(map[string]any{
+		"error": map[string]string{
+			"type":    code,
+			"code":    code,
+			"message": message,
+		},
+	})
+	return batchResponse{StatusCode: statusCode, Body: body}
+}
+
+// taskUsesTools reports whether any tools are enabled for the task.
+func taskUsesTools(task config.Task) bool {
+	_, hasTools := task.GetResolvedToolSelector().GetEnabledToolsByName()
+	return hasTools
+}
+
+func isStreamingRequest(body []byte) bool {
+	var request struct {
+		Stream bool `json:"stream"`
+	}
+	return json.Unmarshal(body, &request) == nil && request.Stream
+}
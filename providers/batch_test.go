@@ -0,0 +1,212 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFastBatchPolling shortens batch polling delays for the duration of the test.
+func useFastBatchPolling(t *testing.T) {
+	interval, maxInterval := batchPollInterval, batchMaxPollInterval
+	batchPollInterval, batchMaxPollInterval = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() {
+		batchPollInterval, batchMaxPollInterval = interval, maxInterval
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(content), err
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	var direct atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		direct.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"direct":true}`)
+	}))
+	defer server.Close()
+
+	t.Run("requests are submitted together once all tasks have requested or finished", func(t *testing.T) {
+		direct.Store(0)
+		transport := newBatchTransport("/chat/completions")
+		client := transport.client()
+
+		var submissions [][]*batchRequest
+		batch := newBatch(ctx, logger, transport, 3, func(_ context.Context, _ logging.Logger, requests []*batchRequest) (map[string]batchResponse, error) {
+			submissions = append(submissions, requests)
+			responses := make(map[string]batchResponse, len(requests))
+			for _, request := range requests {
+				responses[request.CustomID] = batchResponse{StatusCode: http.StatusOK, Body: request.Body}
+			}
+			return responses, nil
+		})
+
+		var wg sync.WaitGroup
+		bodies := make([]string, 2)
+		for i := range bodies {
+			wg.Go(func() {
+				defer batch.Done()
+				status, body, err := postJSON(batch.Context(ctx), client, server.URL+"/v1/chat/completions", fmt.Sprintf(`{"task":%d}`, i))
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, status)
+				bodies[i] = body
+			})
+		}
+		wg.Go(func() {
+			batch.Done() // a task that finished without making a request
+		})
+		wg.Wait()
+
+		require.Len(t, submissions, 1)
+		assert.Len(t, submissions[0], 2)
+		assert.Equal(t, []string{`{"task":0}`, `{"task":1}`}, bodies)
+		assert.Zero(t, direct.Load())
+
+		// Requests made after the batch was submitted are sent directly.
+		status, body, err := postJSON(batch.Context(ctx), client, server.URL+"/v1/chat/completions", `{"task":2}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"direct":true}`, body)
+		assert.EqualValues(t, 1, direct.Load())
+	})
+
+	t.Run("other requests are sent directly", func(t *testing.T) {
+		direct.Store(0)
+		transport := newBatchTransport("/chat/completions")
+		other := newBatchTransport("/chat/completions")
+		batch := newBatch(ctx, logger, transport, 1, func(context.Context, logging.Logger, []*batchRequest) (map[string]batchResponse, error) {
+			t.Fatal("batch should not be submitted")
+			return nil, nil
+		})
+		batchCtx := batch.Context(ctx)
+
+		for _, tc := range []struct {
+			ctx    context.Context
+			client *http.Client
+			path   string
+			body   string
+		}{
+			{ctx: batchCtx, client: transport.client(), path: "/v1/files", body: `{}`},
+			{ctx: batchCtx, client: transport.client(), path: "/v1/chat/completions", body: `{"stream":true}`},
+			{ctx: batchCtx, client: other.client(), path: "/v1/chat/completions", body: `{}`},
+			{ctx: ctx, client: transport.client(), path: "/v1/chat/completions", body: `{}`},
+		} {
+			status, body, err := postJSON(tc.ctx, tc.client, server.URL+tc.path, tc.body)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, status)
+			assert.JSONEq(t, `{"direct":true}`, body)
+		}
+		assert.EqualValues(t, 4, direct.Load())
+		batch.Done()
+	})
+
+	t.Run("missing outputs are reported as server errors", func(t *testing.T) {
+		transport := newBatchTransport("/chat/completions")
+		batch := newBatch(ctx, logger, transport, 1, func(context.Context, logging.Logger, []*batchRequest) (map[string]batchResponse, error) {
+			return map[string]batchResponse{}, nil
+		})
+		defer batch.Done()
+
+		status, body, err := postJSON(batch.Context(ctx), transport.client(), server.URL+"/v1/chat/completions", `{}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, status)
+		assert.Contains(t, body, "batch_incomplete")
+	})
+
+	t.Run("failed batch fails all requests", func(t *testing.T) {
+		transport := newBatchTransport("/chat/completions")
+		batch := newBatch(ctx, logger, transport, 1, func(context.Context, logging.Logger, []*batchRequest) (map[string]batchResponse, error) {
+			return nil, errors.New("quota exceeded")
+		})
+		defer batch.Done()
+
+		_, _, err := postJSON(batch.Context(ctx), transport.client(), server.URL+"/v1/chat/completions", `{}`)
+		require.ErrorIs(t, err, ErrBatchExecution)
+		assert.ErrorContains(t, err, "quota exceeded")
+	})
+}
+
+func TestPollBatch(t *testing.T) {
+	useFastBatchPolling(t)
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	t.Run("polls until done", func(t *testing.T) {
+		checks := 0
+		err := pollBatch(ctx, logger, func(context.Context) (bool, error) {
+			checks++
+			return checks == 3, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, checks)
+	})
+
+	t.Run("tolerates transient failures", func(t *testing.T) {
+		checks := 0
+		err := pollBatch(ctx, logger, func(context.Context) (bool, error) {
+			checks++
+			if checks < maxBatchPollErrors {
+				return false, errors.New("connection reset")
+			}
+			return true, nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("gives up after repeated failures", func(t *testing.T) {
+		err := pollBatch(ctx, logger, func(context.Context) (bool, error) {
+			return false, errors.New("unauthorized")
+		})
+		require.ErrorContains(t, err, "unauthorized")
+	})
+}
+
+func TestReadBatchOutput(t *testing.T) {
+	output := `{"custom_id":"request-1","response":{"status_code":200,"body":{"id":"resp-1"}},"error":null}
+{"custom_id":"request-2","response":null,"error":{"code":"batch_expired","message":"This request could not be executed before the completion window expired."}}
+`
+	responses := make(map[string]batchResponse)
+	require.NoError(t, readBatchOutput(strings.NewReader(output), responses))
+	require.Len(t, responses, 2)
+
+	assert.Equal(t, http.StatusOK, responses["request-1"].StatusCode)
+	assert.JSONEq(t, `{"id":"resp-1"}`, string(responses["request-1"].Body))
+	assert.Equal(t, http.StatusInternalServerError, responses["request-2"].StatusCode)
+	assert.JSONEq(t, `{"error":{"type":"batch_expired","code":"batch_expired","message":"This request could not be executed before the completion window expired."}}`, string(responses["request-2"].Body))
+
+	require.Error(t, readBatchOutput(strings.NewReader(`{"custom_id":`), responses))
+}
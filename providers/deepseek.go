@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 
 	deepseek "github.com/cohesion-org/deepseek-go"
 	"github.com/petmal/mindtrial/config"
@@ -23,7 +24,7 @@ import (
 
 // NewDeepseek creates a new DeepSeek provider instance with the given configuration.
 func NewDeepseek(cfg config.DeepseekClientConfig, availableTools []config.ToolConfig) (*Deepseek, error) {
-	opts := make([]deepseek.Option, 0)
+	opts := []deepseek.Option{deepseek.WithHTTPClient(newHTTPClient())}
 	if cfg.RequestTimeout != nil {
 		opts = append(opts, deepseek.WithTimeout(*cfg.RequestTimeout))
 	}
@@ -62,11 +63,7 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 	}
 
 	var request any
-	if len(task.Files) > 0 {
-		if !o.isFileUploadSupported() {
-			return result, ErrFileUploadNotSupported
-		}
-
+	if len(task.Files) > 0 && o.isFileUploadSupported() {
 		messages := []deepseek.ChatCompletionMessageWithImage{}
 		if responseFormatInstruction != "" {
 			messages = append(messages, deepseek.ChatCompletionMessageWithImage{
@@ -99,6 +96,13 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 			JSONMode: !cfg.DisableStructuredOutput,
 		}
 	} else {
+		promptText, err := o.createPromptText(ctx, task.Prompt, task.Files, &result)
+		if errors.Is(err, ErrFeatureNotSupported) {
+			return result, err
+		} else if err != nil {
+			return result, fmt.Errorf("%w: %v", ErrCreatePromptRequest, err)
+		}
+
 		messages := []deepseek.ChatCompletionMessage{}
 		if responseFormatInstruction != "" {
 			messages = append(messages, deepseek.ChatCompletionMessage{
@@ -114,7 +118,7 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 		}
 		messages = append(messages, deepseek.ChatCompletionMessage{
 			Role:    deepseek.ChatMessageRoleUser,
-			Content: result.recordPrompt(task.Prompt),
+			Content: promptText,
 		})
 
 		request = &deepseek.ChatCompletionRequest{
@@ -125,23 +129,17 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 	}
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			// Find the tool config from available tools.
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			if err := o.addToolToRequest(request, *toolCfg); err != nil {
 				return result, fmt.Errorf("%w: %v", ErrToolSetup, err)
 			}
@@ -166,15 +164,14 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 			return result, err
 		}
 
-		resp, err := timed(func() (*deepseek.ChatCompletionResponse, error) {
+		resp, err := timedRequest(&result, func(func()) (*deepseek.ChatCompletionResponse, error) {
 			response, err := o.createChatCompletion(ctx, request)
 			if err != nil && o.isTransientResponse(err) {
 				return response, WrapErrRetryable(err)
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -182,10 +179,13 @@ func (o *Deepseek) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 		}
 
 		recordUsage(InputTokenAccountingCacheTokensIncluded, &resp.Usage.PromptTokens, &resp.Usage.CompletionTokens, nil, &resp.Usage.PromptCacheHitTokens, &result.usage)
+		recordRequestOutputTokens(&resp.Usage.CompletionTokens, &result)
+		// The non-streaming usage response does not report reasoning tokens separately.
 		if len(resp.Choices) == 0 {
 			return result, ErrNoResponseCandidates
 		}
 		for _, candidate := range resp.Choices {
+			result.recordReasoning(candidate.Message.ReasoningContent)
 			isTerminal := o.isTerminalStopReason(candidate.FinishReason)
 			logFinishReason(ctx, logger, candidate.FinishReason, isTerminal)
 
@@ -240,7 +240,7 @@ func (o *Deepseek) createPromptMessageParts(ctx context.Context, promptText stri
 		if fileType, err := file.TypeValue(ctx); err != nil {
 			return parts, err
 		} else if !isSupportedImageType(fileType) {
-			return parts, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
+			return parts, unsupportedFileError(file, fileType)
 		}
 
 		dataURL, err := file.GetDataURL(ctx)
@@ -269,6 +269,31 @@ func (o *Deepseek) createPromptMessageParts(ctx context.Context, promptText stri
 	return parts, nil
 }
 
+// createPromptText builds a plain text prompt with any text document files inlined before the prompt.
+// Other files require file upload, which is not supported.
+func (o *Deepseek) createPromptText(ctx context.Context, promptText string, files []config.TaskFile, result *Result) (string, error) {
+	parts := make([]string, 0, (len(files)*2)+1)
+	for _, file := range files {
+		if fileType, err := file.TypeValue(ctx); err != nil {
+			return "", err
+		} else if classifyFile(fileType) != fileKindText {
+			return "", ErrFileUploadNotSupported
+		}
+
+		text, err := taskFileText(ctx, file)
+		if err != nil {
+			return "", err
+		}
+
+		// Attach file name before the inlined document text.
+		parts = append(parts, result.recordPrompt(DefaultTaskFileNameInstruction(file)), text)
+	}
+
+	parts = append(parts, result.recordPrompt(promptText)) // append the prompt text after the file data for improved context integrity
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
 func (o *Deepseek) applyModelParameters(request any, modelParams config.DeepseekModelParams) {
 	switch req := request.(type) {
 	case *deepseek.ChatCompletionRequest:
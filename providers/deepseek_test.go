@@ -30,6 +30,20 @@ func TestDeepseek_FileUploadNotSupported(t *testing.T) {
 	require.ErrorIs(t, err, ErrFileUploadNotSupported)
 }
 
+func TestDeepseek_CreatePromptText(t *testing.T) {
+	textFile := mockTaskFile(t, "notes", testutils.CreateMockFile(t, "notes-*.txt", []byte("plain notes")), "text/plain")
+
+	result := &Result{}
+	prompt, err := (&Deepseek{}).createPromptText(context.Background(), "Summarize.", []config.TaskFile{textFile}, result)
+	require.NoError(t, err)
+	assert.Equal(t, "[file: notes]\n\nplain notes\n\nSummarize.", prompt)
+	assert.Equal(t, []string{"[file: notes]", "Summarize."}, result.GetPrompts())
+
+	prompt, err = (&Deepseek{}).createPromptText(context.Background(), "Summarize.", nil, &Result{})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize.", prompt)
+}
+
 func TestDeepseekApplyModelParameters(t *testing.T) {
 	provider := &Deepseek{}
 
@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/providers"
+)
+
+const (
+	// rateLimitedFactor scales the rate down after a rate limited response.
+	rateLimitedFactor = 0.5
+	// lowQuotaFactor scales the rate down when the remaining quota runs low.
+	lowQuotaFactor = 0.8
+	// recoveryFactor scales the rate up while enough quota remains.
+	recoveryFactor = 1.1
+	// lowQuotaThreshold is the remaining quota fraction below which the rate is lowered.
+	lowQuotaThreshold = 0.1
+	// highQuotaThreshold is the remaining quota fraction above which the rate is raised.
+	highQuotaThreshold = 0.5
+	// minRateFraction bounds how far the rate can be lowered relative to the configured rate.
+	minRateFraction = 0.05
+	// recoveryCooldown is the time after lowering the rate during which it is not raised again.
+	recoveryCooldown = 30 * time.Second
+)
+
+// Limiter limits the rate of requests sent to a provider.
+type Limiter interface {
+	// Wait blocks until a request may be sent or the context is done.
+	Wait(ctx context.Context) error
+}
+
+// rateLimitObserver is implemented by limiters that adapt to the rate limit hints of provider responses.
+type rateLimitObserver interface {
+	Observe(ctx context.Context, info providers.RateLimitInfo)
+}
+
+// AdaptiveLimiter is a Limiter whose rate adapts to the rate limit hints of provider responses.
+// The rate starts at the configured maximum, is lowered after rate limited responses or when the
+// remaining quota reported by the provider runs low, and is raised back towards the maximum while
+// enough quota remains. A server-requested Retry-After delay on a rate limited response pauses all
+// requests. Every adjustment is logged, and pauses and reductions of the rate are also recorded as
+// events of the task whose response caused them. It is safe for concurrent use.
+type AdaptiveLimiter struct {
+	limiter *rate.Limiter
+	maxRate rate.Limit
+	minRate rate.Limit
+	logger  logging.Logger
+	name    string
+	now     func() time.Time
+
+	mu           sync.Mutex
+	pausedUntil  time.Time
+	lastLowering time.Time
+}
+
+// NewAdaptiveLimiter creates an adaptive limiter that allows at most requestsPerMinute requests.
+// Adjustments are logged with the given name, e.g. the provider name.
+func NewAdaptiveLimiter(logger logging.Logger, name string, requestsPerMinute int) *AdaptiveLimiter {
+	maxRate := rate.Limit(requestsPerMinute) / 60
+	return &AdaptiveLimiter{
+		limiter: rate.NewLimiter(maxRate, requestsPerMinute),
+		maxRate: maxRate,
+		minRate: maxRate * minRateFraction,
+		logger:  logger,
+		name:    name,
+		now:     time.Now,
+	}
+}
+
+// Wait blocks until requests are no longer paused and the current rate allows a request.
+func (a *AdaptiveLimiter) Wait(ctx context.Context) error {
+	a.mu.Lock()
+	pause := a.pausedUntil.Sub(a.now())
+	a.mu.Unlock()
+	if pause > 0 {
+		timer := time.NewTimer(pause)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return a.limiter.Wait(ctx)
+}
+
+// Observe adjusts the rate based on the rate limit hints of a provider response.
+func (a *AdaptiveLimiter) Observe(ctx context.Context, info providers.RateLimitInfo) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+
+	if info.IsRateLimited() {
+		if info.RetryAfter > 0 && now.Add(info.RetryAfter).After(a.pausedUntil) {
+			a.pausedUntil = now.Add(info.RetryAfter)
+			a.logger.Message(ctx, logging.LevelWarn, "%s: adaptive rate limit: pausing requests for %s as requested by the server.", a.name, info.RetryAfter)
+			recordEvent(ctx, EventSourceRateLimit, info.RetryAfter, "paused requests to %s for %v as requested by the server", a.name, info.RetryAfter)
+		}
+		a.lowerLocked(ctx, now, rateLimitedFactor, "after a rate limited response")
+		return
+	}
+
+	quota, known := info.RemainingQuota()
+	switch {
+	case !known:
+	case quota < lowQuotaThreshold:
+		a.lowerLocked(ctx, now, lowQuotaFactor, fmt.Sprintf("with %.0f%% of the quota remaining", quota*100))
+	case quota > highQuotaThreshold && now.Sub(a.lastLowering) >= recoveryCooldown:
+		a.raiseLocked(ctx, fmt.Sprintf("with %.0f%% of the quota remaining", quota*100))
+	}
+}
+
+func (a *AdaptiveLimiter) lowerLocked(ctx context.Context, now time.Time, factor float64, reason string) {
+	a.lastLowering = now
+	current := a.limiter.Limit()
+	lowered := max(current*rate.Limit(factor), a.minRate)
+	if lowered < current {
+		a.limiter.SetLimit(lowered)
+		a.logger.Message(ctx, logging.LevelWarn, "%s: adaptive rate limit: lowered from %s to %s requests/min %s.", a.name, formatPerMinute(current), formatPerMinute(lowered), reason)
+		recordEvent(ctx, EventSourceRateLimit, 0, "lowered the rate limit of %s from %s to %s requests/min %s", a.name, formatPerMinute(current), formatPerMinute(lowered), reason)
+	}
+}
+
+func (a *AdaptiveLimiter) raiseLocked(ctx context.Context, reason string) {
+	current := a.limiter.Limit()
+	raised := min(current*recoveryFactor, a.maxRate)
+	if raised > current {
+		a.limiter.SetLimit(raised)
+		a.logger.Message(ctx, logging.LevelInfo, "%s: adaptive rate limit: raised from %s to %s requests/min %s.", a.name, formatPerMinute(current), formatPerMinute(raised), reason)
+	}
+}
+
+// RequestsPerMinute returns the current rate.
+func (a *AdaptiveLimiter) RequestsPerMinute() float64 {
+	return float64(a.limiter.Limit() * 60)
+}
+
+func formatPerMinute(limit rate.Limit) string {
+	return strconv.FormatFloat(float64(limit*60), 'f', 1, 64)
+}
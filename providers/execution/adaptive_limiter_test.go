@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package execution
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/providers"
+)
+
+func quota(remaining int64, limit int64) providers.RateLimitInfo {
+	return providers.RateLimitInfo{StatusCode: http.StatusOK, RemainingRequests: &remaining, LimitRequests: &limit}
+}
+
+func TestAdaptiveLimiter_Observe(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewAdaptiveLimiter(testutils.NewTestLogger(t), "test-provider", 100)
+	limiter.now = func() time.Time { return now }
+
+	// Plenty of quota at the maximum rate changes nothing.
+	limiter.Observe(ctx, quota(90, 100))
+	assert.InDelta(t, 100, limiter.RequestsPerMinute(), 0.001)
+
+	// A rate limited response halves the rate and pauses requests.
+	limiter.Observe(ctx, providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second})
+	assert.InDelta(t, 50, limiter.RequestsPerMinute(), 0.001)
+	assert.Equal(t, now.Add(5*time.Second), limiter.pausedUntil)
+
+	// Low remaining quota lowers the rate further.
+	limiter.Observe(ctx, quota(5, 100))
+	assert.InDelta(t, 40, limiter.RequestsPerMinute(), 0.001)
+
+	// The rate is not raised again right after it was lowered.
+	limiter.Observe(ctx, quota(90, 100))
+	assert.InDelta(t, 40, limiter.RequestsPerMinute(), 0.001)
+
+	// Once the cooldown has passed, the rate recovers up to the configured maximum.
+	now = now.Add(recoveryCooldown)
+	for range 20 {
+		limiter.Observe(ctx, quota(90, 100))
+	}
+	assert.InDelta(t, 100, limiter.RequestsPerMinute(), 0.001)
+
+	// The rate is never lowered below the floor.
+	for range 20 {
+		limiter.Observe(ctx, providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests})
+	}
+	assert.InDelta(t, 100*minRateFraction, limiter.RequestsPerMinute(), 0.001)
+}
+
+func TestAdaptiveLimiter_Wait(t *testing.T) {
+	limiter := NewAdaptiveLimiter(testutils.NewTestLogger(t), "test-provider", 600)
+
+	start := time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	limiter.Observe(context.Background(), providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: 100 * time.Millisecond})
+	start = time.Now()
+	require.NoError(t, limiter.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	limiter.Observe(context.Background(), providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestAdaptiveLimiter_ObserveRecordsEvents(t *testing.T) {
+	limiter := NewAdaptiveLimiter(testutils.NewTestLogger(t), "test-provider", 100)
+
+	var events []Event
+	ctx := WithEventRecorder(context.Background(), func(event Event) { events = append(events, event) })
+	limiter.Observe(ctx, providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second})
+	limiter.Observe(ctx, quota(90, 100)) // raising the rate is not recorded
+
+	require.Len(t, events, 2)
+	assert.Equal(t, Event{Time: events[0].Time, Source: EventSourceRateLimit, Message: "paused requests to test-provider for 5s as requested by the server", Delay: 5 * time.Second}, events[0])
+	assert.Equal(t, Event{Time: events[1].Time, Source: EventSourceRateLimit, Message: "lowered the rate limit of test-provider from 100.0 to 50.0 requests/min after a rate limited response"}, events[1])
+}
@@ -0,0 +1,222 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/providers"
+)
+
+// ErrProviderUnavailable is returned for requests that are not sent because a circuit breaker
+// has given up on a failing provider.
+type ErrProviderUnavailable struct {
+	// Provider is the name of the unavailable provider.
+	Provider string
+	// Transitions lists the state transitions of the circuit breaker.
+	Transitions []string
+}
+
+func (e *ErrProviderUnavailable) Error() string {
+	return fmt.Sprintf("provider %s is unavailable: circuit breaker gave up after repeated failures", e.Provider)
+}
+
+type circuitState int
+
+const (
+	// circuitClosed lets all requests through.
+	circuitClosed circuitState = iota
+	// circuitOpen pauses all requests until the next probe is due.
+	circuitOpen
+	// circuitHalfOpen lets a single probe request through while the others wait for its outcome.
+	circuitHalfOpen
+	// circuitUnavailable rejects all requests.
+	circuitUnavailable
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitUnavailable:
+		return "unavailable"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops sending requests to a provider that keeps failing. It is shared by all
+// executors of the provider and is safe for concurrent use.
+//
+// After FailureThreshold consecutive transient errors the circuit opens and pauses all requests.
+// Every ProbeIntervalSeconds a single request is let through as a probe. A successful probe
+// closes the circuit and resumes all requests; a probe that fails with a transient error keeps it
+// open. A probe that fails with any other error, e.g. because the task itself is invalid, says
+// nothing about the provider, so the next request probes again without waiting. After
+// MaxProbeAttempts failed probes the provider is considered unavailable and all remaining requests
+// fail with ErrProviderUnavailable without being sent. Every state transition is logged and
+// recorded as an event of the request that caused it, and the time requests wait while the
+// circuit is open is recorded as an event of each waiting request.
+type CircuitBreaker struct {
+	logger        logging.Logger
+	name          string
+	threshold     int
+	probeInterval time.Duration
+	maxProbes     int
+	now           func() time.Time
+
+	mu           sync.Mutex
+	state        circuitState
+	failures     int
+	failedProbes int
+	probeAt      time.Time
+	changed      chan struct{}
+	transitions  []string
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. State transitions are logged with the
+// given name, e.g. the provider name.
+func NewCircuitBreaker(logger logging.Logger, name string, cfg config.CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		logger:        logger,
+		name:          name,
+		threshold:     cfg.FailureThreshold,
+		probeInterval: time.Duration(cfg.ProbeIntervalSeconds) * time.Second,
+		maxProbes:     cfg.MaxProbeAttempts,
+		now:           time.Now,
+		changed:       make(chan struct{}),
+	}
+}
+
+// Transitions returns the state transitions of the circuit breaker so far.
+func (b *CircuitBreaker) Transitions() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.transitions...)
+}
+
+// acquire waits until a request may be sent. It reports whether the request is a probe,
+// in which case its outcome decides whether the circuit closes.
+func (b *CircuitBreaker) acquire(ctx context.Context) (probe bool, err error) {
+	start := time.Now()
+	defer func() {
+		if waited := time.Since(start); waited >= minRecordedWait {
+			recordEvent(ctx, EventSourceCircuitBreaker, waited, "waited %v while the circuit breaker of %s was open", waited.Round(time.Millisecond), b.name)
+		}
+	}()
+	for {
+		b.mu.Lock()
+		var timer *time.Timer
+		switch b.state {
+		case circuitClosed:
+			b.mu.Unlock()
+			return false, nil
+		case circuitUnavailable:
+			err = &ErrProviderUnavailable{Provider: b.name, Transitions: append([]string(nil), b.transitions...)}
+			b.mu.Unlock()
+			return false, err
+		case circuitOpen:
+			if delay := b.probeAt.Sub(b.now()); delay > 0 {
+				timer = time.NewTimer(delay)
+			} else {
+				b.transitionLocked(ctx, circuitHalfOpen, "probing the provider")
+				b.mu.Unlock()
+				return true, nil
+			}
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		var probeDue <-chan time.Time // nil while another request is probing
+		if timer != nil {
+			probeDue = timer.C
+		}
+		select {
+		case <-probeDue:
+		case <-changed:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// record updates the circuit with the outcome of a request.
+func (b *CircuitBreaker) record(ctx context.Context, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := errors.Is(err, providers.ErrRetryable)
+	if probe && b.state == circuitHalfOpen {
+		switch {
+		case err == nil:
+			b.failures, b.failedProbes = 0, 0
+			b.transitionLocked(ctx, circuitClosed, "probe succeeded, resuming requests")
+		case ctx.Err() != nil:
+			// The probe was abandoned, let another request probe the provider.
+			b.probeAt = b.now()
+			b.transitionLocked(ctx, circuitOpen, "probe was canceled")
+		case failed:
+			b.failedProbes++
+			if b.maxProbes > 0 && b.failedProbes >= b.maxProbes {
+				b.transitionLocked(ctx, circuitUnavailable, fmt.Sprintf("giving up after %d failed probes", b.failedProbes))
+			} else {
+				b.probeAt = b.now().Add(b.probeInterval)
+				b.transitionLocked(ctx, circuitOpen, fmt.Sprintf("probe failed, next probe in %v", b.probeInterval))
+			}
+		default:
+			// The probe failed with an error that does not tell whether the provider has
+			// recovered, let another request probe it right away.
+			b.probeAt = b.now()
+			b.transitionLocked(ctx, circuitOpen, "probe was inconclusive")
+		}
+		return
+	}
+
+	if b.state != circuitClosed {
+		return // outcome of a request sent before the circuit opened
+	}
+	if !failed {
+		if err == nil || ctx.Err() == nil {
+			b.failures = 0
+		}
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.probeAt = b.now().Add(b.probeInterval)
+		b.transitionLocked(ctx, circuitOpen, fmt.Sprintf("%d consecutive transient errors, pausing requests for %v", b.failures, b.probeInterval))
+	}
+}
+
+func (b *CircuitBreaker) transitionLocked(ctx context.Context, state circuitState, reason string) {
+	from := b.state
+	b.transitions = append(b.transitions, fmt.Sprintf("%s: %s -> %s: %s", b.now().Format(time.RFC3339), from, state, reason))
+	b.state = state
+	close(b.changed)
+	b.changed = make(chan struct{})
+
+	level := logging.LevelWarn
+	if state == circuitClosed {
+		level = logging.LevelInfo
+	}
+	b.logger.Message(ctx, level, "%s: circuit breaker %s -> %s: %s.", b.name, from, state, reason)
+	recordEvent(ctx, EventSourceCircuitBreaker, 0, "circuit breaker of %s %s -> %s: %s", b.name, from, state, reason)
+}
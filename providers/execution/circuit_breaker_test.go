@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/providers"
+)
+
+var errTransient = fmt.Errorf("%w: service unavailable", providers.ErrRetryable)
+
+func newTestCircuitBreaker(t *testing.T, threshold int, maxProbes int) *CircuitBreaker {
+	breaker := NewCircuitBreaker(testutils.NewTestLogger(t), "test-provider", config.CircuitBreakerConfig{
+		FailureThreshold:     threshold,
+		ProbeIntervalSeconds: 1,
+		MaxProbeAttempts:     maxProbes,
+	})
+	breaker.probeInterval = 10 * time.Millisecond
+	return breaker
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	ctx := context.Background()
+	breaker := newTestCircuitBreaker(t, 3, 0)
+
+	breaker.record(ctx, false, errTransient)
+	breaker.record(ctx, false, errTransient)
+	breaker.record(ctx, false, nil) // a success resets the count
+	breaker.record(ctx, false, errTransient)
+	breaker.record(ctx, false, errors.New("invalid request")) // so does a permanent error
+	breaker.record(ctx, false, errTransient)
+	breaker.record(ctx, false, errTransient)
+	assert.Equal(t, circuitClosed, breaker.state)
+
+	breaker.record(ctx, false, errTransient)
+	assert.Equal(t, circuitOpen, breaker.state)
+	require.Len(t, breaker.Transitions(), 1)
+	assert.Contains(t, breaker.Transitions()[0], "closed -> open: 3 consecutive transient errors")
+}
+
+func TestCircuitBreaker_ProbeSuccessResumesRequests(t *testing.T) {
+	ctx := context.Background()
+	breaker := newTestCircuitBreaker(t, 1, 0)
+	breaker.record(ctx, false, errTransient)
+
+	start := time.Now()
+	probe, err := breaker.acquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, probe)
+	assert.GreaterOrEqual(t, time.Since(start), 5*time.Millisecond)
+
+	// Other requests wait for the outcome of the probe.
+	var wg sync.WaitGroup
+	released := make(chan bool, 3)
+	for range 3 {
+		wg.Go(func() {
+			probe, err := breaker.acquire(ctx)
+			assert.NoError(t, err)
+			released <- probe
+		})
+	}
+	select {
+	case <-released:
+		t.Fatal("requests must wait while the provider is probed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	breaker.record(ctx, true, nil)
+	wg.Wait()
+	close(released)
+	for probe := range released {
+		assert.False(t, probe)
+	}
+	assert.Equal(t, circuitClosed, breaker.state)
+	assert.Len(t, breaker.Transitions(), 3)
+}
+
+func TestCircuitBreaker_GivesUpAfterFailedProbes(t *testing.T) {
+	ctx := context.Background()
+	breaker := newTestCircuitBreaker(t, 1, 2)
+	breaker.record(ctx, false, errTransient)
+
+	for range 2 {
+		probe, err := breaker.acquire(ctx)
+		require.NoError(t, err)
+		require.True(t, probe)
+		breaker.record(ctx, probe, errTransient)
+	}
+
+	_, err := breaker.acquire(ctx)
+	var unavailableErr *ErrProviderUnavailable
+	require.ErrorAs(t, err, &unavailableErr)
+	assert.Equal(t, "test-provider", unavailableErr.Provider)
+	assert.Len(t, unavailableErr.Transitions, 5)
+	assert.Contains(t, unavailableErr.Transitions[4], "half-open -> unavailable: giving up after 2 failed probes")
+}
+
+func TestCircuitBreaker_InconclusiveProbe(t *testing.T) {
+	ctx := context.Background()
+	breaker := newTestCircuitBreaker(t, 1, 1)
+	breaker.probeInterval = time.Hour
+	breaker.record(ctx, false, errTransient)
+	breaker.probeAt = breaker.now() // let the first probe through right away
+
+	probe, err := breaker.acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, probe)
+
+	// A permanent error neither closes the circuit nor counts as a failed probe.
+	breaker.record(ctx, probe, errors.New("invalid request"))
+	assert.Equal(t, circuitOpen, breaker.state)
+	assert.Zero(t, breaker.failedProbes)
+	assert.Contains(t, breaker.Transitions()[2], "half-open -> open: probe was inconclusive")
+
+	// The next request probes again without waiting for the probe interval.
+	probe, err = breaker.acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, probe)
+	breaker.record(ctx, probe, nil)
+	assert.Equal(t, circuitClosed, breaker.state)
+}
+
+func TestCircuitBreaker_RecordsEvents(t *testing.T) {
+	breaker := newTestCircuitBreaker(t, 1, 0)
+	breaker.probeInterval = 50 * time.Millisecond
+
+	var mu sync.Mutex
+	var events []Event
+	ctx := WithEventRecorder(context.Background(), func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+
+	breaker.record(ctx, false, errTransient)
+	probe, err := breaker.acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, probe)
+	breaker.record(ctx, probe, nil)
+
+	require.Len(t, events, 4)
+	for _, event := range events {
+		assert.Equal(t, EventSourceCircuitBreaker, event.Source)
+	}
+	assert.Equal(t, "circuit breaker of test-provider closed -> open: 1 consecutive transient errors, pausing requests for 50ms", events[0].Message)
+	assert.Equal(t, "circuit breaker of test-provider open -> half-open: probing the provider", events[1].Message)
+	assert.Contains(t, events[2].Message, "while the circuit breaker of test-provider was open")
+	assert.Greater(t, events[2].Delay, 20*time.Millisecond)
+	assert.Equal(t, "circuit breaker of test-provider half-open -> closed: probe succeeded, resuming requests", events[3].Message)
+}
+
+func TestCircuitBreaker_CanceledWait(t *testing.T) {
+	breaker := newTestCircuitBreaker(t, 1, 0)
+	breaker.probeInterval = time.Hour
+	breaker.record(context.Background(), false, errTransient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := breaker.acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestExecutor_Execute_CircuitBreaker(t *testing.T) {
+	provider := &rateLimitedProvider{failures: 100}
+	breaker := newTestCircuitBreaker(t, 2, 1)
+	executor := NewExecutor(provider, config.RunConfig{Name: "mock"}, nil).WithCircuitBreaker(breaker)
+	logger := testutils.NewTestLogger(t)
+
+	// Two failures open the circuit and the third request is a probe, whose failure gives up.
+	for range 3 {
+		_, err := executor.Execute(context.Background(), logger, config.Task{Name: "task"})
+		require.ErrorIs(t, err, providers.ErrRetryable)
+	}
+	require.Len(t, provider.attempts, 3)
+	assert.Equal(t, circuitUnavailable, breaker.state)
+
+	// Remaining tasks fail without being sent.
+	_, err := executor.Execute(context.Background(), logger, config.Task{Name: "next"})
+	var unavailableErr *ErrProviderUnavailable
+	require.ErrorAs(t, err, &unavailableErr)
+	assert.Len(t, provider.attempts, 3)
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// EventSourceRateLimit identifies events of the rate limiters, e.g. waits for a free
+	// request slot or adjustments of the adaptive rate.
+	EventSourceRateLimit = "rate-limit"
+	// EventSourceRetry identifies events of the retry policy, e.g. delays requested by the server.
+	EventSourceRetry = "retry"
+	// EventSourceCircuitBreaker identifies events of the circuit breaker, e.g. waits while
+	// the circuit is open or transitions between its states.
+	EventSourceCircuitBreaker = "circuit-breaker"
+)
+
+// minRecordedWait is the shortest wait for a rate limiter that is recorded as an event.
+const minRecordedWait = 10 * time.Millisecond
+
+// Event records something that delayed or otherwise affected the execution of a task
+// without being an error of the task itself, e.g. throttling by a rate limiter.
+type Event struct {
+	// Time is when the event occurred.
+	Time time.Time
+	// Source identifies the component that produced the event, e.g. EventSourceRateLimit.
+	Source string
+	// Message describes the event.
+	Message string
+	// Delay is how long the event delayed the task, or 0 if it did not delay it directly.
+	Delay time.Duration
+}
+
+// EventRecorder receives the events that affected the execution of a task.
+// It may be called concurrently.
+type EventRecorder func(event Event)
+
+type eventRecorderKey struct{}
+
+// WithEventRecorder returns a copy of ctx that reports the execution events of requests
+// made with it to the given recorder.
+func WithEventRecorder(ctx context.Context, recorder EventRecorder) context.Context {
+	return context.WithValue(ctx, eventRecorderKey{}, recorder)
+}
+
+// recordEvent reports an event to the EventRecorder set on ctx, if any.
+func recordEvent(ctx context.Context, source string, delay time.Duration, format string, args ...interface{}) {
+	if recorder, ok := ctx.Value(eventRecorderKey{}).(EventRecorder); ok {
+		recorder(Event{
+			Time:    time.Now(),
+			Source:  source,
+			Message: fmt.Sprintf(format, args...),
+			Delay:   delay,
+		})
+	}
+}
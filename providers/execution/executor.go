@@ -12,6 +12,10 @@ package execution
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
 	"sync/atomic"
 	"time"
 
@@ -23,6 +27,43 @@ import (
 	"github.com/petmal/mindtrial/providers"
 )
 
+const (
+	// maxRetryAfter caps the retry delay requested by a server.
+	maxRetryAfter = 10 * time.Minute
+	// maxBackoffDelay caps randomized retry delays when the retry policy sets no maximum delay.
+	maxBackoffDelay = 24 * time.Hour
+)
+
+// Attempt records a single attempt to execute a task.
+type Attempt struct {
+	// StartedAt is when the attempt started.
+	StartedAt time.Time
+	// Duration is how long the attempt took.
+	Duration time.Duration
+	// Err is the error the attempt failed with, or nil if it succeeded.
+	Err error
+	// ErrorClass is the class of the error used by retry rules, e.g. config.ErrorClassRateLimit,
+	// or empty if the error matches no class.
+	ErrorClass string
+}
+
+// ErrAttempts is returned when a task executed with a retry policy fails. It records
+// every attempt and wraps the error of the last one.
+type ErrAttempts struct {
+	// Attempts lists all attempts in the order they were made.
+	Attempts []Attempt
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *ErrAttempts) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ErrAttempts) Unwrap() error {
+	return e.Err
+}
+
 // BackoffWithCallback wraps a retry.Backoff with a callback function that is called
 // before each retry attempt. The callback receives the next retry attempt number
 // and the delay duration.
@@ -41,19 +82,33 @@ func BackoffWithCallback(onBackoff func(nextRetryAttempt uint64, nextDelay time.
 	})
 }
 
+// BackoffWithRetryAfter wraps a retry.Backoff so that each delay is at least the delay
+// returned by retryAfter, e.g. the delay requested by the server with a rate limited response.
+func BackoffWithRetryAfter(retryAfter func() time.Duration, next retry.Backoff) retry.Backoff {
+	return retry.BackoffFunc(func() (nextDelay time.Duration, stop bool) {
+		nextDelay, stop = next.Next()
+		if stop {
+			return
+		}
+		return max(nextDelay, retryAfter()), false
+	})
+}
+
 // Executor provides a unified way to execute provider tasks with retry logic and rate limiting.
 type Executor struct {
 	Provider      providers.Provider
 	RunConfig     config.RunConfig
-	sharedLimiter *rate.Limiter
+	sharedLimiter Limiter
 	limiter       *rate.Limiter
+	breaker       *CircuitBreaker
 }
 
 // NewExecutor creates a new provider executor with the given provider and run configuration.
 // An optional shared rate limiter can be provided to enforce an aggregate rate limit across
 // multiple executors (e.g., all runs within a provider). When both a shared limiter and
-// a per-run limiter are configured, the shared limiter is checked first.
-func NewExecutor(provider providers.Provider, runConfig config.RunConfig, sharedLimiter *rate.Limiter) *Executor {
+// a per-run limiter are configured, the shared limiter is checked first. If the shared limiter
+// is an AdaptiveLimiter, it is informed about the rate limit hints of every provider response.
+func NewExecutor(provider providers.Provider, runConfig config.RunConfig, sharedLimiter Limiter) *Executor {
 	var limiter *rate.Limiter
 	if runConfig.MaxRequestsPerMinute > 0 {
 		ratePerSecond := rate.Limit(runConfig.MaxRequestsPerMinute) / 60
@@ -68,55 +123,278 @@ func NewExecutor(provider providers.Provider, runConfig config.RunConfig, shared
 	}
 }
 
+// WithoutRateLimit returns a copy of the executor that applies no rate limits.
+// It is used for tasks submitted through a provider's batch API, where requests are not
+// sent to the model individually.
+func (e *Executor) WithoutRateLimit() *Executor {
+	return &Executor{
+		Provider:  e.Provider,
+		RunConfig: e.RunConfig,
+		breaker:   e.breaker,
+	}
+}
+
+// WithCircuitBreaker returns a copy of the executor that sends requests through the given
+// circuit breaker, which is typically shared by all executors of the provider.
+func (e *Executor) WithCircuitBreaker(breaker *CircuitBreaker) *Executor {
+	executor := *e
+	executor.breaker = breaker
+	return &executor
+}
+
 // Execute runs the task using the configured provider, applying retry logic and rate limiting as configured.
 func (e *Executor) Execute(ctx context.Context, logger logging.Logger, task config.Task) (providers.Result, error) {
 	if e.RunConfig.RetryPolicy != nil && e.RunConfig.RetryPolicy.MaxRetryAttempts > 0 {
 		return e.executeWithRetry(ctx, logger, task)
 	}
-	return e.executeOnce(ctx, logger, task)
+	result, errorClass, err := e.executeOnce(ctx, logger, task)
+	if e.isRetryable(err, errorClass) {
+		err = retry.RetryableError(err)
+	}
+	return result, err
 }
 
 func (e *Executor) executeWithRetry(ctx context.Context, logger logging.Logger, task config.Task) (result providers.Result, err error) {
-	backoff := retry.NewExponential(time.Duration(e.RunConfig.RetryPolicy.InitialDelaySeconds) * time.Second)
-	backoff = retry.WithMaxRetries(uint64(e.RunConfig.RetryPolicy.MaxRetryAttempts), backoff)
+	policy := e.RunConfig.RetryPolicy
+	var retryAfter atomic.Int64
+	backoff := newRetryBackoff(*policy)
+	backoff = BackoffWithRetryAfter(func() time.Duration {
+		delay := time.Duration(retryAfter.Swap(0))
+		if delay > maxRetryAfter {
+			logger.Message(ctx, logging.LevelWarn, "server requested to retry after %v, which exceeds the maximum of %v", delay, maxRetryAfter)
+			recordEvent(ctx, EventSourceRetry, maxRetryAfter, "server requested to retry after %v, capped at %v", delay, maxRetryAfter)
+			return maxRetryAfter
+		} else if delay > 0 {
+			logger.Message(ctx, logging.LevelInfo, "server requested to retry after %v", delay)
+			recordEvent(ctx, EventSourceRetry, delay, "server requested to retry after %v", delay)
+		}
+		return delay
+	}, backoff)
+	if policy.MaxTotalSeconds > 0 {
+		backoff = retry.WithMaxDuration(time.Duration(policy.MaxTotalSeconds)*time.Second, backoff)
+	}
 	backoff = BackoffWithCallback(func(nextRetryAttempt uint64, nextDelay time.Duration) {
 		logger.Message(ctx, logging.LevelInfo, "retrying task %d/%d in %v",
-			nextRetryAttempt, e.RunConfig.RetryPolicy.MaxRetryAttempts, nextDelay)
+			nextRetryAttempt, policy.MaxRetryAttempts, nextDelay)
 	}, backoff)
 
+	var attempts []Attempt
 	err = retry.Do(ctx, backoff, func(ctx context.Context) error {
-		executionResult, executionError := e.executeOnce(ctx, logger, task)
+		retryAfter.Store(0) // only the last attempt's hint applies
+		ctx = providers.WithRateLimitObserver(ctx, func(info providers.RateLimitInfo) {
+			if info.RetryAfter > time.Duration(retryAfter.Load()) {
+				retryAfter.Store(int64(info.RetryAfter))
+			}
+		})
+		start := time.Now()
+		executionResult, errorClass, executionError := e.executeOnce(ctx, logger, task)
 		result = executionResult // capture the last attempt's result
+		attempts = append(attempts, Attempt{
+			StartedAt:  start,
+			Duration:   time.Since(start),
+			Err:        executionError,
+			ErrorClass: errorClass,
+		})
+		if e.isRetryable(executionError, errorClass) {
+			return retry.RetryableError(executionError)
+		}
 		return executionError
 	})
 
+	if err != nil {
+		err = &ErrAttempts{Attempts: attempts, Err: err}
+	}
 	return result, err
 }
 
-func (e *Executor) executeOnce(ctx context.Context, logger logging.Logger, task config.Task) (result providers.Result, err error) {
+// newRetryBackoff creates the backoff between retry attempts described by the policy.
+func newRetryBackoff(policy config.RetryPolicy) retry.Backoff {
+	initialDelay := time.Duration(policy.InitialDelaySeconds) * time.Second
+	maxDelay := time.Duration(policy.MaxDelaySeconds) * time.Second
+
+	var backoff retry.Backoff
+	switch policy.Jitter {
+	case config.RetryJitterDecorrelated:
+		backoff = newDecorrelatedJitterBackoff(initialDelay, maxDelay)
+	case config.RetryJitterFull:
+		backoff = retry.NewExponential(initialDelay)
+		if maxDelay > 0 {
+			backoff = retry.WithCappedDuration(maxDelay, backoff)
+		}
+		backoff = withFullJitter(backoff)
+	default:
+		backoff = retry.NewExponential(initialDelay)
+		if maxDelay > 0 {
+			backoff = retry.WithCappedDuration(maxDelay, backoff)
+		}
+	}
+	return retry.WithMaxRetries(uint64(policy.MaxRetryAttempts), backoff)
+}
+
+// withFullJitter wraps a retry.Backoff so that each delay is picked at random between zero and the original delay.
+func withFullJitter(next retry.Backoff) retry.Backoff {
+	return retry.BackoffFunc(func() (nextDelay time.Duration, stop bool) {
+		nextDelay, stop = next.Next()
+		if stop || nextDelay <= 0 {
+			return
+		}
+		return rand.N(nextDelay + 1), false
+	})
+}
+
+// newDecorrelatedJitterBackoff creates a backoff that picks each delay at random between the initial
+// delay and three times the previous delay, capped at maxDelay, or at maxBackoffDelay if maxDelay is 0.
+func newDecorrelatedJitterBackoff(initialDelay time.Duration, maxDelay time.Duration) retry.Backoff {
+	if maxDelay <= 0 {
+		maxDelay = maxBackoffDelay
+	}
+	previous := initialDelay
+	return retry.BackoffFunc(func() (time.Duration, bool) {
+		upper := min(previous*3, maxDelay)
+		if upper <= initialDelay {
+			previous = min(initialDelay, maxDelay)
+		} else {
+			previous = initialDelay + rand.N(upper-initialDelay+1)
+		}
+		return previous, false
+	})
+}
+
+// isRetryable reports whether an attempt that failed with the given error of the given class should be retried.
+// Without explicit rules, all errors that the provider reports as transient are retried.
+func (e *Executor) isRetryable(err error, errorClass string) bool {
+	switch {
+	case err == nil:
+		return false
+	case e.RunConfig.RetryPolicy == nil || len(e.RunConfig.RetryPolicy.RetryOn) == 0:
+		return errors.Is(err, providers.ErrRetryable)
+	default:
+		return errorClass != "" && slices.Contains(e.RunConfig.RetryPolicy.RetryOn, errorClass)
+	}
+}
+
+// executeOnce makes a single attempt to execute the task, recovering a response that could not be parsed
+// according to the response recovery policy if configured.
+func (e *Executor) executeOnce(ctx context.Context, logger logging.Logger, task config.Task) (result providers.Result, errorClass string, err error) {
+	result, errorClass, err = e.executeRequest(ctx, logger, task)
+	if policy := e.RunConfig.ResponseRecovery; policy != nil && errorClass == config.ErrorClassParseError {
+		result, errorClass, err = e.recoverResponse(ctx, logger, *policy, task, result, err)
+	}
+	return
+}
+
+// recoverResponse attempts to recover from a response that could not be parsed by the local steps enabled
+// in the policy, and if they fail, by asking the model to correct its response in up to MaxCorrectiveTurns
+// follow-up requests that quote the error. The returned result accumulates the usage of all requests.
+func (e *Executor) recoverResponse(ctx context.Context, logger logging.Logger, policy config.ResponseRecoveryPolicy, task config.Task, result providers.Result, err error) (providers.Result, string, error) {
+	errorClass := config.ErrorClassParseError
+	for turn := 1; ; turn++ {
+		var unmarshalErr *providers.ErrUnmarshalResponse
+		if !errors.As(err, &unmarshalErr) {
+			return result, errorClass, err
+		}
+		failure := providers.RecoverResponse(ctx, logger, policy, task.ResponseResultFormat, unmarshalErr, &result)
+		if failure == nil {
+			return result, "", nil
+		}
+		if turn > policy.MaxCorrectiveTurns {
+			return result, errorClass, err
+		}
+
+		logger.Message(ctx, logging.LevelInfo, "asking the model to correct the response that could not be parsed %d/%d", turn, policy.MaxCorrectiveTurns)
+		var corrected providers.Result
+		corrected, errorClass, err = e.executeRequest(ctx, logger, providers.CorrectiveTask(task, unmarshalErr.RawMessage, failure))
+		result = providers.MergeCorrectiveResult(result, corrected)
+		if err == nil {
+			logger.Message(ctx, logging.LevelInfo, "response that could not be parsed was recovered by %s", providers.RecoveryCorrectiveTurn)
+			return result, "", nil
+		}
+	}
+}
+
+// executeRequest sends the task to the provider once, subject to the circuit breaker and rate limits.
+func (e *Executor) executeRequest(ctx context.Context, logger logging.Logger, task config.Task) (result providers.Result, errorClass string, err error) {
 	if err = ctx.Err(); err != nil {
 		logger.Error(ctx, logging.LevelWarn, err, "aborting task")
 		return
 	}
 
+	if e.breaker != nil {
+		var probe bool
+		if probe, err = e.breaker.acquire(ctx); err != nil {
+			logger.Error(ctx, logging.LevelWarn, err, "aborting task")
+			return
+		}
+		defer func() {
+			e.breaker.record(ctx, probe, err)
+		}()
+	}
+
 	if e.sharedLimiter != nil {
-		if err = e.sharedLimiter.Wait(ctx); err != nil {
+		if err = waitForLimiter(ctx, e.sharedLimiter, "provider"); err != nil {
 			logger.Error(ctx, logging.LevelWarn, err, "aborting task")
 			return
 		}
+		if observer, ok := e.sharedLimiter.(rateLimitObserver); ok {
+			ctx = providers.WithRateLimitObserver(ctx, func(info providers.RateLimitInfo) {
+				observer.Observe(ctx, info)
+			})
+		}
 	}
 
 	if e.limiter != nil {
-		if err = e.limiter.Wait(ctx); err != nil {
+		if err = waitForLimiter(ctx, e.limiter, "run"); err != nil {
 			logger.Error(ctx, logging.LevelWarn, err, "aborting task")
 			return
 		}
 	}
 
+	var statusCode atomic.Int64
+	ctx = providers.WithRateLimitObserver(ctx, func(info providers.RateLimitInfo) {
+		statusCode.Store(int64(info.StatusCode))
+	})
 	result, err = e.Provider.Run(ctx, logger, e.RunConfig, task)
-	if errors.Is(err, providers.ErrRetryable) {
-		logger.Error(ctx, logging.LevelWarn, err, "task encountered a transient error")
-		err = retry.RetryableError(err)
+	if err != nil {
+		errorClass = classifyError(ctx, err, int(statusCode.Load()))
+		if errors.Is(err, providers.ErrRetryable) {
+			logger.Error(ctx, logging.LevelWarn, err, "task encountered a transient error")
+		}
 	}
 	return
 }
+
+// waitForLimiter waits until the limiter allows a request, recording the wait as an event
+// of the task if it was long enough to matter. The scope names the limit, e.g. "run".
+func waitForLimiter(ctx context.Context, limiter Limiter, scope string) error {
+	start := time.Now()
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	if waited := time.Since(start); waited >= minRecordedWait {
+		recordEvent(ctx, EventSourceRateLimit, waited, "waited %v for the %s rate limit", waited.Round(time.Millisecond), scope)
+	}
+	return nil
+}
+
+// classifyError assigns the error of an attempt to one of the error classes used by retry rules,
+// e.g. config.ErrorClassRateLimit. The statusCode is the status of the last error response received
+// during the attempt, or 0 if none. It returns an empty string for errors that match no class.
+func classifyError(ctx context.Context, err error, statusCode int) string {
+	var unmarshalErr *providers.ErrUnmarshalResponse
+	var noActionableContentErr *providers.ErrNoActionableContent
+	var netErr net.Error
+	switch {
+	case errors.As(err, &unmarshalErr):
+		return config.ErrorClassParseError
+	case errors.As(err, &noActionableContentErr):
+		return config.ErrorClassNoContent
+	case statusCode == http.StatusTooManyRequests:
+		return config.ErrorClassRateLimit
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout,
+		ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()):
+		return config.ErrorClassTimeout
+	case statusCode >= http.StatusInternalServerError, errors.Is(err, providers.ErrStreamResponse):
+		return config.ErrorClassServerError
+	}
+	return ""
+}
@@ -8,6 +8,9 @@ package execution
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
 	"github.com/petmal/mindtrial/pkg/testutils"
 	"github.com/petmal/mindtrial/pkg/utils"
 	"github.com/petmal/mindtrial/providers"
@@ -486,3 +490,261 @@ func TestExecutor_Execute_PreservesMetadataOnSuccess(t *testing.T) {
 		assert.NotNil(t, res.GetUsage().InputTokens, "usage must be populated on retry success")
 	})
 }
+
+// rateLimitedProvider fails with a rate limited response carrying the given Retry-After
+// delay until the given number of attempts has been made.
+type rateLimitedProvider struct {
+	retryAfter time.Duration
+	failures   int
+	attempts   []time.Time
+}
+
+func (p *rateLimitedProvider) Name() string { return "rate-limited" }
+
+func (p *rateLimitedProvider) Run(ctx context.Context, _ logging.Logger, _ config.RunConfig, task config.Task) (providers.Result, error) {
+	p.attempts = append(p.attempts, time.Now())
+	if len(p.attempts) <= p.failures {
+		providers.ReportRateLimit(ctx, providers.RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: p.retryAfter})
+		return providers.Result{}, fmt.Errorf("%w: too many requests", providers.ErrRetryable)
+	}
+	return providers.Result{Title: task.Name}, nil
+}
+
+func (p *rateLimitedProvider) Close(context.Context) error { return nil }
+
+func TestBackoffWithRetryAfter(t *testing.T) {
+	hints := []time.Duration{0, 50 * time.Millisecond, time.Millisecond}
+	backoff := BackoffWithRetryAfter(func() time.Duration {
+		hint := hints[0]
+		hints = hints[1:]
+		return hint
+	}, retry.WithMaxRetries(3, retry.NewConstant(10*time.Millisecond)))
+
+	for _, expected := range []time.Duration{10 * time.Millisecond, 50 * time.Millisecond, 10 * time.Millisecond} {
+		delay, stop := backoff.Next()
+		require.False(t, stop)
+		assert.Equal(t, expected, delay)
+	}
+	_, stop := backoff.Next()
+	assert.True(t, stop)
+}
+
+func TestExecutor_Execute_HonorsRetryAfter(t *testing.T) {
+	provider := &rateLimitedProvider{retryAfter: 1500 * time.Millisecond, failures: 1}
+	executor := NewExecutor(provider, config.RunConfig{
+		Name: "mock",
+		RetryPolicy: &config.RetryPolicy{
+			MaxRetryAttempts:    1,
+			InitialDelaySeconds: 1,
+		},
+	}, nil)
+
+	var events []Event
+	ctx := WithEventRecorder(context.Background(), func(event Event) { events = append(events, event) })
+	result, err := executor.Execute(ctx, testutils.NewTestLogger(t), config.Task{Name: "task"})
+	require.NoError(t, err)
+	assert.Equal(t, "task", result.Title)
+	require.Len(t, provider.attempts, 2)
+	assert.GreaterOrEqual(t, provider.attempts[1].Sub(provider.attempts[0]), provider.retryAfter)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventSourceRetry, events[0].Source)
+	assert.Equal(t, provider.retryAfter, events[0].Delay)
+	assert.Equal(t, "server requested to retry after 1.5s", events[0].Message)
+}
+
+func TestExecutor_Execute_ReportsToAdaptiveLimiter(t *testing.T) {
+	provider := &rateLimitedProvider{failures: 1}
+	limiter := NewAdaptiveLimiter(testutils.NewTestLogger(t), "test-provider", 600)
+	executor := NewExecutor(provider, config.RunConfig{Name: "mock"}, limiter)
+
+	var events []Event
+	ctx := WithEventRecorder(context.Background(), func(event Event) { events = append(events, event) })
+	_, err := executor.Execute(ctx, testutils.NewTestLogger(t), config.Task{Name: "task"})
+	require.Error(t, err)
+	assert.InDelta(t, 300, limiter.RequestsPerMinute(), 0.001)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventSourceRateLimit, events[0].Source)
+	assert.Zero(t, events[0].Delay)
+	assert.Equal(t, "lowered the rate limit of test-provider from 600.0 to 300.0 requests/min after a rate limited response", events[0].Message)
+}
+
+func TestExecutor_Execute_RecordsRateLimitWaits(t *testing.T) {
+	provider, err := createMockProvider("test-provider")
+	require.NoError(t, err)
+	executor := NewExecutor(provider, config.RunConfig{Name: "mock"}, rate.NewLimiter(10, 1))
+	task := config.Task{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")}
+
+	var events []Event
+	ctx := WithEventRecorder(context.Background(), func(event Event) { events = append(events, event) })
+	for range 2 {
+		_, err := executor.Execute(ctx, testutils.NewTestLogger(t), task)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, events, 1, "only the second request waits for the limiter")
+	assert.Equal(t, EventSourceRateLimit, events[0].Source)
+	assert.GreaterOrEqual(t, events[0].Delay, 50*time.Millisecond)
+	assert.Contains(t, events[0].Message, "for the provider rate limit")
+}
+
+func TestNewRetryBackoff(t *testing.T) {
+	delays := func(backoff retry.Backoff) (result []time.Duration) {
+		for {
+			delay, stop := backoff.Next()
+			if stop {
+				return
+			}
+			result = append(result, delay)
+		}
+	}
+
+	t.Run("exponential", func(t *testing.T) {
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+			delays(newRetryBackoff(config.RetryPolicy{MaxRetryAttempts: 4, InitialDelaySeconds: 1})))
+	})
+
+	t.Run("exponential with max delay", func(t *testing.T) {
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+			delays(newRetryBackoff(config.RetryPolicy{MaxRetryAttempts: 4, InitialDelaySeconds: 1, MaxDelaySeconds: 3})))
+	})
+
+	t.Run("full jitter", func(t *testing.T) {
+		for range 50 {
+			got := delays(newRetryBackoff(config.RetryPolicy{MaxRetryAttempts: 4, InitialDelaySeconds: 1, MaxDelaySeconds: 3, Jitter: config.RetryJitterFull}))
+			require.Len(t, got, 4)
+			for i, delay := range got {
+				assert.GreaterOrEqual(t, delay, time.Duration(0))
+				assert.LessOrEqual(t, delay, min(time.Second<<i, 3*time.Second))
+			}
+		}
+	})
+
+	t.Run("decorrelated jitter", func(t *testing.T) {
+		for range 50 {
+			got := delays(newRetryBackoff(config.RetryPolicy{MaxRetryAttempts: 6, InitialDelaySeconds: 1, MaxDelaySeconds: 10, Jitter: config.RetryJitterDecorrelated}))
+			require.Len(t, got, 6)
+			previous := time.Second
+			for _, delay := range got {
+				assert.GreaterOrEqual(t, delay, time.Second)
+				assert.LessOrEqual(t, delay, min(3*previous, 10*time.Second))
+				previous = delay
+			}
+		}
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		statusCode int
+		want       string
+	}{
+		{name: "rate limit", err: errors.New("too many requests"), statusCode: http.StatusTooManyRequests, want: config.ErrorClassRateLimit},
+		{name: "server error", err: errors.New("bad gateway"), statusCode: http.StatusBadGateway, want: config.ErrorClassServerError},
+		{name: "interrupted stream", err: providers.WrapErrRetryable(providers.ErrStreamResponse), want: config.ErrorClassServerError},
+		{name: "gateway timeout", err: errors.New("gateway timeout"), statusCode: http.StatusGatewayTimeout, want: config.ErrorClassTimeout},
+		{name: "request timeout", err: fmt.Errorf("request failed: %w", context.DeadlineExceeded), want: config.ErrorClassTimeout},
+		{name: "task canceled", ctx: canceled, err: fmt.Errorf("request failed: %w", context.DeadlineExceeded)},
+		{name: "parse error", err: providers.NewErrUnmarshalResponse(errors.New("invalid json"), nil, nil), want: config.ErrorClassParseError},
+		{name: "no content", err: providers.NewErrNoActionableContent([]byte("max_tokens"), nil), want: config.ErrorClassNoContent},
+		{name: "client error", err: errors.New("bad request"), statusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			assert.Equal(t, tt.want, classifyError(ctx, tt.err, tt.statusCode))
+		})
+	}
+}
+
+// failingProvider fails with the given errors, one per attempt, and succeeds afterwards.
+type failingProvider struct {
+	errs     []error
+	attempts int
+}
+
+func (p *failingProvider) Name() string { return "failing" }
+
+func (p *failingProvider) Run(_ context.Context, _ logging.Logger, _ config.RunConfig, task config.Task) (providers.Result, error) {
+	p.attempts++
+	if p.attempts <= len(p.errs) {
+		return providers.Result{}, p.errs[p.attempts-1]
+	}
+	return providers.Result{Title: task.Name}, nil
+}
+
+func (p *failingProvider) Close(context.Context) error { return nil }
+
+func TestExecutor_Execute_RetryOn(t *testing.T) {
+	parseErr := providers.NewErrUnmarshalResponse(errors.New("invalid json"), nil, nil)
+	transientErr := providers.WrapErrRetryable(providers.ErrStreamResponse)
+
+	t.Run("retries only the listed error classes", func(t *testing.T) {
+		provider := &failingProvider{errs: []error{parseErr, transientErr}}
+		executor := NewExecutor(provider, config.RunConfig{
+			Name: "mock",
+			RetryPolicy: &config.RetryPolicy{
+				MaxRetryAttempts:    3,
+				InitialDelaySeconds: 1,
+				MaxDelaySeconds:     1,
+				Jitter:              config.RetryJitterFull,
+				RetryOn:             []string{config.ErrorClassParseError},
+			},
+		}, nil)
+
+		_, err := executor.Execute(context.Background(), testutils.NewTestLogger(t), config.Task{Name: "task"})
+		require.ErrorIs(t, err, providers.ErrStreamResponse)
+		assert.Equal(t, 2, provider.attempts)
+
+		var attemptsErr *ErrAttempts
+		require.ErrorAs(t, err, &attemptsErr)
+		require.Len(t, attemptsErr.Attempts, 2)
+		assert.Equal(t, config.ErrorClassParseError, attemptsErr.Attempts[0].ErrorClass)
+		assert.ErrorIs(t, attemptsErr.Attempts[0].Err, parseErr)
+		assert.Equal(t, config.ErrorClassServerError, attemptsErr.Attempts[1].ErrorClass)
+		assert.False(t, attemptsErr.Attempts[1].StartedAt.Before(attemptsErr.Attempts[0].StartedAt.Add(attemptsErr.Attempts[0].Duration)))
+	})
+
+	t.Run("parse errors are not retried by default", func(t *testing.T) {
+		provider := &failingProvider{errs: []error{parseErr}}
+		executor := NewExecutor(provider, config.RunConfig{
+			Name: "mock",
+			RetryPolicy: &config.RetryPolicy{
+				MaxRetryAttempts:    3,
+				InitialDelaySeconds: 1,
+			},
+		}, nil)
+
+		_, err := executor.Execute(context.Background(), testutils.NewTestLogger(t), config.Task{Name: "task"})
+		require.ErrorAs(t, err, new(*providers.ErrUnmarshalResponse))
+		assert.Equal(t, 1, provider.attempts)
+	})
+
+	t.Run("total time budget stops retrying", func(t *testing.T) {
+		provider := &failingProvider{errs: []error{transientErr, transientErr, transientErr, transientErr}}
+		executor := NewExecutor(provider, config.RunConfig{
+			Name: "mock",
+			RetryPolicy: &config.RetryPolicy{
+				MaxRetryAttempts:    3,
+				InitialDelaySeconds: 1,
+				MaxTotalSeconds:     1,
+			},
+		}, nil)
+
+		start := time.Now()
+		_, err := executor.Execute(context.Background(), testutils.NewTestLogger(t), config.Task{Name: "task"})
+		require.ErrorIs(t, err, providers.ErrRetryable)
+		assert.Less(t, time.Since(start), 2*time.Second)
+		assert.Equal(t, 2, provider.attempts)
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"sync"
+
+	"github.com/petmal/mindtrial/config"
+)
+
+// uploadedFile identifies a task file uploaded to a provider's Files API.
+type uploadedFile struct {
+	// ID is the provider file identifier used to delete the upload.
+	ID string
+	// URI is the provider reference used in prompts, if different from the ID.
+	URI string
+}
+
+// uploadFileFunc uploads the content of a task file to a provider's Files API.
+type uploadFileFunc func(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error)
+
+// deleteFileFunc deletes a file previously uploaded to a provider's Files API.
+type deleteFileFunc func(ctx context.Context, file uploadedFile) error
+
+// fileUploads caches files uploaded to a provider's Files API by content hash and MIME type,
+// so that the same content attached to multiple tasks or runs is uploaded only once.
+// It is safe for concurrent use; concurrent requests for the same content share a single upload.
+type fileUploads struct {
+	mu      sync.Mutex
+	entries map[string]*fileUploadEntry
+}
+
+type fileUploadEntry struct {
+	done chan struct{}
+	file uploadedFile
+	err  error
+}
+
+// newFileUploads creates an empty upload cache.
+func newFileUploads() *fileUploads {
+	return &fileUploads{entries: make(map[string]*fileUploadEntry)}
+}
+
+// getOrUpload returns the cached upload of the given task file content, uploading it first if needed.
+// Failed uploads are not cached and will be attempted again on the next request.
+func (u *fileUploads) getOrUpload(ctx context.Context, file config.TaskFile, mimeType string, upload uploadFileFunc) (uploadedFile, error) {
+	content, err := file.Content(ctx)
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	key := fileUploadKey(content, mimeType)
+
+	u.mu.Lock()
+	if entry, ok := u.entries[key]; ok {
+		u.mu.Unlock()
+		select {
+		case <-entry.done:
+			return entry.file, entry.err
+		case <-ctx.Done():
+			return uploadedFile{}, ctx.Err()
+		}
+	}
+	entry := &fileUploadEntry{done: make(chan struct{})}
+	u.entries[key] = entry
+	u.mu.Unlock()
+
+	entry.file, entry.err = upload(ctx, file, content, mimeType)
+	if entry.err != nil {
+		entry.err = fmt.Errorf("%w '%s': %v", ErrUploadFile, file.Name, entry.err)
+		u.mu.Lock()
+		delete(u.entries, key)
+		u.mu.Unlock()
+	}
+	close(entry.done)
+
+	return entry.file, entry.err
+}
+
+// deleteAll deletes all successfully uploaded files and clears the cache.
+// Calling it again deletes only files uploaded since the previous call.
+// It is a no-op for a nil cache.
+func (u *fileUploads) deleteAll(ctx context.Context, deleteFile deleteFileFunc) error {
+	if u == nil {
+		return nil
+	}
+
+	u.mu.Lock()
+	entries := u.entries
+	u.entries = make(map[string]*fileUploadEntry)
+	u.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		<-entry.done
+		if entry.err != nil {
+			continue
+		}
+		if err := deleteFile(ctx, entry.file); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete uploaded file '%s': %w", entry.file.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// uploadFileName returns the file name to report for an upload. Files APIs commonly infer
+// the file type from its extension, so the extension of the file URI or of the MIME type
+// is appended to the task file name if it has none.
+func uploadFileName(file config.TaskFile, mimeType string) string {
+	name := file.Name
+	if path.Ext(name) != "" {
+		return name
+	}
+	if parsed := file.URI.URL(); parsed != nil && path.Ext(parsed.Path) != "" {
+		return name + path.Ext(parsed.Path)
+	}
+	if extensions, err := mime.ExtensionsByType(mediaType(mimeType)); err == nil && len(extensions) > 0 {
+		return name + extensions[0]
+	}
+	return name
+}
+
+func fileUploadKey(content []byte, mimeType string) string {
+	hash := sha256.Sum256(content)
+	return mediaType(mimeType) + ":" + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileUploads_GetOrUpload(t *testing.T) {
+	ctx := context.Background()
+	first := mockTaskFile(t, "first", testutils.CreateMockFile(t, "first-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	second := mockTaskFile(t, "second", testutils.CreateMockFile(t, "second-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	other := mockTaskFile(t, "other", testutils.CreateMockFile(t, "other-*.pdf", []byte("%PDF-2.0")), "application/pdf")
+
+	var uploads atomic.Int32
+	upload := func(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error) {
+		n := uploads.Add(1)
+		return uploadedFile{ID: fmt.Sprintf("file-%d", n)}, nil
+	}
+
+	t.Run("same content is uploaded once", func(t *testing.T) {
+		u := newFileUploads()
+
+		got, err := u.getOrUpload(ctx, first, "application/pdf", upload)
+		require.NoError(t, err)
+		again, err := u.getOrUpload(ctx, second, "application/pdf", upload)
+		require.NoError(t, err)
+		assert.Equal(t, got, again)
+
+		different, err := u.getOrUpload(ctx, other, "application/pdf", upload)
+		require.NoError(t, err)
+		assert.NotEqual(t, got, different)
+	})
+
+	t.Run("concurrent requests share one upload", func(t *testing.T) {
+		u := newFileUploads()
+		uploads.Store(0)
+
+		var wg sync.WaitGroup
+		ids := make([]string, 10)
+		for i := range ids {
+			wg.Go(func() {
+				got, err := u.getOrUpload(ctx, first, "application/pdf", upload)
+				assert.NoError(t, err)
+				ids[i] = got.ID
+			})
+		}
+		wg.Wait()
+
+		assert.EqualValues(t, 1, uploads.Load())
+		for _, id := range ids {
+			assert.Equal(t, "file-1", id)
+		}
+	})
+
+	t.Run("failed upload is not cached", func(t *testing.T) {
+		u := newFileUploads()
+		failure := errors.New("service unavailable")
+
+		_, err := u.getOrUpload(ctx, first, "application/pdf", func(context.Context, config.TaskFile, []byte, string) (uploadedFile, error) {
+			return uploadedFile{}, failure
+		})
+		require.ErrorIs(t, err, ErrUploadFile)
+		assert.Contains(t, err.Error(), "'first': service unavailable")
+
+		got, err := u.getOrUpload(ctx, first, "application/pdf", upload)
+		require.NoError(t, err)
+		assert.NotEmpty(t, got.ID)
+	})
+}
+
+func TestFileUploads_DeleteAll(t *testing.T) {
+	ctx := context.Background()
+	first := mockTaskFile(t, "first", testutils.CreateMockFile(t, "first-*.png", []byte("first")), "image/png")
+	second := mockTaskFile(t, "second", testutils.CreateMockFile(t, "second-*.png", []byte("second")), "image/png")
+
+	u := newFileUploads()
+	for _, file := range []config.TaskFile{first, second} {
+		_, err := u.getOrUpload(ctx, file, "image/png", func(_ context.Context, file config.TaskFile, _ []byte, _ string) (uploadedFile, error) {
+			return uploadedFile{ID: file.Name}, nil
+		})
+		require.NoError(t, err)
+	}
+
+	var deleted []string
+	deleteFile := func(_ context.Context, file uploadedFile) error {
+		deleted = append(deleted, file.ID)
+		if file.ID == "second" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+
+	err := u.deleteAll(ctx, deleteFile)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete uploaded file 'second'")
+	assert.ElementsMatch(t, []string{"first", "second"}, deleted)
+
+	// Deleting again is a no-op.
+	deleted = nil
+	require.NoError(t, u.deleteAll(ctx, deleteFile))
+	assert.Empty(t, deleted)
+
+	// A nil cache has nothing to delete.
+	var nilUploads *fileUploads
+	require.NoError(t, nilUploads.deleteAll(ctx, deleteFile))
+}
+
+func TestUploadFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     config.TaskFile
+		mimeType string
+		expected string
+	}{
+		{
+			name:     "name with extension",
+			file:     mockTaskFile(t, "report.pdf", "file://data/document", "application/pdf"),
+			mimeType: "application/pdf",
+			expected: "report.pdf",
+		},
+		{
+			name:     "extension from URI",
+			file:     mockTaskFile(t, "report", "https://example.com/files/annual.pdf", "application/pdf"),
+			mimeType: "application/pdf",
+			expected: "report.pdf",
+		},
+		{
+			name:     "extension from MIME type",
+			file:     mockTaskFile(t, "picture", "file://data/picture", "image/png"),
+			mimeType: "image/png",
+			expected: "picture.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, uploadFileName(tt.file, tt.mimeType))
+		})
+	}
+}
@@ -7,6 +7,7 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/logging"
@@ -22,12 +24,16 @@ import (
 	"google.golang.org/genai"
 )
 
+// fileProcessingPollInterval is the interval between checks of an uploaded file that is still being processed.
+const fileProcessingPollInterval = 2 * time.Second
+
 // NewGoogleAI creates a new GoogleAI provider instance with the given configuration.
 // It returns an error if client initialization fails.
 func NewGoogleAI(ctx context.Context, cfg config.GoogleAIClientConfig, availableTools []config.ToolConfig) (*GoogleAI, error) {
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  cfg.APIKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     cfg.APIKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: newHTTPClient(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrCreateClient, err)
@@ -35,6 +41,7 @@ func NewGoogleAI(ctx context.Context, cfg config.GoogleAIClientConfig, available
 	return &GoogleAI{
 		client:         client,
 		availableTools: availableTools,
+		uploads:        newFileUploads(),
 	}, nil
 }
 
@@ -42,6 +49,7 @@ func NewGoogleAI(ctx context.Context, cfg config.GoogleAIClientConfig, available
 type GoogleAI struct {
 	client         *genai.Client
 	availableTools []config.ToolConfig
+	uploads        *fileUploads
 }
 
 func (o GoogleAI) Name() string {
@@ -57,23 +65,17 @@ func (o *GoogleAI) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 	forceTextResponseFormat := cfg.DisableStructuredOutput
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			// Find the tool config from available tools.
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			generateConfig.Tools = append(generateConfig.Tools, &genai.Tool{
 				FunctionDeclarations: []*genai.FunctionDeclaration{{
 					Name:                 toolCfg.Name,
@@ -119,6 +121,14 @@ func (o *GoogleAI) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 				}
 			}
 
+			// Apply IncludeThoughts parameter.
+			if modelParams.IncludeThoughts {
+				if generateConfig.ThinkingConfig == nil {
+					generateConfig.ThinkingConfig = &genai.ThinkingConfig{}
+				}
+				generateConfig.ThinkingConfig.IncludeThoughts = true
+			}
+
 			// Apply MediaResolution parameter.
 			if modelParams.MediaResolution != nil {
 				var mediaResolution genai.MediaResolution
@@ -215,15 +225,14 @@ func (o *GoogleAI) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 		}
 
 		// Execute the completion request.
-		resp, err := timed(func() (*genai.GenerateContentResponse, error) {
+		resp, err := timedRequest(&result, func(func()) (*genai.GenerateContentResponse, error) {
 			response, err := o.client.Models.GenerateContent(ctx, cfg.Model, contents, generateConfig)
 			if err != nil && o.isTransientResponse(err) {
 				return response, WrapErrRetryable(err)
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -234,11 +243,17 @@ func (o *GoogleAI) Run(ctx context.Context, logger logging.Logger, cfg config.Ru
 
 		if resp.UsageMetadata != nil {
 			recordUsage(InputTokenAccountingCacheTokensIncluded, &resp.UsageMetadata.PromptTokenCount, &resp.UsageMetadata.CandidatesTokenCount, nil, &resp.UsageMetadata.CachedContentTokenCount, &result.usage)
+			recordRequestOutputTokens(&resp.UsageMetadata.CandidatesTokenCount, &result)
+			// Thought tokens are reported separately from the candidates tokens and omitted when zero.
+			if resp.UsageMetadata.ThoughtsTokenCount > 0 {
+				recordReasoningTokens(&resp.UsageMetadata.ThoughtsTokenCount, &result.usage)
+			}
 		}
 		if len(resp.Candidates) == 0 {
 			return result, ErrNoResponseCandidates
 		}
 		for _, candidate := range resp.Candidates {
+			result.recordReasoning(o.getThoughtText(candidate))
 			isTerminal := o.hasTerminalStopReason(candidate)
 			logFinishReason(ctx, logger, string(candidate.FinishReason), isTerminal)
 
@@ -367,7 +382,7 @@ func (o *GoogleAI) getMessageText(candidate *genai.Candidate) (text string, ok b
 	if candidate != nil && candidate.Content != nil {
 		var textBuilder strings.Builder
 		for _, part := range candidate.Content.Parts {
-			if part != nil {
+			if part != nil && !part.Thought {
 				textBuilder.WriteString(part.Text)
 			}
 		}
@@ -377,23 +392,57 @@ func (o *GoogleAI) getMessageText(candidate *genai.Candidate) (text string, ok b
 	return
 }
 
+// getThoughtText returns the text of the thought parts of a candidate, which
+// carry the thought summaries of thinking models.
+func (o *GoogleAI) getThoughtText(candidate *genai.Candidate) string {
+	var thoughts []string
+	if candidate != nil && candidate.Content != nil {
+		for _, part := range candidate.Content.Parts {
+			if part != nil && part.Thought && part.Text != "" {
+				thoughts = append(thoughts, part.Text)
+			}
+		}
+	}
+	return strings.Join(thoughts, "\n\n")
+}
+
 func (o *GoogleAI) createPromptMessageParts(ctx context.Context, promptText string, files []config.TaskFile, result *Result) (parts []*genai.Part, err error) {
 	for _, file := range files {
 		fileType, err := file.TypeValue(ctx)
 		if err != nil {
 			return parts, err
-		} else if !isSupportedImageType(fileType) {
-			return parts, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
 		}
 
-		content, err := file.Content(ctx)
-		if err != nil {
-			return parts, err
+		var filePart *genai.Part
+		switch classifyFile(fileType) {
+		case fileKindImage, fileKindPDF, fileKindAudio:
+			if o.uploads != nil && file.GetResolvedFileOptions().UseUpload() {
+				uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, o.uploadFile)
+				if err != nil {
+					return parts, err
+				}
+				filePart = genai.NewPartFromURI(uploaded.URI, fileType)
+				break
+			}
+			content, err := file.Content(ctx)
+			if err != nil {
+				return parts, err
+			}
+			filePart = genai.NewPartFromBytes(content, fileType)
+		case fileKindText:
+			// Text documents are inlined, as inline data only accepts binary media types.
+			text, err := taskFileText(ctx, file)
+			if err != nil {
+				return parts, err
+			}
+			filePart = genai.NewPartFromText(text)
+		default:
+			return parts, unsupportedFileError(file, fileType)
 		}
 
-		// Attach file name as a text part before the blob, for reference.
+		// Attach file name as a text part before the file content, for reference.
 		parts = append(parts, genai.NewPartFromText(result.recordPrompt(DefaultTaskFileNameInstruction(file))))
-		parts = append(parts, genai.NewPartFromBytes(content, fileType))
+		parts = append(parts, filePart)
 	}
 
 	parts = append(parts, genai.NewPartFromText(result.recordPrompt(promptText))) // append the prompt text after the file data for improved context integrity
@@ -402,5 +451,39 @@ func (o *GoogleAI) createPromptMessageParts(ctx context.Context, promptText stri
 }
 
 func (o *GoogleAI) Close(ctx context.Context) error {
-	return nil
+	return o.uploads.deleteAll(ctx, func(ctx context.Context, file uploadedFile) error {
+		_, err := o.client.Files.Delete(ctx, file.ID, nil)
+		return err
+	})
+}
+
+// uploadFile uploads a task file to the Files API and waits until it is ready to be used in prompts.
+func (o *GoogleAI) uploadFile(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error) {
+	uploaded, err := o.client.Files.Upload(ctx, bytes.NewReader(content), &genai.UploadFileConfig{
+		MIMEType:    mediaType(mimeType),
+		DisplayName: uploadFileName(file, mimeType),
+	})
+	if err != nil {
+		return uploadedFile{}, err
+	}
+
+	name := uploaded.Name
+	for err == nil && uploaded.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(fileProcessingPollInterval):
+			uploaded, err = o.client.Files.Get(ctx, name, nil)
+		}
+	}
+	if err == nil && uploaded.State == genai.FileStateFailed {
+		err = fmt.Errorf("file processing failed: %s", name)
+	}
+	if err != nil {
+		// The failed upload is not cached, so remove it now to avoid leaving it behind.
+		_, _ = o.client.Files.Delete(context.WithoutCancel(ctx), name, nil)
+		return uploadedFile{}, err
+	}
+
+	return uploadedFile{ID: name, URI: uploaded.URI}, nil
 }
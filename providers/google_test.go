@@ -23,12 +23,33 @@ func TestGoogle_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "gemini-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
 
+func TestGoogle_CreatePromptMessageParts_Documents(t *testing.T) {
+	ctx := context.Background()
+	pdfFile := mockTaskFile(t, "report", testutils.CreateMockFile(t, "report-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	audioFile := mockTaskFile(t, "clip", testutils.CreateMockFile(t, "clip-*.mp3", []byte("ID3")), "audio/mpeg")
+	textFile := mockTaskFile(t, "data", testutils.CreateMockFile(t, "data-*.csv", []byte("a,b\n1,2")), "text/csv")
+
+	parts, err := (&GoogleAI{}).createPromptMessageParts(ctx, "Summarize.", []config.TaskFile{pdfFile, audioFile, textFile}, &Result{})
+	require.NoError(t, err)
+	require.Len(t, parts, 7)
+
+	require.NotNil(t, parts[1].InlineData)
+	require.Equal(t, "application/pdf", parts[1].InlineData.MIMEType)
+	require.Equal(t, []byte("%PDF-1.7"), parts[1].InlineData.Data)
+
+	require.NotNil(t, parts[3].InlineData)
+	require.Equal(t, "audio/mpeg", parts[3].InlineData.MIMEType)
+
+	require.Nil(t, parts[5].InlineData)
+	require.Equal(t, "a,b\n1,2", parts[5].Text)
+}
+
 func TestRemoveFunctionCallFromRequestConfig(t *testing.T) {
 	tests := []struct {
 		name string
@@ -7,11 +7,14 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -25,11 +28,15 @@ import (
 func NewMistralAI(cfg config.MistralAIClientConfig, availableTools []config.ToolConfig) (*MistralAI, error) {
 	clientCfg := mistralai.NewConfiguration()
 	clientCfg.AddDefaultHeader("Authorization", "Bearer "+cfg.APIKey)
+	batches := newBatchTransport("/chat/completions")
+	clientCfg.HTTPClient = batches.client()
 
 	client := mistralai.NewAPIClient(clientCfg)
 	return &MistralAI{
 		client:         client,
 		availableTools: availableTools,
+		uploads:        newFileUploads(),
+		batches:        batches,
 	}, nil
 }
 
@@ -37,6 +44,8 @@ func NewMistralAI(cfg config.MistralAIClientConfig, availableTools []config.Tool
 type MistralAI struct {
 	client         *mistralai.APIClient
 	availableTools []config.ToolConfig
+	uploads        *fileUploads
+	batches        *batchTransport
 }
 
 func (o MistralAI) Name() string {
@@ -44,12 +53,6 @@ func (o MistralAI) Name() string {
 }
 
 func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConfig, task config.Task) (result Result, err error) {
-	if len(task.Files) > 0 {
-		if !o.isFileUploadSupported(cfg.Model) {
-			return result, ErrFileUploadNotSupported
-		}
-	}
-
 	request := mistralai.NewChatCompletionRequestWithDefaults()
 	request.SetModel(cfg.Model)
 	request.SetN(1)
@@ -95,7 +98,7 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 			})))
 	}
 
-	promptMessage, err := o.createPromptMessage(ctx, task.Prompt, task.Files, &result)
+	promptMessage, err := o.createPromptMessage(ctx, cfg.Model, task.Prompt, task.Files, &result)
 	if errors.Is(err, ErrFeatureNotSupported) {
 		return result, err
 	} else if err != nil {
@@ -104,23 +107,17 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 	request.Messages = append(request.Messages, promptMessage)
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			// Find the tool config from available tools.
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			function := mistralai.NewFunction(toolCfg.Name, toolCfg.Parameters)
 			function.SetDescription(toolCfg.Description)
 			function.SetStrict(false)
@@ -139,7 +136,7 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 			return result, err
 		}
 
-		resp, err := timed(func() (*mistralai.ChatCompletionResponse, error) {
+		resp, err := timedRequest(&result, func(func()) (*mistralai.ChatCompletionResponse, error) {
 			response, httpResponse, err := o.client.ChatAPI.ChatCompletionV1ChatCompletionsPost(ctx).ChatCompletionRequest(*request).Execute()
 			if err != nil {
 				var apiErr *mistralai.GenericOpenAPIError
@@ -151,9 +148,8 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 				}
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -167,6 +163,7 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 				cachedTokens = &value
 			}
 			recordUsage(InputTokenAccountingCacheTokensIncluded, &resp.Usage.PromptTokens, &resp.Usage.CompletionTokens, nil, cachedTokens, &result.usage)
+			recordRequestOutputTokens(&resp.Usage.CompletionTokens, &result)
 		}
 		if len(resp.Choices) == 0 {
 			return result, ErrNoResponseCandidates
@@ -175,6 +172,7 @@ func (o *MistralAI) Run(ctx context.Context, logger logging.Logger, cfg config.R
 			if !candidate.HasMessage() {
 				return result, ErrNoResponseCandidates
 			}
+			result.recordReasoning(o.getMessageThinking(candidate.Message))
 			isTerminal := o.isTerminalStopReason(candidate.FinishReason)
 			logFinishReason(ctx, logger, candidate.FinishReason, isTerminal)
 
@@ -258,6 +256,28 @@ func (o *MistralAI) getMessageTextChunks(message *mistralai.AssistantMessage) (t
 	return
 }
 
+// getMessageThinking returns the text of the thinking chunks of reasoning models.
+func (o *MistralAI) getMessageThinking(message *mistralai.AssistantMessage) string {
+	var thinking []string
+	if content, hasContent := message.GetContentOk(); hasContent && content != nil && content.ArrayOfContentChunk != nil {
+		for _, chunk := range *content.ArrayOfContentChunk {
+			if chunk.ThinkChunk == nil {
+				continue
+			}
+			var text strings.Builder
+			for _, inner := range chunk.ThinkChunk.Thinking {
+				if inner.TextChunk != nil {
+					text.WriteString(inner.TextChunk.Text)
+				}
+			}
+			if text.Len() > 0 {
+				thinking = append(thinking, text.String())
+			}
+		}
+	}
+	return strings.Join(thinking, "\n\n")
+}
+
 func (o *MistralAI) isFileUploadSupported(model string) bool {
 	// Mistral AI models with vision capabilities.
 	// See: https://docs.mistral.ai/capabilities/vision/
@@ -275,6 +295,12 @@ func (o *MistralAI) isFileUploadSupported(model string) bool {
 	})
 }
 
+func (o *MistralAI) isAudioInputSupported(model string) bool {
+	// Mistral AI models with audio understanding capabilities.
+	// See: https://docs.mistral.ai/capabilities/audio/
+	return strings.HasPrefix(model, "voxtral-")
+}
+
 func (o *MistralAI) isTransientResponse(response *http.Response) bool {
 	return response != nil && slices.Contains([]int{
 		http.StatusTooManyRequests,
@@ -333,31 +359,20 @@ func (o *MistralAI) applyModelParameters(request *mistralai.ChatCompletionReques
 	return nil
 }
 
-func (o *MistralAI) createPromptMessage(ctx context.Context, promptText string, files []config.TaskFile, result *Result) (message mistralai.MessagesInner, err error) {
+func (o *MistralAI) createPromptMessage(ctx context.Context, model string, promptText string, files []config.TaskFile, result *Result) (message mistralai.MessagesInner, err error) {
 	var content mistralai.Content
 	if len(files) > 0 {
 		parts := make([]mistralai.ContentChunk, 0, (len(files)*2)+1)
 		for _, file := range files {
-			if fileType, err := file.TypeValue(ctx); err != nil {
-				return message, err
-			} else if !isSupportedImageType(fileType) {
-				return message, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
-			}
-
-			dataURL, err := file.GetDataURL(ctx)
+			filePart, err := o.createFileContentChunk(ctx, model, file)
 			if err != nil {
 				return message, err
 			}
 
-			// Attach file name as a separate text block before the image.
+			// Attach file name as a separate text block before the file content.
 			parts = append(parts, mistralai.TextChunkAsContentChunk(
 				mistralai.NewTextChunk(result.recordPrompt(DefaultTaskFileNameInstruction(file)))))
-
-			// Create image URL struct and chunk.
-			imageURLChunk := mistralai.NewImageURLChunk(mistralai.ImageUrl{
-				ImageURLStruct: mistralai.NewImageURLStruct(dataURL),
-			})
-			parts = append(parts, mistralai.ImageURLChunkAsContentChunk(imageURLChunk))
+			parts = append(parts, filePart)
 		}
 
 		// Append the prompt text after the file data for improved context integrity.
@@ -373,6 +388,67 @@ func (o *MistralAI) createPromptMessage(ctx context.Context, promptText string,
 		mistralai.NewUserMessage(*mistralai.NewNullableContent(&content))), nil
 }
 
+// createFileContentChunk converts a task file to a content chunk supported by the given model.
+// Images require a vision model and audio requires an audio model; text documents are inlined as text.
+// PDF documents are only supported if uploading is enabled for the file.
+func (o *MistralAI) createFileContentChunk(ctx context.Context, model string, file config.TaskFile) (chunk mistralai.ContentChunk, err error) {
+	fileType, err := file.TypeValue(ctx)
+	if err != nil {
+		return chunk, err
+	}
+
+	useUpload := o.uploads != nil && file.GetResolvedFileOptions().UseUpload()
+
+	switch classifyFile(fileType) {
+	case fileKindImage:
+		if !o.isFileUploadSupported(model) {
+			return chunk, ErrFileUploadNotSupported
+		}
+		var imageURL string
+		if useUpload {
+			uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, o.uploadFile)
+			if err != nil {
+				return chunk, err
+			}
+			imageURL = uploaded.URI
+		} else if imageURL, err = file.GetDataURL(ctx); err != nil {
+			return chunk, err
+		}
+		return mistralai.ImageURLChunkAsContentChunk(mistralai.NewImageURLChunk(mistralai.ImageUrl{
+			ImageURLStruct: mistralai.NewImageURLStruct(imageURL),
+		})), nil
+	case fileKindPDF:
+		// Documents can only be referenced by URL, which requires uploading them first.
+		if !useUpload {
+			return chunk, unsupportedFileError(file, fileType)
+		}
+		uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, o.uploadFile)
+		if err != nil {
+			return chunk, err
+		}
+		document := mistralai.NewDocumentURLChunk(uploaded.URI)
+		document.SetDocumentName(file.Name)
+		return mistralai.DocumentURLChunkAsContentChunk(document), nil
+	case fileKindAudio:
+		if !o.isAudioInputSupported(model) {
+			return chunk, unsupportedFileError(file, fileType)
+		}
+		base64Data, err := file.Base64(ctx)
+		if err != nil {
+			return chunk, err
+		}
+		return mistralai.AudioChunkAsContentChunk(mistralai.NewAudioChunk(mistralai.InputAudio{String: &base64Data})), nil
+	case fileKindText:
+		text, err := taskFileText(ctx, file)
+		if err != nil {
+			return chunk, err
+		}
+		return mistralai.TextChunkAsContentChunk(mistralai.NewTextChunk(text)), nil
+	default:
+		return chunk, unsupportedFileError(file, fileType)
+	}
+}
+
 func marshalToolArguments(args mistralai.Arguments) (argsData json.RawMessage, err error) {
 	if args.MapmapOfStringAny != nil {
 		argsData, err = json.Marshal(*args.MapmapOfStringAny)
@@ -382,6 +458,142 @@ func marshalToolArguments(args mistralai.Arguments) (argsData json.RawMessage, e
 	return
 }
 
+// CanBatch reports whether the task can be executed through the Batch API.
+// Tasks that use tools need a conversation loop and are executed synchronously.
+func (o *MistralAI) CanBatch(_ config.RunConfig, task config.Task) bool {
+	return !taskUsesTools(task)
+}
+
+// NewBatch creates a batch executed through the Batch API.
+func (o *MistralAI) NewBatch(ctx context.Context, logger logging.Logger, cfg config.RunConfig, size int) *Batch {
+	return newBatch(ctx, logger, o.batches, size, func(ctx context.Context, logger logging.Logger, requests []*batchRequest) (map[string]batchResponse, error) {
+		return o.submitBatch(ctx, logger, cfg.Model, requests)
+	})
+}
+
+// submitBatch executes the requests as a batch job with inline requests and returns their outputs.
+func (o *MistralAI) submitBatch(ctx context.Context, logger logging.Logger, model string, requests []*batchRequest) (map[string]batchResponse, error) {
+	batchRequests := make([]mistralai.BatchRequest, len(requests))
+	for i, request := range requests {
+		var body map[string]interface{}
+		if err := json.Unmarshal(request.Body, &body); err != nil {
+			return nil, err
+		}
+		batchRequests[i] = *mistralai.NewBatchRequest(body)
+		batchRequests[i].SetCustomId(request.CustomID)
+	}
+	jobRequest := mistralai.NewCreateBatchJobRequest(mistralai.APIENDPOINT_V1_CHAT_COMPLETIONS)
+	jobRequest.SetModel(model)
+	jobRequest.Requests = batchRequests
+
+	job, _, err := o.client.BatchAPI.JobsApiRoutesBatchCreateBatchJob(ctx).CreateBatchJobRequest(*jobRequest).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch job: %w", err)
+	}
+	logger.Message(ctx, logging.LevelInfo, "batch job '%s' created, waiting for completion...", job.Id)
+
+	jobID := job.Id
+	if err := pollBatch(ctx, logger, func(ctx context.Context) (bool, error) {
+		job, _, err = o.client.BatchAPI.JobsApiRoutesBatchGetBatchJob(ctx, jobID).Inline(true).Execute()
+		if err != nil {
+			return false, err
+		}
+		switch job.Status {
+		case mistralai.BATCHJOBSTATUS_QUEUED, mistralai.BATCHJOBSTATUS_RUNNING, mistralai.BATCHJOBSTATUS_CANCELLATION_REQUESTED:
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		if _, _, cancelErr := o.client.BatchAPI.JobsApiRoutesBatchCancelBatchJob(context.WithoutCancel(ctx), jobID).Execute(); cancelErr != nil {
+			logger.Error(ctx, logging.LevelWarn, cancelErr, "failed to cancel batch job")
+		}
+		return nil, err
+	}
+
+	if job.Status == mistralai.BATCHJOBSTATUS_FAILED {
+		messages := make([]string, 0, len(job.Errors))
+		for _, jobErr := range job.Errors {
+			messages = append(messages, jobErr.Message)
+		}
+		return nil, fmt.Errorf("batch job '%s' failed: %s", jobID, strings.Join(messages, "; "))
+	}
+
+	responses := make(map[string]batchResponse, len(requests))
+	for _, output := range job.Outputs {
+		line, err := json.Marshal(output)
+		if err != nil {
+			return nil, err
+		}
+		if err := readBatchOutput(bytes.NewReader(line), responses); err != nil {
+			return nil, err
+		}
+	}
+	if len(job.Outputs) == 0 {
+		for _, fileID := range []mistralai.NullableString{job.OutputFile, job.ErrorFile} {
+			if fileID.Get() == nil || *fileID.Get() == "" {
+				continue
+			}
+			if err := o.readBatchFile(ctx, logger, *fileID.Get(), responses); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return responses, nil
+}
+
+// readBatchFile reads the batch outputs from the given file and deletes it.
+func (o *MistralAI) readBatchFile(ctx context.Context, logger logging.Logger, fileID string, responses map[string]batchResponse) error {
+	file, _, err := o.client.FilesAPI.FilesApiRoutesDownloadFile(ctx, fileID).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to download batch output: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, _, err := o.client.FilesAPI.FilesApiRoutesDeleteFile(context.WithoutCancel(ctx), fileID).Execute(); err != nil {
+		logger.Error(ctx, logging.LevelWarn, err, "failed to delete batch file '%s'", fileID)
+	}
+	return readBatchOutput(file, responses)
+}
+
 func (o *MistralAI) Close(ctx context.Context) error {
-	return nil
+	return o.uploads.deleteAll(ctx, func(ctx context.Context, file uploadedFile) error {
+		_, _, err := o.client.FilesAPI.FilesApiRoutesDeleteFile(ctx, file.ID).Execute()
+		return err
+	})
+}
+
+// uploadFile uploads a task file to the Files API and returns it with a signed URL
+// that can be referenced in prompts. The signed URL is valid for the default 24 hours.
+func (o *MistralAI) uploadFile(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error) {
+	// The generated client only uploads from an os.File, whose name is sent as the file name.
+	dir, err := os.MkdirTemp("", "mindtrial-upload-*")
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(uploadFileName(file, mimeType)))
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return uploadedFile{}, err
+	}
+	localFile, err := os.Open(path)
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	defer localFile.Close()
+
+	uploaded, _, err := o.client.FilesAPI.FilesApiRoutesUploadFile(ctx).File(localFile).Purpose(mistralai.FILEPURPOSE_OCR).Execute()
+	if err != nil {
+		return uploadedFile{}, err
+	}
+
+	signedURL, _, err := o.client.FilesAPI.FilesApiRoutesGetSignedUrl(ctx, uploaded.Id).Execute()
+	if err != nil {
+		// The failed upload is not cached, so remove it now to avoid leaving it behind.
+		_, _, _ = o.client.FilesAPI.FilesApiRoutesDeleteFile(context.WithoutCancel(ctx), uploaded.Id).Execute()
+		return uploadedFile{}, err
+	}
+
+	return uploadedFile{ID: uploaded.Id, URI: signedURL.Url}, nil
 }
@@ -70,8 +70,32 @@ func TestMistral_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "mistral-large-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
+
+func TestMistral_CreateFileContentChunk(t *testing.T) {
+	ctx := context.Background()
+	audioFile := mockTaskFile(t, "clip", testutils.CreateMockFile(t, "clip-*.mp3", []byte("ID3")), "audio/mpeg")
+	textFile := mockTaskFile(t, "notes", testutils.CreateMockFile(t, "notes-*.txt", []byte("plain notes")), "text/plain")
+	pdfFile := mockTaskFile(t, "report", "file://report.pdf", "application/pdf")
+	p := &MistralAI{}
+
+	chunk, err := p.createFileContentChunk(ctx, "mistral-embed", textFile)
+	require.NoError(t, err)
+	require.NotNil(t, chunk.TextChunk)
+	require.Equal(t, "plain notes", chunk.TextChunk.Text)
+
+	chunk, err = p.createFileContentChunk(ctx, "voxtral-small-latest", audioFile)
+	require.NoError(t, err)
+	require.NotNil(t, chunk.AudioChunk)
+	require.Equal(t, "SUQz", *chunk.AudioChunk.InputAudio.String)
+
+	_, err = p.createFileContentChunk(ctx, "mistral-large-latest", audioFile)
+	require.ErrorIs(t, err, ErrFileNotSupported)
+
+	_, err = p.createFileContentChunk(ctx, "mistral-large-latest", pdfFile)
+	require.ErrorIs(t, err, ErrFileNotSupported)
+}
@@ -11,10 +11,12 @@ package providers
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/petmal/mindtrial/config"
@@ -35,9 +37,20 @@ type MockProvider struct {
 	name    string
 	tools   []config.ToolConfig
 	retries sync.Map
+
+	inFlight     atomic.Int64
+	peakInFlight atomic.Int64
+}
+
+// parallelGateTimeout bounds how long a "parallel_" task waits for another request to run concurrently.
+const parallelGateTimeout = 5 * time.Second
+
+// PeakConcurrency returns the highest number of requests that the provider handled at the same time.
+func (m *MockProvider) PeakConcurrency() int64 {
+	return m.peakInFlight.Load()
 }
 
-func (m MockProvider) Name() string {
+func (m *MockProvider) Name() string {
 	return m.name
 }
 
@@ -45,18 +58,39 @@ func (m MockProvider) Name() string {
 //
 // The method supports several modes based on cfg.Name:
 //   - "pass": Always returns success with the first expected answer.
-//   - "mock": Handles special task names (error, not_supported, failure and and retry_N patterns).
+//   - "mock": Handles special task names (error, not_supported, failure, hang, malformed, unparsable,
+//     low_quota, parallel_ and retry_N patterns). A task named "hang" blocks until the context is done,
+//     a task named "low_quota" reports that little of the rate limit quota remains, and a task whose
+//     name starts with "parallel_" waits until at least two requests have run concurrently.
 //   - "judge_evaluation": Parses judge prompts and evaluates responses.
 //   - Other: Returns the task name as the final answer.
 func (m *MockProvider) Run(ctx context.Context, logger logging.Logger, cfg config.RunConfig, task config.Task) (result Result, err error) {
 	logger.Message(ctx, logging.LevelDebug, "executing mock run for task '%s' with config '%s'", task.Name, cfg.Name)
 	result = m.createBaseResult(task.Name)
 
+	current := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for peak := m.peakInFlight.Load(); current > peak && !m.peakInFlight.CompareAndSwap(peak, current); peak = m.peakInFlight.Load() {
+	}
+
 	switch cfg.Name {
 	case "pass":
 		expectedValidAnswers := task.ExpectedResult.Values()
 		return m.handlePassMode(result, expectedValidAnswers[0]), nil
 	case "mock":
+		if task.Name == "hang" {
+			<-ctx.Done()
+			return result, WrapErrGenerateResponse(ctx.Err())
+		}
+		if task.Name == "malformed" || task.Name == "unparsable" {
+			return m.handleMalformedResponse(result, task)
+		}
+		if strings.HasPrefix(task.Name, "parallel_") {
+			m.awaitParallelRequest(ctx)
+		}
+		if task.Name == "low_quota" {
+			ReportRateLimit(ctx, RateLimitInfo{StatusCode: http.StatusOK, RemainingRequests: testutils.Ptr(int64(1)), LimitRequests: testutils.Ptr(int64(100))})
+		}
 		return m.handleMockMode(result, cfg, task)
 	case "judge_evaluation":
 		return m.handleJudgeEvaluation(result, cfg, task)
@@ -66,6 +100,23 @@ func (m *MockProvider) Run(ctx context.Context, logger logging.Logger, cfg confi
 	}
 }
 
+// awaitParallelRequest blocks until at least two requests have been in flight at the same time,
+// the context is done, or parallelGateTimeout elapses.
+func (m *MockProvider) awaitParallelRequest(ctx context.Context) {
+	deadline := time.After(parallelGateTimeout)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for m.peakInFlight.Load() < 2 {
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // createBaseResult creates a base Result with mock data.
 func (m *MockProvider) createBaseResult(taskName string) Result {
 	result := Result{
@@ -75,6 +126,9 @@ func (m *MockProvider) createBaseResult(taskName string) Result {
 			"Et magnam velit unde.",
 			"Dolore odio esse et esse.",
 		},
+		reasoning: []string{
+			"Quia voluptatem dolores sint.",
+		},
 		usage: Usage{
 			InputTokens:  testutils.Ptr(int64(8200209999917998)),
 			OutputTokens: nil,
@@ -121,6 +175,24 @@ func (m *MockProvider) handleMockMode(result Result, cfg config.RunConfig, task
 	return result, nil
 }
 
+// handleMalformedResponse returns a response that cannot be parsed. A "malformed" task responds
+// with JSON that needs to be repaired, while an "unparsable" task responds with plain text
+// until it is asked to correct its response.
+func (m *MockProvider) handleMalformedResponse(result Result, task config.Task) (Result, error) {
+	answer := fmt.Sprint(task.ExpectedResult.Values()[0])
+	if task.Name == "unparsable" && strings.Contains(task.Prompt, "could not be parsed") {
+		result.Explanation = "mock corrected"
+		result.FinalAnswer = Answer{Content: answer}
+		return result, nil
+	}
+
+	rawMessage := fmt.Sprintf("The answer is: %s", answer)
+	if task.Name == "malformed" {
+		rawMessage = fmt.Sprintf("```json\n{\"title\": %q, \"explanation\": \"mock malformed\", \"final_answer\": %q,}\n```", task.Name, answer)
+	}
+	return result, NewErrUnmarshalResponse(fmt.Errorf("invalid character"), []byte(rawMessage), []byte("stop"))
+}
+
 func (m *MockProvider) handleJudgeEvaluation(result Result, cfg config.RunConfig, task config.Task) (Result, error) {
 	expectedAnswers := m.extractExpectedAnswers(task.Prompt)
 	actualResponse := m.extractActualResponse(task.Prompt)
@@ -255,6 +327,16 @@ func (m *MockProvider) addRetryAttempt(key string) int {
 	}
 }
 
+// CanBatch reports whether the task can be executed in a batch. Tasks that use tools are executed synchronously.
+func (m *MockProvider) CanBatch(cfg config.RunConfig, task config.Task) bool {
+	return !taskUsesTools(task)
+}
+
+// NewBatch creates a batch that is never submitted, since mock runs make no requests.
+func (m *MockProvider) NewBatch(ctx context.Context, logger logging.Logger, cfg config.RunConfig, size int) *Batch {
+	return newBatch(ctx, logger, newBatchTransport(), size, nil)
+}
+
 func (m *MockProvider) Close(ctx context.Context) error {
 	return nil
 }
@@ -32,7 +32,7 @@ func TestMoonshotAI_Run_IncompatibleResponseFormat(t *testing.T) {
 	task := config.Task{
 		Name: "t",
 		Files: []config.TaskFile{
-			mockTaskFile(t, "test.txt", "file://test.txt", "text/plain"), // Unsupported file type to cause early error
+			mockTaskFile(t, "test.zip", "file://test.zip", "application/zip"), // Unsupported file type to cause early error
 		},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
@@ -47,7 +47,7 @@ func TestMoonshotAI_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "kimi-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
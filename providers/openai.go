@@ -21,10 +21,20 @@ import (
 
 // NewOpenAI creates a new OpenAI provider instance with the given configuration.
 func NewOpenAI(cfg config.OpenAIClientConfig, availableTools []config.ToolConfig) *OpenAI {
-	opts := []option.RequestOption{option.WithAPIKey(cfg.APIKey)}
+	batches := newBatchTransport("/responses", "/chat/completions")
+	opts := []option.RequestOption{option.WithAPIKey(cfg.APIKey), option.WithHTTPClient(batches.client())}
+	uploads := newFileUploads()
+	completionProvider := newOpenAICompletionsProvider(availableTools, opts...)
+	completionProvider.SupportsPDF = true
+	completionProvider.SupportsAudio = true
+	completionProvider.uploads = uploads
+	responsesProvider := newOpenAIResponsesProvider(availableTools, opts...)
+	responsesProvider.uploads = uploads
 	return &OpenAI{
-		completionProvider: newOpenAICompletionsProvider(availableTools, opts...),
-		responsesProvider:  newOpenAIResponsesProvider(availableTools, opts...),
+		completionProvider: completionProvider,
+		responsesProvider:  responsesProvider,
+		uploads:            uploads,
+		batches:            batches,
 	}
 }
 
@@ -32,12 +42,29 @@ func NewOpenAI(cfg config.OpenAIClientConfig, availableTools []config.ToolConfig
 type OpenAI struct {
 	completionProvider *openAICompletionsProvider
 	responsesProvider  *openAIResponsesProvider
+
+	// uploads caches task files uploaded by either API, shared across tasks and runs.
+	uploads *fileUploads
+
+	// batches holds back requests of tasks executed in a batch.
+	batches *batchTransport
 }
 
 func (o OpenAI) Name() string {
 	return config.OPENAI
 }
 
+// CanBatch reports whether the task can be executed through the OpenAI Batch API.
+// Tasks that use tools need a conversation loop and are executed synchronously.
+func (o *OpenAI) CanBatch(_ config.RunConfig, task config.Task) bool {
+	return !taskUsesTools(task)
+}
+
+// NewBatch creates a batch executed through the OpenAI Batch API.
+func (o *OpenAI) NewBatch(ctx context.Context, logger logging.Logger, _ config.RunConfig, size int) *Batch {
+	return newBatch(ctx, logger, o.batches, size, submitOpenAIBatch(&o.responsesProvider.client))
+}
+
 func (o *OpenAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConfig, task config.Task) (result Result, err error) {
 	openAIV3Params := openAIV3ModelParams{}
 
@@ -71,6 +98,7 @@ func (o *OpenAI) Close(ctx context.Context) error {
 	return errors.Join(
 		o.completionProvider.Close(ctx),
 		o.responsesProvider.Close(ctx),
+		o.uploads.deleteAll(ctx, deleteOpenAIFile(&o.responsesProvider.client)),
 	)
 }
 
@@ -83,6 +111,7 @@ func (o *OpenAI) copyToOpenAIV3Params(openAIModelParams config.OpenAIModelParams
 	openAIV3Params.ReasoningEffort = openAIModelParams.ReasoningEffort
 	openAIV3Params.ReasoningContext = openAIModelParams.ReasoningContext
 	openAIV3Params.ReasoningMode = openAIModelParams.ReasoningMode
+	openAIV3Params.ReasoningSummary = openAIModelParams.ReasoningSummary
 	openAIV3Params.Verbosity = openAIModelParams.Verbosity
 	if openAIModelParams.Temperature != nil {
 		openAIV3Params.Temperature = utils.Ptr(float64(*openAIModelParams.Temperature))
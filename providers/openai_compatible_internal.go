@@ -7,7 +7,16 @@
 package providers
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/packages/respjson"
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
 )
 
 // extractExtraFieldRaw returns the raw JSON string for a non-standard field if it is
@@ -19,3 +28,132 @@ func extractExtraFieldRaw(extraFields map[string]respjson.Field, key string) (st
 	}
 	return "", false
 }
+
+// uploadOpenAIFile returns a function that uploads task files to the OpenAI Files API
+// for use as model inputs.
+func uploadOpenAIFile(client *openai.Client) uploadFileFunc {
+	return func(ctx context.Context, file config.TaskFile, content []byte, mimeType string) (uploadedFile, error) {
+		uploaded, err := client.Files.New(ctx, openai.FileNewParams{
+			File:    openai.File(bytes.NewReader(content), uploadFileName(file, mimeType), mimeType),
+			Purpose: openai.FilePurposeUserData,
+		})
+		if err != nil {
+			return uploadedFile{}, err
+		}
+		return uploadedFile{ID: uploaded.ID}, nil
+	}
+}
+
+// deleteOpenAIFile returns a function that deletes files uploaded to the OpenAI Files API.
+func deleteOpenAIFile(client *openai.Client) deleteFileFunc {
+	return func(ctx context.Context, file uploadedFile) error {
+		_, err := client.Files.Delete(ctx, file.ID)
+		return err
+	}
+}
+
+// submitOpenAIBatch returns a function that executes requests through the OpenAI Batch API.
+// The requests are uploaded as a JSONL input file, and the output and error files of the
+// finished batch are read back and deleted together with the input file.
+func submitOpenAIBatch(client *openai.Client) batchSubmitFunc {
+	return func(ctx context.Context, logger logging.Logger, requests []*batchRequest) (map[string]batchResponse, error) {
+		var input bytes.Buffer
+		var endpoint string
+		for _, request := range requests {
+			url := openAIBatchURL(request.Path)
+			if endpoint == "" {
+				endpoint = url
+			} else if endpoint != url {
+				return nil, fmt.Errorf("requests for different endpoints cannot be batched together: %s, %s", endpoint, url)
+			}
+			line, err := json.Marshal(map[string]any{
+				"custom_id": request.CustomID,
+				"method":    "POST",
+				"url":       url,
+				"body":      request.Body,
+			})
+			if err != nil {
+				return nil, err
+			}
+			input.Write(line)
+			input.WriteByte('\n')
+		}
+
+		inputFile, err := client.Files.New(ctx, openai.FileNewParams{
+			File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+			Purpose: openai.FilePurposeBatch,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload batch input: %w", err)
+		}
+		defer deleteOpenAIBatchFile(ctx, logger, client, inputFile.ID)
+
+		batch, err := client.Batches.New(ctx, openai.BatchNewParams{
+			InputFileID:      inputFile.ID,
+			Endpoint:         openai.BatchNewParamsEndpoint(endpoint),
+			CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create batch: %w", err)
+		}
+		logger.Message(ctx, logging.LevelInfo, "batch '%s' created, waiting for completion...", batch.ID)
+
+		batchID := batch.ID
+		if err := pollBatch(ctx, logger, func(ctx context.Context) (bool, error) {
+			batch, err = client.Batches.Get(ctx, batchID)
+			if err != nil {
+				return false, err
+			}
+			switch batch.Status {
+			case openai.BatchStatusCompleted, openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+				return true, nil
+			}
+			return false, nil
+		}); err != nil {
+			if _, cancelErr := client.Batches.Cancel(context.WithoutCancel(ctx), batchID); cancelErr != nil {
+				logger.Error(ctx, logging.LevelWarn, cancelErr, "failed to cancel batch")
+			}
+			return nil, err
+		}
+
+		if batch.Status == openai.BatchStatusFailed {
+			messages := make([]string, 0, len(batch.Errors.Data))
+			for _, batchErr := range batch.Errors.Data {
+				messages = append(messages, batchErr.Message)
+			}
+			return nil, fmt.Errorf("batch '%s' failed: %s", batchID, strings.Join(messages, "; "))
+		}
+
+		responses := make(map[string]batchResponse, len(requests))
+		for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+			if fileID == "" {
+				continue
+			}
+			content, err := client.Files.Content(ctx, fileID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to download batch output: %w", err)
+			}
+			err = readBatchOutput(content.Body, responses)
+			_ = content.Body.Close()
+			deleteOpenAIBatchFile(ctx, logger, client, fileID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return responses, nil
+	}
+}
+
+// openAIBatchURL returns the relative API URL of a request for use in a batch input file.
+func openAIBatchURL(path string) string {
+	if idx := strings.Index(path, "/v1/"); idx >= 0 {
+		return path[idx:]
+	}
+	return path
+}
+
+func deleteOpenAIBatchFile(ctx context.Context, logger logging.Logger, client *openai.Client, fileID string) {
+	if _, err := client.Files.Delete(context.WithoutCancel(ctx), fileID); err != nil {
+		logger.Error(ctx, logging.LevelWarn, err, "failed to delete batch file '%s'", fileID)
+	}
+}
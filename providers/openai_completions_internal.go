@@ -11,12 +11,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/packages/respjson"
 	"github.com/openai/openai-go/v3/shared"
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/logging"
@@ -58,6 +61,11 @@ type CompletionHandler interface {
 	// during streaming or extracted from non-streaming message metadata.
 	ToParam(ctx context.Context, logger logging.Logger, message openai.ChatCompletionMessage) openai.ChatCompletionMessageParamUnion
 
+	// Reasoning returns the reasoning text that accompanied a response message, or an
+	// empty string if there was none. Reasoning is not part of the OpenAI-standard
+	// message, so implementations read it from non-standard fields.
+	Reasoning(ctx context.Context, logger logging.Logger, message openai.ChatCompletionMessage) string
+
 	// InputCacheTokens extracts the cache write/read token counts from a Chat
 	// Completions usage response. Implementations may override this to read
 	// provider-specific usage shapes that differ from the OpenAI-standard one.
@@ -66,11 +74,23 @@ type CompletionHandler interface {
 
 // defaultCompletionHandler is the standard CompletionHandler that delegates to
 // the SDK's ChatCompletionAccumulator and uses the default ToParam() conversion.
+// It additionally accumulates the widely used non-standard reasoning_content and
+// reasoning fields, which the SDK's accumulator drops, for Reasoning.
 type defaultCompletionHandler struct {
-	acc openai.ChatCompletionAccumulator
+	acc       openai.ChatCompletionAccumulator
+	reasoning strings.Builder
 }
 
-func (h *defaultCompletionHandler) AddChunk(_ context.Context, _ logging.Logger, chunk openai.ChatCompletionChunk) bool {
+// reasoningKeys lists the non-standard message fields that OpenAI-compatible APIs
+// use to convey the reasoning text, in order of preference.
+var reasoningKeys = []string{reasoningContentKey, "reasoning"}
+
+func (h *defaultCompletionHandler) AddChunk(ctx context.Context, logger logging.Logger, chunk openai.ChatCompletionChunk) bool {
+	for _, choice := range chunk.Choices {
+		if delta, ok := reasoningField(ctx, logger, choice.Delta.JSON.ExtraFields); ok {
+			h.reasoning.WriteString(delta)
+		}
+	}
 	return h.acc.AddChunk(chunk)
 }
 
@@ -86,6 +106,31 @@ func (h *defaultCompletionHandler) ToParam(_ context.Context, _ logging.Logger,
 	return message.ToParam()
 }
 
+// Reasoning prefers the reasoning accumulated from streaming chunks and falls back to
+// the fields of a non-streaming response message.
+func (h *defaultCompletionHandler) Reasoning(ctx context.Context, logger logging.Logger, message openai.ChatCompletionMessage) string {
+	if h.reasoning.Len() > 0 {
+		return h.reasoning.String()
+	}
+	reasoning, _ := reasoningField(ctx, logger, message.JSON.ExtraFields)
+	return reasoning
+}
+
+// reasoningField reads the first string reasoning field present in the extra fields.
+func reasoningField(ctx context.Context, logger logging.Logger, extraFields map[string]respjson.Field) (string, bool) {
+	for _, key := range reasoningKeys {
+		if raw, ok := extractExtraFieldRaw(extraFields, key); ok {
+			var value string
+			if err := json.Unmarshal([]byte(raw), &value); err != nil {
+				logger.Error(ctx, slog.LevelWarn, err, "failed to unmarshal %s field", key)
+				continue
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
 // InputCacheTokens reads the cache counters from a Chat Completions usage response.
 // The SDK models them as plain int64, so presence comes from the JSON metadata to
 // tell an unreported counter from a reported zero.
@@ -112,6 +157,16 @@ type openAICompletionsProvider struct {
 	// and type-assert it inside their factory. When nil, the defaultCompletionHandler
 	// is used.
 	NewCompletionHandler func(args any) CompletionHandler
+
+	// SupportsPDF and SupportsAudio are fixed at provider construction and enable
+	// PDF file parts and input audio parts, respectively, for endpoints that accept them.
+	// Other files are rejected as not supported, except for images and text documents.
+	SupportsPDF   bool
+	SupportsAudio bool
+
+	// uploads caches task files uploaded to the Files API. When nil, all files are inlined.
+	// Only PDF documents can be referenced by file ID; other files are always inlined.
+	uploads *fileUploads
 }
 
 // openAIV3ModelParams is an internal model configuration used by OpenAI implementations.
@@ -129,6 +184,10 @@ type openAIV3ModelParams struct {
 	// Only supported by the Responses API.
 	ReasoningMode *string
 
+	// ReasoningSummary requests a summary of the model's reasoning.
+	// Only supported by the Responses API.
+	ReasoningSummary *string
+
 	// PromptCacheKey is a stable identifier used to improve prompt cache
 	// hit-rate consistency by influencing request routing. Supported by both
 	// the Responses API and the Chat Completions API.
@@ -206,6 +265,7 @@ func (r ResponseFormat) Ptr() *ResponseFormat {
 func newOpenAICompletionsProvider(availableTools []config.ToolConfig, opts ...option.RequestOption) *openAICompletionsProvider {
 	clientOpts := append([]option.RequestOption{
 		option.WithMaxRetries(0), // disable SDK retries since MindTrial has its own retry policy
+		option.WithHTTPClient(newHTTPClient()),
 	}, opts...)
 
 	return &openAICompletionsProvider{
@@ -257,6 +317,9 @@ func (o *openAICompletionsProvider) run(ctx context.Context, logger logging.Logg
 			if modelParams.ReasoningMode != nil {
 				logger.Message(ctx, logging.LevelWarn, "reasoning-mode parameter is not supported by the Chat Completions API and will be ignored")
 			}
+			if modelParams.ReasoningSummary != nil {
+				logger.Message(ctx, logging.LevelWarn, "reasoning-summary parameter is not supported by the Chat Completions API and will be ignored")
+			}
 			if modelParams.PromptCacheKey != nil {
 				request.PromptCacheKey = param.NewOpt(*modelParams.PromptCacheKey)
 			}
@@ -341,22 +404,17 @@ func (o *openAICompletionsProvider) run(ctx context.Context, logger logging.Logg
 	request.Messages = append(request.Messages, promptMessage)
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			request.Tools = append(request.Tools, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
 				Name:        toolCfg.Name,
 				Description: param.NewOpt(toolCfg.Description),
@@ -378,15 +436,14 @@ func (o *openAICompletionsProvider) run(ctx context.Context, logger logging.Logg
 		// Create a fresh completion handler for each API call.
 		handler := o.newCompletionHandler(args)
 
-		resp, err := timed(func() (*openai.ChatCompletion, error) {
-			response, err := o.handleRequest(ctx, logger, request, handler)
+		resp, err := timedRequest(&result, func(onFirstToken func()) (*openai.ChatCompletion, error) {
+			response, err := o.handleRequest(ctx, logger, request, handler, onFirstToken)
 			if err != nil && o.isTransientResponse(err) {
 				return response, WrapErrRetryable(err)
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -395,11 +452,16 @@ func (o *openAICompletionsProvider) run(ctx context.Context, logger logging.Logg
 
 		cacheWriteTokens, cacheReadTokens := handler.InputCacheTokens(resp.Usage)
 		recordUsage(InputTokenAccountingCacheTokensIncluded, &resp.Usage.PromptTokens, &resp.Usage.CompletionTokens, cacheWriteTokens, cacheReadTokens, &result.usage)
+		recordRequestOutputTokens(&resp.Usage.CompletionTokens, &result)
+		if resp.Usage.CompletionTokensDetails.JSON.ReasoningTokens.Valid() {
+			recordReasoningTokens(&resp.Usage.CompletionTokensDetails.ReasoningTokens, &result.usage)
+		}
 
 		if len(resp.Choices) == 0 {
 			return result, ErrNoResponseCandidates
 		}
 		for _, candidate := range resp.Choices {
+			result.recordReasoning(handler.Reasoning(ctx, logger, candidate.Message))
 			isTerminal := handler.IsTerminalStopReason(candidate)
 			logFinishReason(ctx, logger, candidate.FinishReason, isTerminal)
 
@@ -451,21 +513,12 @@ func (o *openAICompletionsProvider) createPromptMessage(ctx context.Context, log
 	if len(files) > 0 {
 		parts := make([]openai.ChatCompletionContentPartUnionParam, 0, (len(files)*2)+1)
 		for _, file := range files {
-			if fileType, err := file.TypeValue(ctx); err != nil {
-				return message, err
-			} else if !isSupportedImageType(fileType) {
-				return message, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
-			}
-			dataURL, err := file.GetDataURL(ctx)
+			filePart, err := o.createFileContentPart(ctx, logger, file)
 			if err != nil {
 				return message, err
 			}
 			parts = append(parts, openai.TextContentPart(result.recordPrompt(DefaultTaskFileNameInstruction(file))))
-			detail := o.mapImageDetailToOpenAI(ctx, logger, file.GetResolvedFileOptions().ImageDetail)
-			parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
-				URL:    dataURL,
-				Detail: detail,
-			}))
+			parts = append(parts, filePart)
 		}
 		// Append the prompt text after the file data for improved context integrity.
 		parts = append(parts, openai.TextContentPart(result.recordPrompt(promptText)))
@@ -475,6 +528,76 @@ func (o *openAICompletionsProvider) createPromptMessage(ctx context.Context, log
 	}
 }
 
+// createFileContentPart converts a task file to a chat completion content part.
+// Images are always sent as data URLs and text documents are inlined as text;
+// PDF documents and audio are only sent if enabled for the provider.
+func (o *openAICompletionsProvider) createFileContentPart(ctx context.Context, logger logging.Logger, file config.TaskFile) (part openai.ChatCompletionContentPartUnionParam, err error) {
+	fileType, err := file.TypeValue(ctx)
+	if err != nil {
+		return part, err
+	}
+
+	switch kind := classifyFile(fileType); {
+	case kind == fileKindImage:
+		dataURL, err := file.GetDataURL(ctx)
+		if err != nil {
+			return part, err
+		}
+		return openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL:    dataURL,
+			Detail: o.mapImageDetailToOpenAI(ctx, logger, file.GetResolvedFileOptions().ImageDetail),
+		}), nil
+	case kind == fileKindText:
+		text, err := taskFileText(ctx, file)
+		if err != nil {
+			return part, err
+		}
+		return openai.TextContentPart(text), nil
+	case kind == fileKindPDF && o.SupportsPDF:
+		if o.uploads != nil && file.GetResolvedFileOptions().UseUpload() {
+			uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, uploadOpenAIFile(&o.client))
+			if err != nil {
+				return part, err
+			}
+			return openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+				FileID: param.NewOpt(uploaded.ID),
+			}), nil
+		}
+		dataURL, err := file.GetDataURL(ctx)
+		if err != nil {
+			return part, err
+		}
+		return openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+			FileData: param.NewOpt(dataURL),
+			Filename: param.NewOpt(file.Name),
+		}), nil
+	case kind == fileKindAudio && o.SupportsAudio:
+		format, ok := openAIAudioFormats[mediaType(fileType)]
+		if !ok {
+			return part, unsupportedFileError(file, fileType)
+		}
+		base64Data, err := file.Base64(ctx)
+		if err != nil {
+			return part, err
+		}
+		return openai.InputAudioContentPart(openai.ChatCompletionContentPartInputAudioInputAudioParam{
+			Data:   base64Data,
+			Format: format,
+		}), nil
+	default:
+		return part, unsupportedFileError(file, fileType)
+	}
+}
+
+// openAIAudioFormats maps the audio MIME types accepted by input audio content parts to their format names.
+var openAIAudioFormats = map[string]string{
+	"audio/wav":   "wav",
+	"audio/wave":  "wav",
+	"audio/x-wav": "wav",
+	"audio/mpeg":  "mp3",
+	"audio/mp3":   "mp3",
+}
+
 // mapImageDetailToOpenAI maps a provider-agnostic ImageDetail value to the OpenAI API detail string.
 // OpenAI supports "auto", "low", "high", and "original". The generic "medium" value is mapped
 // to "high" (nearest higher) to avoid artificially reducing image fidelity during evaluations.
@@ -530,21 +653,26 @@ func (o *openAICompletionsProvider) newCompletionHandler(args any) CompletionHan
 }
 
 // handleRequest dispatches the request to the appropriate handler based on streaming mode.
-func (o *openAICompletionsProvider) handleRequest(ctx context.Context, logger logging.Logger, request openai.ChatCompletionNewParams, acc CompletionAccumulator) (*openai.ChatCompletion, error) {
+func (o *openAICompletionsProvider) handleRequest(ctx context.Context, logger logging.Logger, request openai.ChatCompletionNewParams, acc CompletionAccumulator, onFirstToken func()) (*openai.ChatCompletion, error) {
 	if request.StreamOptions.IncludeUsage.Value {
-		return o.handleStreamingRequest(ctx, logger, request, acc)
+		return o.handleStreamingRequest(ctx, logger, request, acc, onFirstToken)
 	}
 	return o.client.Chat.Completions.New(ctx, request)
 }
 
 // handleStreamingRequest executes a streaming chat completion request,
 // delegating chunk accumulation to the provided CompletionAccumulator.
-func (o *openAICompletionsProvider) handleStreamingRequest(ctx context.Context, logger logging.Logger, request openai.ChatCompletionNewParams, acc CompletionAccumulator) (resp *openai.ChatCompletion, err error) {
+// The onFirstToken callback is invoked for every chunk carrying output.
+func (o *openAICompletionsProvider) handleStreamingRequest(ctx context.Context, logger logging.Logger, request openai.ChatCompletionNewParams, acc CompletionAccumulator, onFirstToken func()) (resp *openai.ChatCompletion, err error) {
 	stream := o.client.Chat.Completions.NewStreaming(ctx, request)
 	defer stream.Close()
 
 	for stream.Next() {
-		if !acc.AddChunk(ctx, logger, stream.Current()) {
+		chunk := stream.Current()
+		if chunkHasOutput(chunk) {
+			onFirstToken()
+		}
+		if !acc.AddChunk(ctx, logger, chunk) {
 			return nil, ErrStreamResponse
 		}
 	}
@@ -554,6 +682,22 @@ func (o *openAICompletionsProvider) handleStreamingRequest(ctx context.Context,
 	return acc.Result(), nil
 }
 
+// chunkHasOutput reports whether a streamed chunk carries any generated output,
+// including provider-specific extra fields such as reasoning content.
+func chunkHasOutput(chunk openai.ChatCompletionChunk) bool {
+	for _, choice := range chunk.Choices {
+		if choice.Delta.Content != "" || choice.Delta.Refusal != "" || len(choice.Delta.ToolCalls) > 0 {
+			return true
+		}
+		for key := range choice.Delta.JSON.ExtraFields {
+			if raw, ok := extractExtraFieldRaw(choice.Delta.JSON.ExtraFields, key); ok && raw != `""` {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (o *openAICompletionsProvider) Close(ctx context.Context) error {
 	return nil
 }
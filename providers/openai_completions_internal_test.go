@@ -72,12 +72,57 @@ func TestOpenAICompletions_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "gpt-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
 
+func TestOpenAICompletions_CreateFileContentPart(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+	pdfFile := mockTaskFile(t, "report", testutils.CreateMockFile(t, "report-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	audioFile := mockTaskFile(t, "clip", testutils.CreateMockFile(t, "clip-*.wav", []byte("RIFF")), "audio/wav")
+	flacFile := mockTaskFile(t, "song", testutils.CreateMockFile(t, "song-*.flac", []byte("fLaC")), "audio/flac")
+	textFile := mockTaskFile(t, "notes", testutils.CreateMockFile(t, "notes-*.md", []byte("# Notes")), "text/markdown")
+
+	t.Run("text documents are inlined", func(t *testing.T) {
+		part, err := (&openAICompletionsProvider{}).createFileContentPart(ctx, logger, textFile)
+		require.NoError(t, err)
+		require.NotNil(t, part.OfText)
+		assert.Equal(t, "# Notes", part.OfText.Text)
+	})
+
+	t.Run("PDF and audio require provider support", func(t *testing.T) {
+		p := &openAICompletionsProvider{}
+		_, err := p.createFileContentPart(ctx, logger, pdfFile)
+		require.ErrorIs(t, err, ErrFileNotSupported)
+		assert.Contains(t, err.Error(), "(PDF document)")
+		_, err = p.createFileContentPart(ctx, logger, audioFile)
+		require.ErrorIs(t, err, ErrFileNotSupported)
+		assert.Contains(t, err.Error(), "(audio)")
+	})
+
+	t.Run("PDF and audio when supported", func(t *testing.T) {
+		p := &openAICompletionsProvider{SupportsPDF: true, SupportsAudio: true}
+
+		part, err := p.createFileContentPart(ctx, logger, pdfFile)
+		require.NoError(t, err)
+		require.NotNil(t, part.OfFile)
+		assert.Equal(t, "data:application/pdf;base64,JVBERi0xLjc=", part.OfFile.File.FileData.Value)
+		assert.Equal(t, "report", part.OfFile.File.Filename.Value)
+
+		part, err = p.createFileContentPart(ctx, logger, audioFile)
+		require.NoError(t, err)
+		require.NotNil(t, part.OfInputAudio)
+		assert.Equal(t, "UklGRg==", part.OfInputAudio.InputAudio.Data)
+		assert.Equal(t, "wav", part.OfInputAudio.InputAudio.Format)
+
+		_, err = p.createFileContentPart(ctx, logger, flacFile)
+		require.ErrorIs(t, err, ErrFileNotSupported)
+	})
+}
+
 func TestDefaultCompletionHandler_ToParam(t *testing.T) {
 	ctx := context.Background()
 	logger := testutils.NewTestLogger(t)
@@ -217,6 +262,66 @@ func TestDefaultCompletionHandler_InputCacheTokens(t *testing.T) {
 	}
 }
 
+func TestDefaultCompletionHandler_Reasoning(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	t.Run("streaming", func(t *testing.T) {
+		handler := &defaultCompletionHandler{}
+		for _, raw := range []string{
+			`{"choices": [{"index": 0, "delta": {"reasoning_content": "Thinking "}}]}`,
+			`{"choices": [{"index": 0, "delta": {"reasoning": "aloud"}}]}`,
+			`{"choices": [{"index": 0, "delta": {"content": "Answer"}}]}`,
+		} {
+			var chunk openai.ChatCompletionChunk
+			require.NoError(t, json.Unmarshal([]byte(raw), &chunk))
+			handler.AddChunk(ctx, logger, chunk)
+		}
+		assert.Equal(t, "Thinking aloud", handler.Reasoning(ctx, logger, openai.ChatCompletionMessage{}))
+	})
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "none", message: `{"role": "assistant", "content": "Answer"}`, want: ""},
+		{name: "reasoning content", message: `{"role": "assistant", "content": "Answer", "reasoning_content": "Thinking"}`, want: "Thinking"},
+		{name: "reasoning", message: `{"role": "assistant", "content": "Answer", "reasoning": "Thinking"}`, want: "Thinking"},
+		{name: "malformed", message: `{"role": "assistant", "content": "Answer", "reasoning_content": 1}`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var message openai.ChatCompletionMessage
+			require.NoError(t, json.Unmarshal([]byte(tt.message), &message))
+			assert.Equal(t, tt.want, (&defaultCompletionHandler{}).Reasoning(ctx, logger, message))
+		})
+	}
+}
+
+func TestChunkHasOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk string
+		want  bool
+	}{
+		{name: "role only", chunk: `{"choices": [{"index": 0, "delta": {"role": "assistant", "content": ""}}]}`, want: false},
+		{name: "usage only", chunk: `{"choices": [], "usage": {"completion_tokens": 5}}`, want: false},
+		{name: "null extra field", chunk: `{"choices": [{"index": 0, "delta": {"reasoning_content": null}}]}`, want: false},
+		{name: "content", chunk: `{"choices": [{"index": 0, "delta": {"content": "Hello"}}]}`, want: true},
+		{name: "refusal", chunk: `{"choices": [{"index": 0, "delta": {"refusal": "No"}}]}`, want: true},
+		{name: "tool call", chunk: `{"choices": [{"index": 0, "delta": {"tool_calls": [{"index": 0, "function": {"name": "f"}}]}}]}`, want: true},
+		{name: "reasoning content", chunk: `{"choices": [{"index": 0, "delta": {"reasoning_content": "Thinking..."}}]}`, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chunk openai.ChatCompletionChunk
+			require.NoError(t, json.Unmarshal([]byte(tt.chunk), &chunk))
+			assert.Equal(t, tt.want, chunkHasOutput(chunk))
+		})
+	}
+}
+
 func TestMapImageDetailToOpenAI(t *testing.T) {
 	provider := &openAICompletionsProvider{}
 	logger := testutils.NewTestLogger(t)
@@ -83,11 +83,15 @@ type openAIResponsesProvider struct {
 	// for each API call (both streaming and non-streaming). When nil, the
 	// defaultResponseHandler is used.
 	NewResponseHandler func() ResponseHandler
+
+	// uploads caches task files uploaded to the Files API. When nil, all files are inlined.
+	uploads *fileUploads
 }
 
 func newOpenAIResponsesProvider(availableTools []config.ToolConfig, opts ...option.RequestOption) *openAIResponsesProvider {
 	clientOpts := append([]option.RequestOption{
 		option.WithMaxRetries(0), // disable SDK retries since MindTrial has its own retry policy
+		option.WithHTTPClient(newHTTPClient()),
 	}, opts...)
 
 	return &openAIResponsesProvider{
@@ -144,6 +148,9 @@ func (o *openAIResponsesProvider) Run(ctx context.Context, logger logging.Logger
 				// object's own (SDK-native, object-scoped) extra-fields mechanism.
 				request.Reasoning.SetExtraFields(map[string]any{"mode": *modelParams.ReasoningMode})
 			}
+			if modelParams.ReasoningSummary != nil {
+				request.Reasoning.Summary = shared.ReasoningSummary(*modelParams.ReasoningSummary)
+			}
 			if modelParams.PromptCacheKey != nil {
 				request.PromptCacheKey = param.NewOpt(*modelParams.PromptCacheKey)
 			}
@@ -230,22 +237,17 @@ func (o *openAIResponsesProvider) Run(ctx context.Context, logger logging.Logger
 	request.Input.OfInputItemList = append(request.Input.OfInputItemList, promptItems...)
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			request.Tools = append(request.Tools, responses.ToolUnionParam{
 				OfFunction: &responses.FunctionToolParam{
 					Name:        toolCfg.Name,
@@ -271,15 +273,14 @@ func (o *openAIResponsesProvider) Run(ctx context.Context, logger logging.Logger
 		// Create a fresh response handler for each API call.
 		handler := o.newResponseHandler()
 
-		resp, err := timed(func() (*responses.Response, error) {
-			response, err := o.handleRequest(ctx, logger, request, useStreaming, handler)
+		resp, err := timedRequest(&result, func(onFirstToken func()) (*responses.Response, error) {
+			response, err := o.handleRequest(ctx, logger, request, useStreaming, handler, onFirstToken)
 			if err != nil && o.isTransientResponse(err) {
 				return response, WrapErrRetryable(err)
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -287,6 +288,11 @@ func (o *openAIResponsesProvider) Run(ctx context.Context, logger logging.Logger
 		}
 
 		recordUsage(InputTokenAccountingCacheTokensIncluded, &resp.Usage.InputTokens, &resp.Usage.OutputTokens, &resp.Usage.InputTokensDetails.CacheWriteTokens, &resp.Usage.InputTokensDetails.CachedTokens, &result.usage)
+		recordRequestOutputTokens(&resp.Usage.OutputTokens, &result)
+		if resp.Usage.OutputTokensDetails.JSON.ReasoningTokens.Valid() {
+			recordReasoningTokens(&resp.Usage.OutputTokensDetails.ReasoningTokens, &result.usage)
+		}
+		result.recordReasoning(reasoningText(resp.Output))
 
 		isTerminal := o.isTerminalResponseStatus(resp)
 		logFinishReason(ctx, logger, string(resp.Status), isTerminal)
@@ -383,12 +389,7 @@ func (o *openAIResponsesProvider) createPromptInputItems(ctx context.Context, lo
 	if len(files) > 0 {
 		parts := make(responses.ResponseInputMessageContentListParam, 0, (len(files)*2)+1)
 		for _, file := range files {
-			if fileType, err := file.TypeValue(ctx); err != nil {
-				return nil, err
-			} else if !isSupportedImageType(fileType) {
-				return nil, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
-			}
-			dataURL, err := file.GetDataURL(ctx)
+			filePart, err := o.createFileInputContent(ctx, logger, file)
 			if err != nil {
 				return nil, err
 			}
@@ -397,13 +398,7 @@ func (o *openAIResponsesProvider) createPromptInputItems(ctx context.Context, lo
 					Text: result.recordPrompt(DefaultTaskFileNameInstruction(file)),
 				},
 			})
-			detail := mapImageDetailToResponses(ctx, logger, file.GetResolvedFileOptions().ImageDetail)
-			parts = append(parts, responses.ResponseInputContentUnionParam{
-				OfInputImage: &responses.ResponseInputImageParam{
-					ImageURL: param.NewOpt(dataURL),
-					Detail:   detail,
-				},
-			})
+			parts = append(parts, filePart)
 		}
 		// Append the prompt text after the file data for improved context integrity.
 		parts = append(parts, responses.ResponseInputContentUnionParam{
@@ -426,6 +421,70 @@ func (o *openAIResponsesProvider) createPromptInputItems(ctx context.Context, lo
 	}, nil
 }
 
+// createFileInputContent converts a task file to a Responses API input content part.
+// Images and PDF documents are sent as data URLs, or as file IDs if uploading is enabled
+// for the file; text documents are inlined as text.
+func (o *openAIResponsesProvider) createFileInputContent(ctx context.Context, logger logging.Logger, file config.TaskFile) (responses.ResponseInputContentUnionParam, error) {
+	fileType, err := file.TypeValue(ctx)
+	if err != nil {
+		return responses.ResponseInputContentUnionParam{}, err
+	}
+
+	switch kind := classifyFile(fileType); kind {
+	case fileKindImage, fileKindPDF:
+		if o.uploads != nil && file.GetResolvedFileOptions().UseUpload() {
+			uploaded, err := o.uploads.getOrUpload(ctx, file, fileType, uploadOpenAIFile(&o.client))
+			if err != nil {
+				return responses.ResponseInputContentUnionParam{}, err
+			}
+			if kind == fileKindImage {
+				return responses.ResponseInputContentUnionParam{
+					OfInputImage: &responses.ResponseInputImageParam{
+						FileID: param.NewOpt(uploaded.ID),
+						Detail: mapImageDetailToResponses(ctx, logger, file.GetResolvedFileOptions().ImageDetail),
+					},
+				}, nil
+			}
+			return responses.ResponseInputContentUnionParam{
+				OfInputFile: &responses.ResponseInputFileParam{
+					FileID: param.NewOpt(uploaded.ID),
+				},
+			}, nil
+		}
+
+		dataURL, err := file.GetDataURL(ctx)
+		if err != nil {
+			return responses.ResponseInputContentUnionParam{}, err
+		}
+		if kind == fileKindImage {
+			return responses.ResponseInputContentUnionParam{
+				OfInputImage: &responses.ResponseInputImageParam{
+					ImageURL: param.NewOpt(dataURL),
+					Detail:   mapImageDetailToResponses(ctx, logger, file.GetResolvedFileOptions().ImageDetail),
+				},
+			}, nil
+		}
+		return responses.ResponseInputContentUnionParam{
+			OfInputFile: &responses.ResponseInputFileParam{
+				FileData: param.NewOpt(dataURL),
+				Filename: param.NewOpt(file.Name),
+			},
+		}, nil
+	case fileKindText:
+		text, err := taskFileText(ctx, file)
+		if err != nil {
+			return responses.ResponseInputContentUnionParam{}, err
+		}
+		return responses.ResponseInputContentUnionParam{
+			OfInputText: &responses.ResponseInputTextParam{
+				Text: text,
+			},
+		}, nil
+	default:
+		return responses.ResponseInputContentUnionParam{}, unsupportedFileError(file, fileType)
+	}
+}
+
 // mapImageDetailToResponses maps a provider-agnostic ImageDetail value to the Responses API
 // image detail setting. Supports "auto", "low", "high", and "original". The generic "medium"
 // value is mapped to "high" (nearest higher) to avoid artificially reducing image fidelity.
@@ -450,21 +509,26 @@ func mapImageDetailToResponses(ctx context.Context, logger logging.Logger, detai
 }
 
 // handleRequest dispatches the request to the appropriate handler based on streaming mode.
-func (o *openAIResponsesProvider) handleRequest(ctx context.Context, logger logging.Logger, request responses.ResponseNewParams, streaming bool, acc ResponseAccumulator) (*responses.Response, error) {
+func (o *openAIResponsesProvider) handleRequest(ctx context.Context, logger logging.Logger, request responses.ResponseNewParams, streaming bool, acc ResponseAccumulator, onFirstToken func()) (*responses.Response, error) {
 	if streaming {
-		return o.handleStreamingRequest(ctx, logger, request, acc)
+		return o.handleStreamingRequest(ctx, logger, request, acc, onFirstToken)
 	}
 	return o.client.Responses.New(ctx, request)
 }
 
 // handleStreamingRequest executes a streaming Responses API request,
 // delegating event accumulation to the provided ResponseAccumulator.
-func (o *openAIResponsesProvider) handleStreamingRequest(ctx context.Context, logger logging.Logger, request responses.ResponseNewParams, acc ResponseAccumulator) (resp *responses.Response, err error) {
+// The onFirstToken callback is invoked for every output delta event.
+func (o *openAIResponsesProvider) handleStreamingRequest(ctx context.Context, logger logging.Logger, request responses.ResponseNewParams, acc ResponseAccumulator, onFirstToken func()) (resp *responses.Response, err error) {
 	stream := o.client.Responses.NewStreaming(ctx, request)
 	defer stream.Close()
 
 	for stream.Next() {
-		if err = acc.AddEvent(ctx, logger, stream.Current()); err != nil {
+		event := stream.Current()
+		if strings.HasSuffix(event.Type, ".delta") {
+			onFirstToken()
+		}
+		if err = acc.AddEvent(ctx, logger, event); err != nil {
 			return nil, err
 		}
 	}
@@ -520,6 +584,27 @@ func isRetryableResponseErrorCode(code responses.ResponseErrorCode) bool {
 	}, code)
 }
 
+// reasoningText joins the reasoning summaries of a response's reasoning items, or the
+// full reasoning text of items that carry no summary.
+func reasoningText(output []responses.ResponseOutputItemUnion) string {
+	var parts []string
+	for _, item := range output {
+		if item.Type != "reasoning" {
+			continue
+		}
+		reasoning := item.AsReasoning()
+		for _, summary := range reasoning.Summary {
+			parts = append(parts, summary.Text)
+		}
+		if len(reasoning.Summary) == 0 {
+			for _, content := range reasoning.Content {
+				parts = append(parts, content.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
 // responseOutputText extracts text from message output items, returning two
 // values: otherText contains concatenated text from all non-final_answer message
 // items (commentary/preambles), and finalAnswer contains concatenated text from
@@ -9,8 +9,13 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/responses"
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/testutils"
@@ -58,12 +63,92 @@ func TestOpenAIResponses_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "gpt-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
 
+func TestOpenAIResponses_CreatePromptInputItems_UploadedFiles(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	var uploads, deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, header, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			file.Close()
+			assert.Equal(t, "user_data", r.FormValue("purpose"))
+			uploads = append(uploads, header.Filename)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"file-%d","object":"file","bytes":8,"created_at":0,"filename":%q,"purpose":"user_data","status":"processed"}`, len(uploads), header.Filename)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/files/"):
+			deletes = append(deletes, strings.TrimPrefix(r.URL.Path, "/files/"))
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":%q,"object":"file","deleted":true}`, strings.TrimPrefix(r.URL.Path, "/files/"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := newOpenAIResponsesProvider(nil, option.WithBaseURL(server.URL), option.WithAPIKey("test"))
+	p.uploads = newFileUploads()
+
+	pdfPath := testutils.CreateMockFile(t, "report-*.pdf", []byte("%PDF-1.7"))
+	upload := config.FileOptions{Upload: testutils.Ptr(true)}
+	pdfFile := mockTaskFile(t, "report", pdfPath, "application/pdf")
+	pdfFile.ResolveFileOptions(upload)
+	sameFile := mockTaskFile(t, "copy", pdfPath, "application/pdf")
+	sameFile.ResolveFileOptions(upload)
+	inlineFile := mockTaskFile(t, "inline", pdfPath, "application/pdf")
+
+	items, err := p.createPromptInputItems(ctx, logger, "Compare.", []config.TaskFile{pdfFile, sameFile, inlineFile}, &Result{})
+	require.NoError(t, err)
+	parts := items[0].OfMessage.Content.OfInputItemContentList
+	require.Len(t, parts, 7)
+
+	require.NotNil(t, parts[1].OfInputFile)
+	assert.Equal(t, "file-1", parts[1].OfInputFile.FileID.Value)
+	assert.False(t, parts[1].OfInputFile.FileData.Valid())
+	require.NotNil(t, parts[3].OfInputFile)
+	assert.Equal(t, "file-1", parts[3].OfInputFile.FileID.Value)
+	require.NotNil(t, parts[5].OfInputFile)
+	assert.False(t, parts[5].OfInputFile.FileID.Valid())
+	assert.Equal(t, []string{"report.pdf"}, uploads)
+
+	require.NoError(t, p.uploads.deleteAll(ctx, deleteOpenAIFile(&p.client)))
+	assert.Equal(t, []string{"file-1"}, deletes)
+}
+
+func TestOpenAIResponses_CreatePromptInputItems_Documents(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+	pdfFile := mockTaskFile(t, "report", testutils.CreateMockFile(t, "report-*.pdf", []byte("%PDF-1.7")), "application/pdf")
+	textFile := mockTaskFile(t, "notes", testutils.CreateMockFile(t, "notes-*.txt", []byte("plain notes")), "text/plain; charset=utf-8")
+
+	items, err := (&openAIResponsesProvider{}).createPromptInputItems(ctx, logger, "Summarize.", []config.TaskFile{pdfFile, textFile}, &Result{})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	parts := items[0].OfMessage.Content.OfInputItemContentList
+	require.Len(t, parts, 5)
+
+	require.NotNil(t, parts[1].OfInputFile)
+	assert.Equal(t, "data:application/pdf;base64,JVBERi0xLjc=", parts[1].OfInputFile.FileData.Value)
+	assert.Equal(t, "report", parts[1].OfInputFile.Filename.Value)
+
+	require.NotNil(t, parts[3].OfInputText)
+	assert.Equal(t, "plain notes", parts[3].OfInputText.Text)
+
+	audioFile := mockTaskFile(t, "clip", "file://clip.wav", "audio/wav")
+	_, err = (&openAIResponsesProvider{}).createPromptInputItems(ctx, logger, "Transcribe.", []config.TaskFile{audioFile}, &Result{})
+	require.ErrorIs(t, err, ErrFileNotSupported)
+}
+
 func TestDefaultResponseHandler_AddEvent(t *testing.T) {
 	ctx := context.Background()
 	logger := testutils.NewTestLogger(t)
@@ -341,6 +426,18 @@ func TestResponseOutputText(t *testing.T) {
 	}
 }
 
+func TestReasoningText(t *testing.T) {
+	var output []responses.ResponseOutputItemUnion
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"type": "reasoning", "id": "rs_1", "summary": [{"type": "summary_text", "text": "first"}, {"type": "summary_text", "text": "second"}]},
+		{"type": "message", "id": "msg_1", "role": "assistant", "status": "completed", "content": [{"type": "output_text", "text": "answer", "annotations": []}]},
+		{"type": "reasoning", "id": "rs_2", "summary": [], "content": [{"type": "reasoning_text", "text": "raw"}]},
+		{"type": "reasoning", "id": "rs_3", "summary": [], "encrypted_content": "blob"}
+	]`), &output))
+	assert.Equal(t, "first\n\nsecond\n\nraw", reasoningText(output))
+	assert.Empty(t, reasoningText(nil))
+}
+
 func TestMapImageDetailToResponses(t *testing.T) {
 	logger := testutils.NewTestLogger(t)
 
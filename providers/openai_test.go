@@ -7,12 +7,22 @@
 package providers
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/testutils"
 	"github.com/petmal/mindtrial/pkg/utils"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,7 +39,7 @@ func TestOpenAI_Run_IncompatibleResponseFormat(t *testing.T) {
 	task := config.Task{
 		Name: "t",
 		Files: []config.TaskFile{
-			mockTaskFile(t, "test.txt", "file://test.txt", "text/plain"), // Unsupported file type to cause early error
+			mockTaskFile(t, "test.zip", "file://test.zip", "application/zip"), // Unsupported file type to cause early error
 		},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
@@ -44,7 +54,7 @@ func TestOpenAI_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "gpt-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
@@ -203,3 +213,95 @@ func TestUseChatCompletionsAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestSubmitOpenAIBatch(t *testing.T) {
+	useFastBatchPolling(t)
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	var mu sync.Mutex
+	var inputLines []map[string]any
+	var deleted []string
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, _, err := r.FormFile("file")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer file.Close()
+			assert.Equal(t, "batch", r.FormValue("purpose"))
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var line map[string]any
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				inputLines = append(inputLines, line)
+			}
+			fmt.Fprint(w, `{"id":"file-input","object":"file","purpose":"batch","status":"processed"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "file-input", body["input_file_id"])
+			assert.Equal(t, "/v1/responses", body["endpoint"])
+			assert.Equal(t, "24h", body["completion_window"])
+			fmt.Fprint(w, `{"id":"batch_1","object":"batch","status":"validating"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/batches/batch_1":
+			polls++
+			if polls < 3 {
+				fmt.Fprint(w, `{"id":"batch_1","object":"batch","status":"in_progress"}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":"batch_1","object":"batch","status":"completed","output_file_id":"file-output","error_file_id":"file-errors"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-output/content":
+			fmt.Fprintln(w, `{"custom_id":"request-1","response":{"status_code":200,"body":{"id":"resp_1"}},"error":null}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-errors/content":
+			fmt.Fprintln(w, `{"custom_id":"request-2","response":{"status_code":400,"body":{"error":{"message":"bad request"}}},"error":null}`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/files/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/files/"))
+			fmt.Fprintf(w, `{"id":%q,"object":"file","deleted":true}`, strings.TrimPrefix(r.URL.Path, "/files/"))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+	requests := []*batchRequest{
+		{CustomID: "request-1", Path: "/v1/responses", Body: json.RawMessage(`{"model":"gpt-test","input":"first"}`)},
+		{CustomID: "request-2", Path: "/v1/responses", Body: json.RawMessage(`{"model":"gpt-test","input":"second"}`)},
+	}
+
+	responses, err := submitOpenAIBatch(&client)(ctx, logger, requests)
+	require.NoError(t, err)
+
+	require.Len(t, inputLines, 2)
+	assert.Equal(t, "request-1", inputLines[0]["custom_id"])
+	assert.Equal(t, "POST", inputLines[0]["method"])
+	assert.Equal(t, "/v1/responses", inputLines[0]["url"])
+	assert.Equal(t, map[string]any{"model": "gpt-test", "input": "first"}, inputLines[0]["body"])
+
+	require.Len(t, responses, 2)
+	assert.Equal(t, 200, responses["request-1"].StatusCode)
+	assert.JSONEq(t, `{"id":"resp_1"}`, string(responses["request-1"].Body))
+	assert.Equal(t, 400, responses["request-2"].StatusCode)
+	assert.ElementsMatch(t, []string{"file-input", "file-output", "file-errors"}, deleted)
+
+	t.Run("mixed endpoints", func(t *testing.T) {
+		_, err := submitOpenAIBatch(&client)(ctx, logger, []*batchRequest{
+			{CustomID: "request-1", Path: "/v1/responses", Body: json.RawMessage(`{}`)},
+			{CustomID: "request-2", Path: "/v1/chat/completions", Body: json.RawMessage(`{}`)},
+		})
+		require.ErrorContains(t, err, "different endpoints")
+	})
+}
+
+func TestOpenAIBatchURL(t *testing.T) {
+	assert.Equal(t, "/v1/responses", openAIBatchURL("/v1/responses"))
+	assert.Equal(t, "/v1/chat/completions", openAIBatchURL("/proxy/v1/chat/completions"))
+	assert.Equal(t, "/responses", openAIBatchURL("/responses"))
+}
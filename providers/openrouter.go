@@ -42,6 +42,8 @@ func NewOpenRouter(cfg config.OpenRouterClientConfig, availableTools []config.To
 	}
 
 	openaiProvider := newOpenAICompletionsProvider(availableTools, openAIV3Opts...)
+	openaiProvider.SupportsPDF = true
+	openaiProvider.SupportsAudio = true
 	openaiProvider.NewCompletionHandler = func(any) CompletionHandler {
 		return &openRouterCompletionHandler{}
 	}
@@ -131,6 +133,33 @@ func (h *openRouterCompletionHandler) ToParam(ctx context.Context, logger loggin
 	return result
 }
 
+// Reasoning prefers the readable text and summary blocks of reasoning_details,
+// since encrypted blocks carry no text, and falls back to the plain reasoning
+// fields otherwise.
+func (h *openRouterCompletionHandler) Reasoning(ctx context.Context, logger logging.Logger, message openai.ChatCompletionMessage) string {
+	details := h.reasoningDetails
+	if details == nil {
+		if raw, ok := extractExtraFieldRaw(message.JSON.ExtraFields, "reasoning_details"); ok {
+			if err := json.Unmarshal([]byte(raw), &details); err != nil {
+				logger.Error(ctx, slog.LevelWarn, err, "failed to unmarshal OpenRouter reasoning_details")
+			}
+		}
+	}
+	var texts []string
+	for _, detail := range details {
+		detailType, _ := detail["type"].(string)
+		if field, ok := incrementalReasoningDetailField(detailType); ok {
+			if text, _ := detail[field].(string); strings.TrimSpace(text) != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) > 0 {
+		return strings.Join(texts, "\n\n")
+	}
+	return h.defaultCompletionHandler.Reasoning(ctx, logger, message)
+}
+
 func (h *openRouterCompletionHandler) mergeReasoningDetails(ctx context.Context, logger logging.Logger, extraFields map[string]respjson.Field) {
 	raw, ok := extractExtraFieldRaw(extraFields, "reasoning_details")
 	if !ok {
@@ -192,7 +192,7 @@ func TestOpenRouter_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "openrouter-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
@@ -429,3 +429,35 @@ func TestOpenRouterCopyToOpenAIV3Params(t *testing.T) {
 		require.Empty(t, params.ServerTools[0].Parameters)
 	})
 }
+
+func TestOpenRouterCompletionHandlerReasoning(t *testing.T) {
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	t.Run("streaming details skip encrypted blocks", func(t *testing.T) {
+		handler := &openRouterCompletionHandler{}
+		for _, raw := range []string{
+			`{"choices":[{"delta":{"reasoning_details":[{"type":"reasoning.text","text":"first "}]}}]}`,
+			`{"choices":[{"delta":{"reasoning_details":[{"type":"reasoning.text","text":"step"}]}}]}`,
+			`{"choices":[{"delta":{"reasoning_details":[{"type":"reasoning.encrypted","data":"blob"}]}}]}`,
+			`{"choices":[{"delta":{"reasoning_details":[{"type":"reasoning.summary","summary":"summary"}]}}]}`,
+		} {
+			var chunk openai.ChatCompletionChunk
+			require.NoError(t, json.Unmarshal([]byte(raw), &chunk))
+			handler.AddChunk(ctx, logger, chunk)
+		}
+		require.Equal(t, "first step\n\nsummary", handler.Reasoning(ctx, logger, openai.ChatCompletionMessage{}))
+	})
+
+	t.Run("non-streaming details", func(t *testing.T) {
+		var message openai.ChatCompletionMessage
+		require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":"result","reasoning":"plain","reasoning_details":[{"type":"reasoning.text","text":"detailed"}]}`), &message))
+		require.Equal(t, "detailed", (&openRouterCompletionHandler{}).Reasoning(ctx, logger, message))
+	})
+
+	t.Run("falls back to plain reasoning", func(t *testing.T) {
+		var message openai.ChatCompletionMessage
+		require.NoError(t, json.Unmarshal([]byte(`{"role":"assistant","content":"result","reasoning":"plain","reasoning_details":[{"type":"reasoning.encrypted","data":"blob"}]}`), &message))
+		require.Equal(t, "plain", (&openRouterCompletionHandler{}).Reasoning(ctx, logger, message))
+	})
+}
@@ -14,12 +14,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/invopop/jsonschema"
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/utils"
 	"github.com/petmal/mindtrial/providers/tools"
 	"golang.org/x/exp/constraints"
 )
@@ -49,6 +52,8 @@ var (
 	ErrFileNotSupported = fmt.Errorf("%w: file type", ErrFeatureNotSupported)
 	// ErrFileUploadNotSupported is returned when file upload is not supported by the provider.
 	ErrFileUploadNotSupported = fmt.Errorf("%w: file upload", ErrFeatureNotSupported)
+	// ErrUploadFile is returned when a task file cannot be uploaded to the provider's Files API.
+	ErrUploadFile = errors.New("failed to upload file")
 	// ErrToolUse is returned when tool use fails.
 	ErrToolUse = errors.New("tool use failed")
 	// ErrToolSetup is returned when tool setup/configuration fails.
@@ -72,6 +77,53 @@ var supportedImageMimeTypes = map[string]bool{
 	"image/webp": true,
 }
 
+var supportedTextMimeTypes = map[string]bool{
+	"text/plain":      true,
+	"text/markdown":   true,
+	"text/x-markdown": true,
+	"text/csv":        true,
+}
+
+var supportedAudioMimeTypes = map[string]bool{
+	"audio/wav":    true,
+	"audio/wave":   true,
+	"audio/x-wav":  true,
+	"audio/mpeg":   true,
+	"audio/mp3":    true,
+	"audio/aac":    true,
+	"audio/aiff":   true,
+	"audio/x-aiff": true,
+	"audio/ogg":    true,
+	"audio/flac":   true,
+	"audio/x-flac": true,
+}
+
+// fileKind classifies task files by the way providers need to encode them.
+type fileKind int
+
+const (
+	fileKindUnknown fileKind = iota
+	fileKindImage
+	fileKindPDF
+	fileKindText
+	fileKindAudio
+)
+
+func (k fileKind) String() string {
+	switch k {
+	case fileKindImage:
+		return "image"
+	case fileKindPDF:
+		return "PDF document"
+	case fileKindText:
+		return "text document"
+	case fileKindAudio:
+		return "audio"
+	default:
+		return "unrecognized file"
+	}
+}
+
 // Provider interacts with AI model services.
 // Implementations must support concurrent calls on the same provider instance.
 // Close must be idempotent.
@@ -332,7 +384,11 @@ func DefaultAnswerFormatInstruction(task config.Task) string {
 }
 
 // DefaultTaskFileNameInstruction generates default task file name instruction to be passed to AI models that require it.
+// If a page selection is configured for the file, it is included in the instruction.
 func DefaultTaskFileNameInstruction(file config.TaskFile) string {
+	if pages := file.GetResolvedFileOptions().Pages; pages != nil {
+		return fmt.Sprintf("[file: %s, pages: %s]", file.Name, *pages)
+	}
 	return fmt.Sprintf("[file: %s]", file.Name)
 }
 
@@ -364,6 +420,10 @@ type Usage struct {
 	// OutputTokens used by the output if available.
 	OutputTokens *int64 `json:"-"`
 
+	// ReasoningTokens is the number of tokens spent on reasoning if reported.
+	// Whether they are also counted in OutputTokens depends on the provider.
+	ReasoningTokens *int64 `json:"-"`
+
 	// InputCacheWriteTokens is the number of input tokens written
 	// into a provider prompt cache if reported.
 	InputCacheWriteTokens *int64 `json:"-"`
@@ -474,11 +534,27 @@ type Result struct {
 	// Explanation is a detailed explanation of the answer.
 	Explanation string `json:"explanation" jsonschema:"title=Response Explanation" jsonschema_description:"A comprehensive explanation of the reasoning process, methodology, and context behind the final answer. This should provide clear rationale for how the answer was derived, including any relevant analysis, steps taken, or considerations made." validate:"required"`
 	// FinalAnswer contains the final answer to the task's query.
-	FinalAnswer Answer                  `json:"final_answer" jsonschema:"title=Final Answer" validate:"required"`
-	duration    time.Duration           `json:"-"` // Time to generate the response.
-	prompts     []string                `json:"-"` // Prompts used to generate the response.
-	usage       Usage                   `json:"-"` // Usage statistics.
-	toolCalls   []tools.ToolCallSummary `json:"-"` // Per-invocation tool call log.
+	FinalAnswer Answer                    `json:"final_answer" jsonschema:"title=Final Answer" validate:"required"`
+	duration    time.Duration             `json:"-"` // Time to generate the response.
+	requests    []RequestTiming           `json:"-"` // Timing of each model request.
+	prompts     []string                  `json:"-"` // Prompts used to generate the response.
+	reasoning   []string                  `json:"-"` // Reasoning text or summaries produced by the model.
+	usage       Usage                     `json:"-"` // Usage statistics.
+	toolCalls   []tools.ToolCallSummary   `json:"-"` // Per-invocation tool call log.
+	workspaces  []tools.WorkspaceSnapshot `json:"-"` // Workspaces captured from tool sessions.
+	recovery    ResponseRecovery          `json:"-"` // Step that recovered an unparsable response.
+}
+
+// RequestTiming records the timing of a single model request.
+type RequestTiming struct {
+	// Duration is the time from sending the request until the complete response was received.
+	Duration time.Duration
+	// TimeToFirstToken is the time from sending the request until the first output token
+	// of a streamed response was received. It is nil if the response was not streamed
+	// or no output was received.
+	TimeToFirstToken *time.Duration
+	// OutputTokens is the number of tokens generated in response to the request, if known.
+	OutputTokens *int64
 }
 
 // GetDuration returns the time duration it took to generate this result.
@@ -486,11 +562,22 @@ func (r Result) GetDuration() time.Duration {
 	return r.duration
 }
 
+// GetRequestTimings returns the timing of each model request made to generate this result, in order.
+func (r Result) GetRequestTimings() []RequestTiming {
+	return r.requests
+}
+
 // GetPrompts returns the prompts used to generate this result.
 func (r Result) GetPrompts() []string {
 	return r.prompts
 }
 
+// GetReasoning returns the reasoning text or summaries produced by the model while
+// generating this result, one entry per model response that included any.
+func (r Result) GetReasoning() []string {
+	return r.reasoning
+}
+
 // GetUsage returns the aggregated usage statistics for this result.
 func (r Result) GetUsage() Usage {
 	return r.usage
@@ -501,6 +588,18 @@ func (r Result) GetToolCalls() []tools.ToolCallSummary {
 	return r.toolCalls
 }
 
+// GetToolWorkspaces returns the workspaces captured from the tool sessions when this result
+// was produced, in order of tool names.
+func (r Result) GetToolWorkspaces() []tools.WorkspaceSnapshot {
+	return r.workspaces
+}
+
+// GetRecovery returns the step that recovered the response if it could not be parsed
+// on the first try, or an empty value otherwise.
+func (r Result) GetRecovery() ResponseRecovery {
+	return r.recovery
+}
+
 // GetFinalAnswerContent returns the actual final answer content wrapped in the `FinalAnswer` field.
 // This is a convenience method to access `Result.FinalAnswer.Content` directly.
 func (r Result) GetFinalAnswerContent() interface{} {
@@ -517,17 +616,73 @@ func timed[T any](f func() (T, error), out *time.Duration) (response T, err erro
 	return
 }
 
+// timedRequest measures a single model request like timed, adding its duration to the
+// result, and records the request's timing. Streaming request handlers should call
+// the onFirstToken callback passed to f whenever output arrives; only the first call
+// has an effect.
+func timedRequest[T any](result *Result, f func(onFirstToken func()) (T, error)) (T, error) {
+	var timing RequestTiming
+	start := time.Now()
+	onFirstToken := func() {
+		if timing.TimeToFirstToken == nil {
+			ttft := time.Since(start)
+			timing.TimeToFirstToken = &ttft
+		}
+	}
+	response, err := timed(func() (T, error) {
+		return f(onFirstToken)
+	}, &timing.Duration)
+	result.duration += timing.Duration
+	result.requests = append(result.requests, timing)
+	return response, err
+}
+
+// recordRequestOutputTokens records the number of output tokens generated by the most recent
+// model request measured by timedRequest.
+func recordRequestOutputTokens[T constraints.Signed](outputTokens *T, result *Result) {
+	if outputTokens != nil && len(result.requests) > 0 {
+		tokens := int64(*outputTokens)
+		result.requests[len(result.requests)-1].OutputTokens = &tokens
+	}
+}
+
 func (r *Result) recordPrompt(prompt string) string {
 	r.prompts = append(r.prompts, prompt)
 	return prompt
 }
 
-func (r *Result) recordToolUsage(usage map[string]tools.ToolUsage) {
-	r.usage.ToolUsage = usage
+// recordReasoning records the reasoning text or summary of a single model response.
+// Blank text is ignored.
+func (r *Result) recordReasoning(text string) {
+	if strings.TrimSpace(text) != "" {
+		r.reasoning = append(r.reasoning, text)
+	}
+}
+
+// toolSessionTeardownTimeout limits the time spent capturing the workspaces of tool sessions
+// and removing them when a task completes.
+const toolSessionTeardownTimeout = 2 * time.Minute
+
+// closeToolExecutor ends the tool sessions, recording the workspaces captured from them,
+// and closes the executor. The workspaces are captured even if the task was cancelled or
+// timed out, within toolSessionTeardownTimeout.
+func (r *Result) closeToolExecutor(ctx context.Context, logger logging.Logger, executor tools.ToolExecutor) {
+	teardownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), toolSessionTeardownTimeout)
+	defer cancel()
+	r.workspaces = executor.EndSessions(teardownCtx, logger)
+	if err := executor.Close(); err != nil {
+		logger.Error(ctx, logging.LevelWarn, err, "failed to close tool executor")
+	}
 }
 
-func (r *Result) recordToolCalls(calls []tools.ToolCallSummary) {
-	r.toolCalls = calls
+// recordToolActivity records the usage statistics and call summaries of the tools run by the
+// executor, if any.
+func (r *Result) recordToolActivity(executor tools.ToolExecutor) {
+	if executor == nil {
+		return
+	}
+	r.usage.ToolUsage = executor.GetUsageStats()
+	r.toolCalls = executor.GetCallSummaries()
 }
 
 func recordUsage[T constraints.Signed](inputTokenAccounting InputTokenAccounting, inputTokens *T, outputTokens *T, inputCacheWriteTokens *T, inputCacheReadTokens *T, out *Usage) {
@@ -538,6 +693,11 @@ func recordUsage[T constraints.Signed](inputTokenAccounting InputTokenAccounting
 	addIfNotNil(&out.InputCacheReadTokens, inputCacheReadTokens)
 }
 
+// recordReasoningTokens adds the number of reasoning tokens reported for a model response to the usage.
+func recordReasoningTokens[T constraints.Signed](reasoningTokens *T, out *Usage) {
+	addIfNotNil(&out.ReasoningTokens, reasoningTokens)
+}
+
 // addIfNotNil adds the values from src to dst if src is not nil.
 func addIfNotNil[D ~int64, S constraints.Signed](dst **D, src *S) {
 	if src != nil {
@@ -549,7 +709,48 @@ func addIfNotNil[D ~int64, S constraints.Signed](dst **D, src *S) {
 }
 
 func isSupportedImageType(mimeType string) bool {
-	return supportedImageMimeTypes[strings.ToLower(mimeType)]
+	return supportedImageMimeTypes[mediaType(mimeType)]
+}
+
+// mediaType returns the lower-case media type of the given MIME type without any parameters
+// (e.g. "text/plain; charset=utf-8" becomes "text/plain").
+func mediaType(mimeType string) string {
+	if parsed, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+// classifyFile determines the kind of a task file from its MIME type.
+func classifyFile(mimeType string) fileKind {
+	switch mt := mediaType(mimeType); {
+	case supportedImageMimeTypes[mt]:
+		return fileKindImage
+	case mt == "application/pdf":
+		return fileKindPDF
+	case supportedTextMimeTypes[mt]:
+		return fileKindText
+	case supportedAudioMimeTypes[mt]:
+		return fileKindAudio
+	default:
+		return fileKindUnknown
+	}
+}
+
+// unsupportedFileError returns an error reporting that the given task file cannot be passed to the model.
+func unsupportedFileError(file config.TaskFile, mimeType string) error {
+	return fmt.Errorf("%w: %s (%s) in file '%s'", ErrFileNotSupported, mimeType, classifyFile(mimeType), file.Name)
+}
+
+// taskFileText returns the content of a text document task file to be inlined into the prompt.
+func taskFileText(ctx context.Context, file config.TaskFile) (string, error) {
+	content, err := file.Content(ctx)
+	if err != nil {
+		return "", err
+	} else if !utf8.Valid(content) {
+		return "", fmt.Errorf("%w: text document in file '%s' is not valid UTF-8", ErrFileNotSupported, file.Name)
+	}
+	return string(content), nil
 }
 
 // findToolByName searches for a tool configuration by name in the provided available tools slice.
@@ -563,6 +764,60 @@ func findToolByName(availableTools []config.ToolConfig, name string) (*config.To
 	return nil, false
 }
 
+// setupTools creates an executor for the enabled tools and registers them with it. It returns
+// the executor along with the definitions of the tools to expose to the model, in order of
+// their configured names. Each enabled sandboxed, mock or HTTP tool is exposed as configured, while each
+// enabled MCP tool is replaced by the tools discovered on its server. The caller is
+// responsible for closing the returned executor.
+func setupTools(ctx context.Context, logger logging.Logger, availableTools []config.ToolConfig, enabledTools map[string]config.ToolSelection) (tools.ToolExecutor, []*config.ToolConfig, error) {
+	toolNames := utils.SortedKeys(enabledTools)
+	toolCfgs := make([]config.ToolConfig, 0, len(toolNames))
+	for _, toolName := range toolNames {
+		// Find the tool config from available tools.
+		toolCfg, found := findToolByName(availableTools, toolName)
+		if !found {
+			return nil, nil, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
+		}
+		toolCfgs = append(toolCfgs, *toolCfg)
+	}
+
+	backend, err := tools.ExecutorBackend(toolCfgs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrToolSetup, err)
+	}
+	executor, err := tools.NewToolExecutor(ctx, backend)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrToolSetup, err)
+	}
+
+	var definitions []*config.ToolConfig
+	for i, toolName := range toolNames {
+		toolCfg, toolSelection := &toolCfgs[i], enabledTools[toolName]
+		switch toolCfg.GetType() {
+		case config.ToolTypeMCP:
+			discovered, err := executor.RegisterMCPServer(ctx, logger, toolCfg, toolSelection.MaxCalls, toolSelection.Timeout)
+			if err != nil {
+				executor.Close()
+				return nil, nil, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			}
+			for i := range discovered {
+				definitions = append(definitions, &discovered[i])
+			}
+		case config.ToolTypeMock:
+			executor.RegisterMockTool(tools.NewMockTool(toolCfg, toolSelection.MaxCalls))
+			definitions = append(definitions, toolCfg)
+		case config.ToolTypeHTTP:
+			executor.RegisterHTTPTool(tools.NewHTTPTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout))
+			definitions = append(definitions, toolCfg)
+		default:
+			// Docker and subprocess tools run in the sandbox of the executor backend.
+			executor.RegisterTool(tools.NewSandboxTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent))
+			definitions = append(definitions, toolCfg)
+		}
+	}
+	return executor, definitions, nil
+}
+
 // formatToolExecutionError formats a tool execution error message for consistent
 // error reporting across all providers.
 func formatToolExecutionError(err error) string {
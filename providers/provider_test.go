@@ -39,6 +39,46 @@ func TestTimed(t *testing.T) {
 	assert.GreaterOrEqual(t, duration, sleepDuration)
 }
 
+func TestTimedRequest(t *testing.T) {
+	sleepDuration := 50 * time.Millisecond
+	var result Result
+
+	response, err := timedRequest(&result, func(onFirstToken func()) (string, error) {
+		time.Sleep(sleepDuration)
+		onFirstToken()
+		time.Sleep(sleepDuration)
+		onFirstToken()
+		return "first", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "first", response)
+	recordRequestOutputTokens(testutils.Ptr(int64(42)), &result)
+
+	_, err = timedRequest(&result, func(func()) (string, error) {
+		return "", errors.ErrUnsupported
+	})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+
+	timings := result.GetRequestTimings()
+	require.Len(t, timings, 2)
+	assert.Equal(t, result.GetDuration(), timings[0].Duration+timings[1].Duration)
+
+	assert.GreaterOrEqual(t, timings[0].Duration, 2*sleepDuration)
+	require.NotNil(t, timings[0].TimeToFirstToken)
+	assert.GreaterOrEqual(t, *timings[0].TimeToFirstToken, sleepDuration)
+	assert.Less(t, *timings[0].TimeToFirstToken, timings[0].Duration)
+	assert.Equal(t, testutils.Ptr(int64(42)), timings[0].OutputTokens)
+
+	assert.Nil(t, timings[1].TimeToFirstToken)
+	assert.Nil(t, timings[1].OutputTokens)
+}
+
+func TestRecordRequestOutputTokensWithoutRequest(t *testing.T) {
+	var result Result
+	recordRequestOutputTokens(testutils.Ptr(int32(7)), &result)
+	assert.Empty(t, result.GetRequestTimings())
+}
+
 func TestResultGetPrompts(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1114,6 +1154,62 @@ func TestTaskFilesToDataMap(t *testing.T) {
 	})
 }
 
+func TestClassifyFile(t *testing.T) {
+	tests := []struct {
+		mimeType string
+		expected fileKind
+	}{
+		{"image/png", fileKindImage},
+		{"IMAGE/JPEG", fileKindImage},
+		{"application/pdf", fileKindPDF},
+		{"text/plain; charset=utf-8", fileKindText},
+		{"text/markdown", fileKindText},
+		{"text/csv", fileKindText},
+		{"audio/wav", fileKindAudio},
+		{"audio/mpeg", fileKindAudio},
+		{"application/zip", fileKindUnknown},
+		{"", fileKindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classifyFile(tt.mimeType))
+		})
+	}
+}
+
+func TestUnsupportedFileError(t *testing.T) {
+	err := unsupportedFileError(mockTaskFile(t, "report", "file://report.pdf", "application/pdf"), "application/pdf")
+	require.ErrorIs(t, err, ErrFileNotSupported)
+	require.ErrorIs(t, err, ErrFeatureNotSupported)
+	assert.Contains(t, err.Error(), "application/pdf (PDF document) in file 'report'")
+}
+
+func TestTaskFileText(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid text", func(t *testing.T) {
+		filePath := testutils.CreateMockFile(t, "notes-*.md", []byte("# Notes\nline"))
+		text, err := taskFileText(ctx, mockTaskFile(t, "notes", filePath, "text/markdown"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Notes\nline", text)
+	})
+
+	t.Run("invalid UTF-8", func(t *testing.T) {
+		filePath := testutils.CreateMockFile(t, "data-*.csv", []byte{0xff, 0xfe, 0x00})
+		_, err := taskFileText(ctx, mockTaskFile(t, "data", filePath, "text/csv"))
+		require.ErrorIs(t, err, ErrFileNotSupported)
+	})
+}
+
+func TestDefaultTaskFileNameInstruction(t *testing.T) {
+	file := mockTaskFile(t, "report", "file://report.pdf", "application/pdf")
+	assert.Equal(t, "[file: report]", DefaultTaskFileNameInstruction(file))
+
+	file.ResolveFileOptions(config.FileOptions{Pages: testutils.Ptr("1-3,5")})
+	assert.Equal(t, "[file: report, pages: 1-3,5]", DefaultTaskFileNameInstruction(file))
+}
+
 func mockTaskFile(t *testing.T, name string, uri string, mimeType string) config.TaskFile {
 	// Use YAML unmarshaling to properly initialize the TaskFile functions.
 	yamlStr := fmt.Sprintf("name: %s\nuri: %s\ntype: %s", name, uri, mimeType)
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo holds the rate limit hints a provider returned with a response.
+type RateLimitInfo struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// RetryAfter is the delay the server asked to wait before the next request, or 0 if none.
+	RetryAfter time.Duration
+	// RemainingRequests is the number of requests left in the current window, or nil if not reported.
+	RemainingRequests *int64
+	// LimitRequests is the number of requests allowed in the current window, or nil if not reported.
+	LimitRequests *int64
+	// RemainingTokens is the number of tokens left in the current window, or nil if not reported.
+	RemainingTokens *int64
+	// LimitTokens is the number of tokens allowed in the current window, or nil if not reported.
+	LimitTokens *int64
+}
+
+// IsRateLimited reports whether the response was rejected because of rate limiting.
+func (i RateLimitInfo) IsRateLimited() bool {
+	return i.StatusCode == http.StatusTooManyRequests
+}
+
+// RemainingQuota returns the smallest reported fraction of the request and token quota
+// left in the current window, or false if the response did not report any quota.
+func (i RateLimitInfo) RemainingQuota() (float64, bool) {
+	quota, known := 1.0, false
+	for _, pair := range [][2]*int64{{i.RemainingRequests, i.LimitRequests}, {i.RemainingTokens, i.LimitTokens}} {
+		if pair[0] != nil && pair[1] != nil && *pair[1] > 0 {
+			quota = min(quota, float64(*pair[0])/float64(*pair[1]))
+			known = true
+		}
+	}
+	return max(quota, 0), known
+}
+
+// RateLimitObserver receives the rate limit hints of every provider response that carries
+// any, as well as the status of every error response. It may be called concurrently.
+type RateLimitObserver func(info RateLimitInfo)
+
+type rateLimitObserverKey struct{}
+
+// WithRateLimitObserver returns a copy of ctx that reports the rate limit hints of provider
+// responses to the given observer. An observer already set on ctx is called as well.
+func WithRateLimitObserver(ctx context.Context, observer RateLimitObserver) context.Context {
+	if parent, ok := ctx.Value(rateLimitObserverKey{}).(RateLimitObserver); ok {
+		next := observer
+		observer = func(info RateLimitInfo) {
+			next(info)
+			parent(info)
+		}
+	}
+	return context.WithValue(ctx, rateLimitObserverKey{}, observer)
+}
+
+// ReportRateLimit reports the rate limit hints of a provider response to the
+// RateLimitObserver set on ctx, if any.
+func ReportRateLimit(ctx context.Context, info RateLimitInfo) {
+	if observer, ok := ctx.Value(rateLimitObserverKey{}).(RateLimitObserver); ok {
+		observer(info)
+	}
+}
+
+// ParseRateLimitHeaders extracts rate limit hints from response headers. It understands
+// the standard Retry-After header, the retry-after-ms extension and the x-ratelimit-*
+// and anthropic-ratelimit-* quota headers. It returns false if the response is neither
+// rate limited nor carries any hints.
+func ParseRateLimitHeaders(statusCode int, header http.Header, now time.Time) (RateLimitInfo, bool) {
+	info := RateLimitInfo{StatusCode: statusCode}
+	found := info.IsRateLimited()
+
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		info.RetryAfter = time.Duration(ms * float64(time.Millisecond))
+		found = true
+	} else if retryAfter := strings.TrimSpace(header.Get("Retry-After")); retryAfter != "" {
+		if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil && seconds > 0 {
+			info.RetryAfter = time.Duration(seconds * float64(time.Second))
+			found = true
+		} else if at, err := http.ParseTime(retryAfter); err == nil && at.After(now) {
+			info.RetryAfter = at.Sub(now)
+			found = true
+		}
+	}
+
+	for _, target := range []struct {
+		value *(*int64)
+		names []string
+	}{
+		{&info.RemainingRequests, []string{"X-Ratelimit-Remaining-Requests", "Anthropic-Ratelimit-Requests-Remaining"}},
+		{&info.LimitRequests, []string{"X-Ratelimit-Limit-Requests", "Anthropic-Ratelimit-Requests-Limit"}},
+		{&info.RemainingTokens, []string{"X-Ratelimit-Remaining-Tokens", "Anthropic-Ratelimit-Tokens-Remaining"}},
+		{&info.LimitTokens, []string{"X-Ratelimit-Limit-Tokens", "Anthropic-Ratelimit-Tokens-Limit"}},
+	} {
+		for _, name := range target.names {
+			if value, err := strconv.ParseInt(strings.TrimSpace(header.Get(name)), 10, 64); err == nil {
+				*target.value = &value
+				found = true
+				break
+			}
+		}
+	}
+
+	return info, found
+}
+
+// rateLimitTransport is an http.RoundTripper that reports the rate limit hints and error
+// statuses of responses to the RateLimitObserver set on the request context.
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+// newHTTPClient creates the HTTP client used by provider SDKs, which reports rate limit hints.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: &rateLimitTransport{base: http.DefaultTransport}}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if info, found := ParseRateLimitHeaders(resp.StatusCode, resp.Header, time.Now()); found || resp.StatusCode >= http.StatusBadRequest {
+		ReportRateLimit(req.Context(), info)
+	}
+	return resp, nil
+}
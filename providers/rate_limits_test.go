@@ -0,0 +1,158 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name       string
+		statusCode int
+		header     http.Header
+		want       RateLimitInfo
+		wantFound  bool
+	}{
+		{
+			name:       "no hints",
+			statusCode: http.StatusOK,
+			header:     http.Header{},
+			want:       RateLimitInfo{StatusCode: http.StatusOK},
+		},
+		{
+			name:       "rate limited without hints",
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{},
+			want:       RateLimitInfo{StatusCode: http.StatusTooManyRequests},
+			wantFound:  true,
+		},
+		{
+			name:       "retry after seconds",
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{"20"}},
+			want:       RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: 20 * time.Second},
+			wantFound:  true,
+		},
+		{
+			name:       "retry after date",
+			statusCode: http.StatusServiceUnavailable,
+			header:     http.Header{"Retry-After": []string{now.Add(time.Minute).Format(http.TimeFormat)}},
+			want:       RateLimitInfo{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Minute},
+			wantFound:  true,
+		},
+		{
+			name:       "retry after milliseconds take precedence",
+			statusCode: http.StatusTooManyRequests,
+			header:     http.Header{"Retry-After": []string{"2"}, "Retry-After-Ms": []string{"1500"}},
+			want:       RateLimitInfo{StatusCode: http.StatusTooManyRequests, RetryAfter: 1500 * time.Millisecond},
+			wantFound:  true,
+		},
+		{
+			name:       "invalid retry after is ignored",
+			statusCode: http.StatusOK,
+			header:     http.Header{"Retry-After": []string{"soon"}},
+			want:       RateLimitInfo{StatusCode: http.StatusOK},
+		},
+		{
+			name:       "openai quota headers",
+			statusCode: http.StatusOK,
+			header: http.Header{
+				"X-Ratelimit-Remaining-Requests": []string{"59"},
+				"X-Ratelimit-Limit-Requests":     []string{"60"},
+				"X-Ratelimit-Remaining-Tokens":   []string{"1000"},
+				"X-Ratelimit-Limit-Tokens":       []string{"150000"},
+			},
+			want: RateLimitInfo{
+				StatusCode:        http.StatusOK,
+				RemainingRequests: int64Ptr(59),
+				LimitRequests:     int64Ptr(60),
+				RemainingTokens:   int64Ptr(1000),
+				LimitTokens:       int64Ptr(150000),
+			},
+			wantFound: true,
+		},
+		{
+			name:       "anthropic quota headers",
+			statusCode: http.StatusOK,
+			header: http.Header{
+				"Anthropic-Ratelimit-Requests-Remaining": []string{"10"},
+				"Anthropic-Ratelimit-Requests-Limit":     []string{"50"},
+			},
+			want: RateLimitInfo{
+				StatusCode:        http.StatusOK,
+				RemainingRequests: int64Ptr(10),
+				LimitRequests:     int64Ptr(50),
+			},
+			wantFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := ParseRateLimitHeaders(tt.statusCode, tt.header, now)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRateLimitInfo_RemainingQuota(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	_, known := RateLimitInfo{}.RemainingQuota()
+	assert.False(t, known)
+
+	quota, known := RateLimitInfo{RemainingRequests: int64Ptr(30), LimitRequests: int64Ptr(60)}.RemainingQuota()
+	assert.True(t, known)
+	assert.InDelta(t, 0.5, quota, 0.0001)
+
+	quota, known = RateLimitInfo{
+		RemainingRequests: int64Ptr(30), LimitRequests: int64Ptr(60),
+		RemainingTokens: int64Ptr(100), LimitTokens: int64Ptr(1000),
+	}.RemainingQuota()
+	assert.True(t, known)
+	assert.InDelta(t, 0.1, quota, 0.0001)
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var observed []RateLimitInfo
+	ctx := WithRateLimitObserver(context.Background(), func(info RateLimitInfo) {
+		observed = append(observed, info)
+	})
+	ctx = WithRateLimitObserver(ctx, func(info RateLimitInfo) {
+		observed = append(observed, info)
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := newHTTPClient().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Len(t, observed, 2)
+	for _, info := range observed {
+		assert.True(t, info.IsRateLimited())
+		assert.Equal(t, 3*time.Second, info.RetryAfter)
+	}
+}
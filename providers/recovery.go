@@ -0,0 +1,344 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/utils"
+	"github.com/petmal/mindtrial/providers/tools"
+)
+
+// ResponseRecovery identifies the step that recovered a response that could not be parsed.
+type ResponseRecovery string
+
+const (
+	// RecoveryRepair indicates a response whose syntax was repaired locally.
+	RecoveryRepair ResponseRecovery = "repair"
+	// RecoveryCoercion indicates a response that was coerced to the result schema.
+	RecoveryCoercion ResponseRecovery = "coercion"
+	// RecoveryCorrectiveTurn indicates a response that was corrected by the model when asked to.
+	RecoveryCorrectiveTurn ResponseRecovery = "corrective-turn"
+)
+
+// RecoverResponse attempts to recover the result from a response that could not be parsed
+// by the local steps enabled in the policy: first repairing the syntax of the response and
+// then coercing it to the result schema of the given response format. On success, it populates
+// the result and records the step that recovered it. Otherwise, it returns the error
+// the response failed with, suitable to be quoted to the model.
+func RecoverResponse(ctx context.Context, logger logging.Logger, policy config.ResponseRecoveryPolicy, responseFormat config.ResponseFormat, unmarshalErr *ErrUnmarshalResponse, result *Result) error {
+	content := string(unmarshalErr.RawMessage)
+	failure := unmarshalErr.Cause
+	if !policy.Repair && !policy.Coerce {
+		return failure
+	}
+
+	schema, err := ResultJSONSchemaRaw(responseFormat)
+	if err != nil {
+		return err
+	}
+
+	if policy.Repair {
+		repaired, err := utils.RepairTextJSON(content)
+		if err == nil {
+			content = repaired
+			var value interface{}
+			if err = json.Unmarshal([]byte(repaired), &value); err == nil {
+				err = utils.ValidateAgainstSchema(schema, value)
+			}
+			if err == nil {
+				return recoverResult(ctx, logger, value, RecoveryRepair, result)
+			}
+		}
+		failure = err
+	}
+
+	if policy.Coerce {
+		var value interface{}
+		if err := json.Unmarshal([]byte(content), &value); err != nil {
+			value = content // not JSON at all, which is acceptable only for a plain text answer
+		}
+		value = coerceResult(value, schema)
+		if err := utils.ValidateAgainstSchema(schema, value); err != nil {
+			return err
+		}
+		return recoverResult(ctx, logger, value, RecoveryCoercion, result)
+	}
+
+	return failure
+}
+
+// recoverResult populates the result from the recovered response value.
+// A result already recovered by a corrective turn keeps that recovery step.
+func recoverResult(ctx context.Context, logger logging.Logger, value interface{}, step ResponseRecovery, result *Result) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var recovered Result
+	if err := json.Unmarshal(content, &recovered); err != nil {
+		return err
+	}
+	result.Title = recovered.Title
+	result.Explanation = recovered.Explanation
+	result.FinalAnswer = recovered.FinalAnswer
+	if result.recovery == "" {
+		result.recovery = step
+	}
+	logger.Message(ctx, logging.LevelInfo, "response that could not be parsed was recovered by %s", step)
+	return nil
+}
+
+// CorrectiveTask returns a copy of the task that asks the model to correct its previous response,
+// quoting the response and the error it failed with.
+func CorrectiveTask(task config.Task, rawMessage []byte, failure error) config.Task {
+	task.Prompt = fmt.Sprintf("%s\n\nYour previous response to this task could not be parsed: %v\n\nPrevious response:\n%s\n\n"+
+		"Respond again with the complete answer, strictly following the required response format.", task.Prompt, failure, rawMessage)
+	return task
+}
+
+// MergeCorrectiveResult returns the result of a corrective turn combined with the duration,
+// request timings, prompts, reasoning, usage and tool calls of the previous result it corrects.
+// The combined result is marked as recovered by a corrective turn.
+func MergeCorrectiveResult(previous Result, corrected Result) Result {
+	corrected.duration += previous.duration
+	corrected.requests = append(slices.Clone(previous.requests), corrected.requests...)
+	corrected.prompts = append(slices.Clone(previous.prompts), corrected.prompts...)
+	corrected.reasoning = append(slices.Clone(previous.reasoning), corrected.reasoning...)
+	corrected.toolCalls = append(slices.Clone(previous.toolCalls), corrected.toolCalls...)
+
+	usage := previous.usage
+	usage.InputTokens, usage.OutputTokens, usage.ReasoningTokens = nil, nil, nil
+	usage.InputCacheWriteTokens, usage.InputCacheReadTokens = nil, nil
+	for _, u := range []Usage{previous.usage, corrected.usage} {
+		addIfNotNil(&usage.InputTokens, u.InputTokens)
+		addIfNotNil(&usage.OutputTokens, u.OutputTokens)
+		addIfNotNil(&usage.ReasoningTokens, u.ReasoningTokens)
+		addIfNotNil(&usage.InputCacheWriteTokens, u.InputCacheWriteTokens)
+		addIfNotNil(&usage.InputCacheReadTokens, u.InputCacheReadTokens)
+		if u.InputTokenAccounting != "" {
+			usage.InputTokenAccounting = u.InputTokenAccounting
+		}
+	}
+	usage.ToolUsage = nil
+	for _, u := range []Usage{previous.usage, corrected.usage} {
+		for name, toolUsage := range u.ToolUsage {
+			if usage.ToolUsage == nil {
+				usage.ToolUsage = make(map[string]tools.ToolUsage)
+			}
+			total := usage.ToolUsage[name]
+			total.CallCount += toolUsage.CallCount
+			total.TotalDurationNs += toolUsage.TotalDurationNs
+			total.Exhausted += toolUsage.Exhausted
+			total.PoolHits += toolUsage.PoolHits
+			total.PoolMisses += toolUsage.PoolMisses
+			total.PoolSavedNs += toolUsage.PoolSavedNs
+			usage.ToolUsage[name] = total
+		}
+	}
+	corrected.usage = usage
+	corrected.recovery = RecoveryCorrectiveTurn
+	return corrected
+}
+
+// coerceResult fits a response value to the result schema. A value that is not an object
+// with any of the result properties is taken to be the bare final answer.
+func coerceResult(value interface{}, schema map[string]interface{}) interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if object, ok := value.(map[string]interface{}); !ok || !hasAnyProperty(object, properties) {
+		value = map[string]interface{}{"final_answer": value}
+	}
+	return coerceToSchema(value, schema)
+}
+
+// coerceToSchema converts the value to fit the JSON schema where the intent is unambiguous:
+// misspelled object properties are renamed, unknown ones dropped if not allowed and missing
+// required text properties left empty, a value that is not an object is wrapped in the only
+// required property, a single value is wrapped in an array and scalar values are converted
+// between strings, numbers and booleans. Values that cannot be converted are left unchanged.
+func coerceToSchema(value interface{}, schema map[string]interface{}) interface{} {
+	types := schemaTypes(schema)
+	if len(types) == 0 || slices.ContainsFunc(types, func(t string) bool { return isOfType(value, t) }) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			return coerceObject(v, schema)
+		case []interface{}:
+			return coerceArray(v, schema)
+		}
+		return value
+	}
+
+	for _, t := range types {
+		switch t {
+		case "object":
+			if required := requiredProperties(schema); len(required) == 1 {
+				return coerceObject(map[string]interface{}{required[0]: value}, schema)
+			}
+		case "array":
+			return coerceArray([]interface{}{value}, schema)
+		case "string":
+			switch v := value.(type) {
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				return strconv.FormatBool(v)
+			}
+		case "number", "integer":
+			if v, ok := value.(string); ok {
+				if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && isOfType(number, t) {
+					return number
+				}
+			}
+		case "boolean":
+			if v, ok := value.(string); ok {
+				if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+					return b
+				}
+			}
+		}
+	}
+	return value
+}
+
+func coerceObject(object map[string]interface{}, schema map[string]interface{}) interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return object
+	}
+	if required := requiredProperties(schema); len(required) == 1 && !hasAnyProperty(object, properties) {
+		object = map[string]interface{}{required[0]: object}
+	}
+
+	additionalAllowed := true
+	if allowed, ok := schema["additionalProperties"].(bool); ok {
+		additionalAllowed = allowed
+	}
+	coerced := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		if _, ok := properties[key]; !ok {
+			if name, ok := findProperty(key, properties); ok && !hasKey(object, name) {
+				key = name
+			} else if !additionalAllowed {
+				continue
+			}
+		}
+		coerced[key] = value
+	}
+
+	for key, value := range coerced {
+		if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+			coerced[key] = coerceToSchema(value, propertySchema)
+		}
+	}
+	for _, name := range requiredProperties(schema) {
+		if _, ok := coerced[name]; !ok {
+			if propertySchema, ok := properties[name].(map[string]interface{}); ok && slices.Equal(schemaTypes(propertySchema), []string{"string"}) {
+				coerced[name] = ""
+			}
+		}
+	}
+	return coerced
+}
+
+func coerceArray(array []interface{}, schema map[string]interface{}) interface{} {
+	items, ok := schema["items"].(map[string]interface{})
+	if !ok {
+		return array
+	}
+	coerced := make([]interface{}, len(array))
+	for i, item := range array {
+		coerced[i] = coerceToSchema(item, items)
+	}
+	return coerced
+}
+
+// schemaTypes returns the types allowed by the schema, or nil if it does not restrict the type.
+func schemaTypes(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func isOfType(value interface{}, schemaType string) bool {
+	switch v := value.(type) {
+	case nil:
+		return schemaType == "null"
+	case map[string]interface{}:
+		return schemaType == "object"
+	case []interface{}:
+		return schemaType == "array"
+	case string:
+		return schemaType == "string"
+	case bool:
+		return schemaType == "boolean"
+	case float64:
+		return schemaType == "number" || schemaType == "integer" && v == float64(int64(v))
+	}
+	return false
+}
+
+func requiredProperties(schema map[string]interface{}) []string {
+	var names []string
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func hasKey(object map[string]interface{}, key string) bool {
+	_, ok := object[key]
+	return ok
+}
+
+func hasAnyProperty(object map[string]interface{}, properties map[string]interface{}) bool {
+	for key := range object {
+		if _, ok := properties[key]; ok {
+			return true
+		}
+		if _, ok := findProperty(key, properties); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findProperty finds the schema property that the key refers to, ignoring differences in case
+// and word separators (e.g. "finalAnswer" refers to "final_answer").
+func findProperty(key string, properties map[string]interface{}) (string, bool) {
+	normalizedKey := normalizePropertyName(key)
+	for _, name := range utils.SortedKeys(properties) {
+		if normalizePropertyName(name) == normalizedKey {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func normalizePropertyName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(name))
+}
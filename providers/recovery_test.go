@@ -0,0 +1,184 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/providers/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverResponse(t *testing.T) {
+	textFormat := config.NewResponseFormat("Answer with a single word.")
+	schemaFormat := config.NewResponseFormat(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+			"age":  map[string]interface{}{"type": "integer"},
+			"tags": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required":             []interface{}{"name", "age"},
+		"additionalProperties": false,
+	})
+	repairAndCoerce := config.ResponseRecoveryPolicy{Repair: true, Coerce: true}
+
+	tests := []struct {
+		name           string
+		policy         config.ResponseRecoveryPolicy
+		responseFormat config.ResponseFormat
+		rawMessage     string
+		wantRecovery   ResponseRecovery
+		wantTitle      string
+		wantAnswer     interface{}
+		wantErr        bool
+	}{
+		{
+			name:           "repair markdown code block with trailing comma",
+			policy:         repairAndCoerce,
+			responseFormat: textFormat,
+			rawMessage:     "```json\n{\"title\": \"Answer\", \"explanation\": \"Because.\", \"final_answer\": \"yes\",}\n```",
+			wantRecovery:   RecoveryRepair,
+			wantTitle:      "Answer",
+			wantAnswer:     "yes",
+		},
+		{
+			name:           "coerce bare plain text answer",
+			policy:         repairAndCoerce,
+			responseFormat: textFormat,
+			rawMessage:     "yes",
+			wantRecovery:   RecoveryCoercion,
+			wantAnswer:     "yes",
+		},
+		{
+			name:           "coerce misspelled properties and number",
+			policy:         config.ResponseRecoveryPolicy{Coerce: true},
+			responseFormat: textFormat,
+			rawMessage:     `{"Title": "Answer", "finalAnswer": 42}`,
+			wantRecovery:   RecoveryCoercion,
+			wantTitle:      "Answer",
+			wantAnswer:     "42",
+		},
+		{
+			name:           "coerce structured answer without content wrapper",
+			policy:         repairAndCoerce,
+			responseFormat: schemaFormat,
+			rawMessage:     `{"title": "Person", "explanation": "Found.", "final_answer": {"name": "Ann", "age": "42", "tags": "admin", "extra": true}}`,
+			wantRecovery:   RecoveryCoercion,
+			wantTitle:      "Person",
+			wantAnswer:     map[string]interface{}{"name": "Ann", "age": float64(42), "tags": []interface{}{"admin"}},
+		},
+		{
+			name:           "repair only does not coerce",
+			policy:         config.ResponseRecoveryPolicy{Repair: true},
+			responseFormat: textFormat,
+			rawMessage:     `{"final_answer": 42}`,
+			wantErr:        true,
+		},
+		{
+			name:           "structured answer that cannot be coerced",
+			policy:         repairAndCoerce,
+			responseFormat: schemaFormat,
+			rawMessage:     `{"final_answer": {"name": "Ann", "age": "unknown"}}`,
+			wantErr:        true,
+		},
+		{
+			name:           "no local steps enabled",
+			policy:         config.ResponseRecoveryPolicy{MaxCorrectiveTurns: 1},
+			responseFormat: textFormat,
+			rawMessage:     "yes",
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unmarshalErr := NewErrUnmarshalResponse(errors.New("invalid character"), []byte(tt.rawMessage), nil)
+			result := Result{usage: Usage{InputTokens: testutils.Ptr(int64(10))}}
+
+			err := RecoverResponse(context.Background(), testutils.NewTestLogger(t), tt.policy, tt.responseFormat, unmarshalErr, &result)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Empty(t, result.GetRecovery())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRecovery, result.GetRecovery())
+			assert.Equal(t, tt.wantTitle, result.Title)
+			assert.Equal(t, tt.wantAnswer, result.GetFinalAnswerContent())
+			assert.Equal(t, testutils.Ptr(int64(10)), result.GetUsage().InputTokens, "usage must be kept")
+		})
+	}
+}
+
+func TestCorrectiveTask(t *testing.T) {
+	task := config.Task{Name: "task", Prompt: "What is 2+2?"}
+
+	corrective := CorrectiveTask(task, []byte("four"), errors.New("invalid character 'f'"))
+
+	assert.Equal(t, "task", corrective.Name)
+	assert.Equal(t, "What is 2+2?\n\nYour previous response to this task could not be parsed: invalid character 'f'\n\nPrevious response:\nfour\n\n"+
+		"Respond again with the complete answer, strictly following the required response format.", corrective.Prompt)
+	assert.Equal(t, "What is 2+2?", task.Prompt, "original task must not change")
+}
+
+func TestMergeCorrectiveResult(t *testing.T) {
+	previous := Result{
+		duration:  time.Second,
+		requests:  []RequestTiming{{Duration: time.Second}},
+		prompts:   []string{"first"},
+		reasoning: []string{"thinking"},
+		usage: Usage{
+			InputTokens:          testutils.Ptr(int64(100)),
+			OutputTokens:         testutils.Ptr(int64(10)),
+			InputTokenAccounting: InputTokenAccountingCacheTokensIncluded,
+			ToolUsage:            map[string]tools.ToolUsage{"python": {CallCount: 1, TotalDurationNs: 5, PoolHits: 1, PoolSavedNs: 300}},
+		},
+		toolCalls: []tools.ToolCallSummary{{Tool: "python"}},
+	}
+	corrected := Result{
+		Title:       "Answer",
+		FinalAnswer: Answer{Content: "4"},
+		duration:    2 * time.Second,
+		requests:    []RequestTiming{{Duration: 2 * time.Second}},
+		prompts:     []string{"second"},
+		usage: Usage{
+			InputTokens:     testutils.Ptr(int64(200)),
+			ReasoningTokens: testutils.Ptr(int64(5)),
+			ToolUsage:       map[string]tools.ToolUsage{"python": {CallCount: 2, TotalDurationNs: 7, PoolHits: 1, PoolMisses: 1, PoolSavedNs: 200}, "bash": {CallCount: 1}},
+		},
+	}
+
+	merged := MergeCorrectiveResult(previous, corrected)
+
+	assert.Equal(t, "Answer", merged.Title)
+	assert.Equal(t, "4", merged.GetFinalAnswerContent())
+	assert.Equal(t, RecoveryCorrectiveTurn, merged.GetRecovery())
+	assert.Equal(t, 3*time.Second, merged.GetDuration())
+	assert.Equal(t, []RequestTiming{{Duration: time.Second}, {Duration: 2 * time.Second}}, merged.GetRequestTimings())
+	assert.Equal(t, []string{"first", "second"}, merged.GetPrompts())
+	assert.Equal(t, []string{"thinking"}, merged.GetReasoning())
+	assert.Len(t, merged.GetToolCalls(), 1)
+	assert.Equal(t, Usage{
+		InputTokens:          testutils.Ptr(int64(300)),
+		OutputTokens:         testutils.Ptr(int64(10)),
+		ReasoningTokens:      testutils.Ptr(int64(5)),
+		InputTokenAccounting: InputTokenAccountingCacheTokensIncluded,
+		ToolUsage: map[string]tools.ToolUsage{
+			"python": {CallCount: 3, TotalDurationNs: 12, PoolHits: 2, PoolMisses: 1, PoolSavedNs: 500},
+			"bash":   {CallCount: 1},
+		},
+	}, merged.GetUsage())
+	assert.Equal(t, int64(100), *previous.GetUsage().InputTokens, "previous result must not change")
+}
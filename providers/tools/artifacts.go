@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+
+	"github.com/petmal/mindtrial/pkg/logging"
+)
+
+// Artifact is a file collected from the sandbox of a tool after a call.
+type Artifact struct {
+	// Path is the absolute slash-separated path of the file in the sandbox.
+	Path string
+	// Bytes is the size of the file.
+	Bytes int64
+	// MediaType is the media type of the file, derived from its extension or content.
+	MediaType string
+	// Content is the content of the file, or nil if it would exceed the size limit.
+	Content []byte
+}
+
+// artifactCapture collects the artifacts of a call, keeping file contents until their
+// total size reaches the limit.
+type artifactCapture struct {
+	artifacts []Artifact
+	remaining int64
+}
+
+func newArtifactCapture(maxBytes int64) *artifactCapture {
+	return &artifactCapture{remaining: maxBytes}
+}
+
+// add records a file of the given size, reading its content from r if it fits the limit.
+func (c *artifactCapture) add(sandboxPath string, size int64, r io.Reader) error {
+	artifact := Artifact{Path: sandboxPath, Bytes: size, MediaType: mime.TypeByExtension(path.Ext(sandboxPath))}
+	if size <= c.remaining {
+		content, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return fmt.Errorf("failed to read artifact %q: %w", sandboxPath, err)
+		}
+		artifact.Content = content
+		c.remaining -= int64(len(content))
+		if artifact.MediaType == "" {
+			artifact.MediaType = http.DetectContentType(content)
+		}
+	}
+	if artifact.MediaType == "" {
+		artifact.MediaType = "application/octet-stream"
+	}
+	c.artifacts = append(c.artifacts, artifact)
+	return nil
+}
+
+// addArchive records the regular files in a tar archive of a path, as returned by Docker
+// for the path. Entry names are relative to the parent directory of the path.
+func (c *artifactCapture) addArchive(sandboxPath string, archive io.Reader) error {
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read artifact archive of %q: %w", sandboxPath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := c.add(path.Join(path.Dir(sandboxPath), header.Name), header.Size, reader); err != nil {
+			return err
+		}
+	}
+}
+
+// addHostPath records the regular files at a host path, being a file or a directory,
+// that is available in the sandbox at the given path.
+func (c *artifactCapture) addHostPath(hostPath string, sandboxPath string) error {
+	err := filepath.WalkDir(hostPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(hostPath, filePath)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return c.add(path.Join(sandboxPath, filepath.ToSlash(relPath)), info.Size(), file)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // the tool did not produce the artifact
+	}
+	return err
+}
+
+// sorted returns the collected artifacts in lexical order of their paths.
+func (c *artifactCapture) sorted() []Artifact {
+	slices.SortFunc(c.artifacts, func(a, b Artifact) int { return strings.Compare(a.Path, b.Path) })
+	return c.artifacts
+}
+
+// collectContainerArtifacts collects the artifacts declared by the tool from a container.
+// Collection failures are logged rather than failing the call, keeping the artifacts
+// collected so far.
+func collectContainerArtifacts(ctx context.Context, logger logging.Logger, cli *client.Client, containerID string, tool *SandboxTool) []Artifact {
+	if tool.artifacts == nil {
+		return nil
+	}
+	capture := newArtifactCapture(tool.artifacts.GetMaxBytes())
+	for _, artifactPath := range tool.artifacts.Paths {
+		archive, _, err := cli.CopyFromContainer(ctx, containerID, artifactPath)
+		if errdefs.IsNotFound(err) {
+			logger.Message(ctx, logging.LevelDebug, "artifact path %s does not exist in tool container %q", artifactPath, containerID)
+			continue
+		} else if err != nil {
+			logger.Error(ctx, logging.LevelWarn, err, "failed to copy artifact path %s from tool container %q", artifactPath, containerID)
+			continue
+		}
+		err = capture.addArchive(artifactPath, archive)
+		archive.Close()
+		if err != nil {
+			logger.Error(ctx, logging.LevelWarn, err, "failed to collect artifacts from tool container %q", containerID)
+		}
+	}
+	return logCollectedArtifacts(ctx, logger, capture.sorted())
+}
+
+// collectHostArtifacts collects the artifacts declared by the tool from the host directories
+// mapped into its sandbox. Artifact paths outside of the mapped directories are ignored.
+func collectHostArtifacts(ctx context.Context, logger logging.Logger, tool *SandboxTool, mappings []pathMapping) []Artifact {
+	if tool.artifacts == nil {
+		return nil
+	}
+	capture := newArtifactCapture(tool.artifacts.GetMaxBytes())
+	for _, artifactPath := range tool.artifacts.Paths {
+		hostPath, ok := hostPathOf(artifactPath, mappings)
+		if !ok {
+			logger.Message(ctx, logging.LevelWarn, "artifact path %s is not within a directory kept after the call and will be ignored", artifactPath)
+			continue
+		}
+		if err := capture.addHostPath(hostPath, artifactPath); err != nil {
+			logger.Error(ctx, logging.LevelWarn, err, "failed to collect artifacts from %s", artifactPath)
+		}
+	}
+	return logCollectedArtifacts(ctx, logger, capture.sorted())
+}
+
+// hostPathOf returns the host path of a sandbox path within the most specific of the mappings.
+func hostPathOf(sandboxPath string, mappings []pathMapping) (string, bool) {
+	var best *pathMapping
+	for i, mapping := range mappings {
+		if isWithinPath(sandboxPath, mapping.sandboxPath) && (best == nil || len(mapping.sandboxPath) > len(best.sandboxPath)) {
+			best = &mappings[i]
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(best.sandboxPath), filepath.FromSlash(sandboxPath))
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(best.hostPath, rel), true
+}
+
+// logCollectedArtifacts logs the collected artifacts, warning about those whose content was omitted.
+func logCollectedArtifacts(ctx context.Context, logger logging.Logger, artifacts []Artifact) []Artifact {
+	for _, artifact := range artifacts {
+		if artifact.Content == nil {
+			logger.Message(ctx, logging.LevelWarn, "artifact %s (%d bytes) exceeds the size limit and its content was omitted", artifact.Path, artifact.Bytes)
+		} else {
+			logger.Message(ctx, logging.LevelDebug, "collected artifact %s (%d bytes)", artifact.Path, artifact.Bytes)
+		}
+	}
+	return artifacts
+}
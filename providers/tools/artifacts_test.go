@@ -0,0 +1,110 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func TestDockerToolExecutorExecuteTool_Artifacts(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	tool := newTestTool("plot")
+	tool.artifacts = &config.ToolArtifactsConfig{Paths: []string{"/out", "/missing.txt"}, MaxBytes: testutils.Ptr(int64(10))}
+	executor.RegisterTool(tool)
+	configureSuccessfulExecution(t, mock, tool, "payload", `{"status":"ok"}`, nil)
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "out/", Mode: 0o755}))
+	for _, file := range []struct{ name, content string }{{"out/chart.png", "png"}, {"out/large.txt", "exceeds limit"}} {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: file.name, Mode: 0o644, Size: int64(len(file.content))}))
+		_, err := writer.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	mock.onArchiveGet = func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/out" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Could not find the file /missing.txt in container"}`))
+			return
+		}
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString([]byte(`{"name":"out","mode":2147484141}`)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(archive.Bytes())
+	}
+
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := executor.ExecuteTool(ctx, testutils.NewTestLogger(t), tool.name, json.RawMessage(`{"input":"payload"}`), nil, nil)
+	require.NoError(t, err)
+
+	call := onlyCall(t, executor, tool.name)
+	assert.Equal(t, []Artifact{
+		{Path: "/out/chart.png", Bytes: 3, MediaType: "image/png", Content: []byte("png")},
+		{Path: "/out/large.txt", Bytes: 13, MediaType: "text/plain; charset=utf-8"},
+	}, call.Artifacts)
+}
+
+func TestSubprocessToolExecutorExecuteTool_Artifacts(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	cfg := newTestSubprocessTool("plot", "sh", "-c", "mkdir -p out && printf 'data' > out/result && echo ok")
+	cfg.Session = &config.ToolSessionConfig{WorkspaceDir: "/workspace"}
+	cfg.Artifacts = &config.ToolArtifactsConfig{Paths: []string{"/workspace/out", "/workspace/missing.txt"}}
+	executor.RegisterTool(NewSandboxTool(cfg, nil, nil, nil, nil))
+	logger := testutils.NewTestLogger(t)
+	t.Cleanup(func() { executor.EndSessions(context.Background(), logger) })
+
+	_, err := executor.ExecuteTool(context.Background(), logger, "plot", json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+
+	summaries := executor.GetCallSummaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, []Artifact{
+		{Path: "/workspace/out/result", Bytes: 4, MediaType: "text/plain; charset=utf-8", Content: []byte("data")},
+	}, summaries[0].Artifacts)
+}
+
+func TestHostPathOf(t *testing.T) {
+	mappings := []pathMapping{
+		{hostPath: filepath.FromSlash("/tmp/session"), sandboxPath: "/workspace"},
+		{hostPath: filepath.FromSlash("/tmp/shared"), sandboxPath: "/workspace/shared"},
+	}
+	tests := []struct {
+		name        string
+		sandboxPath string
+		want        string
+		wantOK      bool
+	}{
+		{name: "mapped directory", sandboxPath: "/workspace", want: "/tmp/session", wantOK: true},
+		{name: "nested file", sandboxPath: "/workspace/out/chart.png", want: "/tmp/session/out/chart.png", wantOK: true},
+		{name: "most specific mapping", sandboxPath: "/workspace/shared/log.txt", want: "/tmp/shared/log.txt", wantOK: true},
+		{name: "unmapped path", sandboxPath: "/tmp/chart.png"},
+		{name: "sibling with common prefix", sandboxPath: "/workspace-other/chart.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := hostPathOf(tt.sandboxPath, mappings)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, filepath.FromSlash(tt.want), got)
+		})
+	}
+}
@@ -0,0 +1,368 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/build"
+	"github.com/docker/docker/errdefs"
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+// contentHashTagLength is the number of hexadecimal digits of the content hash used as an image tag.
+const contentHashTagLength = 16
+
+// dockerIgnoreFile is the name of the file in the root of the build context that lists the
+// paths excluded from it.
+const dockerIgnoreFile = ".dockerignore"
+
+// builtImageKey identifies the image built for the configured image reference from the
+// Dockerfile in the build context.
+func builtImageKey(image string, buildCfg *config.ToolBuildConfig) string {
+	return fmt.Sprintf("%s|%s|%s", buildCfg.Context, buildCfg.GetDockerfile(), image)
+}
+
+// imageOf returns the reference of the image to run a Docker tool with: the image built for it
+// if it declares a build and the image has been built, or its configured image otherwise.
+func (d *DockerToolExecutor) imageOf(tool *SandboxTool) string {
+	if tool.build != nil {
+		if image, ok := d.images.Load(builtImageKey(tool.image, tool.build)); ok {
+			return image.(string)
+		}
+	}
+	return tool.image
+}
+
+// buildMessage is a message of the JSON stream returned by the Docker image build API.
+type buildMessage struct {
+	Stream      string `json:"stream"`
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+// buildToolImage builds the image of a Docker tool from its build context and returns its
+// reference. The image is always built, relying on the build cache of Docker to make unchanged
+// builds cheap, unless it is tagged with the content hash of its build inputs and an image with
+// that tag already exists.
+func (d *DockerToolExecutor) buildToolImage(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) (string, error) {
+	buildCfg := cfg.Build
+	info, err := os.Stat(buildCfg.Context)
+	switch {
+	case err != nil:
+		return "", fmt.Errorf("%w: build context of tool %q cannot be read: %v", ErrToolNotAvailable, cfg.Name, err)
+	case !info.IsDir():
+		return "", fmt.Errorf("%w: build context %q of tool %q is not a directory", ErrToolNotAvailable, buildCfg.Context, cfg.Name)
+	}
+	if _, err := os.Stat(filepath.Join(buildCfg.Context, filepath.FromSlash(buildCfg.GetDockerfile()))); err != nil {
+		return "", fmt.Errorf("%w: dockerfile of tool %q cannot be read: %v", ErrToolNotAvailable, cfg.Name, err)
+	}
+	if _, err := readDockerIgnore(buildCfg.Context); err != nil {
+		return "", fmt.Errorf("%w: %s of tool %q cannot be read: %v", ErrToolNotAvailable, dockerIgnoreFile, cfg.Name, err)
+	}
+
+	image := cfg.Image
+	if buildCfg.ContentHashTag {
+		digest, err := hashBuildInputs(*buildCfg)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to hash build context of tool %q: %v", ErrToolInternal, cfg.Name, err)
+		}
+		image = imageRepository(cfg.Image) + ":" + digest[:contentHashTagLength]
+
+		// The tag identifies the build inputs, so an existing image was built from the same inputs.
+		if _, err := d.client.ImageInspect(ctx, image); err == nil {
+			logger.Message(ctx, logging.LevelDebug, "reusing docker image %q of tool %q", image, cfg.Name)
+			return image, nil
+		} else if !errdefs.IsNotFound(err) {
+			return "", fmt.Errorf("%w: failed to inspect docker image %q: %v", ErrToolInternal, image, err)
+		}
+	}
+
+	logger.Message(ctx, logging.LevelInfo, "building docker image %q of tool %q from %s", image, cfg.Name, buildCfg.Context)
+	buildContext, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeBuildContext(writer, *buildCfg))
+	}()
+	defer buildContext.Close()
+
+	buildArgs := make(map[string]*string, len(buildCfg.Args))
+	for name, value := range buildCfg.Args {
+		buildArgs[name] = &value
+	}
+	resp, err := d.client.ImageBuild(ctx, buildContext, build.ImageBuildOptions{
+		Tags:        []string{image},
+		Dockerfile:  buildCfg.GetDockerfile(),
+		BuildArgs:   buildArgs,
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to build docker image %q of tool %q: %v", ErrToolNotAvailable, image, cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if err := streamBuildLog(ctx, logger, resp.Body); err != nil {
+		return "", fmt.Errorf("%w: failed to build docker image %q of tool %q: %v", ErrToolNotAvailable, image, cfg.Name, err)
+	}
+	logger.Message(ctx, logging.LevelInfo, "built docker image %q of tool %q", image, cfg.Name)
+	return image, nil
+}
+
+// streamBuildLog logs the output of an image build at debug level as it arrives and returns
+// the error reported by the build, if any.
+func streamBuildLog(ctx context.Context, logger logging.Logger, body io.Reader) error {
+	decoder := json.NewDecoder(bufio.NewReader(body))
+	for {
+		var message buildMessage
+		if err := decoder.Decode(&message); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read build output: %v", err)
+		}
+		if message.ErrorDetail != nil && message.ErrorDetail.Message != "" {
+			return errors.New(message.ErrorDetail.Message)
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+		if line := strings.TrimRight(message.Stream, "\r\n"); strings.TrimSpace(line) != "" {
+			logger.Message(ctx, logging.LevelDebug, "build: %s", line)
+		}
+	}
+}
+
+// ignorePattern is a rule of a .dockerignore file.
+type ignorePattern struct {
+	// segments are the slash-separated elements of the cleaned pattern.
+	segments []string
+	// exclusion indicates a pattern prefixed with "!" that includes the matching paths again.
+	exclusion bool
+}
+
+// matches reports whether the pattern matches the path or one of its parent directories.
+func (p ignorePattern) matches(name string) bool {
+	segments := strings.Split(name, "/")
+	for i := 1; i <= len(segments); i++ {
+		if matchSegments(p.segments, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether the pattern segments match the path segments. A "**" segment
+// matches any number of path segments, the others match a single one as in path.Match.
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], segments[0])
+	return matched && matchSegments(pattern[1:], segments[1:])
+}
+
+// readDockerIgnore reads the rules of the .dockerignore file in the build context directory
+// in the order they are listed. Returns no rules if the file does not exist.
+func readDockerIgnore(contextDir string) ([]ignorePattern, error) {
+	content, err := os.ReadFile(filepath.Join(contextDir, dockerIgnoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var patterns []ignorePattern
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var pattern ignorePattern
+		if strings.HasPrefix(line, "!") {
+			pattern.exclusion = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = strings.TrimPrefix(path.Clean(line), "/")
+		if line == "" || line == "." {
+			continue
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", line, err)
+		}
+		pattern.segments = strings.Split(line, "/")
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isIgnored reports whether the path is excluded from the build context by the rules.
+// The last rule that matches the path decides.
+func isIgnored(patterns []ignorePattern, name string) bool {
+	ignored := false
+	for _, pattern := range patterns {
+		if pattern.matches(name) {
+			ignored = !pattern.exclusion
+		}
+	}
+	return ignored
+}
+
+// walkBuildContext calls fn for every entry of the build context directory in lexical order,
+// except the directory itself and the entries excluded by its .dockerignore file, with its
+// slash-separated path relative to the directory. Like in Docker, the Dockerfile and the
+// .dockerignore file are never excluded.
+func walkBuildContext(buildCfg config.ToolBuildConfig, fn func(name string, path string, entry fs.DirEntry) error) error {
+	patterns, err := readDockerIgnore(buildCfg.Context)
+	if err != nil {
+		return err
+	}
+	kept := []string{path.Clean(buildCfg.GetDockerfile()), dockerIgnoreFile}
+	hasExclusions := slices.ContainsFunc(patterns, func(pattern ignorePattern) bool {
+		return pattern.exclusion
+	})
+
+	return filepath.WalkDir(buildCfg.Context, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(buildCfg.Context, filePath)
+		if err != nil || name == "." {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if !slices.Contains(kept, name) && isIgnored(patterns, name) {
+			// Descend into an excluded directory only if some of its entries may be included again.
+			if entry.IsDir() && !hasExclusions && !slices.ContainsFunc(kept, func(keptName string) bool {
+				return strings.HasPrefix(keptName, name+"/")
+			}) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(name, filePath, entry)
+	})
+}
+
+// writeBuildContext writes the build context directory as a tar archive.
+func writeBuildContext(w io.Writer, buildCfg config.ToolBuildConfig) error {
+	archive := tar.NewWriter(w)
+	err := walkBuildContext(buildCfg, func(name string, path string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			return copyFile(archive, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// hashBuildInputs returns the hexadecimal SHA-256 hash of everything that determines the built
+// image: the contents of the build context not excluded by its .dockerignore file, the Dockerfile
+// path and the build arguments.
+func hashBuildInputs(buildCfg config.ToolBuildConfig) (string, error) {
+	digest := sha256.New()
+	fmt.Fprintf(digest, "dockerfile %q\n", buildCfg.GetDockerfile())
+	for _, name := range utils.SortedKeys(buildCfg.Args) {
+		fmt.Fprintf(digest, "arg %q=%q\n", name, buildCfg.Args[name])
+	}
+	err := walkBuildContext(buildCfg, func(name string, path string, entry fs.DirEntry) error {
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(digest, "entry %q %s\n", name, info.Mode())
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(digest, "link %q\n", link)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(digest, "size %d\n", info.Size())
+			return copyFile(digest, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// copyFile copies the contents of a file to the writer.
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// imageRepository returns the image reference without its tag or digest.
+func imageRepository(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+	return image
+}
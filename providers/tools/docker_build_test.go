@@ -0,0 +1,273 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func newTestBuildContext(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docker", "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker", "Dockerfile.tool"), []byte("FROM alpine\nCOPY src /src\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker", "src", "main.sh"), []byte("echo ok\n"), 0o755))
+	return dir
+}
+
+func newTestBuildTool(t *testing.T, contentHashTag bool) config.ToolConfig {
+	return config.ToolConfig{
+		Name:  "builder",
+		Image: "mindtrial/builder:latest",
+		Build: &config.ToolBuildConfig{
+			Context:        newTestBuildContext(t),
+			Dockerfile:     "docker/Dockerfile.tool",
+			Args:           map[string]string{"VERSION": "1.0"},
+			ContentHashTag: contentHashTag,
+		},
+	}
+}
+
+// archivedNames returns the names of the entries of a tar archive in their order.
+func archivedNames(t *testing.T, r io.Reader) []string {
+	var names []string
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return names
+		}
+		require.NoError(t, err)
+		names = append(names, header.Name)
+	}
+}
+
+func imageNotFound(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"message":"No such image"}`))
+}
+
+func TestDockerToolExecutorValidateTool_BuildImage(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	mock.onImageInspect = imageNotFound
+	var archived []string
+	mock.onBuild = func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "mindtrial/builder:latest", query.Get("t"))
+		assert.Equal(t, "docker/Dockerfile.tool", query.Get("dockerfile"))
+		assert.JSONEq(t, `{"VERSION":"1.0"}`, query.Get("buildargs"))
+
+		archived = archivedNames(t, r.Body)
+		_, _ = w.Write([]byte("{\"stream\":\"Step 1/2 : FROM alpine\\n\"}\n{\"stream\":\"Successfully built 0123456789ab\\n\"}\n"))
+	}
+
+	executor := newTestExecutor(t, mock)
+	cfg := newTestBuildTool(t, false)
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+
+	assert.Equal(t, []string{"docker/", "docker/Dockerfile.tool", "docker/src/", "docker/src/main.sh"}, archived)
+	assert.Equal(t, "mindtrial/builder:latest", executor.imageOf(NewSandboxTool(&cfg, nil, nil, nil, nil)))
+}
+
+func TestDockerToolExecutorValidateTool_BuildImageReused(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	var inspected string
+	mock.onImageInspect = func(w http.ResponseWriter, r *http.Request) {
+		inspected = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, mock.basePath()+"/images/"), "/json")
+		_, _ = w.Write([]byte(`{"Id":"sha256:test"}`))
+	}
+
+	executor := newTestExecutor(t, mock)
+	cfg := newTestBuildTool(t, true)
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+
+	digest, err := hashBuildInputs(*cfg.Build)
+	require.NoError(t, err)
+	want := "mindtrial/builder:" + digest[:contentHashTagLength]
+	assert.Equal(t, want, inspected)
+	tool := NewSandboxTool(&cfg, nil, nil, nil, nil)
+	executor.RegisterTool(tool)
+	assert.Equal(t, want, tool.image, "registered tools must run the built image")
+
+	other := newTestExecutor(t, mock)
+	assert.Equal(t, "mindtrial/builder:latest", other.imageOf(NewSandboxTool(&cfg, nil, nil, nil, nil)), "images built by another executor must not be used")
+}
+
+func TestDockerToolExecutorValidateTool_BuildImageRebuiltWithoutContentHashTag(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	builds := 0
+	mock.onBuild = func(w http.ResponseWriter, r *http.Request) {
+		builds++
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("{\"stream\":\"Successfully built 0123456789ab\\n\"}\n"))
+	}
+
+	executor := newTestExecutor(t, mock)
+	cfg := newTestBuildTool(t, false)
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+
+	assert.Equal(t, 2, builds, "an existing image without a content hash tag must not be reused")
+	assert.Equal(t, "mindtrial/builder:latest", executor.imageOf(NewSandboxTool(&cfg, nil, nil, nil, nil)))
+}
+
+func TestDockerToolExecutorValidateTool_BuildImageInvalidDockerIgnore(t *testing.T) {
+	executor := newTestExecutor(t, newDockerAPIMock(t))
+	cfg := newTestBuildTool(t, false)
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Build.Context, dockerIgnoreFile), []byte("[\n"), 0o644))
+
+	err := executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg)
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Contains(t, err.Error(), `.dockerignore of tool "builder" cannot be read: invalid pattern "["`)
+}
+
+func TestWriteBuildContext_DockerIgnore(t *testing.T) {
+	cfg := newTestBuildTool(t, true).Build
+	for name, content := range map[string]string{
+		"docker/src/keep.sh":   "echo keep\n",
+		"docker/src/debug.log": "debug\n",
+		"cache/tmp/data":       "data\n",
+		"notes/tmp/todo":       "todo\n",
+		".git/HEAD":            "ref: refs/heads/main\n",
+	} {
+		path := filepath.Join(cfg.Context, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Context, dockerIgnoreFile), []byte(
+		"# excluded from the image\n"+
+			".git\n"+
+			"/docker\n"+
+			"!docker/src/*.sh\n"+
+			"**/*.log\n"+
+			"**/tmp\n"+
+			".dockerignore\n",
+	), 0o644))
+
+	digest, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeBuildContext(writer, *cfg))
+	}()
+	assert.Equal(t, []string{
+		".dockerignore",
+		"cache/",
+		"docker/Dockerfile.tool",
+		"docker/src/keep.sh",
+		"docker/src/main.sh",
+		"notes/",
+	}, archivedNames(t, reader))
+
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Context, "docker", "src", "debug.log"), []byte("changed\n"), 0o644))
+	unchanged, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+	assert.Equal(t, digest, unchanged, "excluded files must not affect the content hash")
+}
+
+func TestReadDockerIgnore(t *testing.T) {
+	dir := t.TempDir()
+	patterns, err := readDockerIgnore(dir)
+	require.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, dockerIgnoreFile), []byte("# comment\n\n /build/ \n! build/keep\n**/*.tmp\n"), 0o644))
+	patterns, err = readDockerIgnore(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []ignorePattern{
+		{segments: []string{"build"}},
+		{segments: []string{"build", "keep"}, exclusion: true},
+		{segments: []string{"**", "*.tmp"}},
+	}, patterns)
+
+	tests := []struct {
+		name    string
+		ignored bool
+	}{
+		{name: "build", ignored: true},
+		{name: "build/out.bin", ignored: true},
+		{name: "build/keep", ignored: false},
+		{name: "build/keep/file", ignored: false},
+		{name: "src/build", ignored: false},
+		{name: "a.tmp", ignored: true},
+		{name: "src/nested/b.tmp", ignored: true},
+		{name: "src/main.go", ignored: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.ignored, isIgnored(patterns, tt.name))
+		})
+	}
+}
+
+func TestDockerToolExecutorValidateTool_BuildImageFailed(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	mock.onImageInspect = imageNotFound
+	mock.onBuild = func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte("{\"stream\":\"Step 1/2 : FROM alpine\\n\"}\n{\"errorDetail\":{\"message\":\"COPY failed: no source files\"},\"error\":\"COPY failed: no source files\"}\n"))
+	}
+
+	executor := newTestExecutor(t, mock)
+	cfg := newTestBuildTool(t, false)
+	err := executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg)
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.EqualError(t, err, `tool not available: failed to build docker image "mindtrial/builder:latest" of tool "builder": COPY failed: no source files`)
+	assert.Equal(t, "mindtrial/builder:latest", executor.imageOf(NewSandboxTool(&cfg, nil, nil, nil, nil)))
+
+	cfg.Build.Dockerfile = "missing/Dockerfile"
+	err = executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg)
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Contains(t, err.Error(), `dockerfile of tool "builder" cannot be read`)
+}
+
+func TestHashBuildInputs(t *testing.T) {
+	cfg := newTestBuildTool(t, true).Build
+	digest, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+
+	again, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+	assert.Equal(t, digest, again)
+
+	cfg.Args["VERSION"] = "2.0"
+	changedArgs, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, changedArgs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Context, "docker", "src", "main.sh"), []byte("echo changed\n"), 0o755))
+	changedContent, err := hashBuildInputs(*cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, changedArgs, changedContent)
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := map[string]string{
+		"python":                            "python",
+		"python:3.12":                       "python",
+		"registry.local:5000/tools/python":  "registry.local:5000/tools/python",
+		"registry.local:5000/tools/py:3.12": "registry.local:5000/tools/py",
+		"python@sha256:0123":                "python",
+	}
+	for image, want := range tests {
+		assert.Equal(t, want, imageRepository(image), image)
+	}
+}
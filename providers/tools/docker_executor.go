@@ -16,10 +16,8 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
-	"slices"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/containerd/errdefs"
@@ -35,47 +33,12 @@ import (
 
 // DockerToolExecutor executes tools within Docker containers.
 type DockerToolExecutor struct {
-	client        *client.Client
-	tools         sync.Map         // map[string]*DockerTool
-	usage         sync.Map         // map[string]*ToolUsage
-	calls         callSummaryState // shared log of every invocation attempt across all tools, in completion order
-	getSharedDir  func(context.Context, *DockerToolExecutor) (string, error)
-	sharedDirPath atomic.Pointer[string] // stores the actual shared directory path if created
-}
-
-// ToolUsage tracks aggregate execution statistics for a tool: CallCount and
-// TotalDurationNs only reflect invocations whose container actually started running
-// (regardless of exit code) - i.e. actual compute time spent, not every invocation
-// attempt. An invocation that fails before the container ever starts (e.g. invalid
-// arguments, or an infrastructure error during setup) does not affect these aggregates.
-// See ToolCallSummary/GetCallSummaries for a complete per-invocation log that does
-// include such attempts.
-type ToolUsage struct {
-	CallCount       int64
-	TotalDurationNs int64
-	Exhausted       int32
-}
-
-// callSummaryState holds the shared log of per-call summaries across all tools. A single
-// shared log preserves the true chronological order calls completed in, across all tools.
-type callSummaryState struct {
-	sync.Mutex
-	calls []ToolCallSummary
-}
-
-// record appends a per-call summary, synchronized internally.
-func (s *callSummaryState) record(summary ToolCallSummary) {
-	s.Lock()
-	defer s.Unlock()
-	s.calls = append(s.calls, summary)
-}
-
-// snapshot returns a deep copy of the recorded per-call summaries, synchronized internally,
-// so callers never share the backing array.
-func (s *callSummaryState) snapshot() []ToolCallSummary {
-	s.Lock()
-	defer s.Unlock()
-	return slices.Clone(s.calls)
+	executorState
+	client     *client.Client
+	sessions   sessionRegistry[*dockerSession]
+	pools      *containerPools
+	ownedPools bool      // the pools are closed with the executor, as they are not shared
+	images     *sync.Map // map[string]string of the images built for tools by builtImageKey
 }
 
 // maxCallOutputPreviewBytes caps the size of the Stdout/Stderr preview captured per tool call.
@@ -92,6 +55,7 @@ const (
 	toolCallStatusTimeout             = "timeout"
 	toolCallStatusInvalidArguments    = "invalid_arguments"
 	toolCallStatusInfrastructureError = "infrastructure_error"
+	toolCallStatusToolError           = "tool_error"
 )
 
 // OutputCapture holds a size-limited preview of a tool call's output stream.
@@ -116,6 +80,8 @@ type ToolCallSummary struct {
 	// ConversationTurn is the 1-based conversation turn this call was made during, or 0 if
 	// unknown/not provided by the caller.
 	ConversationTurn int
+	// Arguments are the raw JSON arguments the call was made with, as received from the model.
+	Arguments json.RawMessage
 	// StartedAt is when this call began (start of setup, before the container ever runs).
 	StartedAt time.Time
 	// CompletedAt is when this call finished, successfully or not.
@@ -129,18 +95,20 @@ type ToolCallSummary struct {
 	// through output retrieval - i.e. DurationNs plus setup/teardown overhead. Unlike
 	// DurationNs, this is always set, even for calls whose container never ran.
 	WallTimeNs int64
-	// ExitCode is the container's exit code, or nil if no exit code is known (e.g. setup never
+	// ExitCode is the exit code of the container or process, or nil if no exit code is known (e.g. setup never
 	// reached container start, or the run was aborted/cancelled before it could be observed).
 	ExitCode *int64
 	// TimedOut indicates the call was aborted due to exceeding its configured timeout.
 	TimedOut bool
 	// Status is one of: "success", "nonzero_exit", "empty_output", "timeout",
-	// "invalid_arguments", "infrastructure_error". Statuses are chosen to be meaningful for
+	// "invalid_arguments", "infrastructure_error", "tool_error". Statuses are chosen to be meaningful for
 	// result analysis: "nonzero_exit", "empty_output", and "invalid_arguments" reflect the
 	// tool being used incorrectly (a plausible model/tool-usage issue), while
 	// "infrastructure_error" covers environment/tooling failures (container/filesystem setup,
 	// Docker runtime errors including cancellation, and log retrieval failures) that the
 	// model has no influence over and are not informative about the model or tool under test.
+	// "tool_error" is reported when an MCP server returns an error result for the call, or an
+	// HTTP service responds with an error status or a response exceeding the size limit.
 	Status string
 	// Stdout is a size-limited capture of the call's standard output, or nil if no output was
 	// ever captured (e.g. an infrastructure_error before or during log retrieval).
@@ -150,46 +118,74 @@ type ToolCallSummary struct {
 	Stderr *OutputCapture
 	// ErrorMessage is a short explanation of the failure when Status is not "success".
 	ErrorMessage string
+	// Artifacts lists the files collected from the sandbox after the call, in lexical order
+	// of their paths, if the tool declares artifact paths (see config.ToolArtifactsConfig).
+	Artifacts []Artifact
 }
 
-// newSharedDirFactory creates a factory function that lazily creates a shared temporary directory.
-// The directory is created once on the first call and the same path is returned for all subsequent calls.
-func newSharedDirFactory() func(context.Context, *DockerToolExecutor) (string, error) {
-	return config.OnceWithContext(func(ctx context.Context, state *DockerToolExecutor) (sharedDir string, err error) {
-		sharedDir, err = os.MkdirTemp("", "mindtrial-tool-shared-*")
-		if err != nil {
-			return "", fmt.Errorf("failed to create shared temporary directory: %w", err)
-		}
-		state.sharedDirPath.Store(&sharedDir)
-		return
-	})
-}
-
-// NewDockerToolExecutor creates a new Docker tool executor.
+// NewDockerToolExecutor creates a new Docker tool executor. It uses the container pools and
+// built images of the ExecutorResources attached to ctx, if any, or its own otherwise.
 func NewDockerToolExecutor(ctx context.Context) (*DockerToolExecutor, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	cli, err := newDockerClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
 
-	return &DockerToolExecutor{
-		client:       cli,
-		tools:        sync.Map{},
-		getSharedDir: newSharedDirFactory(),
-	}, nil
+	executor := &DockerToolExecutor{client: cli}
+	if resources, ok := executorResourcesFrom(ctx); ok {
+		executor.pools, executor.images = resources.pools, resources.images
+	} else {
+		executor.pools, executor.ownedPools = newContainerPools(newDockerClient), true
+		executor.images = &sync.Map{}
+	}
+	executor.getSharedDir = newSharedDirFactory()
+	return executor, nil
 }
 
-// RegisterTool registers a tool with the executor.
-func (d *DockerToolExecutor) RegisterTool(tool *DockerTool) {
-	d.tools.Store(tool.name, tool)
+func newDockerClient() (*client.Client, error) {
+	return client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
 }
 
-// ValidateTool ensures the Docker image referenced by the tool configuration is available locally.
-func (d *DockerToolExecutor) ValidateTool(ctx context.Context, cfg config.ToolConfig) error {
+// RegisterTool registers a tool with the executor, starting to warm up its container pool
+// if it has one. A tool that declares a build runs the image built while validating it.
+func (d *DockerToolExecutor) RegisterTool(tool *SandboxTool) {
+	tool.image = d.imageOf(tool)
+	d.executorState.RegisterTool(tool)
+	if tool.pool != nil && tool.session == nil {
+		d.pools.warm(tool)
+	}
+}
+
+// ValidateTool ensures the Docker image referenced by the tool configuration is available locally,
+// building it first if the tool declares a build and the image does not exist yet. For MCP tools, it ensures the command launching the MCP server, if any, can be found instead,
+// for mock tools, that their responses are configured, and for HTTP tools, that their service
+// is configured and allowed.
+func (d *DockerToolExecutor) ValidateTool(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) error {
+	switch cfg.GetType() {
+	case config.ToolTypeMCP:
+		return validateMCPTool(cfg)
+	case config.ToolTypeMock:
+		return validateMockTool(cfg)
+	case config.ToolTypeHTTP:
+		return validateHTTPTool(cfg)
+	case config.ToolTypeDocker:
+	default:
+		return fmt.Errorf("%w: %w: %s tool %q cannot be run in a docker container", ErrToolInternal, ErrUnsupportedToolType, cfg.GetType(), cfg.Name)
+	}
+
 	if cfg.Image == "" {
 		return fmt.Errorf("%w: docker image is not configured for tool %q", ErrToolInternal, cfg.Name)
 	}
 
+	if cfg.Build != nil {
+		image, err := d.buildToolImage(ctx, logger, cfg)
+		if err != nil {
+			return err
+		}
+		d.images.Store(builtImageKey(cfg.Image, cfg.Build), image)
+		return nil
+	}
+
 	if _, err := d.client.ImageInspect(ctx, cfg.Image); err != nil {
 		switch {
 		case errdefs.IsNotFound(err):
@@ -202,82 +198,53 @@ func (d *DockerToolExecutor) ValidateTool(ctx context.Context, cfg config.ToolCo
 	return nil
 }
 
-// ToolCallContext carries optional caller-supplied metadata to attach to the
-// ToolCallSummary recorded for a single ExecuteTool call. All fields are optional; a nil
-// ToolCallContext (or a zero-value one) simply leaves the corresponding ToolCallSummary
-// fields unset. New fields can be added here in the future without changing ExecuteTool's
-// signature again.
-type ToolCallContext struct {
-	// CallID is the provider's own identifier for this tool call, if the provider's API
-	// assigns one (e.g. OpenAI/Anthropic/DeepSeek/Mistral AI/xAI's tool_call/tool_use ID).
-	// Reusing the provider's own ID - rather than minting an unrelated one - means this same
-	// ID can also be found in any API error message that references the call. If empty,
-	// ExecuteTool generates one internally (a ULID) instead, so ToolCallSummary.CallID is
-	// never empty; note this means CallID's shape/format varies depending on whether - and
-	// how - the calling provider assigns its own IDs.
-	CallID string
-	// ConversationTurn is the 1-based conversation turn this call is being made during, or 0
-	// if unknown/not applicable.
-	ConversationTurn int
-}
-
 // ExecuteTool executes a tool by name with the given arguments and auxiliary data files.
 // callCtx carries optional caller-supplied metadata (see ToolCallContext) to attach to the
 // resulting ToolCallSummary; pass nil if there is none to provide.
 func (d *DockerToolExecutor) ExecuteTool(ctx context.Context, logger logging.Logger, toolName string, args json.RawMessage, data map[string][]byte, callCtx *ToolCallContext) (json.RawMessage, error) {
-	toolValue, exists := d.tools.Load(toolName)
-	if !exists {
-		return nil, fmt.Errorf("%w: %s", ErrToolNotAvailable, toolName)
-	}
-
-	tool, ok := toolValue.(*DockerTool)
-	if !ok {
-		return nil, fmt.Errorf("tool %q encountered an error: %w: %w: %T", toolName, ErrToolInternal, ErrUnsupportedToolType, toolValue)
-	}
-
-	// Check MaxCalls limit.
-	// NOTE: The current execution model creates a new DockerToolExecutor per provider Run,
-	// so ExecuteTool is not called concurrently on this executor instance today.
-	// If this ever changes (shared executor across concurrent calls), reserve a call slot
-	// atomically before execution to avoid check-then-act races.
-	if tool.maxCalls != nil {
-		usageValue, _ := d.usage.LoadOrStore(toolName, &ToolUsage{})
-		usage := usageValue.(*ToolUsage)
-		callCount := atomic.LoadInt64(&usage.CallCount)
-		if callCount >= int64(*tool.maxCalls) {
-			atomic.StoreInt32(&usage.Exhausted, 1)
-			return nil, fmt.Errorf("%w: tool %q has exceeded its maximum call limit of %d for this session. Do not call this tool again during the current conversation", ErrToolMaxCallsExceeded, toolName, *tool.maxCalls)
-		}
-	}
+	return d.executeTool(ctx, logger, toolName, args, data, callCtx, d.executeDockerTool)
+}
 
-	// Assign an ID to this call so it can be correlated between the log and the recorded
-	// ToolCallSummary - preferring the caller-supplied ID (see ToolCallContext.CallID) so
-	// the same ID can also be matched against the provider's own API error messages, and
-	// falling back to a freshly generated ULID when the caller did not supply one. Create a
-	// logger that includes it alongside the tool name.
-	callID := ""
-	if callCtx != nil {
-		callID = callCtx.CallID
+// IsToolExhausted reports whether the named tool has exceeded its maximum call limit.
+// Returns false if the executor is nil or the tool has not been used.
+func (d *DockerToolExecutor) IsToolExhausted(toolName string) bool {
+	if d == nil {
+		return false
 	}
-	if callID == "" {
-		callID = ulid.Make().String()
+	return d.isToolExhausted(toolName)
+}
+
+// GetUsageStats returns aggregate execution statistics for all tools.
+// Returns nil if the executor is nil.
+func (d *DockerToolExecutor) GetUsageStats() map[string]ToolUsage {
+	if d == nil {
+		return nil
 	}
-	toolLogger := logger.WithContext(fmt.Sprintf("%s[%s]: ", toolName, callID))
+	return d.usageStats()
+}
 
-	// Execute the tool.
-	result, err := d.executeDockerTool(ctx, toolLogger, tool, args, data, callID, callCtx)
-	if err != nil {
-		return nil, fmt.Errorf("tool %q encountered an error: %w", toolName, err)
+// GetCallSummaries returns a log of every recorded invocation attempt across all tools, in
+// the order calls completed, including attempts that never actually ran (e.g. due to
+// invalid arguments or an infrastructure error during setup). This is tracked
+// independently of GetUsageStats; a tool with no entry in GetUsageStats (because its
+// container never once ran) can still appear here. Returns nil if the executor is nil.
+func (d *DockerToolExecutor) GetCallSummaries() []ToolCallSummary {
+	if d == nil {
+		return nil
 	}
-	return result, nil
+	return d.callSummaries()
 }
 
-// Close closes the Docker client connection and cleans up shared directories.
+// Close removes the containers of any tool sessions and of its own container pools, stops any
+// MCP servers, closes the Docker client connection and cleans up shared directories.
 func (d *DockerToolExecutor) Close() error {
-	// Clean up shared directory if it was created.
-	if sharedDirPtr := d.sharedDirPath.Load(); sharedDirPtr != nil {
-		defer os.RemoveAll(*sharedDirPtr)
+	if d.client != nil {
+		d.endSessions(context.Background(), nil, false)
 	}
+	if d.ownedPools {
+		d.pools.close(context.Background())
+	}
+	d.close()
 
 	if d.client != nil {
 		return d.client.Close()
@@ -322,9 +289,9 @@ func newOutputCapture(content string, includePreview bool) *OutputCapture {
 // executeDockerTool executes a Docker tool with the given arguments and auxiliary data
 // files. callID uniquely identifies this call (see ExecuteTool) and callCtx carries
 // optional caller-supplied metadata (see ToolCallContext); callCtx may be nil.
-func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger logging.Logger, tool *DockerTool, args json.RawMessage, data map[string][]byte, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
+func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger logging.Logger, tool *SandboxTool, args json.RawMessage, data map[string][]byte, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
 	startTime := time.Now()
-	summary := ToolCallSummary{CallID: callID, StartedAt: startTime}
+	summary := ToolCallSummary{CallID: callID, StartedAt: startTime, Arguments: args}
 	if callCtx != nil {
 		summary.ConversationTurn = callCtx.ConversationTurn
 	}
@@ -351,6 +318,13 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 	}
 	logger.Message(ctx, logging.LevelTrace, "parsed input arguments: %v", argMap)
 
+	switch {
+	case tool.session != nil:
+		return d.executeDockerSessionCall(ctx, logger, tool, argMap, data, &summary)
+	case tool.pool != nil:
+		return d.executeDockerPooledCall(ctx, logger, tool, argMap, data, &summary)
+	}
+
 	// Create a temporary directory for file mappings.
 	tempDir, err := os.MkdirTemp("", "mindtrial-tool-*")
 	if err != nil {
@@ -366,20 +340,12 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 	for argName, containerPath := range tool.parameterFiles {
 		if argValue, exists := argMap[argName]; exists {
 			// Convert argument value to string.
-			var content string
-			switch v := argValue.(type) {
-			case string:
-				content = v
-			default:
-				// For non-string values, marshal back to JSON.
-				contentBytes, err := json.Marshal(v)
-				if err != nil {
-					logger.Error(ctx, logging.LevelError, err, "failed to marshal argument %q to JSON: %v", argName, argValue)
-					wrapErr := fmt.Errorf("%w: failed to serialize argument %q to JSON (argument values must be JSON-serializable): %v", ErrInvalidToolArguments, argName, err)
-					summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
-					return nil, wrapErr
-				}
-				content = string(contentBytes)
+			content, err := argumentFileContent(argValue)
+			if err != nil {
+				logger.Error(ctx, logging.LevelError, err, "failed to marshal argument %q to JSON: %v", argName, argValue)
+				wrapErr := fmt.Errorf("%w: failed to serialize argument %q to JSON (argument values must be JSON-serializable): %v", ErrInvalidToolArguments, argName, err)
+				summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+				return nil, wrapErr
 			}
 
 			// Create a unique temporary file for this mapping.
@@ -429,7 +395,7 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 
 	// Mount shared directory if configured.
 	if tool.sharedDir != "" {
-		sharedTempDir, err := d.getSharedDir(ctx, d)
+		sharedTempDir, err := d.getSharedDir(ctx, &d.executorState)
 		if err != nil {
 			wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
 			summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
@@ -446,11 +412,7 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 	}
 
 	// Prepare environment variables.
-	env := make([]string, 0, len(tool.env))
-	// Add tool-specific environment
-	for k, v := range tool.env {
-		env = append(env, fmt.Sprintf("%s=%s", k, v))
-	}
+	env := containerEnv(tool.env)
 	logger.Message(ctx, logging.LevelTrace, "setting environment variables: %v", env)
 
 	// Create container configuration.
@@ -474,19 +436,7 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 	}
 
 	// Set resource limits.
-	if tool.maxMemoryMB != nil {
-		// Convert MB to bytes
-		hostConfig.Memory = int64(*tool.maxMemoryMB) * 1024 * 1024
-		logger.Message(ctx, logging.LevelTrace, "setting memory limit to %d MB (%d bytes)", *tool.maxMemoryMB, hostConfig.Memory)
-	}
-	if tool.cpuPercent != nil {
-		// Convert CPU percentage to NanoCPU units.
-		// NanoCPUs = (numCPUs * percent / 100) * 1e9
-		numCPUs := runtime.NumCPU()
-		nanoCPUs := int64(numCPUs) * int64(*tool.cpuPercent) * 10000000 // 1e9 / 100 = 1e7
-		hostConfig.NanoCPUs = nanoCPUs
-		logger.Message(ctx, logging.LevelTrace, "setting CPU limit to %d%% (%d NanoCPUs, %d CPUs total)", *tool.cpuPercent, nanoCPUs, numCPUs)
-	}
+	setResourceLimits(ctx, logger, tool, hostConfig)
 
 	// Generate a unique container name.
 	containerName := fmt.Sprintf("%s-tool-%s", tool.name, ulid.Make().String())
@@ -546,6 +496,7 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 
 	logger.Message(ctx, logging.LevelDebug, "tool container %q exited with code %d in %v", createResp.ID, status.StatusCode, runDuration)
 	summary.ExitCode = &status.StatusCode
+	summary.Artifacts = collectContainerArtifacts(ctx, logger, d.client, createResp.ID, tool)
 
 	if status.StatusCode != 0 {
 		summary.Status = toolCallStatusNonZeroExit
@@ -595,13 +546,13 @@ func (d *DockerToolExecutor) executeDockerTool(ctx context.Context, logger loggi
 // outcome, so every return path (success or failure) is guaranteed exactly one entry: at
 // LevelError for infrastructure failures and timeouts (environment/tooling problems, not
 // attributable to the model or tool), LevelWarn for statuses reflecting apparent tool
-// misuse (nonzero_exit, empty_output, invalid_arguments), and LevelInfo for success.
+// misuse (nonzero_exit, empty_output, invalid_arguments, tool_error), and LevelInfo for success.
 func logCallOutcome(ctx context.Context, logger logging.Logger, summary ToolCallSummary) {
 	level := logging.LevelInfo
 	switch summary.Status {
 	case toolCallStatusInfrastructureError, toolCallStatusTimeout:
 		level = logging.LevelError
-	case toolCallStatusNonZeroExit, toolCallStatusEmptyOutput, toolCallStatusInvalidArguments:
+	case toolCallStatusNonZeroExit, toolCallStatusEmptyOutput, toolCallStatusInvalidArguments, toolCallStatusToolError:
 		level = logging.LevelWarn
 	}
 	if summary.ErrorMessage != "" {
@@ -611,6 +562,19 @@ func logCallOutcome(ctx context.Context, logger logging.Logger, summary ToolCall
 	logger.Message(ctx, level, "call finished: status=%s wall_time=%s", summary.Status, time.Duration(summary.WallTimeNs))
 }
 
+// argumentFileContent converts a tool argument value to the content of its parameter file:
+// strings are written as is, while other values are marshaled back to JSON.
+func argumentFileContent(value interface{}) (string, error) {
+	if text, ok := value.(string); ok {
+		return text, nil
+	}
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
 // TextOrData is a constraint for types that can be written to files.
 type TextOrData interface {
 	~string | ~[]byte
@@ -638,6 +602,40 @@ func writeTempFile[T TextOrData](tempDir string, prefix string, content T) (stri
 	return tempFile.Name(), nil
 }
 
+// containerEnv formats the environment variables of a tool container.
+func containerEnv(vars map[string]string) []string {
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, fmt.Sprintf("%s=%s", k, v))
+	}
+	return env
+}
+
+// setResourceLimits applies the memory and CPU limits of the tool to the container.
+func setResourceLimits(ctx context.Context, logger logging.Logger, tool *SandboxTool, hostConfig *container.HostConfig) {
+	applyResourceLimits(tool, hostConfig)
+	if tool.maxMemoryMB != nil {
+		logger.Message(ctx, logging.LevelTrace, "setting memory limit to %d MB (%d bytes)", *tool.maxMemoryMB, hostConfig.Memory)
+	}
+	if tool.cpuPercent != nil {
+		logger.Message(ctx, logging.LevelTrace, "setting CPU limit to %d%% (%d NanoCPUs, %d CPUs total)", *tool.cpuPercent, hostConfig.NanoCPUs, runtime.NumCPU())
+	}
+}
+
+// applyResourceLimits sets the memory and CPU limits of the tool in the host configuration.
+func applyResourceLimits(tool *SandboxTool, hostConfig *container.HostConfig) {
+	if tool.maxMemoryMB != nil {
+		// Convert MB to bytes
+		hostConfig.Memory = int64(*tool.maxMemoryMB) * 1024 * 1024
+	}
+	if tool.cpuPercent != nil {
+		// Convert CPU percentage to NanoCPU units.
+		// NanoCPUs = (numCPUs * percent / 100) * 1e9
+		numCPUs := runtime.NumCPU()
+		hostConfig.NanoCPUs = int64(numCPUs) * int64(*tool.cpuPercent) * 10000000 // 1e9 / 100 = 1e7
+	}
+}
+
 // runContainer starts a container and waits for it to complete, returning the final status.
 func (d *DockerToolExecutor) runContainer(ctx context.Context, containerID string) (status container.WaitResponse, err error) {
 	// Start the container.
@@ -660,105 +658,3 @@ func (d *DockerToolExecutor) runContainer(ctx context.Context, containerID strin
 
 	return status, nil
 }
-
-// recordUsage records the aggregate execution statistics for a tool.
-func (d *DockerToolExecutor) recordUsage(toolName string, duration time.Duration) {
-	usageValue, _ := d.usage.LoadOrStore(toolName, &ToolUsage{})
-	toolUsage := usageValue.(*ToolUsage)
-
-	atomic.AddInt64(&toolUsage.CallCount, 1)
-	atomic.AddInt64(&toolUsage.TotalDurationNs, duration.Nanoseconds())
-}
-
-// recordCallSummary appends a per-call summary to the shared call log, tagging it with
-// the tool name.
-func (d *DockerToolExecutor) recordCallSummary(toolName string, summary ToolCallSummary) {
-	summary.Tool = toolName
-	d.calls.record(summary)
-}
-
-// IsToolExhausted reports whether the named tool has exceeded its maximum call limit.
-// Returns false if the executor is nil or the tool has not been used.
-func (d *DockerToolExecutor) IsToolExhausted(toolName string) bool {
-	if d == nil {
-		return false
-	}
-	usageValue, ok := d.usage.Load(toolName)
-	if !ok {
-		return false
-	}
-	return atomic.LoadInt32(&usageValue.(*ToolUsage).Exhausted) != 0
-}
-
-// GetUsageStats returns aggregate execution statistics for all tools.
-func (d *DockerToolExecutor) GetUsageStats() map[string]ToolUsage {
-	if d == nil {
-		return nil
-	}
-	stats := make(map[string]ToolUsage)
-	d.usage.Range(func(key, value interface{}) bool {
-		toolName := key.(string)
-		usage := value.(*ToolUsage)
-		stats[toolName] = ToolUsage{
-			CallCount:       atomic.LoadInt64(&usage.CallCount),
-			TotalDurationNs: atomic.LoadInt64(&usage.TotalDurationNs),
-			Exhausted:       atomic.LoadInt32(&usage.Exhausted),
-		}
-		return true
-	})
-	return stats
-}
-
-// GetCallSummaries returns a log of every recorded invocation attempt across all tools, in
-// the order calls completed, including attempts that never actually ran (e.g. due to
-// invalid arguments or an infrastructure error during setup). This is tracked
-// independently of GetUsageStats; a tool with no entry in GetUsageStats (because its
-// container never once ran) can still appear here. Returns nil if the executor is nil.
-func (d *DockerToolExecutor) GetCallSummaries() []ToolCallSummary {
-	if d == nil {
-		return nil
-	}
-	return d.calls.snapshot()
-}
-
-type DockerTool struct {
-	name           string
-	image          string
-	description    string
-	parameters     map[string]interface{}
-	parameterFiles map[string]string
-	auxiliaryDir   string
-	sharedDir      string
-	command        []string
-	env            map[string]string
-	maxCalls       *int
-	timeout        *time.Duration
-	maxMemoryMB    *int
-	cpuPercent     *int
-}
-
-// NewDockerTool creates a new Docker tool.
-func NewDockerTool(cfg *config.ToolConfig, maxCalls *int, timeout *time.Duration, maxMemoryMB *int, cpuPercent *int) *DockerTool {
-	return &DockerTool{
-		name:           cfg.Name,
-		image:          cfg.Image,
-		description:    cfg.Description,
-		parameters:     cfg.Parameters,
-		parameterFiles: cfg.ParameterFiles,
-		auxiliaryDir:   cfg.AuxiliaryDir,
-		sharedDir:      cfg.SharedDir,
-		command:        cfg.Command,
-		env:            cfg.Env,
-		maxCalls:       maxCalls,
-		timeout:        timeout,
-		maxMemoryMB:    maxMemoryMB,
-		cpuPercent:     cpuPercent,
-	}
-}
-
-func (t *DockerTool) getTimeoutValue() string {
-	if t.timeout != nil {
-		return fmt.Sprintf("%v", *t.timeout)
-	}
-	return "<none>"
-}
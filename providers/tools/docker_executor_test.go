@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,6 +45,13 @@ type dockerAPIMock struct {
 	onWait         func(http.ResponseWriter, *http.Request)
 	onLogs         func(http.ResponseWriter, *http.Request)
 	onRemove       func(http.ResponseWriter, *http.Request)
+	onExecCreate   func(http.ResponseWriter, *http.Request)
+	onExecStart    func(http.ResponseWriter, *http.Request)
+	onExecInspect  func(http.ResponseWriter, *http.Request)
+	onArchivePut   func(http.ResponseWriter, *http.Request)
+	onArchiveGet   func(http.ResponseWriter, *http.Request)
+	onInspect      func(http.ResponseWriter, *http.Request)
+	onBuild        func(http.ResponseWriter, *http.Request)
 }
 
 func newDockerAPIMock(t *testing.T) *dockerAPIMock {
@@ -93,6 +101,14 @@ func (m *dockerAPIMock) handle(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if r.Method == http.MethodPost && path == m.basePath()+"/build" {
+		if m.onBuild == nil {
+			m.t.Fatalf("unexpected ImageBuild call without handler: %s", path)
+		}
+		m.onBuild(w, r)
+		return
+	}
+
 	if strings.HasPrefix(path, m.basePath()+"/containers") {
 		trimmed := strings.TrimPrefix(path, m.basePath()+"/containers")
 		switch {
@@ -120,6 +136,30 @@ func (m *dockerAPIMock) handle(w http.ResponseWriter, r *http.Request) {
 			}
 			m.onLogs(w, r)
 			return
+		case r.Method == http.MethodPost && strings.HasSuffix(trimmed, "/exec"):
+			if m.onExecCreate == nil {
+				m.t.Fatalf("unexpected ContainerExecCreate call without handler: %s", path)
+			}
+			m.onExecCreate(w, r)
+			return
+		case r.Method == http.MethodPut && strings.HasSuffix(trimmed, "/archive"):
+			if m.onArchivePut == nil {
+				m.t.Fatalf("unexpected CopyToContainer call without handler: %s", path)
+			}
+			m.onArchivePut(w, r)
+			return
+		case r.Method == http.MethodGet && strings.HasSuffix(trimmed, "/archive"):
+			if m.onArchiveGet == nil {
+				m.t.Fatalf("unexpected CopyFromContainer call without handler: %s", path)
+			}
+			m.onArchiveGet(w, r)
+			return
+		case r.Method == http.MethodGet && strings.HasSuffix(trimmed, "/json"):
+			if m.onInspect == nil {
+				m.t.Fatalf("unexpected ContainerInspect call without handler: %s", path)
+			}
+			m.onInspect(w, r)
+			return
 		case r.Method == http.MethodDelete:
 			if m.onRemove == nil {
 				m.t.Fatalf("unexpected ContainerRemove call without handler: %s", path)
@@ -129,6 +169,24 @@ func (m *dockerAPIMock) handle(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if strings.HasPrefix(path, m.basePath()+"/exec") {
+		trimmed := strings.TrimPrefix(path, m.basePath()+"/exec")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(trimmed, "/start"):
+			if m.onExecStart == nil {
+				m.t.Fatalf("unexpected ContainerExecAttach call without handler: %s", path)
+			}
+			m.onExecStart(w, r)
+			return
+		case r.Method == http.MethodGet && strings.HasSuffix(trimmed, "/json"):
+			if m.onExecInspect == nil {
+				m.t.Fatalf("unexpected ContainerExecInspect call without handler: %s", path)
+			}
+			m.onExecInspect(w, r)
+			return
+		}
+	}
+
 	m.t.Fatalf("unexpected request: %s %s", r.Method, path)
 }
 
@@ -181,18 +239,16 @@ func newTestExecutor(t *testing.T, mock *dockerAPIMock) *DockerToolExecutor {
 
 	cli.NegotiateAPIVersion(context.Background())
 
-	executor := &DockerToolExecutor{
-		client:       cli,
-		getSharedDir: newSharedDirFactory(),
-	}
+	executor := &DockerToolExecutor{client: cli, images: &sync.Map{}}
+	executor.getSharedDir = newSharedDirFactory()
 	t.Cleanup(func() {
 		_ = executor.Close()
 	})
 	return executor
 }
 
-func newTestTool(name string) *DockerTool {
-	return &DockerTool{
+func newTestTool(name string) *SandboxTool {
+	return &SandboxTool{
 		name:           name,
 		image:          "alpine:latest",
 		command:        []string{"/bin/echo"},
@@ -211,7 +267,7 @@ func TestDockerToolExecutorValidateTool_ImageAvailable(t *testing.T) {
 	executor := newTestExecutor(t, mock)
 	cfg := config.ToolConfig{Name: "echo", Image: "alpine:latest"}
 
-	require.NoError(t, executor.ValidateTool(context.Background(), cfg))
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
 }
 
 func TestDockerToolExecutorValidateTool_ImageMissing(t *testing.T) {
@@ -224,7 +280,7 @@ func TestDockerToolExecutorValidateTool_ImageMissing(t *testing.T) {
 	executor := newTestExecutor(t, mock)
 	cfg := config.ToolConfig{Name: "echo", Image: "missing:latest"}
 
-	err := executor.ValidateTool(context.Background(), cfg)
+	err := executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg)
 	require.Error(t, err)
 	assert.EqualError(t, err, "tool not available: docker image \"missing:latest\" is not available locally. Pull the image with `docker pull missing:latest` and try again")
 }
@@ -239,7 +295,7 @@ func TestDockerToolExecutorValidateTool_ImageInspectError(t *testing.T) {
 	executor := newTestExecutor(t, mock)
 	cfg := config.ToolConfig{Name: "echo", Image: "test:latest"}
 
-	err := executor.ValidateTool(context.Background(), cfg)
+	err := executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "tool internal error: failed to inspect docker image \"test:latest\"")
 }
@@ -268,7 +324,7 @@ func callsFor(executor *DockerToolExecutor, toolName string) []ToolCallSummary {
 	return toolCalls
 }
 
-func configureSuccessfulExecution(t *testing.T, mock *dockerAPIMock, tool *DockerTool, expectedFileContent, logOutput string, expectedAuxiliaryFiles map[string][]byte) func() string {
+func configureSuccessfulExecution(t *testing.T, mock *dockerAPIMock, tool *SandboxTool, expectedFileContent, logOutput string, expectedAuxiliaryFiles map[string][]byte) func() string {
 	var mountedFile string
 
 	mock.onCreate = func(w http.ResponseWriter, r *http.Request) {
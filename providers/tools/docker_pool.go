@@ -0,0 +1,362 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/oklog/ulid/v2"
+	"github.com/petmal/mindtrial/pkg/logging"
+)
+
+// poolHealthCheckTimeout limits the time the health check command of a pool may take.
+const poolHealthCheckTimeout = 30 * time.Second
+
+// ContainerPoolStats summarizes the use of a pool of pre-started tool containers.
+type ContainerPoolStats struct {
+	// Image is the Docker image of the pooled containers.
+	Image string
+	// Hits is the number of calls that used a pre-started container.
+	Hits int64
+	// Misses is the number of calls that had to start a container because none was ready.
+	Misses int64
+	// SavedNs estimates the latency saved by the hits as the startup time of the
+	// containers they used, in nanoseconds.
+	SavedNs int64
+}
+
+// containerPools manages the pools of pre-started containers, keyed by the image, resource
+// limits and commands of the pooled containers.
+type containerPools struct {
+	mu         sync.Mutex
+	newClient  func() (*client.Client, error)
+	client     *client.Client // created with the first pool
+	pools      map[string]*containerPool
+	ctx        context.Context // cancelled when the pools are closed, stopping any warm-ups
+	cancel     context.CancelFunc
+	background sync.WaitGroup // pending warm-ups and removals
+}
+
+func newContainerPools(newClient func() (*client.Client, error)) *containerPools {
+	pools := &containerPools{newClient: newClient}
+	pools.ctx, pools.cancel = context.WithCancel(context.Background())
+	return pools
+}
+
+// containerPool keeps a number of pre-started containers of the same configuration ready.
+type containerPool struct {
+	mu          sync.Mutex
+	image       string
+	config      container.Config     // copied for each container, as the client modifies it
+	hostConfig  container.HostConfig // copied for each container, as the client modifies it
+	healthCheck []string
+	size        int
+	idle        []pooledContainer // in order of their startup
+	warming     int               // containers being started
+	closed      bool
+	hits        int64
+	misses      int64
+	savedNs     int64
+}
+
+// pooledContainer is a running container waiting in a pool.
+type pooledContainer struct {
+	id      string
+	startup time.Duration // time it took to create and start the container
+}
+
+// getPool returns the pool for the containers of the tool, creating it if it does not exist.
+func (p *containerPools) getPool(tool *SandboxTool) (*client.Client, *containerPool, error) {
+	containerConfig := container.Config{
+		Image:      tool.image,
+		Entrypoint: tool.pool.GetKeepAliveCommand(),
+	}
+	hostConfig := container.HostConfig{
+		NetworkMode:   network.NetworkNone,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyDisabled},
+	}
+	applyResourceLimits(tool, &hostConfig)
+	key := fmt.Sprintf("%s|%q|%q|%d|%d", tool.image, containerConfig.Entrypoint, tool.pool.HealthCheckCommand, hostConfig.Memory, hostConfig.NanoCPUs)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		cli, err := p.newClient()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create Docker client: %w", err)
+		}
+		p.client = cli
+	}
+	pool, exists := p.pools[key]
+	if !exists {
+		pool = &containerPool{
+			image:       tool.image,
+			config:      containerConfig,
+			hostConfig:  hostConfig,
+			healthCheck: tool.pool.HealthCheckCommand,
+		}
+		if p.pools == nil {
+			p.pools = make(map[string]*containerPool)
+		}
+		p.pools[key] = pool
+	}
+	pool.mu.Lock()
+	pool.size = max(pool.size, tool.pool.Size)
+	pool.mu.Unlock()
+	return p.client, pool, nil
+}
+
+// warm starts containers for the tool in the background until its pool holds the configured
+// number of idle containers. Failures are ignored here, as they are reported by the calls.
+func (p *containerPools) warm(tool *SandboxTool) {
+	if cli, pool, err := p.getPool(tool); err == nil {
+		p.refill(cli, pool)
+	}
+}
+
+func (p *containerPools) refill(cli *client.Client, pool *containerPool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.closed {
+		return
+	}
+	warmCtx := p.ctx
+	for range pool.size - len(pool.idle) - pool.warming {
+		pool.warming++
+		p.background.Add(1)
+		go func() {
+			defer p.background.Done()
+			pooled, err := startPooledContainer(warmCtx, cli, pool)
+			pool.mu.Lock()
+			defer pool.mu.Unlock()
+			pool.warming--
+			switch {
+			case err != nil:
+				// The pool is refilled again on its next use.
+			case pool.closed:
+				_ = removeContainer(context.Background(), cli, pooled.id)
+			default:
+				pool.idle = append(pool.idle, pooled)
+			}
+		}()
+	}
+}
+
+// acquire takes a healthy container from the pool of the tool, or starts a new one if none
+// is ready, and refills the pool in the background. The returned function discards the
+// container and must be called after use.
+func (p *containerPools) acquire(ctx context.Context, logger logging.Logger, tool *SandboxTool) (containerID string, release func(), hit bool, saved time.Duration, err error) {
+	cli, pool, err := p.getPool(tool)
+	if err != nil {
+		return "", nil, false, 0, err
+	}
+	defer p.refill(cli, pool)
+
+	for {
+		pool.mu.Lock()
+		if len(pool.idle) == 0 {
+			pool.mu.Unlock()
+			break
+		}
+		pooled := pool.idle[0]
+		pool.idle = pool.idle[1:]
+		pool.mu.Unlock()
+
+		if err := checkPooledContainer(ctx, cli, pool, pooled.id); err != nil {
+			logger.Error(ctx, logging.LevelWarn, err, "discarding unhealthy pooled container %q", pooled.id)
+			p.discard(cli, pooled.id)
+			continue
+		}
+		pool.mu.Lock()
+		pool.hits++
+		pool.savedNs += pooled.startup.Nanoseconds()
+		pool.mu.Unlock()
+		return pooled.id, func() { p.discard(cli, pooled.id) }, true, pooled.startup, nil
+	}
+
+	pooled, err := startPooledContainer(ctx, cli, pool)
+	if err != nil {
+		return "", nil, false, 0, err
+	}
+	pool.mu.Lock()
+	pool.misses++
+	pool.mu.Unlock()
+	return pooled.id, func() { p.discard(cli, pooled.id) }, false, 0, nil
+}
+
+// discard removes a container taken from a pool in the background.
+func (p *containerPools) discard(cli *client.Client, containerID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.background.Add(1)
+	go func() {
+		defer p.background.Done()
+		_ = removeContainer(context.Background(), cli, containerID)
+	}()
+}
+
+// close stops any warm-ups, waits for pending removals, removes the idle containers of all
+// pools and returns their statistics.
+func (p *containerPools) close(ctx context.Context) (stats []ContainerPoolStats) {
+	p.mu.Lock()
+	p.cancel()
+	cli, pools := p.client, p.pools
+	p.client, p.pools = nil, nil
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	for _, pool := range pools {
+		pool.mu.Lock()
+		pool.closed = true
+		pool.mu.Unlock()
+	}
+	p.mu.Unlock()
+
+	p.background.Wait()
+	for _, pool := range pools {
+		for _, pooled := range pool.idle {
+			_ = removeContainer(context.WithoutCancel(ctx), cli, pooled.id)
+		}
+		pool.idle = nil
+		stats = append(stats, ContainerPoolStats{
+			Image:   pool.image,
+			Hits:    pool.hits,
+			Misses:  pool.misses,
+			SavedNs: pool.savedNs,
+		})
+	}
+	if cli != nil {
+		_ = cli.Close()
+	}
+	slices.SortFunc(stats, func(a, b ContainerPoolStats) int { return strings.Compare(a.Image, b.Image) })
+	return stats
+}
+
+// startPooledContainer creates and starts a container of the pool.
+func startPooledContainer(ctx context.Context, cli *client.Client, pool *containerPool) (pooledContainer, error) {
+	start := time.Now()
+	containerName := fmt.Sprintf("mindtrial-pool-%s", ulid.Make().String())
+	containerConfig, hostConfig := pool.config, pool.hostConfig
+	createResp, err := cli.ContainerCreate(ctx, &containerConfig, &hostConfig, nil, nil, containerName)
+	if err != nil {
+		return pooledContainer{}, fmt.Errorf("failed to create pooled container (image: %q): %w", pool.image, err)
+	}
+	if err := cli.ContainerStart(ctx, createResp.ID, container.StartOptions{}); err != nil {
+		_ = removeContainer(context.WithoutCancel(ctx), cli, createResp.ID)
+		return pooledContainer{}, fmt.Errorf("failed to start pooled container: %w", err)
+	}
+	return pooledContainer{id: createResp.ID, startup: time.Since(start)}, nil
+}
+
+// checkPooledContainer ensures that a pooled container is still running and passes the
+// health check of the pool, if any.
+func checkPooledContainer(ctx context.Context, cli *client.Client, pool *containerPool, containerID string) error {
+	inspectResp, err := cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("failed to inspect pooled container: %w", err)
+	}
+	if inspectResp.State == nil || !inspectResp.State.Running {
+		return errors.New("pooled container is not running")
+	}
+	if len(pool.healthCheck) == 0 {
+		return nil
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, poolHealthCheckTimeout)
+	defer cancel()
+	exitCode, stdout, stderr, err := execInContainer(checkCtx, cli, containerID, pool.healthCheck, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to run health check: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("health check exited with code %d: %s", exitCode, strings.TrimSpace(stdout+stderr))
+	}
+	return nil
+}
+
+// removeContainer force-removes a container, ignoring containers that are already gone.
+func removeContainer(ctx context.Context, cli *client.Client, containerID string) error {
+	err := cli.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+	if err == nil || errdefs.IsConflict(err) || errdefs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// executeDockerPooledCall executes a call of a Docker tool with a pool in a pre-started
+// container. Parameter and auxiliary data files are copied into the container before the
+// tool command is executed, and the container is discarded after the call.
+func (d *DockerToolExecutor) executeDockerPooledCall(ctx context.Context, logger logging.Logger, tool *SandboxTool, argMap map[string]interface{}, data map[string][]byte, summary *ToolCallSummary) (json.RawMessage, error) {
+	files, err := collectArchiveFiles(ctx, logger, tool, argMap, data, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	containerID, release, hit, saved, err := d.pools.acquire(ctx, logger, tool)
+	if err != nil {
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	d.recordPoolUse(tool.name, hit, saved)
+	if hit {
+		logger.Message(ctx, logging.LevelDebug, "took pre-started tool container %q from pool, saving %v", containerID, saved)
+	} else {
+		logger.Message(ctx, logging.LevelDebug, "no pre-started tool container ready, started container %q", containerID)
+	}
+	defer release() // never reuse a container, so that no state leaks to other calls
+
+	if err := d.copyFilesToContainer(ctx, logger, containerID, files, summary); err != nil {
+		return nil, err
+	}
+
+	// Apply timeout if specified.
+	execCtx := ctx
+	if tool.timeout != nil {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, *tool.timeout)
+		defer cancel()
+	}
+
+	runStart := time.Now()
+	logger.Message(ctx, logging.LevelInfo, "starting execution in tool container %q", containerID)
+	exitCode, stdout, stderr, err := execInContainer(execCtx, d.client, containerID, tool.command, tool.env, "")
+	runDuration := time.Since(runStart)
+	d.recordUsage(tool.name, runDuration)
+	durationNs := runDuration.Nanoseconds()
+	summary.DurationNs = &durationNs
+
+	// Handle execution errors.
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		wrapErr := fmt.Errorf("%w: execution timed out after %s", ErrToolTimeout, tool.getTimeoutValue())
+		summary.Status, summary.TimedOut, summary.ErrorMessage = toolCallStatusTimeout, true, wrapErr.Error()
+		return nil, wrapErr
+	case errors.Is(err, context.Canceled):
+		wrapErr := fmt.Errorf("%w: execution was cancelled", ErrToolInternal)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	case err != nil:
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	logger.Message(ctx, logging.LevelDebug, "tool process exited with code %d in %v", exitCode, runDuration)
+	summary.Artifacts = collectContainerArtifacts(ctx, logger, d.client, containerID, tool)
+	return completeProcessCall(ctx, logger, summary, exitCode, stdout, stderr)
+}
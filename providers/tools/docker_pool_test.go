@@ -0,0 +1,268 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func newTestPoolTool(name string, size int) *SandboxTool {
+	tool := newTestTool(name)
+	tool.pool = &config.ToolPoolConfig{Size: size}
+	return tool
+}
+
+// newTestContainerPools attaches container pools using the Docker API mock to the executor.
+func newTestContainerPools(t *testing.T, executor *DockerToolExecutor) *containerPools {
+	pools := newContainerPools(func() (*client.Client, error) { return executor.client, nil })
+	executor.pools = pools
+	t.Cleanup(func() {
+		pools.close(t.Context())
+	})
+	return pools
+}
+
+// poolMockState records the containers created and removed through the Docker API mock.
+type poolMockState struct {
+	mu       sync.Mutex
+	created  []string
+	removed  []string
+	stopped  map[string]bool // containers reported as not running
+	sessions *sessionMockState
+}
+
+func (s *poolMockState) snapshot() (created []string, removed []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.created...), append([]string(nil), s.removed...)
+}
+
+// configurePoolExecution configures the mock to create uniquely named containers whose exec
+// calls print the given output and exit with the given code.
+func configurePoolExecution(t *testing.T, mock *dockerAPIMock, output string, exitCode int) *poolMockState {
+	state := &poolMockState{
+		stopped:  make(map[string]bool),
+		sessions: configureSessionExecution(t, mock, output, exitCode),
+	}
+	mock.onCreate = func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Entrypoint []string `json:"Entrypoint"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, []string{"sleep", "infinity"}, payload.Entrypoint)
+		state.mu.Lock()
+		id := fmt.Sprintf("pooled-%d", len(state.created)+1)
+		state.created = append(state.created, id)
+		state.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"Id":%q}`, id)
+	}
+	mock.onRemove = func(w http.ResponseWriter, r *http.Request) {
+		state.mu.Lock()
+		state.removed = append(state.removed, path.Base(r.URL.Path))
+		state.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+	mock.onInspect = func(w http.ResponseWriter, r *http.Request) {
+		id := path.Base(path.Dir(r.URL.Path))
+		state.mu.Lock()
+		running := !state.stopped[id]
+		state.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Id": id, "State": map[string]interface{}{"Running": running}})
+	}
+	return state
+}
+
+// waitForIdle waits until the pool of the tool holds the given number of idle containers.
+func waitForIdle(t *testing.T, pools *containerPools, tool *SandboxTool, count int) {
+	_, pool, err := pools.getPool(tool)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		pool.mu.Lock()
+		defer pool.mu.Unlock()
+		return len(pool.idle) == count && pool.warming == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestContainerPoolsAcquire(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	pools := newTestContainerPools(t, executor)
+	state := configurePoolExecution(t, mock, "done", 0)
+	tool := newTestPoolTool("pooled", 2)
+	logger := testutils.NewTestLogger(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	// Without warming up, the first container is started on demand.
+	containerID, release, hit, saved, err := pools.acquire(ctx, logger, tool)
+	require.NoError(t, err)
+	assert.False(t, hit)
+	assert.Zero(t, saved)
+	release()
+
+	// The pool is refilled in the background.
+	waitForIdle(t, pools, tool, 2)
+	pooledID, release, hit, saved, err := pools.acquire(ctx, logger, tool)
+	require.NoError(t, err)
+	assert.True(t, hit)
+	assert.Positive(t, saved)
+	assert.NotEqual(t, containerID, pooledID)
+	release()
+
+	stats := pools.close(ctx)
+	assert.Equal(t, []ContainerPoolStats{{Image: "alpine:latest", Hits: 1, Misses: 1, SavedNs: saved.Nanoseconds()}}, stats)
+
+	// Every container is removed: the used ones after use and the idle ones on close.
+	created, removed := state.snapshot()
+	assert.GreaterOrEqual(t, len(created), 3)
+	assert.ElementsMatch(t, created, removed)
+}
+
+func TestContainerPoolsAcquire_DiscardsUnhealthyContainers(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthCheck []string
+		exitCode    int
+		stopped     bool
+	}{
+		{name: "container not running", stopped: true},
+		{name: "health check failed", healthCheck: []string{"true"}, exitCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newDockerAPIMock(t)
+			executor := newTestExecutor(t, mock)
+			pools := newTestContainerPools(t, executor)
+			state := configurePoolExecution(t, mock, "", tt.exitCode)
+			tool := newTestPoolTool("pooled", 1)
+			tool.pool.HealthCheckCommand = tt.healthCheck
+			logger := testutils.NewTestLogger(t)
+			ctx, cancel := newTestContext()
+			defer cancel()
+
+			pools.warm(tool)
+			waitForIdle(t, pools, tool, 1)
+			state.mu.Lock()
+			state.stopped["pooled-1"] = tt.stopped
+			state.mu.Unlock()
+
+			containerID, release, hit, _, err := pools.acquire(ctx, logger, tool)
+			require.NoError(t, err)
+			assert.False(t, hit)
+			assert.Equal(t, "pooled-2", containerID)
+			release()
+
+			pools.close(ctx)
+			_, removed := state.snapshot()
+			assert.Contains(t, removed, "pooled-1")
+		})
+	}
+}
+
+func TestDockerToolExecutorExecuteTool_Pooled(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	pools := newTestContainerPools(t, executor)
+	state := configurePoolExecution(t, mock, "done", 0)
+	tool := newTestPoolTool("pooled", 1)
+	tool.auxiliaryDir = "/aux"
+	executor.RegisterTool(tool)
+	waitForIdle(t, pools, tool, 1)
+
+	logger := testutils.NewTestLogger(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	result, err := executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{"input":"payload"}`), map[string][]byte{"data.txt": []byte("aux")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(result))
+	assert.Equal(t, map[string]string{"workspace/input.txt": "payload", "aux/data.txt": "aux"}, state.sessions.copiedFiles)
+
+	call := onlyCall(t, executor, tool.name)
+	assert.Equal(t, toolCallStatusSuccess, call.Status)
+	require.NotNil(t, call.ExitCode)
+	assert.Equal(t, int64(0), *call.ExitCode)
+
+	usage := executor.GetUsageStats()[tool.name]
+	assert.Equal(t, int64(1), usage.CallCount)
+	assert.Equal(t, int64(1), usage.PoolHits)
+	assert.Equal(t, int64(0), usage.PoolMisses)
+	assert.Positive(t, usage.PoolSavedNs)
+
+	// The used container is discarded rather than returned to the pool.
+	require.Eventually(t, func() bool {
+		_, removed := state.snapshot()
+		return len(removed) == 1 && removed[0] == "pooled-1"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDockerToolExecutorExecuteTool_PooledTimeout(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	pools := newTestContainerPools(t, executor)
+	state := configurePoolExecution(t, mock, "", 0)
+	tool := newTestPoolTool("pooled", 1)
+	tool.timeout = testutils.Ptr(50 * time.Millisecond)
+	executor.RegisterTool(tool)
+	waitForIdle(t, pools, tool, 1)
+
+	mock.onExecStart = func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		_ = buf.Flush()
+		_, _ = conn.Read(make([]byte, 1)) // produce no output until the client disconnects
+	}
+
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := executor.ExecuteTool(ctx, testutils.NewTestLogger(t), tool.name, json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolTimeout)
+	call := onlyCall(t, executor, tool.name)
+	assert.Equal(t, toolCallStatusTimeout, call.Status)
+
+	require.Eventually(t, func() bool {
+		_, removed := state.snapshot()
+		return len(removed) == 1 && removed[0] == "pooled-1"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewDockerToolExecutor_ContainerPools(t *testing.T) {
+	resources := NewExecutorResources()
+	shared, err := NewDockerToolExecutor(resources.Context(t.Context()))
+	require.NoError(t, err)
+	defer shared.Close()
+	assert.Same(t, resources.pools, shared.pools, "executors created with the resources must share their pools")
+	assert.Same(t, resources.images, shared.images)
+	assert.False(t, shared.ownedPools)
+
+	standalone, err := NewDockerToolExecutor(t.Context())
+	require.NoError(t, err)
+	defer standalone.Close()
+	assert.NotSame(t, resources.pools, standalone.pools)
+	assert.True(t, standalone.ownedPools)
+
+	assert.Empty(t, resources.Close(t.Context()))
+}
@@ -0,0 +1,282 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/oklog/ulid/v2"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+// dockerSession is a container kept running for all calls of a session tool within a task.
+type dockerSession struct {
+	mu          sync.Mutex // serializes the calls of the session
+	tool        *SandboxTool
+	containerID string // empty until the first call, or after the session was reset
+}
+
+// EndSessions removes the containers of all tool sessions, capturing their workspaces first
+// if configured. Returns nil if the executor is nil.
+func (d *DockerToolExecutor) EndSessions(ctx context.Context, logger logging.Logger) []WorkspaceSnapshot {
+	if d == nil {
+		return nil
+	}
+	return d.endSessions(ctx, logger, true)
+}
+
+func (d *DockerToolExecutor) endSessions(ctx context.Context, logger logging.Logger, capture bool) (snapshots []WorkspaceSnapshot) {
+	for _, session := range d.sessions.takeAll() {
+		session.mu.Lock()
+		if session.containerID != "" {
+			if capture && session.tool.session.CaptureWorkspace {
+				snapshots = append(snapshots, d.captureSessionWorkspace(ctx, session))
+			}
+			d.resetSession(ctx, logger, session)
+		}
+		session.mu.Unlock()
+	}
+	return snapshots
+}
+
+// captureSessionWorkspace captures the files in the workspace directory of the session container.
+func (d *DockerToolExecutor) captureSessionWorkspace(ctx context.Context, session *dockerSession) WorkspaceSnapshot {
+	capture := newWorkspaceCapture(session.tool.name, session.tool.session.WorkspaceDir, session.tool.session.GetMaxCaptureBytes())
+	archive, _, err := d.client.CopyFromContainer(ctx, session.containerID, session.tool.session.WorkspaceDir)
+	if err != nil {
+		return capture.fail(fmt.Errorf("failed to copy workspace from tool container: %w", err))
+	}
+	defer archive.Close()
+	return capture.captureArchive(archive)
+}
+
+// resetSession removes the container of the session, so that the next call starts a new one.
+func (d *DockerToolExecutor) resetSession(ctx context.Context, logger logging.Logger, session *dockerSession) {
+	err := d.client.ContainerRemove(context.WithoutCancel(ctx), session.containerID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+	switch {
+	case err == nil, errdefs.IsConflict(err), errdefs.IsNotFound(err):
+		// Container removed successfully or already removed. Ignore.
+	case logger != nil:
+		logger.Error(ctx, logging.LevelWarn, err, "%s: failed to remove session container", session.tool.name)
+	}
+	session.containerID = ""
+}
+
+// startSessionContainer creates and starts the container of a session, kept running by the
+// keep-alive command of the session until it is removed.
+func (d *DockerToolExecutor) startSessionContainer(ctx context.Context, logger logging.Logger, tool *SandboxTool) (string, error) {
+	var mounts []mount.Mount
+	if tool.sharedDir != "" {
+		sharedTempDir, err := d.getSharedDir(ctx, &d.executorState)
+		if err != nil {
+			return "", err
+		}
+		mounts = append(mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: sharedTempDir,
+			Target: tool.sharedDir,
+		})
+		logger.Message(ctx, logging.LevelDebug, "mounted shared directory from %s to container path %s", sharedTempDir, tool.sharedDir)
+	}
+
+	containerConfig := &container.Config{
+		Image:      tool.image,
+		Entrypoint: tool.session.GetKeepAliveCommand(),
+		Env:        containerEnv(tool.env),
+		WorkingDir: tool.session.WorkspaceDir,
+	}
+	hostConfig := &container.HostConfig{
+		Mounts:        mounts,
+		NetworkMode:   network.NetworkNone,
+		RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyDisabled},
+	}
+	setResourceLimits(ctx, logger, tool, hostConfig)
+
+	containerName := fmt.Sprintf("%s-session-%s", tool.name, ulid.Make().String())
+	createResp, err := d.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session container (image: %q): %w", tool.image, err)
+	}
+	if err := d.client.ContainerStart(ctx, createResp.ID, container.StartOptions{}); err != nil {
+		_ = d.client.ContainerRemove(context.WithoutCancel(ctx), createResp.ID, container.RemoveOptions{Force: true, RemoveVolumes: true})
+		return "", fmt.Errorf("failed to start session container: %w", err)
+	}
+	logger.Message(ctx, logging.LevelDebug, "started session container %q (ID: %s)", containerName, createResp.ID)
+	return createResp.ID, nil
+}
+
+// executeDockerSessionCall executes a call of a Docker tool with a session in the session
+// container, starting the container on the first call. Parameter and auxiliary data files
+// are copied into the container before the tool command is executed in the workspace
+// directory. A call that times out or is cancelled resets the session, as its process
+// cannot be stopped without stopping the container.
+func (d *DockerToolExecutor) executeDockerSessionCall(ctx context.Context, logger logging.Logger, tool *SandboxTool, argMap map[string]interface{}, data map[string][]byte, summary *ToolCallSummary) (json.RawMessage, error) {
+	session := d.sessions.getOrCreate(tool.name, func() *dockerSession { return &dockerSession{tool: tool} })
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	files, err := collectArchiveFiles(ctx, logger, tool, argMap, data, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.containerID == "" {
+		containerID, err := d.startSessionContainer(ctx, logger, tool)
+		if err != nil {
+			wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+			return nil, wrapErr
+		}
+		session.containerID = containerID
+	}
+
+	if err := d.copyFilesToContainer(ctx, logger, session.containerID, files, summary); err != nil {
+		return nil, err
+	}
+
+	// Apply timeout if specified.
+	execCtx := ctx
+	if tool.timeout != nil {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, *tool.timeout)
+		defer cancel()
+	}
+
+	runStart := time.Now()
+	logger.Message(ctx, logging.LevelInfo, "starting execution in session container %q", session.containerID)
+	exitCode, stdout, stderr, err := execInContainer(execCtx, d.client, session.containerID, tool.command, tool.env, tool.session.WorkspaceDir)
+	runDuration := time.Since(runStart)
+	d.recordUsage(tool.name, runDuration)
+	durationNs := runDuration.Nanoseconds()
+	summary.DurationNs = &durationNs
+
+	// Handle execution errors.
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		d.resetSession(ctx, logger, session)
+		wrapErr := fmt.Errorf("%w: execution timed out after %s; the session was reset and files in it were lost", ErrToolTimeout, tool.getTimeoutValue())
+		summary.Status, summary.TimedOut, summary.ErrorMessage = toolCallStatusTimeout, true, wrapErr.Error()
+		return nil, wrapErr
+	case errors.Is(err, context.Canceled):
+		d.resetSession(ctx, logger, session)
+		wrapErr := fmt.Errorf("%w: execution was cancelled", ErrToolInternal)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	case err != nil:
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	logger.Message(ctx, logging.LevelDebug, "tool process exited with code %d in %v", exitCode, runDuration)
+	summary.Artifacts = collectContainerArtifacts(ctx, logger, d.client, session.containerID, tool)
+	return completeProcessCall(ctx, logger, summary, exitCode, stdout, stderr)
+}
+
+// collectArchiveFiles collects the parameter and auxiliary data files of a call, to be copied
+// into a running container.
+func collectArchiveFiles(ctx context.Context, logger logging.Logger, tool *SandboxTool, argMap map[string]interface{}, data map[string][]byte, summary *ToolCallSummary) ([]archiveFile, error) {
+	var files []archiveFile
+	for _, argName := range utils.SortedKeys(tool.parameterFiles) {
+		argValue, exists := argMap[argName]
+		if !exists {
+			continue
+		}
+		content, err := argumentFileContent(argValue)
+		if err != nil {
+			logger.Error(ctx, logging.LevelError, err, "failed to marshal argument %q to JSON: %v", argName, argValue)
+			wrapErr := fmt.Errorf("%w: failed to serialize argument %q to JSON (argument values must be JSON-serializable): %v", ErrInvalidToolArguments, argName, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+			return nil, wrapErr
+		}
+		files = append(files, archiveFile{path: filepath.ToSlash(tool.parameterFiles[argName]), content: []byte(content)})
+	}
+	if tool.auxiliaryDir != "" {
+		for _, fileName := range utils.SortedKeys(data) {
+			files = append(files, archiveFile{path: path.Join(filepath.ToSlash(tool.auxiliaryDir), fileName), content: data[fileName]})
+		}
+	}
+	return files, nil
+}
+
+// copyFilesToContainer copies the files of a call into a running container.
+func (d *DockerToolExecutor) copyFilesToContainer(ctx context.Context, logger logging.Logger, containerID string, files []archiveFile, summary *ToolCallSummary) error {
+	if len(files) == 0 {
+		return nil
+	}
+	archive, err := newFileArchive(files)
+	if err != nil {
+		wrapErr := fmt.Errorf("%w: failed to archive files for tool container: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return wrapErr
+	}
+	if err := d.client.CopyToContainer(ctx, containerID, "/", archive, container.CopyToContainerOptions{}); err != nil {
+		wrapErr := fmt.Errorf("%w: failed to copy files into tool container: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return wrapErr
+	}
+	logger.Message(ctx, logging.LevelDebug, "copied %d files into tool container %q", len(files), containerID)
+	return nil
+}
+
+// execInContainer executes a command in a running container and waits for it to complete,
+// returning its exit code and output.
+func execInContainer(ctx context.Context, cli *client.Client, containerID string, command []string, env map[string]string, workDir string) (exitCode int64, stdout string, stderr string, err error) {
+	execResp, err := cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          command,
+		Env:          containerEnv(env),
+		WorkingDir:   workDir,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return 0, "", "", fmt.Errorf("failed to create tool process in container: %w", err)
+	}
+	attachResp, err := cli.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return 0, "", "", fmt.Errorf("failed to start tool process in container: %w", err)
+	}
+	defer attachResp.Close()
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	copyDone := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(&stdoutBuf, &stderrBuf, attachResp.Reader)
+		copyDone <- err
+	}()
+	select {
+	case err := <-copyDone:
+		if err != nil {
+			return 0, "", "", fmt.Errorf("failed to read tool process output: %w", err)
+		}
+	case <-ctx.Done():
+		attachResp.Close()
+		<-copyDone
+		return 0, "", "", fmt.Errorf("tool execution interrupted: %w", ctx.Err())
+	}
+
+	inspectResp, err := cli.ContainerExecInspect(ctx, execResp.ID)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("failed to inspect tool process in container: %w", err)
+	}
+	return int64(inspectResp.ExitCode), stdoutBuf.String(), stderrBuf.String(), nil
+}
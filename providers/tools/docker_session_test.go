@@ -0,0 +1,276 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func newTestSessionTool(name string) *SandboxTool {
+	tool := newTestTool(name)
+	tool.session = &config.ToolSessionConfig{
+		WorkspaceDir:     "/workspace",
+		CaptureWorkspace: true,
+	}
+	return tool
+}
+
+// sessionMockState records the calls made to the Docker API mock by a tool session.
+type sessionMockState struct {
+	creates     atomic.Int32
+	execs       atomic.Int32
+	removes     atomic.Int32
+	copiedFiles map[string]string
+	entrypoint  []string
+	workingDir  string
+}
+
+// configureSessionExecution configures the mock to run a session container whose exec
+// calls print the given output and exit with the given code.
+func configureSessionExecution(t *testing.T, mock *dockerAPIMock, output string, exitCode int) *sessionMockState {
+	state := &sessionMockState{copiedFiles: make(map[string]string)}
+
+	mock.onCreate = func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Entrypoint []string `json:"Entrypoint"`
+			WorkingDir string   `json:"WorkingDir"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		state.entrypoint, state.workingDir = payload.Entrypoint, payload.WorkingDir
+		state.creates.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Id":"session-container"}`))
+	}
+	mock.onStart = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	mock.onRemove = func(w http.ResponseWriter, _ *http.Request) {
+		state.removes.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}
+	mock.onArchivePut = func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/", r.URL.Query().Get("path"))
+		reader := tar.NewReader(r.Body)
+		for {
+			header, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			state.copiedFiles[header.Name] = string(content)
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	mock.onExecCreate = func(w http.ResponseWriter, _ *http.Request) {
+		state.execs.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Id":"exec-id"}`))
+	}
+	mock.onExecStart = func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		_, _ = buf.Write(encodeDockerFrames(dockerLogFrame{Stream: 1, Data: output}))
+		_ = buf.Flush()
+	}
+	mock.onExecInspect = func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ExitCode": exitCode, "Running": false})
+	}
+	return state
+}
+
+func TestDockerToolExecutorExecuteTool_SessionReusesContainer(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	tool := newTestSessionTool("session")
+	tool.auxiliaryDir = "/aux"
+	executor.RegisterTool(tool)
+	state := configureSessionExecution(t, mock, "done", 0)
+
+	logger := testutils.NewTestLogger(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	result, err := executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{"input":"first"}`), map[string][]byte{"data.txt": []byte("aux")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(result))
+	_, err = executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{"input":"second"}`), nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), state.creates.Load())
+	assert.Equal(t, int32(2), state.execs.Load())
+	assert.Equal(t, int32(0), state.removes.Load())
+	assert.Equal(t, []string{"sleep", "infinity"}, state.entrypoint)
+	assert.Equal(t, "/workspace", state.workingDir)
+	assert.Equal(t, map[string]string{"workspace/input.txt": "second", "aux/data.txt": "aux"}, state.copiedFiles)
+
+	calls := callsFor(executor, tool.name)
+	require.Len(t, calls, 2)
+	for _, call := range calls {
+		assert.Equal(t, toolCallStatusSuccess, call.Status)
+		require.NotNil(t, call.ExitCode)
+		assert.Equal(t, int64(0), *call.ExitCode)
+	}
+}
+
+func TestDockerToolExecutorExecuteTool_SessionNonZeroExit(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	tool := newTestSessionTool("session")
+	executor.RegisterTool(tool)
+	state := configureSessionExecution(t, mock, "broken", 2)
+
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := executor.ExecuteTool(ctx, testutils.NewTestLogger(t), tool.name, json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolExecutionFailed)
+	assert.ErrorContains(t, err, "tool process exited with code 2: broken")
+	assert.Equal(t, int32(0), state.removes.Load()) // the session is kept
+
+	call := onlyCall(t, executor, tool.name)
+	assert.Equal(t, toolCallStatusNonZeroExit, call.Status)
+	require.NotNil(t, call.ExitCode)
+	assert.Equal(t, int64(2), *call.ExitCode)
+}
+
+func TestDockerToolExecutorExecuteTool_SessionTimeoutResetsSession(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	tool := newTestSessionTool("session")
+	tool.timeout = testutils.Ptr(50 * time.Millisecond)
+	executor.RegisterTool(tool)
+	state := configureSessionExecution(t, mock, "done", 0)
+
+	mock.onExecStart = func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		_ = buf.Flush()
+		_, _ = io.Copy(io.Discard, conn) // produce no output until the client disconnects
+	}
+
+	logger := testutils.NewTestLogger(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolTimeout)
+	assert.ErrorContains(t, err, "the session was reset")
+	assert.Equal(t, int32(1), state.removes.Load())
+
+	call := onlyCall(t, executor, tool.name)
+	assert.Equal(t, toolCallStatusTimeout, call.Status)
+	assert.True(t, call.TimedOut)
+
+	// The next call starts a new container.
+	configureSessionExecution(t, mock, "done", 0)
+	mock.onCreate = func(w http.ResponseWriter, _ *http.Request) {
+		state.creates.Add(1)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"Id":"session-container-2"}`))
+	}
+	_, err = executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), state.creates.Load())
+}
+
+func TestDockerToolExecutorEndSessions(t *testing.T) {
+	mock := newDockerAPIMock(t)
+	executor := newTestExecutor(t, mock)
+	tool := newTestSessionTool("session")
+	tool.session.MaxCaptureBytes = testutils.Ptr(int64(5))
+	executor.RegisterTool(tool)
+	state := configureSessionExecution(t, mock, "done", 0)
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "workspace/", Mode: 0o755}))
+	for name, content := range map[string]string{"workspace/notes.txt": "hello", "workspace/out/large.txt": "too large"} {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	mock.onArchiveGet = func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/workspace", r.URL.Query().Get("path"))
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString([]byte(`{"name":"workspace","mode":2147484141}`)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(archive.Bytes())
+	}
+
+	logger := testutils.NewTestLogger(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := executor.ExecuteTool(ctx, logger, tool.name, json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+
+	snapshots := executor.EndSessions(ctx, logger)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, WorkspaceSnapshot{
+		Tool: "session",
+		Dir:  "/workspace",
+		Files: []WorkspaceFile{
+			{Path: "notes.txt", Bytes: 5, Content: testutils.Ptr("hello")},
+			{Path: "out/large.txt", Bytes: 9},
+		},
+		Truncated: true,
+	}, snapshots[0])
+	assert.Equal(t, int32(1), state.removes.Load())
+
+	// Sessions are ended only once.
+	assert.Empty(t, executor.EndSessions(ctx, logger))
+	assert.Equal(t, int32(1), state.removes.Load())
+}
+
+func TestDockerToolExecutorEndSessions_NilReceiver(t *testing.T) {
+	var executor *DockerToolExecutor
+	assert.Nil(t, executor.EndSessions(nil, nil)) //nolint:staticcheck
+}
+
+func TestNewFileArchive(t *testing.T) {
+	archive, err := newFileArchive([]archiveFile{
+		{path: "/workspace/input.txt", content: []byte("input")},
+		{path: "/aux//data.bin", content: []byte{0x00}},
+	})
+	require.NoError(t, err)
+
+	reader := tar.NewReader(archive)
+	var names []string
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.False(t, strings.HasPrefix(header.Name, "/"))
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"workspace/input.txt", "aux/data.bin"}, names)
+}
@@ -0,0 +1,450 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+)
+
+// ToolExecutor executes the tools available to a model while it solves a single task,
+// and tracks their usage. Implementations differ in how sandboxed tools are run.
+type ToolExecutor interface {
+	// RegisterTool registers a sandboxed tool with the executor.
+	RegisterTool(tool *SandboxTool)
+	// RegisterMCPServer starts the MCP server of the given tool configuration and registers
+	// the tools it lists. It returns the definitions of the registered tools.
+	RegisterMCPServer(ctx context.Context, logger logging.Logger, cfg *config.ToolConfig, maxCalls *int, timeout *time.Duration) ([]config.ToolConfig, error)
+	// RegisterMockTool registers a mock tool with the executor.
+	RegisterMockTool(tool *MockTool)
+	// RegisterHTTPTool registers an HTTP tool with the executor.
+	RegisterHTTPTool(tool *HTTPTool)
+	// ValidateTool checks that the tool defined by the given configuration can be executed,
+	// preparing what it needs to run, such as building its image.
+	ValidateTool(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) error
+	// ExecuteTool executes a tool by name with the given arguments and auxiliary data files.
+	// callCtx carries optional caller-supplied metadata (see ToolCallContext) to attach to the
+	// resulting ToolCallSummary; pass nil if there is none to provide.
+	ExecuteTool(ctx context.Context, logger logging.Logger, toolName string, args json.RawMessage, data map[string][]byte, callCtx *ToolCallContext) (json.RawMessage, error)
+	// IsToolExhausted reports whether the named tool has exceeded its maximum call limit.
+	IsToolExhausted(toolName string) bool
+	// GetUsageStats returns aggregate execution statistics for all tools.
+	GetUsageStats() map[string]ToolUsage
+	// GetCallSummaries returns a log of every recorded invocation attempt across all tools,
+	// in the order calls completed.
+	GetCallSummaries() []ToolCallSummary
+	// EndSessions tears down the sessions of all tools and returns the snapshots of the
+	// workspaces captured from those configured to capture them, in order of tool names.
+	EndSessions(ctx context.Context, logger logging.Logger) []WorkspaceSnapshot
+	// Close releases all resources held by the executor.
+	Close() error
+}
+
+// ExecutorBackend returns the type of the sandboxed tools among the given tools, which
+// determines the executor backend able to run all of them: config.ToolTypeDocker if any tool
+// is a Docker tool, or config.ToolTypeSubprocess otherwise. MCP, mock and HTTP tools run with either backend.
+// Docker and subprocess tools cannot be used together.
+func ExecutorBackend(cfgs []config.ToolConfig) (string, error) {
+	backend := ""
+	for _, cfg := range cfgs {
+		switch toolType := cfg.GetType(); toolType {
+		case config.ToolTypeDocker, config.ToolTypeSubprocess:
+			if backend != "" && backend != toolType {
+				return "", fmt.Errorf("%w: docker and subprocess tools cannot be used together", ErrUnsupportedToolType)
+			}
+			backend = toolType
+		}
+	}
+	if backend == "" {
+		return config.ToolTypeSubprocess, nil // no sandboxed tools, so avoid depending on Docker
+	}
+	return backend, nil
+}
+
+// NewToolExecutor creates a tool executor for the given backend (see ExecutorBackend).
+func NewToolExecutor(ctx context.Context, backend string) (ToolExecutor, error) {
+	switch backend {
+	case config.ToolTypeDocker:
+		executor, err := NewDockerToolExecutor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return executor, nil
+	case config.ToolTypeSubprocess:
+		return NewSubprocessToolExecutor(), nil
+	}
+	return nil, fmt.Errorf("%w: %w: executor backend %q", ErrToolInternal, ErrUnsupportedToolType, backend)
+}
+
+// ExecutorResources holds the resources shared by the tool executors of a runner across tasks,
+// such as the container pools that let containers started during one task serve the calls of
+// the next. It must be closed when the executors are no longer used.
+type ExecutorResources struct {
+	pools  *containerPools
+	images *sync.Map // map[string]string of the images built while validating tools by builtImageKey
+}
+
+type executorResourcesContextKey struct{}
+
+// NewExecutorResources creates resources to be shared by tool executors.
+func NewExecutorResources() *ExecutorResources {
+	return &ExecutorResources{pools: newContainerPools(newDockerClient), images: &sync.Map{}}
+}
+
+// Context returns a copy of ctx that makes the tool executors created with it share the resources.
+func (r *ExecutorResources) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, executorResourcesContextKey{}, r)
+}
+
+// Close removes the idle containers of the Docker tool container pools and returns the
+// statistics of the pools ordered by image.
+func (r *ExecutorResources) Close(ctx context.Context) []ContainerPoolStats {
+	return r.pools.close(ctx)
+}
+
+// executorResourcesFrom returns the resources shared by the tool executors created with ctx, if any.
+func executorResourcesFrom(ctx context.Context) (*ExecutorResources, bool) {
+	resources, _ := ctx.Value(executorResourcesContextKey{}).(*ExecutorResources)
+	return resources, resources != nil
+}
+
+// executorState holds the registered tools, their usage and the resources shared by all
+// tool calls of an executor, regardless of its backend.
+type executorState struct {
+	tools         sync.Map         // map[string]*SandboxTool, map[string]*MCPTool, map[string]*MockTool or map[string]*HTTPTool
+	usage         sync.Map         // map[string]*ToolUsage
+	calls         callSummaryState // shared log of every invocation attempt across all tools, in completion order
+	getSharedDir  func(context.Context, *executorState) (string, error)
+	sharedDirPath atomic.Pointer[string] // stores the actual shared directory path if created
+	mcpServersMu  sync.Mutex
+	mcpServers    []*MCPServer // MCP servers started by RegisterMCPServer, stopped on close
+}
+
+// ToolUsage tracks aggregate execution statistics for a tool: CallCount and
+// TotalDurationNs only reflect invocations whose container actually started running
+// (regardless of exit code) - i.e. actual compute time spent, not every invocation
+// attempt. An invocation that fails before the container ever starts (e.g. invalid
+// arguments, or an infrastructure error during setup) does not affect these aggregates.
+// See ToolCallSummary/GetCallSummaries for a complete per-invocation log that does
+// include such attempts.
+//
+// For tools with a container pool, PoolHits and PoolMisses count the calls that did and did
+// not find a pre-started container ready, and PoolSavedNs estimates the latency saved by the
+// hits as the startup time of the containers they used.
+type ToolUsage struct {
+	CallCount       int64
+	TotalDurationNs int64
+	Exhausted       int32
+	PoolHits        int64
+	PoolMisses      int64
+	PoolSavedNs     int64
+}
+
+// callSummaryState holds the shared log of per-call summaries across all tools. A single
+// shared log preserves the true chronological order calls completed in, across all tools.
+type callSummaryState struct {
+	sync.Mutex
+	calls []ToolCallSummary
+}
+
+// record appends a per-call summary, synchronized internally.
+func (s *callSummaryState) record(summary ToolCallSummary) {
+	s.Lock()
+	defer s.Unlock()
+	s.calls = append(s.calls, summary)
+}
+
+// snapshot returns a deep copy of the recorded per-call summaries, synchronized internally,
+// so callers never share the backing array.
+func (s *callSummaryState) snapshot() []ToolCallSummary {
+	s.Lock()
+	defer s.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// newSharedDirFactory creates a factory function that lazily creates a shared temporary directory.
+// The directory is created once on the first call and the same path is returned for all subsequent calls.
+func newSharedDirFactory() func(context.Context, *executorState) (string, error) {
+	return config.OnceWithContext(func(ctx context.Context, state *executorState) (sharedDir string, err error) {
+		sharedDir, err = os.MkdirTemp("", "mindtrial-tool-shared-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create shared temporary directory: %w", err)
+		}
+		state.sharedDirPath.Store(&sharedDir)
+		return
+	})
+}
+
+// RegisterTool registers a tool with the executor.
+func (s *executorState) RegisterTool(tool *SandboxTool) {
+	s.tools.Store(tool.name, tool)
+}
+
+// ToolCallContext carries optional caller-supplied metadata to attach to the
+// ToolCallSummary recorded for a single ExecuteTool call. All fields are optional; a nil
+// ToolCallContext (or a zero-value one) simply leaves the corresponding ToolCallSummary
+// fields unset. New fields can be added here in the future without changing ExecuteTool's
+// signature again.
+type ToolCallContext struct {
+	// CallID is the provider's own identifier for this tool call, if the provider's API
+	// assigns one (e.g. OpenAI/Anthropic/DeepSeek/Mistral AI/xAI's tool_call/tool_use ID).
+	// Reusing the provider's own ID - rather than minting an unrelated one - means this same
+	// ID can also be found in any API error message that references the call. If empty,
+	// ExecuteTool generates one internally (a ULID) instead, so ToolCallSummary.CallID is
+	// never empty; note this means CallID's shape/format varies depending on whether - and
+	// how - the calling provider assigns its own IDs.
+	CallID string
+	// ConversationTurn is the 1-based conversation turn this call is being made during, or 0
+	// if unknown/not applicable.
+	ConversationTurn int
+}
+
+// sandboxRunner executes a single call of a sandboxed tool in a specific executor backend.
+// callID uniquely identifies the call (see ToolCallContext.CallID) and callCtx may be nil.
+type sandboxRunner func(ctx context.Context, logger logging.Logger, tool *SandboxTool, args json.RawMessage, data map[string][]byte, callID string, callCtx *ToolCallContext) (json.RawMessage, error)
+
+// executeTool executes a tool by name with the given arguments and auxiliary data files,
+// running sandboxed tools with runSandboxed. It enforces the maximum number of calls and
+// assigns the call ID shared by all executor backends (see ToolExecutor.ExecuteTool).
+func (s *executorState) executeTool(ctx context.Context, logger logging.Logger, toolName string, args json.RawMessage, data map[string][]byte, callCtx *ToolCallContext, runSandboxed sandboxRunner) (json.RawMessage, error) {
+	toolValue, exists := s.tools.Load(toolName)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrToolNotAvailable, toolName)
+	}
+
+	var maxCalls *int
+	switch tool := toolValue.(type) {
+	case *SandboxTool:
+		maxCalls = tool.maxCalls
+	case *MCPTool:
+		maxCalls = tool.maxCalls
+	case *MockTool:
+		maxCalls = tool.maxCalls
+	case *HTTPTool:
+		maxCalls = tool.maxCalls
+	default:
+		return nil, fmt.Errorf("tool %q encountered an error: %w: %w: %T", toolName, ErrToolInternal, ErrUnsupportedToolType, toolValue)
+	}
+
+	// Check MaxCalls limit.
+	// NOTE: The current execution model creates a new executor per provider Run,
+	// so ExecuteTool is not called concurrently on this executor instance today.
+	// If this ever changes (shared executor across concurrent calls), reserve a call slot
+	// atomically before execution to avoid check-then-act races.
+	if maxCalls != nil {
+		usageValue, _ := s.usage.LoadOrStore(toolName, &ToolUsage{})
+		usage := usageValue.(*ToolUsage)
+		callCount := atomic.LoadInt64(&usage.CallCount)
+		if callCount >= int64(*maxCalls) {
+			atomic.StoreInt32(&usage.Exhausted, 1)
+			return nil, fmt.Errorf("%w: tool %q has exceeded its maximum call limit of %d for this session. Do not call this tool again during the current conversation", ErrToolMaxCallsExceeded, toolName, *maxCalls)
+		}
+	}
+
+	// Assign an ID to this call so it can be correlated between the log and the recorded
+	// ToolCallSummary - preferring the caller-supplied ID (see ToolCallContext.CallID) so
+	// the same ID can also be matched against the provider's own API error messages, and
+	// falling back to a freshly generated ULID when the caller did not supply one. Create a
+	// logger that includes it alongside the tool name.
+	callID := ""
+	if callCtx != nil {
+		callID = callCtx.CallID
+	}
+	if callID == "" {
+		callID = ulid.Make().String()
+	}
+	toolLogger := logger.WithContext(fmt.Sprintf("%s[%s]: ", toolName, callID))
+
+	// Execute the tool.
+	var result json.RawMessage
+	var err error
+	switch tool := toolValue.(type) {
+	case *SandboxTool:
+		result, err = runSandboxed(ctx, toolLogger, tool, args, data, callID, callCtx)
+	case *MCPTool:
+		result, err = s.executeMCPTool(ctx, toolLogger, tool, args, callID, callCtx)
+	case *MockTool:
+		result, err = s.executeMockTool(ctx, toolLogger, tool, args, callID, callCtx)
+	case *HTTPTool:
+		result, err = s.executeHTTPTool(ctx, toolLogger, tool, args, callID, callCtx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tool %q encountered an error: %w", toolName, err)
+	}
+	return result, nil
+}
+
+// close stops any MCP servers and removes the shared directory, if it was created.
+func (s *executorState) close() {
+	s.mcpServersMu.Lock()
+	for _, server := range s.mcpServers {
+		server.Close()
+	}
+	s.mcpServers = nil
+	s.mcpServersMu.Unlock()
+
+	if sharedDirPtr := s.sharedDirPath.Load(); sharedDirPtr != nil {
+		_ = os.RemoveAll(*sharedDirPtr)
+	}
+}
+
+// recordUsage records the aggregate execution statistics for a tool.
+func (s *executorState) recordUsage(toolName string, duration time.Duration) {
+	usageValue, _ := s.usage.LoadOrStore(toolName, &ToolUsage{})
+	toolUsage := usageValue.(*ToolUsage)
+
+	atomic.AddInt64(&toolUsage.CallCount, 1)
+	atomic.AddInt64(&toolUsage.TotalDurationNs, duration.Nanoseconds())
+}
+
+// recordPoolUse records whether a call of the named tool found a pre-started container in
+// its pool, and the startup time saved if it did.
+func (s *executorState) recordPoolUse(toolName string, hit bool, saved time.Duration) {
+	usageValue, _ := s.usage.LoadOrStore(toolName, &ToolUsage{})
+	toolUsage := usageValue.(*ToolUsage)
+
+	if hit {
+		atomic.AddInt64(&toolUsage.PoolHits, 1)
+		atomic.AddInt64(&toolUsage.PoolSavedNs, saved.Nanoseconds())
+	} else {
+		atomic.AddInt64(&toolUsage.PoolMisses, 1)
+	}
+}
+
+// recordCallSummary appends a per-call summary to the shared call log, tagging it with
+// the tool name.
+func (s *executorState) recordCallSummary(toolName string, summary ToolCallSummary) {
+	summary.Tool = toolName
+	s.calls.record(summary)
+}
+
+// isToolExhausted reports whether the named tool has exceeded its maximum call limit.
+func (s *executorState) isToolExhausted(toolName string) bool {
+	usageValue, ok := s.usage.Load(toolName)
+	if !ok {
+		return false
+	}
+	return atomic.LoadInt32(&usageValue.(*ToolUsage).Exhausted) != 0
+}
+
+// usageStats returns aggregate execution statistics for all tools.
+func (s *executorState) usageStats() map[string]ToolUsage {
+	stats := make(map[string]ToolUsage)
+	s.usage.Range(func(key, value interface{}) bool {
+		toolName := key.(string)
+		usage := value.(*ToolUsage)
+		stats[toolName] = ToolUsage{
+			CallCount:       atomic.LoadInt64(&usage.CallCount),
+			TotalDurationNs: atomic.LoadInt64(&usage.TotalDurationNs),
+			Exhausted:       atomic.LoadInt32(&usage.Exhausted),
+			PoolHits:        atomic.LoadInt64(&usage.PoolHits),
+			PoolMisses:      atomic.LoadInt64(&usage.PoolMisses),
+			PoolSavedNs:     atomic.LoadInt64(&usage.PoolSavedNs),
+		}
+		return true
+	})
+	return stats
+}
+
+// callSummaries returns a copy of the log of every recorded invocation attempt.
+func (s *executorState) callSummaries() []ToolCallSummary {
+	return s.calls.snapshot()
+}
+
+// completeProcessCall records the outcome of a tool process that ran to completion in the
+// call summary and returns the result of the call: the trimmed standard output if the
+// process exited successfully and produced any.
+func completeProcessCall(ctx context.Context, logger logging.Logger, summary *ToolCallSummary, exitCode int64, stdout string, stderr string) (json.RawMessage, error) {
+	logger.Message(ctx, logging.LevelTrace, "tool process stdout:\n%s\nstderr:\n%s", stdout, stderr)
+	summary.ExitCode = &exitCode
+
+	if exitCode != 0 {
+		summary.Status = toolCallStatusNonZeroExit
+		summary.Stdout = newOutputCapture(stdout, true)
+		summary.Stderr = newOutputCapture(stderr, true)
+		combinedOutput := strings.TrimSpace(stdout + stderr)
+		wrapErr := fmt.Errorf("%w: tool process exited with code %d: %s", ErrToolExecutionFailed, exitCode, combinedOutput)
+		summary.ErrorMessage = wrapErr.Error()
+		return nil, wrapErr
+	}
+	logger.Message(ctx, logging.LevelInfo, "tool process finished successfully")
+	summary.Stdout = newOutputCapture(stdout, false) // omit preview on success to save space
+	summary.Stderr = newOutputCapture(stderr, true)  // report stray stderr output even on success
+
+	result := strings.TrimSpace(stdout)
+	if result == "" {
+		wrapErr := fmt.Errorf("%w: tool returned no output", ErrToolExecutionFailed)
+		summary.Status, summary.ErrorMessage = toolCallStatusEmptyOutput, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	summary.Status = toolCallStatusSuccess
+	return json.RawMessage(result), nil
+}
+
+// SandboxTool is a tool that runs a command in a sandbox provided by the executor backend,
+// such as a Docker container or an isolated local process.
+type SandboxTool struct {
+	name           string
+	image          string
+	build          *config.ToolBuildConfig
+	description    string
+	parameters     map[string]interface{}
+	parameterFiles map[string]string
+	auxiliaryDir   string
+	sharedDir      string
+	command        []string
+	env            map[string]string
+	maxCalls       *int
+	timeout        *time.Duration
+	maxMemoryMB    *int
+	cpuPercent     *int
+	session        *config.ToolSessionConfig
+	pool           *config.ToolPoolConfig
+	artifacts      *config.ToolArtifactsConfig
+}
+
+// NewSandboxTool creates a new sandboxed tool.
+func NewSandboxTool(cfg *config.ToolConfig, maxCalls *int, timeout *time.Duration, maxMemoryMB *int, cpuPercent *int) *SandboxTool {
+	return &SandboxTool{
+		name:           cfg.Name,
+		image:          cfg.Image,
+		build:          cfg.Build,
+		description:    cfg.Description,
+		parameters:     cfg.Parameters,
+		parameterFiles: cfg.ParameterFiles,
+		auxiliaryDir:   cfg.AuxiliaryDir,
+		sharedDir:      cfg.SharedDir,
+		command:        cfg.Command,
+		env:            cfg.Env,
+		maxCalls:       maxCalls,
+		timeout:        timeout,
+		maxMemoryMB:    maxMemoryMB,
+		cpuPercent:     cpuPercent,
+		session:        cfg.Session,
+		pool:           cfg.Pool,
+		artifacts:      cfg.Artifacts,
+	}
+}
+
+func (t *SandboxTool) getTimeoutValue() string {
+	if t.timeout != nil {
+		return fmt.Sprintf("%v", *t.timeout)
+	}
+	return "<none>"
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+)
+
+// errHTTPHostNotAllowed is returned when a request of an HTTP tool targets a host outside its allowlist.
+var errHTTPHostNotAllowed = errors.New("host is not allowed")
+
+// HTTPTool is a tool that forwards each call to an HTTP service.
+type HTTPTool struct {
+	name     string
+	http     config.HTTPToolConfig
+	client   *http.Client
+	maxCalls *int
+	timeout  *time.Duration
+}
+
+// NewHTTPTool creates a new HTTP tool. Requests, including redirects, are only sent to
+// the hosts allowed by the tool configuration.
+func NewHTTPTool(cfg *config.ToolConfig, maxCalls *int, timeout *time.Duration) *HTTPTool {
+	tool := &HTTPTool{
+		name:     cfg.Name,
+		maxCalls: maxCalls,
+		timeout:  timeout,
+	}
+	if cfg.HTTP != nil {
+		tool.http = *cfg.HTTP
+	}
+	tool.client = &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return tool.checkHost(req.URL)
+		},
+	}
+	return tool
+}
+
+func (t *HTTPTool) getTimeoutValue() string {
+	if t.timeout != nil {
+		return fmt.Sprintf("%v", *t.timeout)
+	}
+	return "<none>"
+}
+
+// checkHost returns an error if the tool may not send requests to the host of the URL.
+func (t *HTTPTool) checkHost(target *url.URL) error {
+	if !t.http.IsHostAllowed(target.Hostname()) {
+		return fmt.Errorf("%w: %s", errHTTPHostNotAllowed, target.Hostname())
+	}
+	return nil
+}
+
+// RegisterHTTPTool registers an HTTP tool with the executor.
+func (s *executorState) RegisterHTTPTool(tool *HTTPTool) {
+	s.tools.Store(tool.name, tool)
+}
+
+// validateHTTPTool checks that the HTTP service of the tool is configured and allowed.
+func validateHTTPTool(cfg config.ToolConfig) error {
+	if cfg.HTTP == nil {
+		return fmt.Errorf("%w: http settings are not configured for tool %q", ErrToolInternal, cfg.Name)
+	}
+	if err := cfg.HTTP.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrToolNotAvailable, err)
+	}
+	return nil
+}
+
+// executeHTTPTool POSTs the arguments of a call to the HTTP service of the tool and returns
+// the response body. callID uniquely identifies this call (see ExecuteTool) and callCtx carries
+// optional caller-supplied metadata (see ToolCallContext); callCtx may be nil.
+func (s *executorState) executeHTTPTool(ctx context.Context, logger logging.Logger, tool *HTTPTool, args json.RawMessage, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
+	startTime := time.Now()
+	summary := ToolCallSummary{CallID: callID, StartedAt: startTime, Arguments: args}
+	if callCtx != nil {
+		summary.ConversationTurn = callCtx.ConversationTurn
+	}
+	defer func() {
+		summary.CompletedAt = time.Now()
+		summary.WallTimeNs = summary.CompletedAt.Sub(startTime).Nanoseconds()
+		s.recordCallSummary(tool.name, summary)
+		logCallOutcome(ctx, logger, summary)
+	}()
+
+	if !json.Valid(args) {
+		logger.Message(ctx, logging.LevelError, "failed to parse input arguments: %s", string(args))
+		wrapErr := fmt.Errorf("%w: input arguments are not valid JSON", ErrInvalidToolArguments)
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	// Apply timeout if specified.
+	execCtx := ctx
+	if tool.timeout != nil {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, *tool.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(execCtx, http.MethodPost, tool.http.URL, bytes.NewReader(args))
+	if err == nil {
+		err = tool.checkHost(req.URL)
+	}
+	if err != nil {
+		wrapErr := fmt.Errorf("%w: failed to create request: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range tool.http.Headers {
+		req.Header.Set(name, value)
+	}
+
+	logger.Message(ctx, logging.LevelInfo, "sending request to %s", tool.http.URL)
+	runStart := time.Now()
+	body, statusCode, err := tool.send(req)
+	runDuration := time.Since(runStart)
+	s.recordUsage(tool.name, runDuration)
+	durationNs := runDuration.Nanoseconds()
+	summary.DurationNs = &durationNs
+
+	var tooLargeErr *httpResponseTooLargeError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		wrapErr := fmt.Errorf("%w: execution timed out after %s", ErrToolTimeout, tool.getTimeoutValue())
+		summary.Status, summary.TimedOut, summary.ErrorMessage = toolCallStatusTimeout, true, wrapErr.Error()
+		return nil, wrapErr
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		wrapErr := fmt.Errorf("%w: execution was cancelled", ErrToolInternal)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	case errors.As(err, &tooLargeErr):
+		wrapErr := fmt.Errorf("%w: %v", ErrToolExecutionFailed, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusToolError, wrapErr.Error()
+		return nil, wrapErr
+	case err != nil:
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	output := string(body)
+	logger.Message(ctx, logging.LevelTrace, "http tool response status %d, body:\n%s", statusCode, output)
+	if statusCode < 200 || statusCode > 299 {
+		summary.Stdout = newOutputCapture(output, true)
+		wrapErr := fmt.Errorf("%w: service responded with status %d: %s", ErrToolExecutionFailed, statusCode, strings.TrimSpace(output))
+		summary.Status, summary.ErrorMessage = toolCallStatusToolError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	summary.Stdout = newOutputCapture(output, false) // omit preview on success to save space
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		wrapErr := fmt.Errorf("%w: tool returned no output", ErrToolExecutionFailed)
+		summary.Status, summary.ErrorMessage = toolCallStatusEmptyOutput, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	summary.Status = toolCallStatusSuccess
+	return json.RawMessage(output), nil
+}
+
+// httpResponseTooLargeError is returned when the response body of an HTTP tool exceeds its size limit.
+type httpResponseTooLargeError struct {
+	limit int64
+}
+
+func (e *httpResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the limit of %d bytes", e.limit)
+}
+
+// send sends the request and reads the response body up to the size limit of the tool.
+func (t *HTTPTool) send(req *http.Request) (body []byte, statusCode int, err error) {
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	limit := t.http.GetMaxResponseBytes()
+	if resp.ContentLength > limit {
+		return nil, resp.StatusCode, &httpResponseTooLargeError{limit: limit}
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, resp.StatusCode, &httpResponseTooLargeError{limit: limit}
+	}
+	return body, resp.StatusCode, nil
+}
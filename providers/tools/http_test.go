@@ -0,0 +1,162 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func newTestHTTPTool(t *testing.T, httpCfg config.HTTPToolConfig, maxCalls *int, timeout *time.Duration) *SubprocessToolExecutor {
+	cfg := &config.ToolConfig{
+		Name:        "service",
+		Type:        config.ToolTypeHTTP,
+		Description: "http tool",
+		Parameters:  map[string]interface{}{"type": "object"},
+		HTTP:        &httpCfg,
+	}
+	executor := NewSubprocessToolExecutor()
+	t.Cleanup(func() {
+		_ = executor.Close()
+	})
+	require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), *cfg))
+	executor.RegisterHTTPTool(NewHTTPTool(cfg, maxCalls, timeout))
+	return executor
+}
+
+func onlyHTTPCall(t *testing.T, executor *SubprocessToolExecutor) ToolCallSummary {
+	summaries := executor.GetCallSummaries()
+	require.Len(t, summaries, 1)
+	return summaries[0]
+}
+
+func TestHTTPToolExecuteTool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + "}\n"))
+	}))
+	defer server.Close()
+
+	executor := newTestHTTPTool(t, config.HTTPToolConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}, nil, nil)
+	result, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "service", json.RawMessage(`{"city":"Paris"}`), nil, &ToolCallContext{CallID: "call-1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"echo":{"city":"Paris"}}`, string(result))
+
+	call := onlyHTTPCall(t, executor)
+	assert.Equal(t, "service", call.Tool)
+	assert.Equal(t, "call-1", call.CallID)
+	assert.Equal(t, toolCallStatusSuccess, call.Status)
+	assert.Equal(t, json.RawMessage(`{"city":"Paris"}`), call.Arguments)
+	require.NotNil(t, call.DurationNs)
+	require.NotNil(t, call.Stdout)
+	assert.Nil(t, call.Stdout.Preview)
+	assert.Equal(t, int64(1), executor.GetUsageStats()["service"].CallCount)
+}
+
+func TestHTTPToolExecuteTool_Failures(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "city not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body) // lets the server notice the client giving up
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(r.Host, "127.0.0.1", "http://localhost", 1)+"/empty", http.StatusTemporaryRedirect)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		args       string
+		wantErr    error
+		wantStatus string
+		timedOut   bool
+	}{
+		{name: "error status", path: "/error", wantErr: ErrToolExecutionFailed, wantStatus: toolCallStatusToolError},
+		{name: "response too large", path: "/large", wantErr: ErrToolExecutionFailed, wantStatus: toolCallStatusToolError},
+		{name: "empty response", path: "/empty", wantErr: ErrToolExecutionFailed, wantStatus: toolCallStatusEmptyOutput},
+		{name: "timeout", path: "/slow", wantErr: ErrToolTimeout, wantStatus: toolCallStatusTimeout, timedOut: true},
+		{name: "redirect to host not allowed", path: "/redirect", wantErr: ErrToolInternal, wantStatus: toolCallStatusInfrastructureError},
+		{name: "invalid arguments", path: "/empty", args: `{"city":`, wantErr: ErrInvalidToolArguments, wantStatus: toolCallStatusInvalidArguments},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newTestHTTPTool(t, config.HTTPToolConfig{
+				URL:              server.URL + tt.path,
+				AllowedHosts:     []string{"127.0.0.1"},
+				MaxResponseBytes: testutils.Ptr(int64(32)),
+			}, nil, testutils.Ptr(200*time.Millisecond))
+
+			args := tt.args
+			if args == "" {
+				args = `{}`
+			}
+			_, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "service", json.RawMessage(args), nil, nil)
+			require.ErrorIs(t, err, tt.wantErr)
+
+			call := onlyHTTPCall(t, executor)
+			assert.Equal(t, tt.wantStatus, call.Status)
+			assert.Equal(t, tt.timedOut, call.TimedOut)
+			assert.NotEmpty(t, call.ErrorMessage)
+		})
+	}
+}
+
+func TestHTTPToolExecuteTool_MaxCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	executor := newTestHTTPTool(t, config.HTTPToolConfig{URL: server.URL}, testutils.Ptr(1), nil)
+	logger := testutils.NewTestLogger(t)
+
+	_, err := executor.ExecuteTool(context.Background(), logger, "service", json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+	_, err = executor.ExecuteTool(context.Background(), logger, "service", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolMaxCallsExceeded)
+	assert.True(t, executor.IsToolExhausted("service"))
+}
+
+func TestValidateHTTPTool(t *testing.T) {
+	cfg := config.ToolConfig{Name: "service", Type: config.ToolTypeHTTP}
+	require.ErrorIs(t, validateHTTPTool(cfg), ErrToolInternal)
+
+	cfg.HTTP = &config.HTTPToolConfig{URL: "http://example.com/weather"}
+	require.ErrorIs(t, validateHTTPTool(cfg), ErrToolNotAvailable)
+
+	cfg.HTTP.AllowedHosts = []string{"example.com"}
+	assert.NoError(t, validateHTTPTool(cfg))
+	assert.NoError(t, (&DockerToolExecutor{}).ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+}
@@ -0,0 +1,835 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/version"
+)
+
+const (
+	// mcpProtocolVersion is the MCP protocol revision requested when initializing a session.
+	mcpProtocolVersion = "2025-06-18"
+	// mcpToolNameSeparator separates the name of the MCP tool configuration from the name
+	// of a tool discovered on its server (see MCPToolName).
+	mcpToolNameSeparator = "__"
+	// defaultMCPStartupTimeout limits how long to wait for an MCP server to start
+	// and list its tools, unless configured otherwise.
+	defaultMCPStartupTimeout = 30 * time.Second
+	// mcpShutdownTimeout limits how long to wait for a local MCP server process to exit
+	// after its connection is closed, before it is killed.
+	mcpShutdownTimeout = 5 * time.Second
+	// mcpStartupPollInterval is how often a local MCP server using the streamable HTTP
+	// transport is probed until it accepts connections.
+	mcpStartupPollInterval = 100 * time.Millisecond
+	// maxMCPMessageBytes caps the size of a single message read from an MCP server.
+	maxMCPMessageBytes = 64 * 1024 * 1024
+)
+
+// JSON-RPC error codes with a specific meaning for MCP tool calls.
+const (
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+)
+
+// ErrMCPServer is returned when an MCP server cannot be started or does not follow the protocol.
+var ErrMCPServer = errors.New("mcp server error")
+
+var invalidToolNameCharMatcher = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// MCPToolName returns the name under which a tool discovered on an MCP server is exposed
+// to the model: the name of the MCP tool configuration and the name of the tool joined by
+// a double underscore, with characters that providers do not accept in tool names replaced
+// by underscores.
+func MCPToolName(serverName string, toolName string) string {
+	return invalidToolNameCharMatcher.ReplaceAllString(serverName+mcpToolNameSeparator+toolName, "_")
+}
+
+// jsonRPCMessage is a JSON-RPC 2.0 request, notification or response.
+type jsonRPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  interface{}     `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+}
+
+// jsonRPCError is the error object of a failed JSON-RPC request.
+type jsonRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *jsonRPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+func newJSONRPCRequest(id int64, method string, params interface{}) jsonRPCMessage {
+	return jsonRPCMessage{JSONRPC: "2.0", ID: json.RawMessage(fmt.Sprint(id)), Method: method, Params: params}
+}
+
+// isResponse reports whether the message is a response rather than a request or notification.
+func (m jsonRPCMessage) isResponse() bool {
+	return m.Method == "" && len(m.ID) > 0
+}
+
+// mcpTransport exchanges JSON-RPC messages with an MCP server.
+type mcpTransport interface {
+	// call sends a request and waits for its response.
+	call(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	// notify sends a notification.
+	notify(ctx context.Context, method string, params interface{}) error
+	// close ends the session.
+	close() error
+}
+
+// MCPServer is a session with a Model Context Protocol (MCP) server, which may be
+// a local process launched for the session.
+type MCPServer struct {
+	name      string
+	transport mcpTransport
+	cmd       *exec.Cmd
+	exited    chan struct{} // closed when the local process exits
+	stderr    *tailBuffer
+	closeOnce sync.Once
+}
+
+// mcpToolInfo describes a tool listed by an MCP server.
+type mcpToolInfo struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// mcpContent is a single content item of an MCP tool call result.
+type mcpContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Resource *struct {
+		URI  string `json:"uri"`
+		Text string `json:"text,omitempty"`
+	} `json:"resource,omitempty"`
+}
+
+// mcpCallToolResult is the result of an MCP tool call.
+type mcpCallToolResult struct {
+	Content           []mcpContent    `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// text returns the result as text to pass back to the model: the structured content if
+// present, otherwise the text of all content items, with non-text items summarized.
+func (r mcpCallToolResult) text() string {
+	if len(r.StructuredContent) > 0 && string(r.StructuredContent) != "null" && !r.IsError {
+		return string(r.StructuredContent)
+	}
+	parts := make([]string, 0, len(r.Content))
+	for _, content := range r.Content {
+		switch {
+		case content.Type == "text":
+			parts = append(parts, content.Text)
+		case content.Resource != nil && content.Resource.Text != "":
+			parts = append(parts, content.Resource.Text)
+		case content.Resource != nil:
+			parts = append(parts, fmt.Sprintf("[%s: %s]", content.Type, content.Resource.URI))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s: %s, %d bytes base64-encoded]", content.Type, content.MimeType, len(content.Data)))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// StartMCPServer launches or connects to the MCP server of the given tool configuration
+// and initializes a session with it. A server that does not start within its startup
+// timeout is stopped and an error is returned. Close the server when no longer needed.
+func StartMCPServer(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) (server *MCPServer, err error) {
+	if cfg.MCP == nil {
+		return nil, fmt.Errorf("%w: mcp settings are not configured for tool %q", ErrToolInternal, cfg.Name)
+	}
+	startupTimeout := defaultMCPStartupTimeout
+	if cfg.MCP.StartupTimeout != nil {
+		startupTimeout = *cfg.MCP.StartupTimeout
+	}
+	startCtx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+
+	server = &MCPServer{name: cfg.Name, stderr: newTailBuffer(maxCallOutputPreviewBytes)}
+	defer func() {
+		if err != nil {
+			server.Close()
+			server = nil
+		}
+	}()
+
+	var stdin io.WriteCloser
+	var stdout io.ReadCloser
+	if len(cfg.Command) > 0 {
+		server.cmd = exec.Command(cfg.Command[0], cfg.Command[1:]...) //nolint:gosec // the command is defined by the user configuration
+		server.cmd.Env = os.Environ()
+		for k, v := range cfg.Env {
+			server.cmd.Env = append(server.cmd.Env, fmt.Sprintf("%s=%s", k, v))
+		}
+		server.cmd.Stderr = server.stderr
+		if cfg.MCP.Transport == config.MCPTransportStdio {
+			if stdin, err = server.cmd.StdinPipe(); err != nil {
+				return server, fmt.Errorf("%w: failed to connect to mcp server %q: %v", ErrToolInternal, cfg.Name, err)
+			}
+			if stdout, err = server.cmd.StdoutPipe(); err != nil {
+				return server, fmt.Errorf("%w: failed to connect to mcp server %q: %v", ErrToolInternal, cfg.Name, err)
+			}
+		}
+		if err = server.cmd.Start(); err != nil {
+			return server, fmt.Errorf("%w: failed to launch mcp server %q: %v", ErrToolNotAvailable, cfg.Name, err)
+		}
+		server.exited = make(chan struct{})
+		go func() {
+			_ = server.cmd.Wait()
+			close(server.exited)
+		}()
+		logger.Message(ctx, logging.LevelDebug, "launched mcp server %q (pid: %d)", cfg.Name, server.cmd.Process.Pid)
+	}
+
+	switch cfg.MCP.Transport {
+	case config.MCPTransportStdio:
+		if server.cmd == nil {
+			return server, fmt.Errorf("%w: command is not configured for mcp server %q", ErrToolInternal, cfg.Name)
+		}
+		server.transport = newMCPStdioTransport(ctx, logger, stdin, stdout)
+	case config.MCPTransportStreamableHTTP:
+		server.transport = newMCPHTTPTransport(cfg.MCP.URL, cfg.MCP.Headers)
+	default:
+		return server, fmt.Errorf("%w: %w: mcp transport %q", ErrToolInternal, ErrUnsupportedToolType, cfg.MCP.Transport)
+	}
+
+	if err = server.initialize(startCtx, logger); err != nil {
+		return server, err
+	}
+	return server, nil
+}
+
+// initialize performs the MCP initialization handshake. A local server using the streamable
+// HTTP transport is probed repeatedly until it accepts connections.
+func (s *MCPServer) initialize(ctx context.Context, logger logging.Logger) error {
+	params := map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": version.Name, "version": version.GetVersion()},
+	}
+	for {
+		result, err := s.transport.call(ctx, "initialize", params)
+		if err == nil {
+			var initResult struct {
+				ProtocolVersion string `json:"protocolVersion"`
+				ServerInfo      struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+				} `json:"serverInfo"`
+			}
+			if err := json.Unmarshal(result, &initResult); err != nil {
+				return fmt.Errorf("%w: %q returned an invalid initialize result: %v", ErrMCPServer, s.name, err)
+			}
+			if httpTransport, ok := s.transport.(*mcpHTTPTransport); ok {
+				httpTransport.protocolVersion = initResult.ProtocolVersion
+			}
+			logger.Message(ctx, logging.LevelDebug, "initialized mcp server %q (%s %s, protocol %s)", s.name, initResult.ServerInfo.Name, initResult.ServerInfo.Version, initResult.ProtocolVersion)
+			if err := s.transport.notify(ctx, "notifications/initialized", nil); err != nil {
+				return fmt.Errorf("%w: failed to complete initialization of %q: %v", ErrMCPServer, s.name, err)
+			}
+			return nil
+		}
+
+		var connErr *mcpConnectionError
+		if _, isHTTP := s.transport.(*mcpHTTPTransport); s.cmd == nil || !isHTTP || !errors.As(err, &connErr) {
+			return s.startupError(ctx, err)
+		}
+		select {
+		case <-ctx.Done():
+			return s.startupError(ctx, err)
+		case <-s.exited:
+			return s.startupError(ctx, errors.New("server process exited"))
+		case <-time.After(mcpStartupPollInterval):
+		}
+	}
+}
+
+// startupError describes why the server failed to start, including its recent error output.
+func (s *MCPServer) startupError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		err = fmt.Errorf("server did not start in time: %v", err)
+	}
+	if s.exited != nil {
+		// Give an exiting process a moment to flush its error output.
+		select {
+		case <-s.exited:
+		case <-time.After(mcpStartupPollInterval):
+		}
+	}
+	if stderr := strings.TrimSpace(s.stderr.String()); stderr != "" {
+		return fmt.Errorf("%w: failed to initialize %q: %v: %s", ErrMCPServer, s.name, err, stderr)
+	}
+	return fmt.Errorf("%w: failed to initialize %q: %v", ErrMCPServer, s.name, err)
+}
+
+// listTools returns all tools listed by the server.
+func (s *MCPServer) listTools(ctx context.Context) ([]mcpToolInfo, error) {
+	var tools []mcpToolInfo
+	var cursor string
+	for {
+		var params interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		result, err := s.transport.call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to list tools of %q: %v", ErrMCPServer, s.name, err)
+		}
+		var page struct {
+			Tools      []mcpToolInfo `json:"tools"`
+			NextCursor string        `json:"nextCursor,omitempty"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
+			return nil, fmt.Errorf("%w: %q returned an invalid tool list: %v", ErrMCPServer, s.name, err)
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// callTool calls the named tool on the server with the given JSON object arguments.
+func (s *MCPServer) callTool(ctx context.Context, name string, args json.RawMessage) (mcpCallToolResult, error) {
+	var result mcpCallToolResult
+	raw, err := s.transport.call(ctx, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return result, fmt.Errorf("%w: %q returned an invalid tool call result: %v", ErrMCPServer, s.name, err)
+	}
+	return result, nil
+}
+
+// Close ends the session and stops the local server process, if any.
+func (s *MCPServer) Close() {
+	s.closeOnce.Do(func() {
+		if s.transport != nil {
+			_ = s.transport.close()
+		}
+		if s.cmd != nil && s.exited != nil {
+			if _, isStdio := s.transport.(*mcpStdioTransport); !isStdio {
+				// Unlike a stdio server, which exits once its input is closed,
+				// a local HTTP server must be asked to exit.
+				_ = s.cmd.Process.Signal(os.Interrupt)
+			}
+			select {
+			case <-s.exited:
+			case <-time.After(mcpShutdownTimeout):
+				_ = s.cmd.Process.Kill()
+				<-s.exited
+			}
+		}
+	})
+}
+
+// mcpConnectionError indicates that a message could not be exchanged with the server at all.
+type mcpConnectionError struct {
+	err error
+}
+
+func (e *mcpConnectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mcpConnectionError) Unwrap() error {
+	return e.err
+}
+
+// mcpStdioTransport exchanges newline-delimited JSON-RPC messages with a local server
+// process over its standard input and output.
+type mcpStdioTransport struct {
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+	pending sync.Map      // map[string]chan jsonRPCMessage, keyed by request ID
+	done    chan struct{} // closed when the server output ends
+	readErr error         // set before done is closed
+}
+
+func newMCPStdioTransport(ctx context.Context, logger logging.Logger, stdin io.WriteCloser, stdout io.Reader) *mcpStdioTransport {
+	t := &mcpStdioTransport{stdin: stdin, done: make(chan struct{})}
+	go t.readLoop(ctx, logger, stdout)
+	return t
+}
+
+// readLoop dispatches responses to waiting requests and answers requests from the server.
+func (t *mcpStdioTransport) readLoop(ctx context.Context, logger logging.Logger, stdout io.Reader) {
+	defer close(t.done)
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if len(line) > maxMCPMessageBytes {
+				t.readErr = fmt.Errorf("message exceeds %d bytes", maxMCPMessageBytes)
+				return
+			}
+			var message jsonRPCMessage
+			if jsonErr := json.Unmarshal(line, &message); jsonErr != nil {
+				logger.Message(ctx, logging.LevelDebug, "ignoring malformed mcp message: %s", strings.TrimSpace(string(line)))
+			} else {
+				t.dispatch(message)
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("server closed its output")
+			}
+			t.readErr = err
+			return
+		}
+	}
+}
+
+func (t *mcpStdioTransport) dispatch(message jsonRPCMessage) {
+	switch {
+	case message.isResponse():
+		if ch, ok := t.pending.LoadAndDelete(string(message.ID)); ok {
+			ch.(chan jsonRPCMessage) <- message
+		}
+	case len(message.ID) > 0: // request from the server
+		reply := jsonRPCMessage{JSONRPC: "2.0", ID: message.ID}
+		if message.Method == "ping" {
+			reply.Result = json.RawMessage("{}")
+		} else {
+			reply.Error = &jsonRPCError{Code: jsonRPCMethodNotFound, Message: "method not supported by client: " + message.Method}
+		}
+		_ = t.write(reply)
+	}
+}
+
+func (t *mcpStdioTransport) write(message jsonRPCMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if _, err := t.stdin.Write(append(data, '\n')); err != nil {
+		return &mcpConnectionError{err}
+	}
+	return nil
+}
+
+func (t *mcpStdioTransport) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	request := newJSONRPCRequest(t.nextID.Add(1), method, params)
+	responseCh := make(chan jsonRPCMessage, 1)
+	t.pending.Store(string(request.ID), responseCh)
+	defer t.pending.Delete(string(request.ID))
+
+	if err := t.write(request); err != nil {
+		return nil, err
+	}
+	select {
+	case response := <-responseCh:
+		if response.Error != nil {
+			return nil, response.Error
+		}
+		return response.Result, nil
+	case <-t.done:
+		return nil, &mcpConnectionError{t.readErr}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *mcpStdioTransport) notify(_ context.Context, method string, params interface{}) error {
+	return t.write(jsonRPCMessage{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// close closes the server input, which asks the server process to exit.
+func (t *mcpStdioTransport) close() error {
+	return t.stdin.Close()
+}
+
+// mcpHTTPTransport exchanges JSON-RPC messages with a server using the streamable HTTP transport.
+// Responses are accepted both as a single JSON message and as a server-sent event stream.
+type mcpHTTPTransport struct {
+	client          *http.Client
+	url             string
+	headers         map[string]string
+	nextID          atomic.Int64
+	sessionMu       sync.Mutex
+	sessionID       string
+	protocolVersion string // set once initialized
+}
+
+func newMCPHTTPTransport(url string, headers map[string]string) *mcpHTTPTransport {
+	return &mcpHTTPTransport{client: &http.Client{}, url: url, headers: headers}
+}
+
+func (t *mcpHTTPTransport) newRequest(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if t.protocolVersion != "" {
+		req.Header.Set("MCP-Protocol-Version", t.protocolVersion)
+	}
+	t.sessionMu.Lock()
+	if t.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", t.sessionID)
+	}
+	t.sessionMu.Unlock()
+	return req, nil
+}
+
+// post sends a message and returns the response, which the caller must close.
+func (t *mcpHTTPTransport) post(ctx context.Context, message jsonRPCMessage) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	req, err := t.newRequest(ctx, http.MethodPost, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &mcpConnectionError{err}
+	}
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		t.sessionMu.Lock()
+		t.sessionID = sessionID
+		t.sessionMu.Unlock()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxCallOutputPreviewBytes))
+		return nil, fmt.Errorf("server responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+func (t *mcpHTTPTransport) call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	request := newJSONRPCRequest(t.nextID.Add(1), method, params)
+	resp, err := t.post(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response *jsonRPCMessage
+	body := io.LimitReader(resp.Body, maxMCPMessageBytes)
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		response, err = readSSEResponse(body, request.ID)
+	} else {
+		response = &jsonRPCMessage{}
+		err = json.NewDecoder(body).Decode(response)
+	}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return nil, ctx.Err()
+	case err != nil:
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	case response.Error != nil:
+		return nil, response.Error
+	}
+	return response.Result, nil
+}
+
+// readSSEResponse reads server-sent events until the response to the request with the given ID.
+func readSSEResponse(body io.Reader, id json.RawMessage) (*jsonRPCMessage, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMCPMessageBytes)
+	var data strings.Builder
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+		if !more || line == "" { // end of event
+			if data.Len() > 0 {
+				var message jsonRPCMessage
+				if err := json.Unmarshal([]byte(data.String()), &message); err == nil && message.isResponse() && bytes.Equal(message.ID, id) {
+					return &message, nil
+				}
+				data.Reset()
+			}
+			if !more {
+				if err := scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, errors.New("event stream ended without a response")
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(value, " "))
+		}
+	}
+}
+
+func (t *mcpHTTPTransport) notify(ctx context.Context, method string, params interface{}) error {
+	resp, err := t.post(ctx, jsonRPCMessage{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxCallOutputPreviewBytes))
+	return resp.Body.Close()
+}
+
+// close terminates the session on the server, if it assigned one.
+func (t *mcpHTTPTransport) close() error {
+	t.sessionMu.Lock()
+	sessionID := t.sessionID
+	t.sessionMu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mcpShutdownTimeout)
+	defer cancel()
+	req, err := t.newRequest(ctx, http.MethodDelete, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// tailBuffer keeps the last bytes written to it, up to its capacity.
+type tailBuffer struct {
+	mu       sync.Mutex
+	data     []byte
+	capacity int
+}
+
+func newTailBuffer(capacity int) *tailBuffer {
+	return &tailBuffer{capacity: capacity}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if excess := len(b.data) - b.capacity; excess > 0 {
+		b.data = slices.Delete(b.data, 0, excess)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// MCPTool is a tool discovered on an MCP server.
+type MCPTool struct {
+	name       string // name exposed to the model
+	remoteName string // name listed by the server
+	server     *MCPServer
+	maxCalls   *int
+	timeout    *time.Duration
+}
+
+func (t *MCPTool) getTimeoutValue() string {
+	if t.timeout != nil {
+		return fmt.Sprintf("%v", *t.timeout)
+	}
+	return "<none>"
+}
+
+// RegisterMCPServer starts the MCP server of the given tool configuration, discovers its tools
+// and registers them with the executor under the names returned by MCPToolName. The maximum
+// number of calls and the timeout apply to each discovered tool. The server is stopped when
+// the executor is closed. It returns the definitions of the registered tools to expose to the model.
+func (s *executorState) RegisterMCPServer(ctx context.Context, logger logging.Logger, cfg *config.ToolConfig, maxCalls *int, timeout *time.Duration) ([]config.ToolConfig, error) {
+	server, err := StartMCPServer(ctx, logger, *cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.mcpServersMu.Lock()
+	s.mcpServers = append(s.mcpServers, server)
+	s.mcpServersMu.Unlock()
+
+	startupTimeout := defaultMCPStartupTimeout
+	if cfg.MCP.StartupTimeout != nil {
+		startupTimeout = *cfg.MCP.StartupTimeout
+	}
+	listCtx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+	listed, err := server.listTools(listCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := make([]config.ToolConfig, 0, len(listed))
+	for _, info := range listed {
+		if len(cfg.MCP.Tools) > 0 && !slices.Contains(cfg.MCP.Tools, info.Name) {
+			continue
+		}
+		name := MCPToolName(cfg.Name, info.Name)
+		parameters := info.InputSchema
+		if parameters == nil {
+			parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		s.tools.Store(name, &MCPTool{
+			name:       name,
+			remoteName: info.Name,
+			server:     server,
+			maxCalls:   maxCalls,
+			timeout:    timeout,
+		})
+		definitions = append(definitions, config.ToolConfig{
+			Name:        name,
+			Type:        config.ToolTypeMCP,
+			Description: info.Description,
+			Parameters:  parameters,
+		})
+	}
+	for _, name := range cfg.MCP.Tools {
+		if !slices.ContainsFunc(listed, func(info mcpToolInfo) bool { return info.Name == name }) {
+			return nil, fmt.Errorf("%w: tool %q is not listed by mcp server %q", ErrToolNotAvailable, name, cfg.Name)
+		}
+	}
+	logger.Message(ctx, logging.LevelDebug, "registered %d tools of mcp server %q", len(definitions), cfg.Name)
+	return definitions, nil
+}
+
+// validateMCPTool checks that the command launching a local MCP server can be found.
+func validateMCPTool(cfg config.ToolConfig) error {
+	if cfg.MCP == nil {
+		return fmt.Errorf("%w: mcp settings are not configured for tool %q", ErrToolInternal, cfg.Name)
+	}
+	if len(cfg.Command) > 0 {
+		if _, err := exec.LookPath(cfg.Command[0]); err != nil {
+			return fmt.Errorf("%w: command %q launching mcp server %q cannot be found: %v", ErrToolNotAvailable, cfg.Command[0], cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// executeMCPTool calls a tool on its MCP server with the given arguments.
+// callID uniquely identifies this call (see ExecuteTool) and callCtx carries
+// optional caller-supplied metadata (see ToolCallContext); callCtx may be nil.
+func (s *executorState) executeMCPTool(ctx context.Context, logger logging.Logger, tool *MCPTool, args json.RawMessage, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
+	startTime := time.Now()
+	summary := ToolCallSummary{CallID: callID, StartedAt: startTime, Arguments: args}
+	if callCtx != nil {
+		summary.ConversationTurn = callCtx.ConversationTurn
+	}
+	defer func() {
+		summary.CompletedAt = time.Now()
+		summary.WallTimeNs = summary.CompletedAt.Sub(startTime).Nanoseconds()
+		s.recordCallSummary(tool.name, summary)
+		logCallOutcome(ctx, logger, summary)
+	}()
+
+	// Check that the arguments are a JSON object, as required by MCP.
+	var argMap map[string]interface{}
+	if err := json.Unmarshal(args, &argMap); err != nil || argMap == nil {
+		if err == nil {
+			err = errors.New("not an object")
+		}
+		logger.Error(ctx, logging.LevelError, err, "failed to parse input arguments: %s", string(args))
+		wrapErr := fmt.Errorf("%w: failed to parse input arguments as JSON object (expected format: {\"argName\": \"value\", ...}): %v", ErrInvalidToolArguments, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	// Apply timeout if specified.
+	execCtx := ctx
+	if tool.timeout != nil {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, *tool.timeout)
+		defer cancel()
+	}
+
+	logger.Message(ctx, logging.LevelInfo, "calling tool %q on mcp server %q", tool.remoteName, tool.server.name)
+	runStart := time.Now()
+	result, err := tool.server.callTool(execCtx, tool.remoteName, args)
+	runDuration := time.Since(runStart)
+	s.recordUsage(tool.name, runDuration)
+	durationNs := runDuration.Nanoseconds()
+	summary.DurationNs = &durationNs
+
+	var rpcErr *jsonRPCError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		wrapErr := fmt.Errorf("%w: execution timed out after %s", ErrToolTimeout, tool.getTimeoutValue())
+		summary.Status, summary.TimedOut, summary.ErrorMessage = toolCallStatusTimeout, true, wrapErr.Error()
+		return nil, wrapErr
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		wrapErr := fmt.Errorf("%w: execution was cancelled", ErrToolInternal)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	case errors.As(err, &rpcErr) && rpcErr.Code == jsonRPCInvalidParams:
+		wrapErr := fmt.Errorf("%w: %s", ErrInvalidToolArguments, rpcErr.Message)
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+		return nil, wrapErr
+	case err != nil:
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	output := result.text()
+	logger.Message(ctx, logging.LevelTrace, "mcp tool %q output:\n%s", tool.remoteName, output)
+	if result.IsError {
+		summary.Stdout = newOutputCapture(output, true)
+		wrapErr := fmt.Errorf("%w: %s", ErrToolExecutionFailed, strings.TrimSpace(output))
+		summary.Status, summary.ErrorMessage = toolCallStatusToolError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	summary.Stdout = newOutputCapture(output, false) // omit preview on success to save space
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		wrapErr := fmt.Errorf("%w: tool returned no output", ErrToolExecutionFailed)
+		summary.Status, summary.ErrorMessage = toolCallStatusEmptyOutput, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	summary.Status = toolCallStatusSuccess
+	return json.RawMessage(output), nil
+}
@@ -0,0 +1,395 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+// testMCPServerEnv selects the behavior of the test binary when it is launched
+// as a stdio MCP server by TestMCPHelperServer.
+const testMCPServerEnv = "MINDTRIAL_TEST_MCP_SERVER"
+
+// testMCPRequest is a JSON-RPC request as received by the test MCP server.
+type testMCPRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+var testMCPTools = []mcpToolInfo{
+	{Name: "add", Description: "Adds two numbers.", InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"a", "b"},
+		"properties": map[string]interface{}{
+			"a": map[string]interface{}{"type": "number"},
+			"b": map[string]interface{}{"type": "number"},
+		},
+	}},
+	{Name: "fail", Description: "Always fails."},
+	{Name: "slow", Description: "Takes a long time."},
+	{Name: "structured", Description: "Returns structured content."},
+	{Name: "empty", Description: "Returns nothing."},
+}
+
+// handleTestMCPRequest implements a minimal MCP server with the tools in testMCPTools.
+// The tool list is split into two pages to exercise pagination.
+func handleTestMCPRequest(request testMCPRequest) (interface{}, *jsonRPCError) {
+	switch request.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "test-server", "version": "1.0.0"},
+		}, nil
+	case "tools/list":
+		var params struct {
+			Cursor string `json:"cursor"`
+		}
+		_ = json.Unmarshal(request.Params, &params)
+		if params.Cursor == "" {
+			return map[string]interface{}{"tools": testMCPTools[:2], "nextCursor": "page-2"}, nil
+		}
+		return map[string]interface{}{"tools": testMCPTools[2:]}, nil
+	case "tools/call":
+		var params struct {
+			Name      string             `json:"name"`
+			Arguments map[string]float64 `json:"arguments"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: err.Error()}
+		}
+		switch params.Name {
+		case "add":
+			a, hasA := params.Arguments["a"]
+			b, hasB := params.Arguments["b"]
+			if !hasA || !hasB {
+				return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "a and b are required"}
+			}
+			return map[string]interface{}{"content": []mcpContent{{Type: "text", Text: fmt.Sprint(a + b)}}}, nil
+		case "fail":
+			return map[string]interface{}{"content": []mcpContent{{Type: "text", Text: "boom"}}, "isError": true}, nil
+		case "slow":
+			time.Sleep(2 * time.Second)
+			return map[string]interface{}{"content": []mcpContent{{Type: "text", Text: "done"}}}, nil
+		case "structured":
+			return map[string]interface{}{
+				"content":           []mcpContent{{Type: "text", Text: `{"ok": true}`}},
+				"structuredContent": map[string]interface{}{"ok": true},
+			}, nil
+		case "empty":
+			return map[string]interface{}{"content": []mcpContent{}}, nil
+		}
+		return nil, &jsonRPCError{Code: jsonRPCInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+	return nil, &jsonRPCError{Code: jsonRPCMethodNotFound, Message: "method not found: " + request.Method}
+}
+
+func newTestMCPResponse(request testMCPRequest) jsonRPCMessage {
+	result, rpcErr := handleTestMCPRequest(request)
+	response := jsonRPCMessage{JSONRPC: "2.0", ID: request.ID, Error: rpcErr}
+	if rpcErr == nil {
+		response.Result, _ = json.Marshal(result)
+	}
+	return response
+}
+
+// TestMCPHelperServer is not a real test. It runs the test MCP server over standard input
+// and output when the test binary is launched by newStdioMCPToolConfig.
+func TestMCPHelperServer(_ *testing.T) {
+	switch os.Getenv(testMCPServerEnv) {
+	case "":
+		return
+	case "crash":
+		fmt.Fprintln(os.Stderr, "fatal: invalid server configuration")
+		os.Exit(1)
+	}
+
+	var writeMu sync.Mutex
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var request testMCPRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil || len(request.ID) == 0 {
+			continue // ignore notifications
+		}
+		go func() {
+			data, _ := json.Marshal(newTestMCPResponse(request))
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			_, _ = os.Stdout.Write(append(data, '\n'))
+		}()
+	}
+	os.Exit(0)
+}
+
+func newStdioMCPToolConfig(t *testing.T, name string, mode string) *config.ToolConfig {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	return &config.ToolConfig{
+		Name:    name,
+		Type:    config.ToolTypeMCP,
+		Command: []string{executable, "-test.run=^TestMCPHelperServer$"},
+		Env:     map[string]string{testMCPServerEnv: mode},
+		MCP:     &config.MCPServerConfig{Transport: config.MCPTransportStdio},
+	}
+}
+
+func newTestMCPExecutor(t *testing.T) *DockerToolExecutor {
+	executor := &DockerToolExecutor{}
+	executor.getSharedDir = newSharedDirFactory()
+	t.Cleanup(func() {
+		_ = executor.Close()
+	})
+	return executor
+}
+
+func toolNames(definitions []config.ToolConfig) []string {
+	names := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+	}
+	return names
+}
+
+func TestMCPToolName(t *testing.T) {
+	assert.Equal(t, "calc__add", MCPToolName("calc", "add"))
+	assert.Equal(t, "my_server__files_read-all", MCPToolName("my server", "files.read-all"))
+}
+
+func TestDockerToolExecutorRegisterMCPServer_Stdio(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+	logger := testutils.NewTestLogger(t)
+	executor := newTestMCPExecutor(t)
+	timeout := 500 * time.Millisecond
+
+	definitions, err := executor.RegisterMCPServer(ctx, logger, newStdioMCPToolConfig(t, "calc", "stdio"), nil, &timeout)
+	require.NoError(t, err)
+	require.Equal(t, []string{"calc__add", "calc__fail", "calc__slow", "calc__structured", "calc__empty"}, toolNames(definitions))
+	assert.Equal(t, config.ToolTypeMCP, definitions[0].Type)
+	assert.Equal(t, "Adds two numbers.", definitions[0].Description)
+	assert.Equal(t, testMCPTools[0].InputSchema, definitions[0].Parameters)
+	assert.Equal(t, map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}, definitions[1].Parameters)
+
+	t.Run("success", func(t *testing.T) {
+		result, err := executor.ExecuteTool(ctx, logger, "calc__add", json.RawMessage(`{"a": 1, "b": 2}`), nil, &ToolCallContext{CallID: "call-1", ConversationTurn: 2})
+		require.NoError(t, err)
+		assert.JSONEq(t, "3", string(result))
+
+		summary := onlyCall(t, executor, "calc__add")
+		assert.Equal(t, toolCallStatusSuccess, summary.Status)
+		assert.Equal(t, "call-1", summary.CallID)
+		assert.Equal(t, 2, summary.ConversationTurn)
+		assert.NotNil(t, summary.DurationNs)
+		assert.Equal(t, int64(1), executor.GetUsageStats()["calc__add"].CallCount)
+	})
+
+	t.Run("structured content", func(t *testing.T) {
+		result, err := executor.ExecuteTool(ctx, logger, "calc__structured", json.RawMessage(`{}`), nil, nil)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ok": true}`, string(result))
+	})
+
+	t.Run("tool error", func(t *testing.T) {
+		_, err := executor.ExecuteTool(ctx, logger, "calc__fail", json.RawMessage(`{}`), nil, nil)
+		require.ErrorIs(t, err, ErrToolExecutionFailed)
+		assert.Contains(t, err.Error(), "boom")
+
+		summary := onlyCall(t, executor, "calc__fail")
+		assert.Equal(t, toolCallStatusToolError, summary.Status)
+		require.NotNil(t, summary.Stdout)
+		require.NotNil(t, summary.Stdout.Preview)
+		assert.Equal(t, "boom", *summary.Stdout.Preview)
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		_, err := executor.ExecuteTool(ctx, logger, "calc__empty", json.RawMessage(`{}`), nil, nil)
+		require.ErrorIs(t, err, ErrToolExecutionFailed)
+		assert.Equal(t, toolCallStatusEmptyOutput, onlyCall(t, executor, "calc__empty").Status)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := executor.ExecuteTool(ctx, logger, "calc__add", json.RawMessage(`{"a": 1}`), nil, nil)
+		require.ErrorIs(t, err, ErrInvalidToolArguments)
+		assert.Contains(t, err.Error(), "a and b are required")
+
+		_, err = executor.ExecuteTool(ctx, logger, "calc__add", json.RawMessage(`[1, 2]`), nil, nil)
+		require.ErrorIs(t, err, ErrInvalidToolArguments)
+
+		calls := callsFor(executor, "calc__add")
+		require.Len(t, calls, 3)
+		assert.Equal(t, toolCallStatusInvalidArguments, calls[1].Status)
+		assert.NotNil(t, calls[1].DurationNs)
+		assert.Equal(t, toolCallStatusInvalidArguments, calls[2].Status)
+		assert.Nil(t, calls[2].DurationNs)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := executor.ExecuteTool(ctx, logger, "calc__slow", json.RawMessage(`{}`), nil, nil)
+		require.ErrorIs(t, err, ErrToolTimeout)
+
+		summary := onlyCall(t, executor, "calc__slow")
+		assert.Equal(t, toolCallStatusTimeout, summary.Status)
+		assert.True(t, summary.TimedOut)
+	})
+}
+
+func TestDockerToolExecutorRegisterMCPServer_ToolFilter(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+	logger := testutils.NewTestLogger(t)
+
+	cfg := newStdioMCPToolConfig(t, "calc", "stdio")
+	cfg.MCP.Tools = []string{"add", "empty"}
+	definitions, err := newTestMCPExecutor(t).RegisterMCPServer(ctx, logger, cfg, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"calc__add", "calc__empty"}, toolNames(definitions))
+
+	cfg = newStdioMCPToolConfig(t, "calc", "stdio")
+	cfg.MCP.Tools = []string{"add", "subtract"}
+	_, err = newTestMCPExecutor(t).RegisterMCPServer(ctx, logger, cfg, nil, nil)
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Contains(t, err.Error(), `tool "subtract" is not listed by mcp server "calc"`)
+}
+
+func TestDockerToolExecutorRegisterMCPServer_MaxCallsExceeded(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+	logger := testutils.NewTestLogger(t)
+	executor := newTestMCPExecutor(t)
+	maxCalls := 1
+
+	_, err := executor.RegisterMCPServer(ctx, logger, newStdioMCPToolConfig(t, "calc", "stdio"), &maxCalls, nil)
+	require.NoError(t, err)
+
+	_, err = executor.ExecuteTool(ctx, logger, "calc__add", json.RawMessage(`{"a": 1, "b": 2}`), nil, nil)
+	require.NoError(t, err)
+	_, err = executor.ExecuteTool(ctx, logger, "calc__add", json.RawMessage(`{"a": 1, "b": 2}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolMaxCallsExceeded)
+	assert.True(t, executor.IsToolExhausted("calc__add"))
+	assert.False(t, executor.IsToolExhausted("calc__fail"))
+}
+
+func TestDockerToolExecutorRegisterMCPServer_StartupFailure(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	_, err := newTestMCPExecutor(t).RegisterMCPServer(ctx, testutils.NewTestLogger(t), newStdioMCPToolConfig(t, "calc", "crash"), nil, nil)
+	require.ErrorIs(t, err, ErrMCPServer)
+	assert.Contains(t, err.Error(), "fatal: invalid server configuration")
+}
+
+func TestDockerToolExecutorRegisterMCPServer_CommandNotFound(t *testing.T) {
+	ctx, cancel := newTestContext()
+	defer cancel()
+
+	cfg := newStdioMCPToolConfig(t, "calc", "stdio")
+	cfg.Command = []string{"mindtrial-missing-mcp-server"}
+	_, err := newTestMCPExecutor(t).RegisterMCPServer(ctx, testutils.NewTestLogger(t), cfg, nil, nil)
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+}
+
+func TestDockerToolExecutorRegisterMCPServer_StreamableHTTP(t *testing.T) {
+	var deleted atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		if r.Method == http.MethodDelete {
+			assert.Equal(t, "session-1", r.Header.Get("Mcp-Session-Id"))
+			deleted.Store(true)
+			return
+		}
+
+		var request testMCPRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		if request.Method == "initialize" {
+			w.Header().Set("Mcp-Session-Id", "session-1")
+		} else {
+			assert.Equal(t, "session-1", r.Header.Get("Mcp-Session-Id"))
+			assert.Equal(t, mcpProtocolVersion, r.Header.Get("MCP-Protocol-Version"))
+		}
+		if len(request.ID) == 0 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		data, err := json.Marshal(newTestMCPResponse(request))
+		require.NoError(t, err)
+		if request.Method == "tools/call" {
+			// Respond with an event stream that carries a notification before the response.
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	ctx, cancel := newTestContext()
+	defer cancel()
+	logger := testutils.NewTestLogger(t)
+	executor := &DockerToolExecutor{}
+	executor.getSharedDir = newSharedDirFactory()
+
+	cfg := &config.ToolConfig{
+		Name: "remote",
+		Type: config.ToolTypeMCP,
+		MCP: &config.MCPServerConfig{
+			Transport: config.MCPTransportStreamableHTTP,
+			URL:       server.URL,
+			Headers:   map[string]string{"Authorization": "secret"},
+		},
+	}
+	definitions, err := executor.RegisterMCPServer(ctx, logger, cfg, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, definitions, len(testMCPTools))
+
+	result, err := executor.ExecuteTool(ctx, logger, "remote__add", json.RawMessage(`{"a": 2, "b": 3}`), nil, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, "5", string(result))
+
+	_, err = executor.ExecuteTool(ctx, logger, "remote__fail", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolExecutionFailed)
+
+	require.NoError(t, executor.Close())
+	assert.True(t, deleted.Load())
+}
+
+func TestDockerToolExecutorValidateTool_MCP(t *testing.T) {
+	executor := newTestMCPExecutor(t)
+
+	require.NoError(t, executor.ValidateTool(t.Context(), testutils.NewTestLogger(t), *newStdioMCPToolConfig(t, "calc", "stdio")))
+	require.NoError(t, executor.ValidateTool(t.Context(), testutils.NewTestLogger(t), config.ToolConfig{
+		Name: "remote",
+		Type: config.ToolTypeMCP,
+		MCP:  &config.MCPServerConfig{Transport: config.MCPTransportStreamableHTTP, URL: "http://localhost:8080/mcp"},
+	}))
+
+	err := executor.ValidateTool(t.Context(), testutils.NewTestLogger(t), config.ToolConfig{
+		Name:    "calc",
+		Type:    config.ToolTypeMCP,
+		Command: []string{"mindtrial-missing-mcp-server"},
+		MCP:     &config.MCPServerConfig{Transport: config.MCPTransportStdio},
+	})
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+}
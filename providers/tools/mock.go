@@ -0,0 +1,150 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+)
+
+// MockTool is a tool that returns canned responses defined in its configuration
+// instead of running anything.
+type MockTool struct {
+	name     string
+	mock     config.MockToolConfig
+	maxCalls *int
+	calls    atomic.Int64 // number of calls made so far, selecting the next response of a sequence
+}
+
+// NewMockTool creates a new mock tool.
+func NewMockTool(cfg *config.ToolConfig, maxCalls *int) *MockTool {
+	tool := &MockTool{
+		name:     cfg.Name,
+		maxCalls: maxCalls,
+	}
+	if cfg.Mock != nil {
+		tool.mock = *cfg.Mock
+	}
+	return tool
+}
+
+// RegisterMockTool registers a mock tool with the executor.
+func (s *executorState) RegisterMockTool(tool *MockTool) {
+	s.tools.Store(tool.name, tool)
+}
+
+// validateMockTool checks that the responses of a mock tool are configured.
+func validateMockTool(cfg config.ToolConfig) error {
+	if cfg.Mock == nil {
+		return fmt.Errorf("%w: mock settings are not configured for tool %q", ErrToolInternal, cfg.Name)
+	}
+	return nil
+}
+
+// respond selects the response to a call of the tool with the given decoded arguments.
+func (t *MockTool) respond(args interface{}) (interface{}, error) {
+	call := t.calls.Add(1) - 1
+	switch {
+	case t.mock.Lookup != nil:
+		lookup := t.mock.Lookup
+		values := lookup.Argument.Select(args)
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: missing argument at %s", ErrInvalidToolArguments, lookup.Argument)
+		}
+		key, ok := values[0].(string)
+		if !ok {
+			data, err := json.Marshal(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to encode argument at %s: %v", ErrInvalidToolArguments, lookup.Argument, err)
+			}
+			key = string(data)
+		}
+		if response, ok := lookup.Responses[key]; ok {
+			return response, nil
+		}
+		if lookup.Default != nil {
+			return lookup.Default, nil
+		}
+		return nil, fmt.Errorf("%w: unsupported value %q of argument at %s", ErrInvalidToolArguments, key, lookup.Argument)
+	case len(t.mock.Sequence) > 0:
+		return t.mock.Sequence[min(call, int64(len(t.mock.Sequence)-1))], nil
+	}
+	return t.mock.Output, nil
+}
+
+// formatMockResponse returns a response verbatim if it is a string, or in its JSON encoding otherwise.
+func formatMockResponse(response interface{}) (string, error) {
+	if text, ok := response.(string); ok {
+		return text, nil
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// executeMockTool returns the canned response of a mock tool to a call with the given arguments.
+// callID uniquely identifies this call (see ExecuteTool) and callCtx carries
+// optional caller-supplied metadata (see ToolCallContext); callCtx may be nil.
+func (s *executorState) executeMockTool(ctx context.Context, logger logging.Logger, tool *MockTool, args json.RawMessage, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
+	startTime := time.Now()
+	summary := ToolCallSummary{CallID: callID, StartedAt: startTime, Arguments: args}
+	if callCtx != nil {
+		summary.ConversationTurn = callCtx.ConversationTurn
+	}
+	defer func() {
+		summary.CompletedAt = time.Now()
+		summary.WallTimeNs = summary.CompletedAt.Sub(startTime).Nanoseconds()
+		s.recordCallSummary(tool.name, summary)
+		logCallOutcome(ctx, logger, summary)
+	}()
+
+	var argValue interface{}
+	if err := json.Unmarshal(args, &argValue); err != nil {
+		logger.Error(ctx, logging.LevelError, err, "failed to parse input arguments: %s", string(args))
+		wrapErr := fmt.Errorf("%w: failed to parse input arguments as JSON: %v", ErrInvalidToolArguments, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	runStart := time.Now()
+	response, err := tool.respond(argValue)
+	s.recordUsage(tool.name, time.Since(runStart))
+	durationNs := time.Since(runStart).Nanoseconds()
+	summary.DurationNs = &durationNs
+	if err != nil {
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, err.Error()
+		return nil, err
+	}
+
+	output, err := formatMockResponse(response)
+	if err != nil {
+		wrapErr := fmt.Errorf("%w: failed to encode mock response: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	logger.Message(ctx, logging.LevelTrace, "mock tool output:\n%s", output)
+	summary.Stdout = newOutputCapture(output, false) // omit preview on success to save space
+
+	output = strings.TrimSpace(output)
+	if output == "" {
+		wrapErr := fmt.Errorf("%w: tool returned no output", ErrToolExecutionFailed)
+		summary.Status, summary.ErrorMessage = toolCallStatusEmptyOutput, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	summary.Status = toolCallStatusSuccess
+	return json.RawMessage(output), nil
+}
@@ -0,0 +1,167 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+func newTestMockTool(t *testing.T, name string, mock config.MockToolConfig, maxCalls *int) (*SubprocessToolExecutor, *config.ToolConfig) {
+	cfg := &config.ToolConfig{
+		Name:        name,
+		Type:        config.ToolTypeMock,
+		Description: "mock tool",
+		Parameters:  map[string]interface{}{"type": "object"},
+		Mock:        &mock,
+	}
+	executor := NewSubprocessToolExecutor()
+	t.Cleanup(func() {
+		_ = executor.Close()
+	})
+	executor.RegisterMockTool(NewMockTool(cfg, maxCalls))
+	return executor, cfg
+}
+
+func TestMockToolExecuteTool(t *testing.T) {
+	city, err := utils.ParseJSONPath("$.city")
+	require.NoError(t, err)
+
+	type call struct {
+		args    string
+		want    string
+		wantErr error
+	}
+	tests := []struct {
+		name  string
+		mock  config.MockToolConfig
+		calls []call
+	}{
+		{
+			name: "static output",
+			mock: config.MockToolConfig{Output: map[string]interface{}{"temperature": 21}},
+			calls: []call{
+				{args: `{"city":"Paris"}`, want: `{"temperature":21}`},
+				{args: `{"city":"Oslo"}`, want: `{"temperature":21}`},
+			},
+		},
+		{
+			name: "sequence repeats last response",
+			mock: config.MockToolConfig{Sequence: []interface{}{"first", 2}},
+			calls: []call{
+				{args: `{}`, want: "first"},
+				{args: `{}`, want: "2"},
+				{args: `{}`, want: "2"},
+			},
+		},
+		{
+			name: "lookup without default",
+			mock: config.MockToolConfig{Lookup: &config.MockLookupConfig{
+				Argument:  city,
+				Responses: map[string]interface{}{"Paris": "sunny", "42": "numeric"},
+			}},
+			calls: []call{
+				{args: `{"city":"Paris"}`, want: "sunny"},
+				{args: `{"city":42}`, want: "numeric"},
+				{args: `{"city":"Oslo"}`, wantErr: ErrInvalidToolArguments},
+				{args: `{"town":"Paris"}`, wantErr: ErrInvalidToolArguments},
+				{args: `not json`, wantErr: ErrInvalidToolArguments},
+			},
+		},
+		{
+			name: "lookup with default",
+			mock: config.MockToolConfig{Lookup: &config.MockLookupConfig{
+				Argument:  city,
+				Responses: map[string]interface{}{"Paris": "sunny"},
+				Default:   "cloudy",
+			}},
+			calls: []call{
+				{args: `{"city":"Oslo"}`, want: "cloudy"},
+			},
+		},
+		{
+			name: "empty output",
+			mock: config.MockToolConfig{Output: " "},
+			calls: []call{
+				{args: `{}`, wantErr: ErrToolExecutionFailed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, cfg := newTestMockTool(t, "weather", tt.mock, nil)
+			require.NoError(t, executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), *cfg))
+			logger := testutils.NewTestLogger(t)
+
+			for _, c := range tt.calls {
+				result, err := executor.ExecuteTool(context.Background(), logger, "weather", json.RawMessage(c.args), nil, &ToolCallContext{ConversationTurn: 2})
+				if c.wantErr != nil {
+					require.ErrorIs(t, err, c.wantErr)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, c.want, string(result))
+			}
+
+			summaries := executor.GetCallSummaries()
+			require.Len(t, summaries, len(tt.calls))
+			for i, summary := range summaries {
+				assert.Equal(t, "weather", summary.Tool)
+				assert.Equal(t, 2, summary.ConversationTurn)
+				assert.Equal(t, json.RawMessage(tt.calls[i].args), summary.Arguments)
+				if tt.calls[i].wantErr == nil {
+					assert.Equal(t, toolCallStatusSuccess, summary.Status)
+				} else {
+					assert.NotEqual(t, toolCallStatusSuccess, summary.Status)
+					assert.NotEmpty(t, summary.ErrorMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestMockToolExecuteTool_MaxCalls(t *testing.T) {
+	executor, _ := newTestMockTool(t, "echo", config.MockToolConfig{Output: "hello"}, testutils.Ptr(1))
+	logger := testutils.NewTestLogger(t)
+
+	_, err := executor.ExecuteTool(context.Background(), logger, "echo", json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+	assert.False(t, executor.IsToolExhausted("echo"))
+
+	_, err = executor.ExecuteTool(context.Background(), logger, "echo", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolMaxCallsExceeded)
+	assert.True(t, executor.IsToolExhausted("echo"))
+	assert.Equal(t, int64(1), executor.GetUsageStats()["echo"].CallCount)
+}
+
+func TestValidateMockTool(t *testing.T) {
+	cfg := config.ToolConfig{Name: "echo", Type: config.ToolTypeMock}
+	require.ErrorIs(t, (&DockerToolExecutor{}).ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg), ErrToolInternal)
+	require.ErrorIs(t, NewSubprocessToolExecutor().ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg), ErrToolInternal)
+
+	cfg.Mock = &config.MockToolConfig{Output: "hello"}
+	assert.NoError(t, (&DockerToolExecutor{}).ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+	assert.NoError(t, NewSubprocessToolExecutor().ValidateTool(context.Background(), testutils.NewTestLogger(t), cfg))
+}
+
+func TestExecutorBackend_MockTools(t *testing.T) {
+	backend, err := ExecutorBackend([]config.ToolConfig{{Name: "echo", Type: config.ToolTypeMock}})
+	require.NoError(t, err)
+	assert.Equal(t, config.ToolTypeSubprocess, backend)
+
+	backend, err = ExecutorBackend([]config.ToolConfig{{Name: "echo", Type: config.ToolTypeMock}, {Name: "python", Image: "python"}})
+	require.NoError(t, err)
+	assert.Equal(t, config.ToolTypeDocker, backend)
+}
@@ -0,0 +1,210 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+// sessionRegistry holds the sessions of the tools of an executor, keyed by tool name.
+type sessionRegistry[T any] struct {
+	mu       sync.Mutex
+	sessions map[string]T
+}
+
+// getOrCreate returns the session of the named tool, creating it if it does not exist.
+func (r *sessionRegistry[T]) getOrCreate(toolName string, create func() T) T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, exists := r.sessions[toolName]; exists {
+		return session
+	}
+	if r.sessions == nil {
+		r.sessions = make(map[string]T)
+	}
+	session := create()
+	r.sessions[toolName] = session
+	return session
+}
+
+// takeAll removes all sessions from the registry and returns them in order of tool names.
+func (r *sessionRegistry[T]) takeAll() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]T, 0, len(r.sessions))
+	for _, toolName := range utils.SortedKeys(r.sessions) {
+		sessions = append(sessions, r.sessions[toolName])
+	}
+	r.sessions = nil
+	return sessions
+}
+
+// WorkspaceSnapshot records the files left in the workspace directory of a tool session
+// when the session ended.
+type WorkspaceSnapshot struct {
+	// Tool is the name of the tool that owned the session.
+	Tool string
+	// Dir is the path of the workspace directory in the sandbox.
+	Dir string
+	// Files lists the regular files found in the workspace, in lexical order of their paths.
+	Files []WorkspaceFile
+	// Truncated indicates that the content of some files was omitted because the
+	// capture size limit was reached.
+	Truncated bool
+	// Error describes why the workspace could not be captured completely, if it could not.
+	Error string
+}
+
+// WorkspaceFile is a file captured from the workspace of a tool session.
+type WorkspaceFile struct {
+	// Path is the slash-separated path of the file relative to the workspace directory.
+	Path string
+	// Bytes is the size of the file.
+	Bytes int64
+	// Content is the content of the file, or nil if it is not valid UTF-8 text or would
+	// exceed the capture size limit.
+	Content *string
+}
+
+// workspaceCapture collects the files of a workspace into a snapshot, keeping file
+// contents until their total size reaches the limit.
+type workspaceCapture struct {
+	snapshot  WorkspaceSnapshot
+	remaining int64
+}
+
+func newWorkspaceCapture(toolName string, dir string, maxBytes int64) *workspaceCapture {
+	return &workspaceCapture{
+		snapshot:  WorkspaceSnapshot{Tool: toolName, Dir: dir},
+		remaining: maxBytes,
+	}
+}
+
+// add records a file of the given size, reading its content from r if it fits the limit.
+func (c *workspaceCapture) add(relPath string, size int64, r io.Reader) error {
+	file := WorkspaceFile{Path: relPath, Bytes: size}
+	if size > c.remaining {
+		c.snapshot.Truncated = true
+	} else {
+		content, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return fmt.Errorf("failed to read workspace file %q: %w", relPath, err)
+		}
+		if utf8.Valid(content) {
+			text := string(content)
+			file.Content = &text
+			c.remaining -= int64(len(content))
+		}
+	}
+	c.snapshot.Files = append(c.snapshot.Files, file)
+	return nil
+}
+
+// fail records the error that stopped the capture.
+func (c *workspaceCapture) fail(err error) WorkspaceSnapshot {
+	c.snapshot.Error = err.Error()
+	return c.snapshot
+}
+
+// captureHostDir captures the regular files in a host directory.
+func (c *workspaceCapture) captureHostDir(root string) WorkspaceSnapshot {
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return c.add(filepath.ToSlash(relPath), info.Size(), file)
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.snapshot
+}
+
+// captureArchive captures the regular files in a tar archive of a directory, as returned
+// by Docker for the directory path. Entry names are prefixed with the directory name.
+func (c *workspaceCapture) captureArchive(archive io.Reader) WorkspaceSnapshot {
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return c.fail(fmt.Errorf("failed to read workspace archive: %w", err))
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// Strip the name of the archived directory itself.
+		_, relPath, _ := strings.Cut(path.Clean(header.Name), "/")
+		if err := c.add(relPath, header.Size, reader); err != nil {
+			return c.fail(err)
+		}
+	}
+	slices.SortFunc(c.snapshot.Files, func(a, b WorkspaceFile) int { return strings.Compare(a.Path, b.Path) })
+	return c.snapshot
+}
+
+// archiveFile is a file to copy into a sandbox.
+type archiveFile struct {
+	path    string // absolute slash-separated path in the sandbox
+	content []byte
+}
+
+// newFileArchive creates a tar archive of the given files, to be extracted at the root
+// of a sandbox.
+func newFileArchive(files []archiveFile) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, file := range files {
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     strings.TrimPrefix(path.Clean(file.path), "/"),
+			Mode:     0o644,
+			Size:     int64(len(file.content)),
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
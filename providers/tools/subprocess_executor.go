@@ -0,0 +1,518 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+const (
+	// defaultSandboxPath is the search path for commands of sandboxed processes
+	// if none is set for MindTrial itself.
+	defaultSandboxPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	// sandboxWaitDelay limits how long to wait for the output of a killed process to be closed.
+	sandboxWaitDelay = time.Second
+)
+
+// sandboxReadOnlyPaths are the host paths made available read-only to sandboxed processes,
+// if they exist.
+var sandboxReadOnlyPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt"}
+
+// SubprocessToolExecutor executes tools as local processes without network access, for
+// environments where Docker is not available. Processes are sandboxed with bubblewrap if it
+// is installed, or in new Linux namespaces set up by MindTrial itself otherwise.
+type SubprocessToolExecutor struct {
+	executorState
+	bubblewrapPath string // empty if bubblewrap is not installed
+	checkSandbox   func() error
+	sessions       sessionRegistry[*subprocessSession]
+}
+
+// subprocessSession is a workspace directory kept for all calls of a session tool within a task.
+type subprocessSession struct {
+	mu      sync.Mutex // serializes the calls of the session
+	tool    *SandboxTool
+	hostDir string // empty until the first call
+}
+
+// NewSubprocessToolExecutor creates a new subprocess tool executor.
+func NewSubprocessToolExecutor() *SubprocessToolExecutor {
+	executor := &SubprocessToolExecutor{}
+	executor.getSharedDir = newSharedDirFactory()
+	if bubblewrapPath, err := exec.LookPath("bwrap"); err == nil {
+		executor.bubblewrapPath = bubblewrapPath
+	}
+	executor.checkSandbox = sync.OnceValue(executor.probeSandbox)
+	return executor
+}
+
+// probeSandbox checks that a trivial command can be run in the sandbox.
+func (d *SubprocessToolExecutor) probeSandbox() error {
+	if err := sandboxSupported(); err != nil {
+		return err
+	}
+	truePath, err := exec.LookPath("true")
+	if err != nil {
+		return fmt.Errorf("failed to find a command to test the sandbox with: %v", err)
+	}
+	tempDir, err := os.MkdirTemp("", "mindtrial-probe-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	cmd := d.sandboxCommand(context.Background(), sandboxSpec{command: []string{truePath}, workDir: tempDir, rootDir: tempDir})
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if d.bubblewrapPath != "" {
+			return fmt.Errorf("failed to run a process sandboxed with bubblewrap: %v: %s", err, strings.TrimSpace(string(output)))
+		}
+		return fmt.Errorf("failed to run a process in new Linux namespaces (install bubblewrap or enable unprivileged user namespaces): %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ValidateTool ensures the command of a subprocess tool can be found and that processes can be
+// sandboxed on this system. MCP, mock and HTTP tools are validated as by the Docker executor.
+func (d *SubprocessToolExecutor) ValidateTool(_ context.Context, _ logging.Logger, cfg config.ToolConfig) error {
+	switch cfg.GetType() {
+	case config.ToolTypeMCP:
+		return validateMCPTool(cfg)
+	case config.ToolTypeMock:
+		return validateMockTool(cfg)
+	case config.ToolTypeHTTP:
+		return validateHTTPTool(cfg)
+	case config.ToolTypeSubprocess:
+	default:
+		return fmt.Errorf("%w: %w: %s tool %q cannot be run as a subprocess", ErrToolInternal, ErrUnsupportedToolType, cfg.GetType(), cfg.Name)
+	}
+
+	if len(cfg.Command) == 0 {
+		return fmt.Errorf("%w: command is not configured for tool %q", ErrToolInternal, cfg.Name)
+	}
+	if err := d.checkSandbox(); err != nil {
+		return fmt.Errorf("%w: subprocess sandbox is not available: %v", ErrToolNotAvailable, err)
+	}
+
+	// Commands provided through parameter files or directories only exist once the tool is called.
+	providedPaths := []string{cfg.AuxiliaryDir, cfg.SharedDir}
+	for _, filePath := range cfg.ParameterFiles {
+		providedPaths = append(providedPaths, filePath)
+	}
+	for _, providedPath := range providedPaths {
+		if providedPath != "" && isWithinPath(cfg.Command[0], providedPath) {
+			return nil
+		}
+	}
+	if _, err := exec.LookPath(cfg.Command[0]); err != nil {
+		return fmt.Errorf("%w: command %q of tool %q cannot be found: %v", ErrToolNotAvailable, cfg.Command[0], cfg.Name, err)
+	}
+	return nil
+}
+
+// ExecuteTool executes a tool by name with the given arguments and auxiliary data files.
+// callCtx carries optional caller-supplied metadata (see ToolCallContext) to attach to the
+// resulting ToolCallSummary; pass nil if there is none to provide.
+func (d *SubprocessToolExecutor) ExecuteTool(ctx context.Context, logger logging.Logger, toolName string, args json.RawMessage, data map[string][]byte, callCtx *ToolCallContext) (json.RawMessage, error) {
+	return d.executeTool(ctx, logger, toolName, args, data, callCtx, d.executeSubprocessTool)
+}
+
+// IsToolExhausted reports whether the named tool has exceeded its maximum call limit.
+// Returns false if the executor is nil or the tool has not been used.
+func (d *SubprocessToolExecutor) IsToolExhausted(toolName string) bool {
+	if d == nil {
+		return false
+	}
+	return d.isToolExhausted(toolName)
+}
+
+// GetUsageStats returns aggregate execution statistics for all tools.
+// Returns nil if the executor is nil.
+func (d *SubprocessToolExecutor) GetUsageStats() map[string]ToolUsage {
+	if d == nil {
+		return nil
+	}
+	return d.usageStats()
+}
+
+// GetCallSummaries returns a log of every recorded invocation attempt across all tools, in
+// the order calls completed, including attempts whose process never ran.
+// Returns nil if the executor is nil.
+func (d *SubprocessToolExecutor) GetCallSummaries() []ToolCallSummary {
+	if d == nil {
+		return nil
+	}
+	return d.callSummaries()
+}
+
+// EndSessions removes the workspace directories of all tool sessions, capturing their
+// files first if configured. Returns nil if the executor is nil.
+func (d *SubprocessToolExecutor) EndSessions(ctx context.Context, logger logging.Logger) []WorkspaceSnapshot {
+	if d == nil {
+		return nil
+	}
+	return d.endSessions(ctx, logger, true)
+}
+
+func (d *SubprocessToolExecutor) endSessions(ctx context.Context, logger logging.Logger, capture bool) (snapshots []WorkspaceSnapshot) {
+	for _, session := range d.sessions.takeAll() {
+		session.mu.Lock()
+		if session.hostDir != "" {
+			if capture && session.tool.session.CaptureWorkspace {
+				snapshot := newWorkspaceCapture(session.tool.name, session.tool.session.WorkspaceDir, session.tool.session.GetMaxCaptureBytes()).captureHostDir(session.hostDir)
+				snapshots = append(snapshots, snapshot)
+			}
+			if err := os.RemoveAll(session.hostDir); err != nil && logger != nil {
+				logger.Error(ctx, logging.LevelWarn, err, "%s: failed to remove session workspace directory", session.tool.name)
+			}
+			session.hostDir = ""
+		}
+		session.mu.Unlock()
+	}
+	return snapshots
+}
+
+// Close removes the workspaces of any tool sessions, stops any MCP servers and cleans up
+// shared directories.
+func (d *SubprocessToolExecutor) Close() error {
+	d.endSessions(context.Background(), nil, false)
+	d.close()
+	return nil
+}
+
+// pathMapping makes a host path available at a path inside the sandbox.
+type pathMapping struct {
+	hostPath    string
+	sandboxPath string
+}
+
+// sandboxSpec describes a process to run in the sandbox.
+type sandboxSpec struct {
+	command     []string
+	env         map[string]string
+	mappings    []pathMapping
+	workDir     string // host directory to start the sandbox in
+	rootDir     string // empty host directory to mount the sandbox root on without bubblewrap
+	sandboxDir  string // directory to run the process in, defaults to the root
+	maxMemoryMB *int
+}
+
+// sandboxInitEnv is the environment variable that makes a MindTrial process set up the sandbox
+// described by its JSON value and execute the sandboxed command instead of running normally.
+const sandboxInitEnv = "MINDTRIAL_SANDBOX_INIT"
+
+// sandboxInit describes the setup done by a re-executed MindTrial process before it executes
+// the sandboxed command.
+type sandboxInit struct {
+	Command        []string
+	Env            []string
+	Dir            string       // directory to execute the command in, if set
+	MaxMemoryBytes int64        // address space limit, if set
+	Root           *sandboxRoot // new root file system, if the process runs in new namespaces
+}
+
+// sandboxRoot describes the root file system of a process running in new namespaces.
+type sandboxRoot struct {
+	Dir           string // empty host directory to mount the root on
+	ReadOnlyPaths []string
+	Binds         []sandboxBind
+}
+
+// sandboxBind makes a host path available read-write at a path inside the sandbox.
+type sandboxBind struct {
+	Source string
+	Target string
+}
+
+// sandboxInitCommand prepares the command re-executing MindTrial to set up the sandbox.
+func sandboxInitCommand(ctx context.Context, setup sandboxInit) *exec.Cmd {
+	self, err := os.Executable()
+	if err != nil {
+		self = os.Args[0]
+	}
+	data, _ := json.Marshal(setup)
+	cmd := exec.CommandContext(ctx, self)
+	cmd.Env = []string{sandboxInitEnv + "=" + string(data)}
+	return cmd
+}
+
+// sandboxCommand prepares the command running the process in the sandbox. The process is
+// killed along with all its descendants when the context is done.
+func (d *SubprocessToolExecutor) sandboxCommand(ctx context.Context, spec sandboxSpec) *exec.Cmd {
+	searchPath := os.Getenv("PATH")
+	if searchPath == "" {
+		searchPath = defaultSandboxPath
+	}
+	env := map[string]string{"PATH": searchPath, "HOME": "/tmp"}
+	maps.Copy(env, spec.env)
+	sandboxDir := spec.sandboxDir
+	if sandboxDir == "" {
+		sandboxDir = "/"
+	}
+
+	setup := sandboxInit{Command: spec.command, Dir: sandboxDir}
+	for _, name := range utils.SortedKeys(env) {
+		setup.Env = append(setup.Env, fmt.Sprintf("%s=%s", name, env[name]))
+	}
+	if spec.maxMemoryMB != nil {
+		setup.MaxMemoryBytes = int64(*spec.maxMemoryMB) * 1024 * 1024
+	}
+
+	var cmd *exec.Cmd
+	if d.bubblewrapPath != "" {
+		args := []string{d.bubblewrapPath, "--die-with-parent", "--new-session", "--unshare-all", "--clearenv"}
+		for _, hostPath := range sandboxReadOnlyPaths {
+			args = append(args, "--ro-bind-try", hostPath, hostPath)
+		}
+		args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+		for _, mapping := range spec.mappings {
+			args = append(args, "--bind", mapping.hostPath, mapping.sandboxPath)
+		}
+		for _, name := range utils.SortedKeys(env) {
+			args = append(args, "--setenv", name, env[name])
+		}
+		args = append(args, "--chdir", sandboxDir, "--")
+		args = append(args, spec.command...)
+		if setup.MaxMemoryBytes == 0 {
+			cmd = exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // the command is defined by the user configuration
+			cmd.Env = setup.Env
+		} else {
+			// Limit the memory of bubblewrap, which passes the limit on to the process.
+			setup.Command, setup.Dir = args, ""
+			cmd = sandboxInitCommand(ctx, setup)
+		}
+		cmd.SysProcAttr = processGroupSysProcAttr()
+	} else {
+		setup.Root = &sandboxRoot{Dir: spec.rootDir, ReadOnlyPaths: sandboxReadOnlyPaths}
+		for _, mapping := range spec.mappings {
+			setup.Root.Binds = append(setup.Root.Binds, sandboxBind{Source: mapping.hostPath, Target: mapping.sandboxPath})
+		}
+		cmd = sandboxInitCommand(ctx, setup)
+		cmd.SysProcAttr = namespaceSysProcAttr()
+	}
+
+	cmd.Dir = spec.workDir
+	cmd.Cancel = func() error {
+		return killProcessTree(cmd.Process)
+	}
+	cmd.WaitDelay = sandboxWaitDelay
+	return cmd
+}
+
+// executeSubprocessTool executes a subprocess tool with the given arguments and auxiliary data
+// files. callID uniquely identifies this call (see ExecuteTool) and callCtx carries
+// optional caller-supplied metadata (see ToolCallContext); callCtx may be nil.
+func (d *SubprocessToolExecutor) executeSubprocessTool(ctx context.Context, logger logging.Logger, tool *SandboxTool, args json.RawMessage, data map[string][]byte, callID string, callCtx *ToolCallContext) (json.RawMessage, error) {
+	startTime := time.Now()
+	summary := ToolCallSummary{CallID: callID, StartedAt: startTime, Arguments: args}
+	if callCtx != nil {
+		summary.ConversationTurn = callCtx.ConversationTurn
+	}
+	// Guarantees exactly one summary is recorded, and its outcome logged, per call
+	// regardless of the return path (see executeDockerTool).
+	defer func() {
+		summary.CompletedAt = time.Now()
+		summary.WallTimeNs = summary.CompletedAt.Sub(startTime).Nanoseconds()
+		d.recordCallSummary(tool.name, summary)
+		logCallOutcome(ctx, logger, summary)
+	}()
+
+	logger.Message(ctx, logging.LevelInfo, "starting setup")
+
+	if err := d.checkSandbox(); err != nil {
+		wrapErr := fmt.Errorf("%w: subprocess sandbox is not available: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	// Parse the arguments.
+	var argMap map[string]interface{}
+	if err := json.Unmarshal(args, &argMap); err != nil {
+		logger.Error(ctx, logging.LevelError, err, "failed to parse input arguments: %s", string(args))
+		wrapErr := fmt.Errorf("%w: failed to parse input arguments as JSON object (expected format: {\"argName\": \"value\", ...}): %v", ErrInvalidToolArguments, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+		return nil, wrapErr
+	}
+	logger.Message(ctx, logging.LevelTrace, "parsed input arguments: %v", argMap)
+
+	// Create a temporary directory holding the files of this call at their sandbox paths.
+	tempDir, err := os.MkdirTemp("", "mindtrial-tool-*")
+	if err != nil {
+		wrapErr := fmt.Errorf("%w: failed to create temporary workspace directory: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	defer os.RemoveAll(tempDir) // clean up temp directory after execution
+	logger.Message(ctx, logging.LevelDebug, "created temporary workspace directory: %s", tempDir)
+	rootDir := filepath.Join(tempDir, "root")
+	workDir := filepath.Join(tempDir, "work")
+
+	sandboxRootDir := filepath.Join(tempDir, "sandbox")
+	if err := os.MkdirAll(sandboxRootDir, 0o755); err != nil {
+		wrapErr := fmt.Errorf("%w: failed to create sandbox root directory: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	spec := sandboxSpec{command: tool.command, env: tool.env, workDir: workDir, rootDir: sandboxRootDir, maxMemoryMB: tool.maxMemoryMB}
+	writeParameterFile := func(sandboxPath string, content []byte) error {
+		hostPath := filepath.Join(rootDir, "params", filepath.FromSlash(sandboxPath))
+		if err := os.MkdirAll(filepath.Dir(hostPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(hostPath, content, 0o644); err != nil {
+			return err
+		}
+		spec.mappings = append(spec.mappings, pathMapping{hostPath: hostPath, sandboxPath: sandboxPath})
+		return nil
+	}
+	if tool.session != nil {
+		// Run in the workspace directory kept for the whole session.
+		session := d.sessions.getOrCreate(tool.name, func() *subprocessSession { return &subprocessSession{tool: tool} })
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		if session.hostDir == "" {
+			hostDir, err := os.MkdirTemp("", "mindtrial-session-*")
+			if err != nil {
+				wrapErr := fmt.Errorf("%w: failed to create session workspace directory: %v", ErrToolInternal, err)
+				summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+				return nil, wrapErr
+			}
+			session.hostDir = hostDir
+			logger.Message(ctx, logging.LevelDebug, "created session workspace directory: %s", hostDir)
+		}
+		spec.workDir, spec.sandboxDir = session.hostDir, tool.session.WorkspaceDir
+		spec.mappings = append(spec.mappings, pathMapping{hostPath: session.hostDir, sandboxPath: tool.session.WorkspaceDir})
+	} else if err := os.MkdirAll(workDir, 0o755); err != nil {
+		wrapErr := fmt.Errorf("%w: failed to create working directory: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	// Write parameter files.
+	for _, argName := range utils.SortedKeys(tool.parameterFiles) {
+		argValue, exists := argMap[argName]
+		if !exists {
+			continue
+		}
+		content, err := argumentFileContent(argValue)
+		if err != nil {
+			logger.Error(ctx, logging.LevelError, err, "failed to marshal argument %q to JSON: %v", argName, argValue)
+			wrapErr := fmt.Errorf("%w: failed to serialize argument %q to JSON (argument values must be JSON-serializable): %v", ErrInvalidToolArguments, argName, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInvalidArguments, wrapErr.Error()
+			return nil, wrapErr
+		}
+		if err := writeParameterFile(tool.parameterFiles[argName], []byte(content)); err != nil {
+			wrapErr := fmt.Errorf("%w: failed to write argument %q to temporary file: %v", ErrToolInternal, argName, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+			return nil, wrapErr
+		}
+		logger.Message(ctx, logging.LevelDebug, "wrote argument %q to sandbox path %s", argName, tool.parameterFiles[argName])
+	}
+
+	// Write data files to auxiliary directory if configured.
+	// Each file is written using its unique name exactly as provided.
+	if tool.auxiliaryDir != "" {
+		auxiliaryHostDir := filepath.Join(rootDir, "aux")
+		if err := os.MkdirAll(auxiliaryHostDir, 0o755); err != nil {
+			wrapErr := fmt.Errorf("%w: failed to create auxiliary directory: %v", ErrToolInternal, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+			return nil, wrapErr
+		}
+		for _, fileName := range utils.SortedKeys(data) {
+			if err := os.WriteFile(filepath.Join(auxiliaryHostDir, fileName), data[fileName], 0o644); err != nil {
+				wrapErr := fmt.Errorf("%w: failed to create temporary file for auxiliary data file %q: %v", ErrToolInternal, fileName, err)
+				summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+				return nil, wrapErr
+			}
+			logger.Message(ctx, logging.LevelDebug, "wrote auxiliary data file %q to sandbox path %s", fileName, path.Join(tool.auxiliaryDir, fileName))
+		}
+		spec.mappings = append(spec.mappings, pathMapping{hostPath: auxiliaryHostDir, sandboxPath: tool.auxiliaryDir})
+	}
+
+	// Map shared directory if configured.
+	if tool.sharedDir != "" {
+		sharedTempDir, err := d.getSharedDir(ctx, &d.executorState)
+		if err != nil {
+			wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+			summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+			return nil, wrapErr
+		}
+		spec.mappings = append(spec.mappings, pathMapping{hostPath: sharedTempDir, sandboxPath: tool.sharedDir})
+		logger.Message(ctx, logging.LevelDebug, "mapped shared directory from %s to sandbox path %s", sharedTempDir, tool.sharedDir)
+	}
+
+	// Apply timeout if specified.
+	execCtx := ctx
+	if tool.timeout != nil {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, *tool.timeout)
+		defer cancel()
+	}
+
+	cmd := d.sandboxCommand(execCtx, spec)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdoutBuf, &stderrBuf
+	logger.Message(ctx, logging.LevelTrace, "setting command: %v", cmd.Args)
+
+	// Start the process and wait for completion.
+	runStart := time.Now()
+	logger.Message(ctx, logging.LevelInfo, "starting execution")
+	if err := cmd.Start(); err != nil {
+		wrapErr := fmt.Errorf("%w: failed to start tool process: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+	err = cmd.Wait()
+	runDuration := time.Since(runStart)
+	d.recordUsage(tool.name, runDuration)
+	durationNs := runDuration.Nanoseconds()
+	summary.DurationNs = &durationNs
+
+	// Handle execution errors.
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		wrapErr := fmt.Errorf("%w: execution timed out after %s", ErrToolTimeout, tool.getTimeoutValue())
+		summary.Status, summary.TimedOut, summary.ErrorMessage = toolCallStatusTimeout, true, wrapErr.Error()
+		return nil, wrapErr
+	case ctx.Err() != nil:
+		wrapErr := fmt.Errorf("%w: execution was cancelled", ErrToolInternal)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	case err != nil && !errors.As(err, &exitErr):
+		wrapErr := fmt.Errorf("%w: %v", ErrToolInternal, err)
+		summary.Status, summary.ErrorMessage = toolCallStatusInfrastructureError, wrapErr.Error()
+		return nil, wrapErr
+	}
+
+	exitCode := int64(processExitCode(cmd.ProcessState))
+	logger.Message(ctx, logging.LevelDebug, "tool process exited with code %d in %v", exitCode, runDuration)
+	summary.Artifacts = collectHostArtifacts(ctx, logger, tool, spec.mappings)
+	return completeProcessCall(ctx, logger, &summary, exitCode, stdoutBuf.String(), stderrBuf.String())
+}
+
+// isWithinPath reports whether the slash-separated path equals or is nested in the base path.
+func isWithinPath(p string, base string) bool {
+	rel, err := filepath.Rel(filepath.FromSlash(base), filepath.FromSlash(p))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
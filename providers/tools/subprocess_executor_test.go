@@ -0,0 +1,306 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+)
+
+func newTestSubprocessExecutor(t *testing.T) *SubprocessToolExecutor {
+	t.Helper()
+	executor := NewSubprocessToolExecutor()
+	if err := executor.checkSandbox(); err != nil {
+		t.Skipf("subprocess sandbox is not available: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = executor.Close()
+	})
+	return executor
+}
+
+func newTestSubprocessTool(name string, command ...string) *config.ToolConfig {
+	return &config.ToolConfig{
+		Name:    name,
+		Type:    config.ToolTypeSubprocess,
+		Command: command,
+	}
+}
+
+func TestSubprocessToolExecutorExecuteTool_Success(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	cfg := newTestSubprocessTool("cat", "sh", "-c", "cat /app/input.txt")
+	cfg.ParameterFiles = map[string]string{"content": "/app/input.txt"}
+	executor.RegisterTool(NewSandboxTool(cfg, nil, nil, nil, nil))
+
+	result, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "cat", json.RawMessage(`{"content":"hello"}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(result))
+
+	summaries := executor.GetCallSummaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, toolCallStatusSuccess, summaries[0].Status)
+	require.NotNil(t, summaries[0].ExitCode)
+	assert.Equal(t, int64(0), *summaries[0].ExitCode)
+	require.NotNil(t, summaries[0].DurationNs)
+	assert.Equal(t, int64(1), executor.GetUsageStats()["cat"].CallCount)
+}
+
+func TestSubprocessToolExecutorExecuteTool_AuxiliaryAndSharedDirectories(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	cfg := newTestSubprocessTool("copy", "sh", "-c", "cat /data/shared.txt 2>/dev/null; cat /aux/file.txt > /data/shared.txt")
+	cfg.AuxiliaryDir = "/aux"
+	cfg.SharedDir = "/data"
+	executor.RegisterTool(NewSandboxTool(cfg, nil, nil, nil, nil))
+	logger := testutils.NewTestLogger(t)
+
+	_, err := executor.ExecuteTool(context.Background(), logger, "copy", json.RawMessage(`{}`), map[string][]byte{"file.txt": []byte("first")}, nil)
+	require.ErrorIs(t, err, ErrToolExecutionFailed) // nothing shared yet
+
+	result, err := executor.ExecuteTool(context.Background(), logger, "copy", json.RawMessage(`{}`), map[string][]byte{"file.txt": []byte("second")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(result))
+}
+
+func TestSubprocessToolExecutorExecuteTool_NonZeroExit(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	executor.RegisterTool(NewSandboxTool(newTestSubprocessTool("fail", "sh", "-c", "echo broken >&2; exit 3"), nil, nil, nil, nil))
+
+	_, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "fail", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolExecutionFailed)
+	assert.ErrorContains(t, err, "tool process exited with code 3: broken")
+
+	summaries := executor.GetCallSummaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, toolCallStatusNonZeroExit, summaries[0].Status)
+	require.NotNil(t, summaries[0].ExitCode)
+	assert.Equal(t, int64(3), *summaries[0].ExitCode)
+}
+
+func TestSubprocessToolExecutorExecuteTool_EmptyOutput(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	executor.RegisterTool(NewSandboxTool(newTestSubprocessTool("noop", "true"), nil, nil, nil, nil))
+
+	_, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "noop", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolExecutionFailed)
+	assert.Equal(t, toolCallStatusEmptyOutput, executor.GetCallSummaries()[0].Status)
+}
+
+func TestSubprocessToolExecutorExecuteTool_Timeout(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	executor.RegisterTool(NewSandboxTool(newTestSubprocessTool("sleep", "sh", "-c", "sleep 5 & wait"), nil, testutils.Ptr(200*time.Millisecond), nil, nil))
+
+	start := time.Now()
+	_, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "sleep", json.RawMessage(`{}`), nil, nil)
+	require.ErrorIs(t, err, ErrToolTimeout)
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	summaries := executor.GetCallSummaries()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, toolCallStatusTimeout, summaries[0].Status)
+	assert.True(t, summaries[0].TimedOut)
+}
+
+func TestSubprocessToolExecutorExecuteTool_Isolation(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	t.Setenv("MINDTRIAL_TEST_SECRET", "secret")
+
+	tests := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			name:     "no network interfaces other than loopback",
+			script:   "tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' '",
+			expected: "lo",
+		},
+		{
+			name:     "host file system is read-only",
+			script:   "touch /usr/mindtrial-test 2>/dev/null && echo writable || echo read-only",
+			expected: "read-only",
+		},
+		{
+			name:     "host home directory is not visible",
+			script:   "test -e /root && echo visible || echo hidden",
+			expected: "hidden",
+		},
+		{
+			name:     "host environment not inherited",
+			script:   "echo \"secret=${MINDTRIAL_TEST_SECRET:-unset}\"",
+			expected: "secret=unset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor.RegisterTool(NewSandboxTool(newTestSubprocessTool(tt.name, "sh", "-c", tt.script), nil, nil, nil, nil))
+			result, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), tt.name, json.RawMessage(`{}`), nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestSubprocessToolExecutorExecuteTool_MemoryLimit(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	executor.RegisterTool(NewSandboxTool(newTestSubprocessTool("limits", "sh", "-c", "ulimit -v"), nil, nil, testutils.Ptr(64), nil))
+
+	result, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "limits", json.RawMessage(`{}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "65536", string(result))
+}
+
+func TestSubprocessToolExecutorExecuteTool_Session(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+	cfg := newTestSubprocessTool("notes", "sh", "-c", "cat note.txt 2>/dev/null; echo \"$(cat /app/input.txt)\" >> note.txt; echo ok")
+	cfg.ParameterFiles = map[string]string{"content": "/app/input.txt"}
+	cfg.Session = &config.ToolSessionConfig{WorkspaceDir: "/workspace", CaptureWorkspace: true}
+	executor.RegisterTool(NewSandboxTool(cfg, nil, nil, nil, nil))
+	logger := testutils.NewTestLogger(t)
+
+	result, err := executor.ExecuteTool(context.Background(), logger, "notes", json.RawMessage(`{"content":"first"}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(result))
+
+	result, err = executor.ExecuteTool(context.Background(), logger, "notes", json.RawMessage(`{"content":"second"}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nok", string(result))
+
+	session := executor.sessions.getOrCreate("notes", nil)
+	hostDir := session.hostDir
+	require.DirExists(t, hostDir)
+
+	snapshots := executor.EndSessions(context.Background(), logger)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, WorkspaceSnapshot{
+		Tool:  "notes",
+		Dir:   "/workspace",
+		Files: []WorkspaceFile{{Path: "note.txt", Bytes: 13, Content: testutils.Ptr("first\nsecond\n")}},
+	}, snapshots[0])
+	assert.NoDirExists(t, hostDir)
+	assert.Empty(t, executor.EndSessions(context.Background(), logger))
+}
+
+func TestSubprocessToolExecutorValidateTool(t *testing.T) {
+	executor := newTestSubprocessExecutor(t)
+
+	tests := []struct {
+		name    string
+		cfg     config.ToolConfig
+		wantErr error
+	}{
+		{
+			name: "command on search path",
+			cfg:  *newTestSubprocessTool("echo", "echo"),
+		},
+		{
+			name:    "command not found",
+			cfg:     *newTestSubprocessTool("missing", "mindtrial-missing-command"),
+			wantErr: ErrToolNotAvailable,
+		},
+		{
+			name: "command provided in auxiliary directory",
+			cfg: config.ToolConfig{
+				Name:         "script",
+				Type:         config.ToolTypeSubprocess,
+				Command:      []string{"/aux/run.sh"},
+				AuxiliaryDir: "/aux",
+			},
+		},
+		{
+			name:    "docker tool",
+			cfg:     config.ToolConfig{Name: "docker", Image: "alpine:latest", Command: []string{"echo"}},
+			wantErr: ErrUnsupportedToolType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.ValidateTool(context.Background(), testutils.NewTestLogger(t), tt.cfg)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSubprocessToolExecutorExecuteTool_WithoutBubblewrap(t *testing.T) {
+	executor := NewSubprocessToolExecutor()
+	executor.bubblewrapPath = ""
+	executor.checkSandbox = sync.OnceValue(executor.probeSandbox)
+	if err := executor.checkSandbox(); err != nil {
+		t.Skipf("Linux namespaces are not available: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = executor.Close()
+	})
+
+	script := strings.Join([]string{
+		"cat /app/input.txt",
+		"ulimit -v",
+		"echo pid=$$",
+		"grep CapEff /proc/self/status | tr -d '\\t'",
+		"touch /usr/mindtrial-test 2>/dev/null && echo writable || echo read-only",
+		"test -e /root && echo visible || echo hidden",
+	}, "; ")
+	cfg := newTestSubprocessTool("check", "sh", "-c", script)
+	cfg.ParameterFiles = map[string]string{"content": "/app/input.txt"}
+	executor.RegisterTool(NewSandboxTool(cfg, nil, nil, testutils.Ptr(64), nil))
+
+	result, err := executor.ExecuteTool(context.Background(), testutils.NewTestLogger(t), "check", json.RawMessage(`{"content":"hello\n"}`), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n65536\npid=1\nCapEff:0000000000000000\nread-only\nhidden", string(result))
+}
+
+func TestExecutorBackend(t *testing.T) {
+	mcpTool := config.ToolConfig{Name: "search", Type: config.ToolTypeMCP}
+	dockerTool := config.ToolConfig{Name: "python", Image: "python:3"}
+	subprocessTool := *newTestSubprocessTool("echo", "echo")
+
+	tests := []struct {
+		name     string
+		cfgs     []config.ToolConfig
+		expected string
+		wantErr  bool
+	}{
+		{name: "docker tools", cfgs: []config.ToolConfig{dockerTool, mcpTool}, expected: config.ToolTypeDocker},
+		{name: "subprocess tools", cfgs: []config.ToolConfig{subprocessTool, mcpTool}, expected: config.ToolTypeSubprocess},
+		{name: "mcp tools only", cfgs: []config.ToolConfig{mcpTool}, expected: config.ToolTypeSubprocess},
+		{name: "docker and subprocess tools", cfgs: []config.ToolConfig{dockerTool, subprocessTool}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := ExecutorBackend(tt.cfgs)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrUnsupportedToolType)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, backend)
+		})
+	}
+}
+
+func TestNewToolExecutor_UnknownBackend(t *testing.T) {
+	executor, err := NewToolExecutor(context.Background(), "vm")
+	require.ErrorIs(t, err, ErrUnsupportedToolType)
+	assert.Nil(t, executor)
+}
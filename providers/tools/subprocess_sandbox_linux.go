@@ -0,0 +1,345 @@
+//go:build linux
+
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxDevices are the host devices made available in the sandbox.
+var sandboxDevices = []string{"null", "zero", "full", "random", "urandom", "tty"}
+
+func init() {
+	if data, ok := os.LookupEnv(sandboxInitEnv); ok {
+		runSandboxInit(data)
+	}
+}
+
+// sandboxSupported reports whether processes can be sandboxed on this platform.
+func sandboxSupported() error {
+	return nil
+}
+
+// namespaceSysProcAttr returns the attributes running a process in new user, mount, network,
+// PID, IPC and UTS namespaces, mapping the current user to root inside the namespace, in its
+// own process group that is killed if MindTrial dies.
+func namespaceSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWNET | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+		},
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+}
+
+// runSandboxInit sets up the sandbox described by data and executes the sandboxed command.
+// It does not return.
+func runSandboxInit(data string) {
+	// Capabilities are per thread, so they are dropped on the thread executing the command.
+	runtime.LockOSThread()
+
+	var setup sandboxInit
+	err := json.Unmarshal([]byte(data), &setup)
+	if err == nil {
+		err = setup.run()
+	}
+	fmt.Fprintf(os.Stderr, "mindtrial sandbox: %v\n", err)
+	os.Exit(127)
+}
+
+func (s sandboxInit) run() error {
+	if len(s.Command) == 0 {
+		return errors.New("no command to execute")
+	}
+	if s.Root != nil {
+		if err := s.Root.mount(); err != nil {
+			return err
+		}
+		if err := setLoopbackUp(); err != nil {
+			return fmt.Errorf("failed to set up loopback interface: %w", err)
+		}
+	}
+	if s.Dir != "" {
+		if err := os.Chdir(s.Dir); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the command using the search path of the sandbox.
+	for _, entry := range s.Env {
+		if value, ok := strings.CutPrefix(entry, "PATH="); ok {
+			_ = os.Setenv("PATH", value)
+		}
+	}
+	commandPath, err := exec.LookPath(s.Command[0])
+	if err != nil {
+		return err
+	}
+
+	if s.Root != nil {
+		if err := dropCapabilities(); err != nil {
+			return fmt.Errorf("failed to drop capabilities: %w", err)
+		}
+	}
+	if s.MaxMemoryBytes > 0 {
+		limit := uint64(s.MaxMemoryBytes)
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &unix.Rlimit{Cur: limit, Max: limit}); err != nil {
+			return fmt.Errorf("failed to limit memory: %w", err)
+		}
+	}
+	return syscall.Exec(commandPath, s.Command, s.Env)
+}
+
+// mount makes the root the root file system of the process. Host paths are only available
+// as configured, with system directories read-only.
+func (r sandboxRoot) mount() error {
+	// Keep the changes from propagating to the host.
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	if err := unix.Mount("tmpfs", r.Dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755"); err != nil {
+		return fmt.Errorf("failed to mount root: %w", err)
+	}
+
+	var readOnlyTargets []string
+	for _, hostPath := range r.ReadOnlyPaths {
+		target := filepath.Join(r.Dir, hostPath)
+		if linkTarget, err := os.Readlink(hostPath); err == nil {
+			if err := os.Symlink(linkTarget, target); err != nil {
+				return err
+			}
+			continue
+		} else if _, err := os.Stat(hostPath); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := bindMount(hostPath, target); err != nil {
+			return err
+		}
+		readOnlyTargets = append(readOnlyTargets, target)
+	}
+
+	if err := mountFileSystem("proc", filepath.Join(r.Dir, "proc"), unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return err
+	}
+	if err := mountFileSystem("tmpfs", filepath.Join(r.Dir, "tmp"), unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+		return err
+	}
+	devDir := filepath.Join(r.Dir, "dev")
+	if err := mountFileSystem("tmpfs", devDir, unix.MS_NOSUID|unix.MS_NOEXEC, "mode=0755"); err != nil {
+		return err
+	}
+	for _, device := range sandboxDevices {
+		if _, err := os.Stat(filepath.Join("/dev", device)); err != nil {
+			continue
+		}
+		if err := bindMount(filepath.Join("/dev", device), filepath.Join(devDir, device)); err != nil {
+			return err
+		}
+	}
+	for i, name := range []string{"stdin", "stdout", "stderr"} {
+		if err := os.Symlink("/proc/self/fd/"+strconv.Itoa(i), filepath.Join(devDir, name)); err != nil {
+			return err
+		}
+	}
+	if err := os.Symlink("/proc/self/fd", filepath.Join(devDir, "fd")); err != nil {
+		return err
+	}
+
+	for _, bind := range r.Binds {
+		if err := bindMount(bind.Source, filepath.Join(r.Dir, bind.Target)); err != nil {
+			return err
+		}
+	}
+	for _, target := range readOnlyTargets {
+		if err := remountReadOnly(target); err != nil {
+			return err
+		}
+	}
+
+	// Switch to the new root and detach the host file system.
+	if err := os.Chdir(r.Dir); err != nil {
+		return err
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("failed to change root: %w", err)
+	}
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("failed to detach host file system: %w", err)
+	}
+	return os.Chdir("/")
+}
+
+// mountFileSystem mounts a new file system of the given type at target, creating the directory.
+func mountFileSystem(fsType string, target string, flags uintptr, data string) error {
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	if err := unix.Mount(fsType, target, fsType, flags, data); err != nil {
+		return fmt.Errorf("failed to mount %s at %s: %w", fsType, target, err)
+	}
+	return nil
+}
+
+// bindMount makes the host file or directory source available at target, creating the target
+// with any missing parent directories.
+func bindMount(source string, target string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		err = os.MkdirAll(target, 0o755)
+	} else if err = os.MkdirAll(filepath.Dir(target), 0o755); err == nil {
+		var file *os.File
+		if file, err = os.OpenFile(target, os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+			err = file.Close()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := unix.Mount(source, target, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("failed to bind %s to %s: %w", source, target, err)
+	}
+	return nil
+}
+
+// remountReadOnly makes the bind mount at target and all mounts below it read-only.
+func remountReadOnly(target string) error {
+	mountInfo, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(mountInfo), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoint := unescapeMountPath(fields[4])
+		if mountPoint != target && !strings.HasPrefix(mountPoint, target+"/") {
+			continue
+		}
+		var stat unix.Statfs_t
+		if err := unix.Statfs(mountPoint, &stat); err != nil {
+			return err
+		}
+		// Flags of mounts inherited from the host are locked and must be kept.
+		flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY)
+		for _, flag := range []struct {
+			statfs int64
+			mount  uintptr
+		}{
+			{unix.ST_NOSUID, unix.MS_NOSUID},
+			{unix.ST_NODEV, unix.MS_NODEV},
+			{unix.ST_NOEXEC, unix.MS_NOEXEC},
+			{unix.ST_NOATIME, unix.MS_NOATIME},
+			{unix.ST_NODIRATIME, unix.MS_NODIRATIME},
+			{unix.ST_RELATIME, unix.MS_RELATIME},
+		} {
+			if stat.Flags&flag.statfs != 0 {
+				flags |= flag.mount
+			}
+		}
+		if stat.Flags&(unix.ST_NOATIME|unix.ST_RELATIME) == 0 {
+			flags |= unix.MS_STRICTATIME
+		}
+		if err := unix.Mount("", mountPoint, "", flags, ""); err != nil {
+			return fmt.Errorf("failed to make %s read-only: %w", mountPoint, err)
+		}
+	}
+	return nil
+}
+
+// unescapeMountPath decodes the octal escapes of a path in /proc/self/mountinfo.
+func unescapeMountPath(escaped string) string {
+	var path strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] == '\\' && i+3 < len(escaped) {
+			if code, err := strconv.ParseUint(escaped[i+1:i+4], 8, 8); err == nil {
+				path.WriteByte(byte(code))
+				i += 3
+				continue
+			}
+		}
+		path.WriteByte(escaped[i])
+	}
+	return path.String()
+}
+
+// setLoopbackUp brings up the loopback interface of the network namespace.
+func setLoopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	request, err := unix.NewIfreq("lo")
+	if err != nil {
+		return err
+	}
+	request.SetUint16(unix.IFF_UP | unix.IFF_RUNNING)
+	return unix.IoctlIfreq(fd, unix.SIOCSIFFLAGS, request)
+}
+
+// dropCapabilities drops all capabilities of the thread, including those the command would
+// gain when executed as root, so it cannot undo the sandbox setup.
+func dropCapabilities() error {
+	for capability := 0; capability <= unix.CAP_LAST_CAP; capability++ {
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0); err != nil && !errors.Is(err, unix.EINVAL) {
+			return err
+		}
+	}
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil {
+		return err
+	}
+	var data [2]unix.CapUserData // version 3 takes two elements
+	if err := unix.Capset(&unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}, &data[0]); err != nil {
+		return err
+	}
+	return unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0)
+}
+
+// processGroupSysProcAttr returns the attributes running a process in its own process group
+// that is killed if MindTrial dies.
+func processGroupSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		Setpgid:   true,
+		Pdeathsig: syscall.SIGKILL,
+	}
+}
+
+// killProcessTree kills the process group of the given process.
+func killProcessTree(process *os.Process) error {
+	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+}
+
+// processExitCode returns the exit code of the exited process, or 128 plus the signal number
+// if the process was terminated by a signal, as reported by shells.
+func processExitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
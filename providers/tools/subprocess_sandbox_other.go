@@ -0,0 +1,40 @@
+//go:build !linux
+
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package tools
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// sandboxSupported reports whether processes can be sandboxed on this platform.
+func sandboxSupported() error {
+	return errors.New("subprocess tools are only supported on Linux")
+}
+
+// namespaceSysProcAttr is not supported on this platform.
+func namespaceSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// processGroupSysProcAttr is not supported on this platform.
+func processGroupSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// killProcessTree kills the given process.
+func killProcessTree(process *os.Process) error {
+	return process.Kill()
+}
+
+// processExitCode returns the exit code of the exited process.
+func processExitCode(state *os.ProcessState) int {
+	return state.ExitCode()
+}
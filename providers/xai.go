@@ -7,11 +7,15 @@
 package providers
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"slices"
 	"strings"
 
@@ -34,10 +38,14 @@ func NewXAI(cfg config.XAIClientConfig, availableTools []config.ToolConfig) (*XA
 		},
 	}
 
+	batches := newBatchTransport("/v1/chat/completions")
+	clientCfg.HTTPClient = batches.client()
+
 	client := xai.NewAPIClient(clientCfg)
 	return &XAI{
 		client:         client,
 		availableTools: availableTools,
+		batches:        batches,
 	}, nil
 }
 
@@ -45,6 +53,7 @@ func NewXAI(cfg config.XAIClientConfig, availableTools []config.ToolConfig) (*XA
 type XAI struct {
 	client         *xai.APIClient
 	availableTools []config.ToolConfig
+	batches        *batchTransport
 }
 
 func (o XAI) Name() string {
@@ -135,23 +144,17 @@ func (o *XAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConf
 	req.Messages = append(req.Messages, xai.MessageOneOf1AsMessage(xai.NewMessageOneOf1(userContent, "user")))
 
 	// Setup tools if any.
-	var executor *tools.DockerToolExecutor
+	var executor tools.ToolExecutor
 	toolSelector := task.GetResolvedToolSelector()
 	if enabledTools, hasTools := toolSelector.GetEnabledToolsByName(); hasTools {
 		var err error
-		executor, err = tools.NewDockerToolExecutor(ctx)
+		var toolDefs []*config.ToolConfig
+		executor, toolDefs, err = setupTools(ctx, logger, o.availableTools, enabledTools)
 		if err != nil {
-			return result, fmt.Errorf("%w: %w", ErrToolSetup, err)
+			return result, err
 		}
-		defer executor.Close()
-		for toolName, toolSelection := range enabledTools {
-			// Find the tool config from available tools
-			toolCfg, found := findToolByName(o.availableTools, toolName)
-			if !found {
-				return result, fmt.Errorf("%w: %s", ErrToolNotFound, toolName)
-			}
-			tool := tools.NewDockerTool(toolCfg, toolSelection.MaxCalls, toolSelection.Timeout, toolSelection.MaxMemoryMB, toolSelection.CpuPercent)
-			executor.RegisterTool(tool)
+		defer result.closeToolExecutor(ctx, logger, executor)
+		for _, toolCfg := range toolDefs {
 			funcDef := xai.NewFunctionDefinition(toolCfg.Name, toolCfg.Parameters)
 			funcDef.SetDescription(toolCfg.Description)
 			funcDef.SetStrict(false)
@@ -170,7 +173,7 @@ func (o *XAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConf
 			return result, err
 		}
 
-		resp, err := timed(func() (*xai.ChatResponse, error) {
+		resp, err := timedRequest(&result, func(func()) (*xai.ChatResponse, error) {
 			response, httpResp, err := o.client.V1API.HandleGenericCompletionRequest(ctx).ChatRequest(*req).Execute()
 			if err != nil {
 				var apiErr *xai.GenericOpenAPIError
@@ -182,9 +185,8 @@ func (o *XAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConf
 				}
 			}
 			return response, err
-		}, &result.duration)
-		result.recordToolUsage(executor.GetUsageStats())
-		result.recordToolCalls(executor.GetCallSummaries())
+		})
+		result.recordToolActivity(executor)
 		if err != nil {
 			return result, WrapErrGenerateResponse(err)
 		} else if resp == nil {
@@ -199,12 +201,15 @@ func (o *XAI) Run(ctx context.Context, logger logging.Logger, cfg config.RunConf
 				completionTokens := int64(u.CompletionTokens)
 				cachedTokens := int64(u.PromptTokensDetails.CachedTokens)
 				recordUsage(InputTokenAccountingCacheTokensIncluded, &promptTokens, &completionTokens, nil, &cachedTokens, &result.usage)
+				recordRequestOutputTokens(&completionTokens, &result)
+				recordReasoningTokens(&u.CompletionTokensDetails.ReasoningTokens, &result.usage)
 			}
 		}
 		if len(resp.Choices) == 0 {
 			return result, ErrNoResponseCandidates
 		}
 		for _, candidate := range resp.Choices {
+			result.recordReasoning(candidate.Message.GetReasoningContent())
 			finishReason := o.getFinishReason(candidate)
 			isTerminal := o.isTerminalStopReason(finishReason)
 			logFinishReason(ctx, logger, finishReason, isTerminal)
@@ -309,34 +314,186 @@ func (o *XAI) isTransientResponse(response *http.Response) bool {
 	}, response.StatusCode)
 }
 
+func (o *XAI) CanBatch(_ config.RunConfig, task config.Task) bool {
+	return !taskUsesTools(task)
+}
+
+func (o *XAI) NewBatch(ctx context.Context, logger logging.Logger, cfg config.RunConfig, size int) *Batch {
+	return newBatch(ctx, logger, o.batches, size, func(ctx context.Context, logger logging.Logger, requests []*batchRequest) (map[string]batchResponse, error) {
+		return o.submitBatch(ctx, logger, cfg.Name, requests)
+	})
+}
+
+// xaiBatch is the batch object returned by the xAI Batch API.
+type xaiBatch struct {
+	BatchID string `json:"batch_id"`
+	State   struct {
+		NumRequests int `json:"num_requests"`
+		NumPending  int `json:"num_pending"`
+	} `json:"state"`
+}
+
+// xaiBatchResults is a page of request outputs returned by the xAI Batch API.
+type xaiBatchResults struct {
+	Results []struct {
+		BatchRequestID string `json:"batch_request_id"`
+		BatchResult    struct {
+			Response *struct {
+				ChatGetCompletion json.RawMessage `json:"chat_get_completion"`
+			} `json:"response"`
+		} `json:"batch_result"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"results"`
+	PaginationToken string `json:"pagination_token"`
+}
+
+// submitBatch executes the requests as an xAI batch and returns their outputs.
+// The generated client does not cover the Batch API, so it is called directly.
+func (o *XAI) submitBatch(ctx context.Context, logger logging.Logger, name string, requests []*batchRequest) (map[string]batchResponse, error) {
+	var batch xaiBatch
+	if err := o.callBatchAPI(ctx, http.MethodPost, "/v1/batches", map[string]string{"name": name}, &batch); err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	batchID := batch.BatchID
+
+	batchRequests := make([]map[string]any, len(requests))
+	for i, request := range requests {
+		batchRequests[i] = map[string]any{
+			"batch_request_id": request.CustomID,
+			"batch_request":    map[string]json.RawMessage{"chat_get_completion": request.Body},
+		}
+	}
+	if err := o.callBatchAPI(ctx, http.MethodPost, "/v1/batches/"+url.PathEscape(batchID)+"/requests", map[string]any{"batch_requests": batchRequests}, nil); err != nil {
+		o.cancelBatch(ctx, logger, batchID)
+		return nil, fmt.Errorf("failed to add batch requests: %w", err)
+	}
+	logger.Message(ctx, logging.LevelInfo, "batch '%s' created, waiting for completion...", batchID)
+
+	if err := pollBatch(ctx, logger, func(ctx context.Context) (bool, error) {
+		if err := o.callBatchAPI(ctx, http.MethodGet, "/v1/batches/"+url.PathEscape(batchID), nil, &batch); err != nil {
+			return false, err
+		}
+		return batch.State.NumPending == 0, nil
+	}); err != nil {
+		o.cancelBatch(ctx, logger, batchID)
+		return nil, err
+	}
+
+	responses := make(map[string]batchResponse, len(requests))
+	query := url.Values{"limit": {"100"}}
+	for {
+		var page xaiBatchResults
+		if err := o.callBatchAPI(ctx, http.MethodGet, "/v1/batches/"+url.PathEscape(batchID)+"/results?"+query.Encode(), nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to read batch results: %w", err)
+		}
+		for _, result := range page.Results {
+			if response := result.BatchResult.Response; response != nil && len(response.ChatGetCompletion) > 0 {
+				responses[result.BatchRequestID] = batchResponse{StatusCode: http.StatusOK, Body: response.ChatGetCompletion}
+				continue
+			}
+			message := cmp.Or(result.ErrorMessage, "batch request produced no response")
+			responses[result.BatchRequestID] = newBatchErrorResponse(http.StatusInternalServerError, "batch_request_failed", message)
+		}
+		if page.PaginationToken == "" || len(page.Results) == 0 {
+			return responses, nil
+		}
+		query.Set("pagination_token", page.PaginationToken)
+	}
+}
+
+func (o *XAI) cancelBatch(ctx context.Context, logger logging.Logger, batchID string) {
+	if err := o.callBatchAPI(context.WithoutCancel(ctx), http.MethodPost, "/v1/batches/"+url.PathEscape(batchID)+":cancel", nil, nil); err != nil {
+		logger.Error(ctx, logging.LevelWarn, err, "failed to cancel batch")
+	}
+}
+
+// callBatchAPI sends a JSON request to the xAI Batch API using the client configuration
+// and decodes the JSON response into out, if given.
+func (o *XAI) callBatchAPI(ctx context.Context, method string, path string, in any, out any) error {
+	cfg := o.client.GetConfig()
+	baseURL, err := cfg.ServerURL(0, nil)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	for key, value := range cfg.DefaultHeader {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return NewErrAPIResponse(fmt.Errorf("%s %s: %s", method, path, resp.Status), data)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
 func (o *XAI) Close(ctx context.Context) error {
 	return nil
 }
 
 func (o *XAI) createPromptMessageParts(ctx context.Context, promptText string, files []config.TaskFile, result *Result) (parts []xai.ContentPart, err error) {
 	for _, file := range files {
-		if fileType, err := file.TypeValue(ctx); err != nil {
+		fileType, err := file.TypeValue(ctx)
+		if err != nil {
 			return parts, err
-		} else if !o.isSupportedImageType(fileType) {
-			return parts, fmt.Errorf("%w: %s", ErrFileNotSupported, fileType)
 		}
 
-		dataURL, err := file.GetDataURL(ctx)
-		if err != nil {
-			return parts, err
+		var filePart *xai.ContentPart
+		if o.isSupportedImageType(fileType) {
+			dataURL, err := file.GetDataURL(ctx)
+			if err != nil {
+				return parts, err
+			}
+
+			// Add image data part.
+			filePart = xai.NewContentPart("image_url")
+			imageUrl := xai.NewImageUrl()
+			imageUrl.SetUrl(dataURL)
+			filePart.SetImageUrl(*imageUrl)
+		} else if classifyFile(fileType) == fileKindText {
+			text, err := taskFileText(ctx, file)
+			if err != nil {
+				return parts, err
+			}
+
+			// Inline text document content.
+			filePart = xai.NewContentPart("text")
+			filePart.SetText(text)
+		} else {
+			return parts, unsupportedFileError(file, fileType)
 		}
 
-		// Add filename as a text part before the image.
+		// Add filename as a text part before the file content.
 		cpText := xai.NewContentPart("text")
 		cpText.SetText(result.recordPrompt(DefaultTaskFileNameInstruction(file)))
-		parts = append(parts, *cpText)
-
-		// Add image data part.
-		imgCp := xai.NewContentPart("image_url")
-		imageUrl := xai.NewImageUrl()
-		imageUrl.SetUrl(dataURL)
-		imgCp.SetImageUrl(*imageUrl)
-		parts = append(parts, *imgCp)
+		parts = append(parts, *cpText, *filePart)
 	}
 
 	// Append the prompt text after the file data for improved context integrity.
@@ -354,5 +511,5 @@ func (o XAI) isSupportedImageType(mimeType string) bool {
 		"image/jpeg",
 		"image/jpg",
 		"image/png",
-	}, strings.ToLower(mimeType))
+	}, mediaType(mimeType))
 }
@@ -8,10 +8,19 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/petmal/mindtrial/config"
 	"github.com/petmal/mindtrial/pkg/testutils"
+	xai "github.com/petmal/mindtrial/pkg/xai"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,8 +31,123 @@ func TestXAI_FileTypeNotSupported(t *testing.T) {
 	runCfg := config.RunConfig{Name: "test-run", Model: "grok-test"}
 	task := config.Task{
 		Name:  "bad_file_type",
-		Files: []config.TaskFile{mockTaskFile(t, "file.txt", "file://file.txt", "text/plain")},
+		Files: []config.TaskFile{mockTaskFile(t, "file.zip", "file://file.zip", "application/zip")},
 	}
 	_, err := p.Run(context.Background(), logger, runCfg, task)
 	require.ErrorIs(t, err, ErrFileNotSupported)
 }
+
+func TestXAI_RunBatch(t *testing.T) {
+	useFastBatchPolling(t)
+	ctx := context.Background()
+	logger := testutils.NewTestLogger(t)
+
+	type submittedRequest struct {
+		BatchRequestID string `json:"batch_request_id"`
+		BatchRequest   struct {
+			ChatGetCompletion json.RawMessage `json:"chat_get_completion"`
+		} `json:"batch_request"`
+	}
+	var mu sync.Mutex
+	var submitted []submittedRequest
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/batches":
+			fmt.Fprint(w, `{"batch_id":"batch_1","state":{"num_requests":0,"num_pending":0}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/batches/batch_1/requests":
+			var body struct {
+				BatchRequests []submittedRequest `json:"batch_requests"`
+			}
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+				return
+			}
+			submitted = append(submitted, body.BatchRequests...)
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/batches/batch_1":
+			polls++
+			pending := len(submitted)
+			if polls > 1 {
+				pending = 0
+			}
+			fmt.Fprintf(w, `{"batch_id":"batch_1","state":{"num_requests":%d,"num_pending":%d}}`, len(submitted), pending)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/batches/batch_1/results":
+			// Return one result per page to exercise pagination.
+			index := 0
+			if token := r.URL.Query().Get("pagination_token"); token != "" {
+				index, _ = strconv.Atoi(token)
+			}
+			if index >= len(submitted) {
+				fmt.Fprint(w, `{"results":[]}`)
+				return
+			}
+			request := submitted[index]
+			var params struct {
+				Messages []struct {
+					Content any `json:"content"`
+				} `json:"messages"`
+			}
+			require.NoError(t, json.Unmarshal(request.BatchRequest.ChatGetCompletion, &params))
+			prompt := fmt.Sprint(params.Messages[len(params.Messages)-1].Content)
+			var result string
+			if strings.Contains(prompt, "invalid") {
+				result = fmt.Sprintf(`{"batch_request_id":%q,"batch_result":{},"error_message":"prompt is invalid"}`, request.BatchRequestID)
+			} else {
+				answer, _ := json.Marshal(map[string]string{"title": "Answer", "explanation": "Echo", "final_answer": "ok"})
+				completion, _ := json.Marshal(map[string]any{
+					"id": "chat_" + request.BatchRequestID, "object": "chat.completion", "created": 1, "model": "grok-test", "service_tier": "default",
+					"choices": []map[string]any{{"index": 0, "finish_reason": "stop", "message": map[string]any{"role": "assistant", "content": string(answer)}}},
+					"usage": map[string]any{
+						"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15, "cost_in_usd_ticks": 0, "num_sources_used": 0,
+						"prompt_tokens_details":     map[string]int{"text_tokens": 10, "audio_tokens": 0, "image_tokens": 0, "cached_tokens": 0},
+						"completion_tokens_details": map[string]int{"reasoning_tokens": 0, "audio_tokens": 0, "accepted_prediction_tokens": 0, "rejected_prediction_tokens": 0},
+					},
+				})
+				result = fmt.Sprintf(`{"batch_request_id":%q,"batch_result":{"response":{"chat_get_completion":%s}}}`, request.BatchRequestID, completion)
+			}
+			fmt.Fprintf(w, `{"results":[%s],"pagination_token":"%d"}`, result, index+1)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewXAI(config.XAIClientConfig{APIKey: "test"}, nil)
+	require.NoError(t, err)
+	p.client.GetConfig().Servers = xai.ServerConfigurations{{URL: server.URL}}
+
+	runCfg := config.RunConfig{Name: "batch-run", Model: "grok-test", ExecutionMode: config.ExecutionModeBatch}
+	prompts := []string{"first", "second", "invalid"}
+	for _, prompt := range prompts {
+		require.True(t, p.CanBatch(runCfg, config.Task{Name: prompt, Prompt: prompt}))
+	}
+
+	batch := p.NewBatch(ctx, logger, runCfg, len(prompts))
+	results := make([]Result, len(prompts))
+	errs := make([]error, len(prompts))
+	var wg sync.WaitGroup
+	for i, prompt := range prompts {
+		wg.Go(func() {
+			defer batch.Done()
+			task := config.Task{Name: prompt, Prompt: prompt, ResponseResultFormat: config.NewResponseFormat("text")}
+			results[i], errs[i] = p.Run(batch.Context(ctx), logger, runCfg, task)
+		})
+	}
+	wg.Wait()
+
+	assert.Len(t, submitted, len(prompts))
+	for i := range prompts[:2] {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "ok", results[i].GetFinalAnswerContent())
+		assert.Equal(t, "Answer", results[i].Title)
+		assert.EqualValues(t, 10, *results[i].GetUsage().InputTokens)
+	}
+	var apiErr *ErrAPIResponse
+	require.ErrorAs(t, errs[2], &apiErr)
+	assert.Contains(t, string(apiErr.Body), "prompt is invalid")
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrStoreArtifacts is returned when tool artifacts cannot be stored.
+var ErrStoreArtifacts = errors.New("failed to store tool artifacts")
+
+// StoreArtifacts writes the content of the tool artifacts collected in the results to files
+// in the named directory within baseDir, typically the directory of the result files.
+// Each artifact is stored at `<dirName>/<trace ID>/<call ID>/<sandbox path>`, with characters
+// not allowed in IDs replaced, and its File is set to that path, so that reports written to
+// baseDir can link to it. The content of stored artifacts is released. Artifacts without
+// content are left unchanged.
+func (r Results) StoreArtifacts(baseDir string, dirName string) (stored int, err error) {
+	for _, provider := range r {
+		for i := range provider {
+			result := &provider[i]
+			for _, calls := range [][]ToolCallSummary{result.Details.Answer.ToolCalls, result.Details.Validation.ToolCalls, result.Details.Error.ToolCalls} {
+				for j := range calls {
+					for k := range calls[j].Artifacts {
+						artifact := &calls[j].Artifacts[k]
+						if artifact.Content == nil {
+							continue
+						}
+						if err = storeArtifact(baseDir, path.Join(dirName, validIDCharMatcher.ReplaceAllString(result.TraceID, "_"), validIDCharMatcher.ReplaceAllString(calls[j].CallID, "_")), artifact); err != nil {
+							return
+						}
+						stored++
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// storeArtifact writes the content of an artifact to a file at its sandbox path within
+// the directory given relative to baseDir.
+func storeArtifact(baseDir string, dir string, artifact *ToolArtifact) error {
+	sandboxPath := strings.TrimPrefix(path.Clean(artifact.Path), "/")
+	if !filepath.IsLocal(filepath.FromSlash(sandboxPath)) {
+		return fmt.Errorf("%w: invalid artifact path %q", ErrStoreArtifacts, artifact.Path)
+	}
+	relPath := path.Join(dir, sandboxPath)
+	filePath := filepath.Join(baseDir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("%w: %v", ErrStoreArtifacts, err)
+	}
+	if err := os.WriteFile(filePath, artifact.Content, 0o644); err != nil {
+		return fmt.Errorf("%w: %v", ErrStoreArtifacts, err)
+	}
+	artifact.File, artifact.Content = relPath, nil
+	return nil
+}
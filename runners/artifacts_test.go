@@ -0,0 +1,88 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsStoreArtifacts(t *testing.T) {
+	baseDir := t.TempDir()
+	results := Results{
+		"openai": {
+			{
+				TraceID: "01JEDE7Z8X",
+				Details: Details{
+					Answer: AnswerDetails{
+						ToolCalls: []ToolCallSummary{
+							{
+								Tool:   "plot",
+								CallID: "call:1",
+								Artifacts: []ToolArtifact{
+									{Path: "/workspace/out/chart.png", Bytes: 3, MediaType: "image/png", Content: []byte("png")},
+									{Path: "/workspace/out/large.bin", Bytes: 1 << 20, MediaType: "application/octet-stream"},
+								},
+							},
+						},
+					},
+					Error: ErrorDetails{
+						ToolCalls: []ToolCallSummary{
+							{
+								Tool:      "plot",
+								CallID:    "call-2",
+								Artifacts: []ToolArtifact{{Path: "/log.txt", Bytes: 4, MediaType: "text/plain", Content: []byte("done")}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	stored, err := results.StoreArtifacts(baseDir, "results-artifacts")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+
+	answerArtifacts := results["openai"][0].Details.Answer.ToolCalls[0].Artifacts
+	assert.Equal(t, ToolArtifact{Path: "/workspace/out/chart.png", Bytes: 3, MediaType: "image/png", File: "results-artifacts/01JEDE7Z8X/call_1/workspace/out/chart.png"}, answerArtifacts[0])
+	assert.Equal(t, ToolArtifact{Path: "/workspace/out/large.bin", Bytes: 1 << 20, MediaType: "application/octet-stream"}, answerArtifacts[1])
+	errorArtifact := results["openai"][0].Details.Error.ToolCalls[0].Artifacts[0]
+	assert.Equal(t, "results-artifacts/01JEDE7Z8X/call-2/log.txt", errorArtifact.File)
+	assert.Nil(t, errorArtifact.Content)
+
+	content, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(answerArtifacts[0].File)))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(content))
+	content, err = os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(errorArtifact.File)))
+	require.NoError(t, err)
+	assert.Equal(t, "done", string(content))
+}
+
+func TestResultsStoreArtifacts_InvalidPath(t *testing.T) {
+	results := Results{
+		"openai": {
+			{
+				TraceID: "01JEDE7Z8X",
+				Details: Details{
+					Answer: AnswerDetails{
+						ToolCalls: []ToolCallSummary{
+							{Tool: "plot", CallID: "call-1", Artifacts: []ToolArtifact{{Path: "../../escape.txt", Content: []byte("x")}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	_, err := results.StoreArtifacts(t.TempDir(), "results-artifacts")
+	require.ErrorIs(t, err, ErrStoreArtifacts)
+}
@@ -10,6 +10,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,7 +31,7 @@ import (
 const asyncEventBufferSize = 3
 
 type toolValidator interface {
-	ValidateTool(ctx context.Context, cfg config.ToolConfig) error
+	ValidateTool(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) error
 	Close() error
 }
 
@@ -41,6 +43,9 @@ type eventEmitter interface {
 type resultCollector interface {
 	eventEmitter
 	appendResult(result RunResult)
+	completeTask()
+	// stopped reports whether the run was canceled and no more tasks should be started.
+	stopped() bool
 }
 
 type resultSet struct {
@@ -62,11 +67,44 @@ func (r *resultSet) appendResult(result RunResult) {
 	r.Lock()
 	defer r.Unlock()
 	r.results[result.Provider] = append(r.results[result.Provider], result)
+}
+
+// completeTask counts a finished task towards the progress.
+func (r *resultSet) completeTask() {
 	r.resultCounter.Add(1)
 }
 
+// orderedResults appends the results of concurrently executed tasks to a result collector
+// in task order. A result is held back until the results of all preceding tasks are available.
+type orderedResults struct {
+	sync.Mutex
+	rs      resultCollector
+	pending []*RunResult
+	next    int
+}
+
+func newOrderedResults(rs resultCollector, taskCount int) *orderedResults {
+	return &orderedResults{
+		rs:      rs,
+		pending: make([]*RunResult, taskCount),
+	}
+}
+
+// set records the result of the task at the given index and appends all results that are ready.
+func (o *orderedResults) set(index int, result RunResult) {
+	o.Lock()
+	defer o.Unlock()
+	o.pending[index] = &result
+	for o.next < len(o.pending) && o.pending[o.next] != nil {
+		o.rs.appendResult(*o.pending[o.next])
+		o.pending[o.next] = nil
+		o.next++
+	}
+}
+
 func (r *resultSet) emitProgressEvent()        {}
 func (r *resultSet) emitMessageEvent(_ string) {}
+func (r *resultSet) stopped() bool             { return false }
 
 type asyncResultSet struct {
 	*resultSet
@@ -75,14 +113,16 @@ type asyncResultSet struct {
 	progressEvents chan float32
 	messageEvents  chan string
 	cancel         context.CancelFunc
+	stopping       atomic.Bool
+	err            error // set before done is released
 }
 
-func (r *asyncResultSet) GetResults() Results {
+func (r *asyncResultSet) GetResults() (Results, error) {
 	if r != nil {
 		r.done.Wait()
-		return r.resultSet.GetResults()
+		return r.resultSet.GetResults(), r.err
 	}
-	return Results{}
+	return Results{}, nil
 }
 
 func (r *asyncResultSet) ProgressEvents() <-chan float32 {
@@ -93,8 +133,17 @@ func (r *asyncResultSet) MessageEvents() <-chan string {
 	return r.messageEvents
 }
 
-func (r *asyncResultSet) Cancel() {
-	r.cancel()
+func (r *asyncResultSet) Cancel(gracePeriod time.Duration) {
+	r.stopping.Store(true)
+	if gracePeriod > 0 {
+		time.AfterFunc(gracePeriod, r.cancel)
+	} else {
+		r.cancel()
+	}
+}
+
+func (r *asyncResultSet) stopped() bool {
+	return r.stopping.Load()
 }
 
 func (r *asyncResultSet) emitProgressEvent() {
@@ -118,20 +167,11 @@ func (r *asyncResultSet) emitMessageEvent(message string) {
 // or in parallel when the provider's MaxParallelRequestsPerMinute is set to a value greater than 0.
 // It returns an error if any provider initialization fails.
 func NewDefaultRunner(ctx context.Context, cfg []config.ProviderConfig, judges []config.JudgeConfig, tools []config.ToolConfig, logger zerolog.Logger) (Runner, error) {
-	toolValidator, err := providertools.NewDockerToolExecutor(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize tool validator: %w", err)
-	}
-
 	targets := make(map[providers.Provider]config.ProviderConfig, len(cfg))
 	totalTargetCount := 0
 	for _, providerConfig := range cfg {
 		client, err := providers.NewProvider(ctx, providerConfig, tools)
 		if err != nil {
-			if cleanupErr := toolValidator.Close(); cleanupErr != nil {
-				logger.Warn().Err(cleanupErr).Msg("failed to close tool validator")
-			}
-
 			return nil, fmt.Errorf("failed to initialize task runner: %w", err)
 		}
 		targets[client] = providerConfig
@@ -145,8 +185,11 @@ func NewDefaultRunner(ctx context.Context, cfg []config.ProviderConfig, judges [
 		totalTargetCount: totalTargetCount,
 		validatorFactory: validatorFactory,
 		tools:            tools,
+		toolResources:    providertools.NewExecutorResources(),
 		logger:           logger,
-		toolValidator:    toolValidator,
+		newToolValidator: func(ctx context.Context, backend string) (toolValidator, error) {
+			return providertools.NewToolExecutor(ctx, backend)
+		},
 	}, nil
 }
 
@@ -155,8 +198,29 @@ type defaultRunner struct {
 	totalTargetCount int
 	validatorFactory *validators.Factory
 	tools            []config.ToolConfig
+	toolResources    *providertools.ExecutorResources // shared by the tool executors of all tasks
 	logger           zerolog.Logger
-	toolValidator    toolValidator
+	// newToolValidator creates a validator for tools run by the given executor backend.
+	// Validators are only created for the backends needed by the tasks to run.
+	newToolValidator func(ctx context.Context, backend string) (toolValidator, error)
+	toolValidators   map[string]toolValidator
+}
+
+// getToolValidator returns the validator for tools run by the given executor backend,
+// creating it on first use.
+func (r *defaultRunner) getToolValidator(ctx context.Context, backend string) (toolValidator, error) {
+	if validator, exists := r.toolValidators[backend]; exists {
+		return validator, nil
+	}
+	validator, err := r.newToolValidator(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	if r.toolValidators == nil {
+		r.toolValidators = make(map[string]toolValidator)
+	}
+	r.toolValidators[backend] = validator
+	return validator, nil
 }
 
 func (r *defaultRunner) assertCanRun(ctx context.Context, tasks []config.Task) error {
@@ -167,6 +231,7 @@ func (r *defaultRunner) assertCanRun(ctx context.Context, tasks []config.Task) e
 	}
 
 	validatedTools := make(map[string]bool)
+	toolLogger := NewEmittingLogger(r.logger, &resultSet{}) // no events are emitted before the run starts
 
 	for _, task := range tasks {
 		// Resolve validation rules for this task.
@@ -182,19 +247,44 @@ func (r *defaultRunner) assertCanRun(ctx context.Context, tasks []config.Task) e
 		// Check that all tools referenced in the task's tool selector exist in tools.
 		resolvedToolSelector := task.GetResolvedToolSelector()
 		enabledTools, _ := resolvedToolSelector.GetEnabledToolsByName()
-		for toolName := range enabledTools {
+		taskTools := make([]config.ToolConfig, 0, len(enabledTools))
+		for _, toolName := range utils.SortedKeys(enabledTools) {
 			toolCfg, exists := availableTools[toolName]
 			if !exists {
 				taskErrors = append(taskErrors, fmt.Errorf("%w: task '%s' requires tool '%s' that does not exist in tools", ErrToolNotFound, task.Name, toolName))
 				continue
 			}
+			taskTools = append(taskTools, toolCfg)
+		}
+		if len(taskTools) == 0 {
+			continue
+		}
 
+		// Check that the tools can be run together by the same executor.
+		backend, err := providertools.ExecutorBackend(taskTools)
+		if err != nil {
+			taskErrors = append(taskErrors, fmt.Errorf("task '%s' cannot use the selected tools: %w", task.Name, err))
+			continue
+		}
+		// Check that the limits of the tools can be enforced by the executor.
+		for _, toolCfg := range taskTools {
+			if toolCfg.GetType() == config.ToolTypeSubprocess && enabledTools[toolCfg.Name].CpuPercent != nil {
+				taskErrors = append(taskErrors, fmt.Errorf("%w: task '%s' sets cpu-percent for subprocess tool '%s' that cannot be CPU limited", ErrUnsupportedToolLimit, task.Name, toolCfg.Name))
+			}
+		}
+		validator, err := r.getToolValidator(ctx, backend)
+		if err != nil {
+			taskErrors = append(taskErrors, fmt.Errorf("task '%s' requires %s tool executor that cannot be initialized: %w", task.Name, backend, err))
+			continue
+		}
+
+		for _, toolCfg := range taskTools {
 			// Validate tool if not already validated.
-			if _, alreadyValidated := validatedTools[toolName]; !alreadyValidated {
-				if err := r.toolValidator.ValidateTool(ctx, toolCfg); err != nil {
-					taskErrors = append(taskErrors, fmt.Errorf("tool '%s' cannot be used: %w", toolName, err))
+			if _, alreadyValidated := validatedTools[toolCfg.Name]; !alreadyValidated {
+				if err := validator.ValidateTool(ctx, toolLogger, toolCfg); err != nil {
+					taskErrors = append(taskErrors, fmt.Errorf("tool '%s' cannot be used: %w", toolCfg.Name, err))
 				}
-				validatedTools[toolName] = true
+				validatedTools[toolCfg.Name] = true
 			}
 		}
 	}
@@ -206,6 +296,7 @@ func (r *defaultRunner) assertCanRun(ctx context.Context, tasks []config.Task) e
 }
 
 func (r *defaultRunner) Start(ctx context.Context, tasks []config.Task) (AsyncResultSet, error) {
+	ctx = r.toolResources.Context(ctx)
 	if err := r.assertCanRun(ctx, tasks); err != nil {
 		return nil, err
 	}
@@ -227,18 +318,18 @@ func (r *defaultRunner) Start(ctx context.Context, tasks []config.Task) (AsyncRe
 		done:           &wg,
 	}
 
-	var err error
 	go func() {
 		defer wg.Done()
 		defer close(progress)
 		defer close(messages)
-		err = r.run(runCtx, tasks, result)
+		result.err = r.run(runCtx, tasks, result)
 	}()
 
-	return result, err
+	return result, nil
 }
 
 func (r *defaultRunner) Run(ctx context.Context, tasks []config.Task) (ResultSet, error) {
+	ctx = r.toolResources.Context(ctx)
 	if err := r.assertCanRun(ctx, tasks); err != nil {
 		return nil, err
 	}
@@ -272,12 +363,23 @@ func (r *defaultRunner) runTasks(ctx context.Context, logger logging.Logger, pro
 	logger.Message(ctx, logging.LevelInfo, "%s: starting %d task%s on this provider in %d configuration%s...", pluralize(provider.Name(), countable(len(tasks)), countable(len(runs)))...)
 	providerStart := time.Now()
 
-	var sharedLimiter *rate.Limiter
+	var sharedLimiter execution.Limiter
 	parallelRunsEnabled := providerConfig.MaxParallelRequestsPerMinute > 0
 	if parallelRunsEnabled {
-		ratePerSecond := rate.Limit(providerConfig.MaxParallelRequestsPerMinute) / 60
-		sharedLimiter = rate.NewLimiter(ratePerSecond, providerConfig.MaxParallelRequestsPerMinute)
-		logger.Message(ctx, logging.LevelInfo, "%s: parallel run execution enabled, aggregate rate limited to %d requests/min.", provider.Name(), providerConfig.MaxParallelRequestsPerMinute)
+		if providerConfig.AdaptiveRateLimit {
+			sharedLimiter = execution.NewAdaptiveLimiter(logger, provider.Name(), providerConfig.MaxParallelRequestsPerMinute)
+			logger.Message(ctx, logging.LevelInfo, "%s: parallel run execution enabled, aggregate rate adaptively limited to at most %d requests/min.", provider.Name(), providerConfig.MaxParallelRequestsPerMinute)
+		} else {
+			ratePerSecond := rate.Limit(providerConfig.MaxParallelRequestsPerMinute) / 60
+			sharedLimiter = rate.NewLimiter(ratePerSecond, providerConfig.MaxParallelRequestsPerMinute)
+			logger.Message(ctx, logging.LevelInfo, "%s: parallel run execution enabled, aggregate rate limited to %d requests/min.", provider.Name(), providerConfig.MaxParallelRequestsPerMinute)
+		}
+	}
+
+	var breaker *execution.CircuitBreaker
+	if providerConfig.CircuitBreaker != nil {
+		breaker = execution.NewCircuitBreaker(logger, provider.Name(), *providerConfig.CircuitBreaker)
+		logger.Message(ctx, logging.LevelInfo, "%s: circuit breaker enabled, requests pause after %d consecutive transient errors.", provider.Name(), providerConfig.CircuitBreaker.FailureThreshold)
 	}
 
 	executeRun := func(run config.RunConfig) {
@@ -293,20 +395,87 @@ func (r *defaultRunner) runTasks(ctx context.Context, logger logging.Logger, pro
 			logger.Message(ctx, logging.LevelInfo, "%s: %s: text-only mode enabled for this configuration.", provider.Name(), run.Name)
 		}
 		executor := execution.NewExecutor(provider, run, sharedLimiter)
+		if breaker != nil {
+			executor = executor.WithCircuitBreaker(breaker)
+		}
+		results := newOrderedResults(rs, len(tasks))
 
-		for _, task := range tasks {
+		executeTask := func(ctx context.Context, executor *execution.Executor, index int, task config.Task) {
 			runResult := RunResult{TraceID: ulid.Make().String()}
 
 			// Create prefixed logger for this specific task.
 			taskLogger := logger.WithContext(fmt.Sprintf("[%s] %s: %s: %s: ", runResult.TraceID, provider.Name(), run.Name, task.Name))
 
-			taskLogger.Message(ctx, logging.LevelInfo, "starting task...")
-			runStart := time.Now()
-			r.runTask(ctx, taskLogger, executor, task, skipTasksWithSchemaResultFormat, skipTasksWithFiles, &runResult)
-			taskLogger.Message(ctx, logging.LevelInfo, "task has finished in %s.", time.Since(runStart))
-			rs.appendResult(runResult)
+			if rs.stopped() || errors.Is(ctx.Err(), context.Canceled) {
+				r.initRunResult(executor, task, &runResult)
+				runResult.Kind = Cancelled
+				runResult.Got = "task was not started because the run was canceled"
+				runResult.Details.Error = ErrorDetails{
+					Title:   "Cancelled",
+					Message: "task was not started because the run was canceled",
+				}
+				taskLogger.Message(ctx, logging.LevelInfo, "task was cancelled before it started.")
+			} else {
+				taskLogger.Message(ctx, logging.LevelInfo, "starting task...")
+				runStart := time.Now()
+				r.runTask(ctx, taskLogger, executor, task, skipTasksWithSchemaResultFormat, skipTasksWithFiles, &runResult)
+				taskLogger.Message(ctx, logging.LevelInfo, "task has finished in %s.", time.Since(runStart))
+			}
+			results.set(index, runResult)
+			rs.completeTask()
 			rs.emitProgressEvent()
 		}
+
+		// In batch mode, batchable tasks run concurrently so that their requests can be
+		// collected into a single batch, while the remaining tasks run synchronously.
+		batched := make([]bool, len(tasks))
+		var batchWG sync.WaitGroup
+		if run.IsBatch() {
+			if batchProvider, ok := provider.(providers.BatchProvider); ok {
+				batchSize := 0
+				for i, task := range tasks {
+					batched[i] = batchProvider.CanBatch(run, task)
+					if batched[i] {
+						batchSize++
+					}
+				}
+				logger.Message(ctx, logging.LevelInfo, "%s: %s: batch execution enabled for %d of %d task%s.", pluralize(provider.Name(), run.Name, batchSize, countable(len(tasks)))...)
+				if batchSize > 0 {
+					batch := batchProvider.NewBatch(ctx, logger.WithContext(fmt.Sprintf("%s: %s: ", provider.Name(), run.Name)), run, batchSize)
+					batchExecutor := executor.WithoutRateLimit()
+					for i, task := range tasks {
+						if batched[i] {
+							batchWG.Go(func() {
+								defer batch.Done()
+								executeTask(batch.Context(ctx), batchExecutor, i, task)
+							})
+						}
+					}
+				}
+			} else {
+				logger.Message(ctx, logging.LevelWarn, "%s: %s: batch execution is not supported by this provider, running tasks synchronously.", provider.Name(), run.Name)
+			}
+		}
+
+		// The remaining tasks run through a pool of up to MaxConcurrentTasks workers.
+		// Requests of all workers are subject to the same rate limiters.
+		concurrency := max(run.MaxConcurrentTasks, 1)
+		if concurrency > 1 {
+			logger.Message(ctx, logging.LevelInfo, "%s: %s: running up to %d tasks concurrently.", provider.Name(), run.Name, concurrency)
+		}
+		workers := make(chan struct{}, concurrency)
+		var taskWG sync.WaitGroup
+		for i, task := range tasks {
+			if !batched[i] {
+				workers <- struct{}{}
+				taskWG.Go(func() {
+					defer func() { <-workers }()
+					executeTask(ctx, executor, i, task)
+				})
+			}
+		}
+		taskWG.Wait()
+		batchWG.Wait()
 	}
 
 	if parallelRunsEnabled {
@@ -328,7 +497,8 @@ func (r *defaultRunner) runTasks(ctx context.Context, logger logging.Logger, pro
 	logger.Message(ctx, logging.LevelInfo, "%s: all tasks in all configurations have finished on this provider in %s.", provider.Name(), time.Since(providerStart))
 }
 
-func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, executor *execution.Executor, task config.Task, skipTasksWithSchemaResultFormat bool, skipTasksWithFiles bool, runResult *RunResult) {
+// initRunResult identifies the task and the run configuration it is executed with in the result.
+func (r *defaultRunner) initRunResult(executor *execution.Executor, task config.Task, runResult *RunResult) {
 	runResult.Task = task.Name
 	runResult.Provider = executor.Provider.Name()
 	runResult.Run = executor.RunConfig.Name
@@ -338,6 +508,11 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 		Difficulty: task.Difficulty,
 		Tags:       task.Tags,
 	}
+	runResult.Provenance = r.provenanceFor(executor.RunConfig, task)
+}
+
+func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, executor *execution.Executor, task config.Task, skipTasksWithSchemaResultFormat bool, skipTasksWithFiles bool, runResult *RunResult) {
+	r.initRunResult(executor, task, runResult)
 
 	// Skip tasks with schema response format when structured output is disabled.
 	if skipTasksWithSchemaResultFormat {
@@ -385,6 +560,19 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 		return validator.ToCanonical(resolvedValidationRules, value)
 	})
 
+	// Record what delayed the task, e.g. rate limits, alongside its result.
+	var events executionEventLog
+	ctx = execution.WithEventRecorder(ctx, events.record)
+	defer func() {
+		runResult.Details.Events = events.details()
+	}()
+
+	if timeout := taskTimeout(executor.RunConfig, task); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %v", ErrTaskTimeout, timeout))
+		defer cancel()
+	}
+
 	defer func() {
 		if p := recover(); p != nil {
 			msg := fmt.Sprintf("%v", p)
@@ -401,10 +589,28 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 	usage := result.GetUsage()
 	toolCalls := result.GetToolCalls()
 	logger.Message(ctx, logging.LevelDebug, "token usage: [in:%s, out:%s]", logging.FormatLogInt64(usage.InputTokens), logging.FormatLogInt64(usage.OutputTokens))
+	if usage.ReasoningTokens != nil {
+		logger.Message(ctx, logging.LevelDebug, "reasoning token usage: %s", logging.FormatLogInt64(usage.ReasoningTokens))
+	}
 	if usage.InputCacheWriteTokens != nil || usage.InputCacheReadTokens != nil {
 		logger.Message(ctx, logging.LevelDebug, "cache token usage: [write:%s, read:%s, accounting:%s]", logging.FormatLogInt64(usage.InputCacheWriteTokens), logging.FormatLogInt64(usage.InputCacheReadTokens), usage.InputTokenAccounting)
 	}
 	logger.Message(ctx, logging.LevelTrace, "prompts:\n%s", logging.FormatLogText(result.GetPrompts()))
+
+	// Extract the final answer from the unstructured response if the task defines how.
+	var rawAnswer string
+	var extractErr error
+	if err == nil && executor.RunConfig.DisableStructuredOutput {
+		if extractor, ok := task.GetResolvedAnswerExtractor(); ok {
+			rawAnswer = fmt.Sprint(result.GetFinalAnswerContent())
+			var answer string
+			if answer, extractErr = extractAnswer(extractor, rawAnswer); extractErr == nil {
+				logger.Message(ctx, logging.LevelDebug, "extracted answer using %s extractor", extractor.Type)
+				result.FinalAnswer.Content = answer
+			}
+		}
+	}
+
 	if err != nil { //nolint:gocritic
 		runResult.Kind = Error
 		runResult.Got = err.Error()
@@ -422,7 +628,37 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 			}
 		default:
 			var unmarshalErr *providers.ErrUnmarshalResponse
-			if errors.As(err, &unmarshalErr) {
+			var unavailableErr *execution.ErrProviderUnavailable
+			if errors.Is(ctx.Err(), context.Canceled) { //nolint:gocritic
+				runResult.Kind = Cancelled
+				runResult.Details.Error = ErrorDetails{
+					Title:     "Cancelled",
+					Message:   "task was stopped because the run was canceled",
+					Usage:     toTokenUsage(usage),
+					ToolUsage: toToolUsage(usage),
+					ToolCalls: toToolCallSummaries(toolCalls),
+				}
+			} else if cause := timeoutCause(ctx); cause != nil {
+				runResult.Kind = TimedOut
+				runResult.Details.Error = ErrorDetails{
+					Title:     "Timed Out",
+					Message:   cause.Error(),
+					Usage:     toTokenUsage(usage),
+					ToolUsage: toToolUsage(usage),
+					ToolCalls: toToolCallSummaries(toolCalls),
+					Transient: transientFlagFor(err),
+				}
+			} else if errors.As(err, &unavailableErr) {
+				runResult.Kind = ProviderUnavailable
+				runResult.Details.Error = ErrorDetails{
+					Title:     "Provider Unavailable",
+					Message:   err.Error(),
+					Usage:     toTokenUsage(usage),
+					ToolUsage: toToolUsage(usage),
+					ToolCalls: toToolCallSummaries(toolCalls),
+					Transient: utils.Ptr(true),
+				}
+			} else if errors.As(err, &unmarshalErr) {
 				runResult.Details.Error = ErrorDetails{
 					Title:     "Response Parsing Error",
 					Message:   unmarshalErr.Cause.Error(),
@@ -444,6 +680,26 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 			populateErrorDetails(&runResult.Details.Error, err)
 			logger.Error(ctx, logging.LevelError, err, "task finished with error")
 		}
+
+		var attemptsErr *execution.ErrAttempts
+		if errors.As(err, &attemptsErr) {
+			runResult.Details.Error.Attempts = toAttemptDetails(attemptsErr.Attempts)
+		}
+	} else if extractErr != nil {
+		runResult.Kind = ExtractionFailed
+		runResult.Got = extractErr.Error()
+		runResult.Details.Error = ErrorDetails{
+			Title:   "Answer Extraction Failed",
+			Message: extractErr.Error(),
+			Details: map[string][]string{
+				"Raw Answer": utils.SplitLines(rawAnswer),
+			},
+			Usage:     toTokenUsage(usage),
+			ToolUsage: toToolUsage(usage),
+			ToolCalls: toToolCallSummaries(toolCalls),
+			Transient: utils.Ptr(false),
+		}
+		logger.Error(ctx, logging.LevelError, extractErr, "task finished with error")
 	} else {
 		logger.Message(ctx, logging.LevelDebug, "using %s for response evaluation", validator.GetName())
 
@@ -459,6 +715,15 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 				ToolCalls: toToolCallSummaries(validationResult.ToolCalls),
 				Transient: transientFlagFor(err),
 			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				runResult.Kind = Cancelled
+				runResult.Details.Error.Title = "Cancelled"
+				runResult.Details.Error.Message = "validation was stopped because the run was canceled"
+			} else if cause := timeoutCause(ctx); cause != nil {
+				runResult.Kind = TimedOut
+				runResult.Details.Error.Title = "Timed Out"
+				runResult.Details.Error.Message = fmt.Sprintf("validation stopped: %v", cause)
+			}
 			populateErrorDetails(&runResult.Details.Error, err)
 		} else {
 			if !validationResult.IsCorrect {
@@ -475,6 +740,23 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 				ToolUsage:   toToolUsage(validationResult.Usage),
 				ToolCalls:   toToolCallSummaries(validationResult.ToolCalls),
 			}
+
+			// Check the tool calls made while producing the answer, failing the task if
+			// any expectation is not met regardless of the answer verdict.
+			if task.ToolExpectations != nil {
+				violations := evaluateToolExpectations(*task.ToolExpectations, toolCalls)
+				explanation := &runResult.Details.Validation.Explanation
+				if len(violations) > 0 {
+					runResult.Kind = Failure
+					*explanation = append(*explanation, fmt.Sprintf("Tool expectations not met (%d):", len(violations)))
+					for _, violation := range violations {
+						*explanation = append(*explanation, "- "+violation)
+					}
+					logger.Message(ctx, logging.LevelDebug, "tool expectations not met: %s", strings.Join(violations, "; "))
+				} else {
+					*explanation = append(*explanation, "Tool expectations met.")
+				}
+			}
 		}
 
 		runResult.Details.Answer = AnswerDetails{
@@ -485,9 +767,29 @@ func (r *defaultRunner) runTask(ctx context.Context, logger logging.Logger, exec
 			Usage:          toTokenUsage(usage),
 			ToolUsage:      toToolUsage(usage),
 			ToolCalls:      toToolCallSummaries(toolCalls),
+			Recovery:       string(result.GetRecovery()),
+		}
+		if rawAnswer != "" {
+			runResult.Details.Answer.RawAnswer = utils.SplitLines(rawAnswer)
+		}
+		if executor.RunConfig.RecordReasoning {
+			runResult.Details.Answer.Reasoning = result.GetReasoning()
 		}
 	}
 	runResult.Duration = result.GetDuration()
+	runResult.Timing = toTiming(result.GetRequestTimings(), toolCalls)
+	runResult.Workspaces = toToolWorkspaces(result.GetToolWorkspaces())
+}
+
+// provenanceFor records the configuration and task definition used to run the task.
+// A judge that cannot be found is left out; runTask reports that as a configuration error.
+func (r *defaultRunner) provenanceFor(run config.RunConfig, task config.Task) *Provenance {
+	if judge := task.GetResolvedValidationRules().Judge; judge.IsEnabled() {
+		if judgeConfig, judgeRun, err := r.validatorFactory.LookupJudge(judge); err == nil {
+			return newProvenance(run, &judgeConfig, &judgeRun, task)
+		}
+	}
+	return newProvenance(run, nil, nil, task)
 }
 
 func (r *defaultRunner) Close(ctx context.Context) {
@@ -500,11 +802,20 @@ func (r *defaultRunner) Close(ctx context.Context) {
 		r.logger.Warn().Err(err).Msg("failed to close validator factory")
 	}
 
-	if r.toolValidator != nil {
-		if err := r.toolValidator.Close(); err != nil {
-			r.logger.Warn().Err(err).Msg("failed to close tool validator")
+	for _, backend := range utils.SortedKeys(r.toolValidators) {
+		if err := r.toolValidators[backend].Close(); err != nil {
+			r.logger.Warn().Err(err).Msgf("failed to close %s tool validator", backend)
 		}
 	}
+
+	for _, stats := range r.toolResources.Close(ctx) {
+		calls := stats.Hits + stats.Misses
+		if calls == 0 {
+			continue
+		}
+		r.logger.Info().Msgf("tool container pool for image %s: %d of %d calls used a pre-started container (%.0f%%), saving an estimated %s",
+			stats.Image, stats.Hits, calls, float64(stats.Hits)*100/float64(calls), time.Duration(stats.SavedNs).Round(time.Millisecond))
+	}
 }
 
 // populateErrorDetails injects additional error details into the provided ErrorDetails struct
@@ -513,8 +824,13 @@ func populateErrorDetails(errorDetails *ErrorDetails, err error) {
 	var unmarshalErr *providers.ErrUnmarshalResponse
 	var apiErr *providers.ErrAPIResponse
 	var noActionableContentErr *providers.ErrNoActionableContent
+	var unavailableErr *execution.ErrProviderUnavailable
 
 	switch {
+	case errors.As(err, &unavailableErr):
+		errorDetails.Details = map[string][]string{
+			"Circuit Breaker": unavailableErr.Transitions,
+		}
 	case errors.As(err, &unmarshalErr):
 		errorDetails.Details = map[string][]string{
 			"Stop Reason":  {string(unmarshalErr.StopReason)},
@@ -534,6 +850,66 @@ func populateErrorDetails(errorDetails *ErrorDetails, err error) {
 	}
 }
 
+// executionEventLog collects the execution events of a task. It is safe for concurrent use.
+type executionEventLog struct {
+	mu     sync.Mutex
+	events []ExecutionEvent
+}
+
+func (l *executionEventLog) record(event execution.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, ExecutionEvent{
+		Time:    event.Time,
+		Source:  event.Source,
+		Message: event.Message,
+		Delay:   event.Delay,
+	})
+}
+
+// details returns the collected events, or nil if there are none.
+func (l *executionEventLog) details() []ExecutionEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.events)
+}
+
+// toAttemptDetails converts the execution attempts of a task to their result representation.
+func toAttemptDetails(attempts []execution.Attempt) []AttemptDetails {
+	details := make([]AttemptDetails, 0, len(attempts))
+	for _, attempt := range attempts {
+		detail := AttemptDetails{
+			StartedAt:  attempt.StartedAt,
+			Duration:   attempt.Duration,
+			ErrorClass: attempt.ErrorClass,
+		}
+		if attempt.Err != nil {
+			detail.Error = attempt.Err.Error()
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// taskTimeout returns the maximum time the task may run in the given run configuration,
+// which is the shorter of the task and run timeouts, or 0 if neither is set.
+func taskTimeout(run config.RunConfig, task config.Task) time.Duration {
+	timeout := task.GetResolvedTimeout()
+	if run.Timeout != nil && (timeout == 0 || *run.Timeout < timeout) {
+		timeout = *run.Timeout
+	}
+	return timeout
+}
+
+// timeoutCause returns the reason the task was stopped if its context exceeded the task
+// timeout or the trial deadline, or nil otherwise.
+func timeoutCause(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return context.Cause(ctx)
+	}
+	return nil
+}
+
 // transientFlagFor returns a pointer to true when err is known to be a transient/retryable
 // error, or nil when transience is unknown. This is a best-effort classification based on
 // the existing retry signal, not a complete error taxonomy.
@@ -567,6 +943,7 @@ func toTokenUsage(u providers.Usage) TokenUsage {
 	return TokenUsage{
 		InputTokens:           u.InputTokens,
 		OutputTokens:          u.OutputTokens,
+		ReasoningTokens:       u.ReasoningTokens,
 		InputCacheWriteTokens: u.InputCacheWriteTokens,
 		InputCacheReadTokens:  u.InputCacheReadTokens,
 		InputTokenAccounting:  InputTokenAccounting(u.InputTokenAccounting),
@@ -578,14 +955,39 @@ func toToolUsage(u providers.Usage) (toolUsage map[string]ToolUsage) {
 	for name, usage := range u.ToolUsage {
 		callCount := usage.CallCount
 		duration := time.Duration(usage.TotalDurationNs) // nanosecond is the natural unit of time.Duration
-		toolUsage[name] = ToolUsage{
+		converted := ToolUsage{
 			CallCount:     &callCount,
 			TotalDuration: &duration,
 		}
+		if usage.PoolHits+usage.PoolMisses > 0 {
+			poolHits, poolMisses, poolSaved := usage.PoolHits, usage.PoolMisses, time.Duration(usage.PoolSavedNs)
+			converted.PoolHits, converted.PoolMisses, converted.PoolSaved = &poolHits, &poolMisses, &poolSaved
+		}
+		toolUsage[name] = converted
 	}
 	return toolUsage
 }
 
+// toTiming collects the timing of the model requests and tool calls made to answer a task.
+// Returns nil if neither was recorded.
+func toTiming(requests []providers.RequestTiming, toolCalls []providertools.ToolCallSummary) *Timing {
+	if len(requests) == 0 && len(toolCalls) == 0 {
+		return nil
+	}
+	timing := &Timing{}
+	for _, r := range requests {
+		timing.Requests = append(timing.Requests, RequestTiming{
+			Duration:         r.Duration,
+			TimeToFirstToken: r.TimeToFirstToken,
+			OutputTokens:     r.OutputTokens,
+		})
+	}
+	for _, c := range toolCalls {
+		timing.ToolTime += time.Duration(c.WallTimeNs)
+	}
+	return timing
+}
+
 // toToolCallSummaries converts tool-package call summaries into the runners package's own,
 // executor-agnostic ToolCallSummary shape (e.g. converting raw nanosecond counts to
 // time.Duration), decoupling runners/formatters from any specific tool executor
@@ -611,6 +1013,50 @@ func toToolCallSummaries(calls []providertools.ToolCallSummary) []ToolCallSummar
 			Stdout:           toToolCallOutput(c.Stdout),
 			Stderr:           toToolCallOutput(c.Stderr),
 			ErrorMessage:     c.ErrorMessage,
+			Artifacts:        toToolArtifacts(c.Artifacts),
+		}
+	}
+	return result
+}
+
+// toToolArtifacts converts tool-package artifacts into the public runners.ToolArtifact
+// shape. Returns nil when there are none.
+func toToolArtifacts(artifacts []providertools.Artifact) []ToolArtifact {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	result := make([]ToolArtifact, len(artifacts))
+	for i, a := range artifacts {
+		result[i] = ToolArtifact{
+			Path:      a.Path,
+			Bytes:     a.Bytes,
+			MediaType: a.MediaType,
+			Content:   a.Content,
+		}
+	}
+	return result
+}
+
+// toToolWorkspaces converts tool-package workspace snapshots into the public
+// runners.ToolWorkspace shape. Returns nil when there are none.
+func toToolWorkspaces(snapshots []providertools.WorkspaceSnapshot) []ToolWorkspace {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	result := make([]ToolWorkspace, len(snapshots))
+	for i, s := range snapshots {
+		result[i] = ToolWorkspace{
+			Tool:      s.Tool,
+			Dir:       s.Dir,
+			Truncated: s.Truncated,
+			Error:     s.Error,
+		}
+		for _, f := range s.Files {
+			result[i].Files = append(result[i].Files, ToolWorkspaceFile{
+				Path:    f.Path,
+				Bytes:   f.Bytes,
+				Content: f.Content,
+			})
 		}
 	}
 	return result
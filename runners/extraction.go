@@ -0,0 +1,158 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/petmal/mindtrial/config"
+)
+
+const boxedPrefix = `\boxed{`
+
+var (
+	codeBlockMatcher     = regexp.MustCompile("(?s)```[^\\n`]*\\n(.*?)```")
+	jsonCodeBlockMatcher = regexp.MustCompile("(?s)```json[ \\t]*\\n(.*?)```")
+)
+
+// extractAnswer extracts the final answer from the text of an unstructured response
+// using the given extraction rule. It returns an error wrapping ErrAnswerNotExtracted
+// if the rule finds no answer in the text.
+func extractAnswer(extractor config.AnswerExtractor, text string) (string, error) {
+	var answer string
+	var found bool
+	switch extractor.Type {
+	case config.AnswerExtractorLastLine:
+		answer, found = extractLastLine(text)
+	case config.AnswerExtractorBoxed:
+		answer, found = extractBoxed(text)
+	case config.AnswerExtractorRegex:
+		pattern, err := extractor.CompilePattern()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrAnswerNotExtracted, err)
+		}
+		answer, found = extractRegexMatch(pattern, text)
+	case config.AnswerExtractorMarker:
+		answer, found = extractAfterMarker(text, extractor.GetMarker())
+	case config.AnswerExtractorCodeBlock:
+		answer, found = extractCodeBlock(text)
+	case config.AnswerExtractorJSON:
+		answer, found = extractJSON(text)
+	default:
+		return "", fmt.Errorf("%w: unknown extractor type '%s'", ErrAnswerNotExtracted, extractor.Type)
+	}
+	if !found {
+		return "", fmt.Errorf("%w: the %s extractor found no answer", ErrAnswerNotExtracted, extractor.Type)
+	}
+	return answer, nil
+}
+
+// extractLastLine returns the last non-blank line of the text.
+func extractLastLine(text string) (string, bool) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	answer := strings.TrimSpace(lines[len(lines)-1])
+	return answer, answer != ""
+}
+
+// extractBoxed returns the content of the last \boxed{} expression with balanced braces.
+func extractBoxed(text string) (string, bool) {
+	for end := len(text); end > 0; {
+		start := strings.LastIndex(text[:end], boxedPrefix)
+		if start < 0 {
+			return "", false
+		}
+		if content, ok := balancedBraces(text[start+len(boxedPrefix):]); ok {
+			return strings.TrimSpace(content), true
+		}
+		end = start
+	}
+	return "", false
+}
+
+// balancedBraces returns the text up to the closing brace that balances an already opened brace.
+func balancedBraces(text string) (string, bool) {
+	depth := 1
+	for i, r := range text {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return text[:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// extractRegexMatch returns the first capture group of the last match of the pattern,
+// or the whole match if the pattern has no capture groups.
+func extractRegexMatch(pattern *regexp.Regexp, text string) (string, bool) {
+	matches := pattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	match := matches[len(matches)-1]
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1]), true
+	}
+	return strings.TrimSpace(match[0]), true
+}
+
+// extractAfterMarker returns the text that follows the last occurrence of the marker, ignoring case,
+// up to the end of its line. If the marker ends its line, the next non-blank line is returned instead.
+// Markdown emphasis around the marker (e.g. "**Answer:**") is ignored.
+func extractAfterMarker(text string, marker string) (string, bool) {
+	matches := regexp.MustCompile("(?i)"+regexp.QuoteMeta(marker)).FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	for _, line := range strings.Split(text[matches[len(matches)-1][1]:], "\n") {
+		if answer := strings.TrimSpace(strings.Trim(line, " \t*_")); answer != "" {
+			return answer, true
+		}
+	}
+	return "", false
+}
+
+// extractCodeBlock returns the content of the last fenced code block.
+func extractCodeBlock(text string) (string, bool) {
+	matches := codeBlockMatcher.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return strings.TrimSpace(matches[len(matches)-1][1]), true
+}
+
+// extractJSON returns the content of the last fenced JSON code block holding valid JSON,
+// or if there is none, the last JSON object or array that is not nested in another one.
+func extractJSON(text string) (string, bool) {
+	matches := jsonCodeBlockMatcher.FindAllStringSubmatch(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		if content := strings.TrimSpace(matches[i][1]); json.Valid([]byte(content)) {
+			return content, true
+		}
+	}
+
+	var answer string
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' && text[i] != '[' {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(text[i:]))
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err == nil {
+			answer = string(value)
+			i += int(decoder.InputOffset()) - 1
+		}
+	}
+	return answer, answer != ""
+}
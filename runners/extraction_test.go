@@ -0,0 +1,140 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"testing"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractAnswer(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor config.AnswerExtractor
+		text      string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "last line skips trailing blank lines",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorLastLine},
+			text:      "Let me think.\nThe result is:\n  42  \n\n",
+			want:      "42",
+		},
+		{
+			name:      "last line of empty text",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorLastLine},
+			text:      " \n\t\n",
+			wantErr:   true,
+		},
+		{
+			name:      "last boxed expression with nested braces",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorBoxed},
+			text:      `First \boxed{1}, then finally \boxed{\frac{1}{2}}.`,
+			want:      `\frac{1}{2}`,
+		},
+		{
+			name:      "unbalanced boxed expression falls back to previous one",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorBoxed},
+			text:      `\boxed{7} and \boxed{8`,
+			want:      "7",
+		},
+		{
+			name:      "no boxed expression",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorBoxed},
+			text:      "The answer is 7.",
+			wantErr:   true,
+		},
+		{
+			name:      "regex capture group of last match",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorRegex, Pattern: `result: (\d+)`},
+			text:      "result: 1\nresult: 2",
+			want:      "2",
+		},
+		{
+			name:      "regex without capture group",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorRegex, Pattern: `[A-D]\)`},
+			text:      "I choose C) because...",
+			want:      "C)",
+		},
+		{
+			name:      "regex without match",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorRegex, Pattern: `result: (\d+)`},
+			text:      "no result",
+			wantErr:   true,
+		},
+		{
+			name:      "default marker ignores case and emphasis",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorMarker},
+			text:      "answer: draft\nReasoning...\n**Answer:** Paris\nDone.",
+			want:      "Paris",
+		},
+		{
+			name:      "custom marker at end of line",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorMarker, Marker: "Final result:"},
+			text:      "Final result:\n\n  blue  ",
+			want:      "blue",
+		},
+		{
+			name:      "marker not found",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorMarker},
+			text:      "The capital is Paris.",
+			wantErr:   true,
+		},
+		{
+			name:      "last fenced code block",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorCodeBlock},
+			text:      "```go\nfirst()\n```\nand\n```python\nprint('second')\n```",
+			want:      "print('second')",
+		},
+		{
+			name:      "no fenced code block",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorCodeBlock},
+			text:      "print('second')",
+			wantErr:   true,
+		},
+		{
+			name:      "fenced JSON block preferred over bare JSON",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorJSON},
+			text:      "```json\n{\"a\": 1}\n```\nAlso {\"b\": 2}",
+			want:      `{"a": 1}`,
+		},
+		{
+			name:      "last top-level bare JSON value",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorJSON},
+			text:      `Draft {"a": {"nested": 1}} then final [1, 2, {"c": 3}] done.`,
+			want:      `[1, 2, {"c": 3}]`,
+		},
+		{
+			name:      "invalid JSON",
+			extractor: config.AnswerExtractor{Type: config.AnswerExtractorJSON},
+			text:      "```json\n{broken\n```",
+			wantErr:   true,
+		},
+		{
+			name:      "unknown extractor type",
+			extractor: config.AnswerExtractor{Type: "unknown"},
+			text:      "42",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractAnswer(tt.extractor, tt.text)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrAnswerNotExtracted)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/petmal/mindtrial/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// contentHashPrefix identifies the hash algorithm used by contentHash.
+	contentHashPrefix = "sha256:"
+	// redactedValue replaces the values of secret-looking properties in snapshots.
+	redactedValue = "[REDACTED]"
+)
+
+// sensitivePropertyMarkers lists lowercase substrings of property names whose values
+// must never be written to result files (e.g. keys passed through free-form extra
+// model parameters).
+var sensitivePropertyMarkers = []string{
+	"api-key", "api_key", "apikey",
+	"secret",
+	"password",
+	"authorization",
+	"access-token", "access_token",
+	"auth-token", "auth_token",
+}
+
+// newProvenance creates the provenance record for a task executed with the given run
+// configuration. The judge configuration is optional and should be provided only when
+// the answer is validated by an LLM judge.
+func newProvenance(run config.RunConfig, judge *config.JudgeConfig, judgeRun *config.RunConfig, task config.Task) *Provenance {
+	p := &Provenance{
+		Run:  newRunSnapshot(run),
+		Task: newTaskSnapshot(task),
+	}
+	if judge != nil && judgeRun != nil {
+		p.Judge = &JudgeSnapshot{
+			Name:     judge.Name,
+			Variant:  judgeRun.Name,
+			Provider: judge.Provider.Name,
+			Run:      newRunSnapshot(*judgeRun),
+		}
+	}
+	p.ConfigHash = configHash(p.Run, p.Judge)
+	return p
+}
+
+// configHash returns the content hash identifying a run and judge configuration pair.
+func configHash(run RunSnapshot, judge *JudgeSnapshot) string {
+	return contentHash(struct {
+		Run   RunSnapshot
+		Judge *JudgeSnapshot
+	}{run, judge})
+}
+
+// newRunSnapshot creates the snapshot of every setting of the run configuration that affects
+// its results. Settings that only identify the run, like its name, are not included.
+func newRunSnapshot(run config.RunConfig) RunSnapshot {
+	s := RunSnapshot{
+		Model:                   run.Model,
+		MaxRequestsPerMinute:    run.MaxRequestsPerMinute,
+		MaxConcurrentTasks:      run.MaxConcurrentTasks,
+		TextOnly:                run.TextOnly,
+		DisableStructuredOutput: run.DisableStructuredOutput,
+		RecordReasoning:         run.RecordReasoning,
+		ModelParams:             toSnapshotMap(run.ModelParams),
+	}
+	if run.Timeout != nil {
+		s.Timeout = *run.Timeout
+	}
+	if run.ExecutionMode != config.ExecutionModeSync {
+		s.ExecutionMode = run.ExecutionMode
+	}
+	if run.RetryPolicy != nil {
+		s.RetryPolicy = &RetryPolicySnapshot{
+			MaxRetryAttempts:    run.RetryPolicy.MaxRetryAttempts,
+			InitialDelaySeconds: run.RetryPolicy.InitialDelaySeconds,
+			MaxDelaySeconds:     run.RetryPolicy.MaxDelaySeconds,
+			Jitter:              run.RetryPolicy.Jitter,
+			MaxTotalSeconds:     run.RetryPolicy.MaxTotalSeconds,
+			RetryOn:             run.RetryPolicy.RetryOn,
+		}
+	}
+	if run.ResponseRecovery != nil {
+		s.ResponseRecovery = &ResponseRecoverySnapshot{
+			Repair:             run.ResponseRecovery.Repair,
+			Coerce:             run.ResponseRecovery.Coerce,
+			MaxCorrectiveTurns: run.ResponseRecovery.MaxCorrectiveTurns,
+		}
+	}
+	return s
+}
+
+func newTaskSnapshot(task config.Task) TaskSnapshot {
+	s := TaskSnapshot{
+		Prompt:          task.Prompt,
+		ExpectedResult:  task.ExpectedResult,
+		ValidationRules: toSnapshotMap(task.GetResolvedValidationRules()),
+		MaxTurns:        task.GetResolvedMaxTurns(),
+		Timeout:         task.GetResolvedTimeout(),
+	}
+	if extractor, ok := task.GetResolvedAnswerExtractor(); ok {
+		s.AnswerExtractor = toSnapshotMap(extractor)
+	}
+	if task.ToolExpectations != nil {
+		s.ToolExpectations = toSnapshotMap(task.ToolExpectations)
+	}
+	if systemPrompt, ok := task.GetResolvedSystemPrompt(); ok {
+		s.SystemPrompt = systemPrompt
+	}
+	if format, ok := task.ResponseResultFormat.AsString(); ok {
+		s.ResponseResultFormat = format
+	} else if schema, ok := task.ResponseResultFormat.AsSchema(); ok {
+		s.ResponseResultFormat = schema
+	}
+	// Key tools by name, since the resolved tool selector does not guarantee a stable order.
+	if enabledTools, hasTools := task.GetResolvedToolSelector().GetEnabledToolsByName(); hasTools {
+		s.Tools = make(map[string]interface{}, len(enabledTools))
+		for name, selection := range enabledTools {
+			settings := toSnapshotMap(selection)
+			delete(settings, "name")
+			delete(settings, "disabled")
+			s.Tools[name] = settings
+		}
+	}
+	for _, file := range task.Files {
+		s.Files = append(s.Files, TaskFileSnapshot{
+			Name: file.Name,
+			URI:  file.URI.String(),
+			Type: file.Type,
+		})
+	}
+	s.Hash = contentHash(s) // Hash is still empty here, so it does not contribute to itself
+	return s
+}
+
+// contentHash returns a stable content hash of the JSON representation of v.
+// Struct fields are encoded in declaration order and map keys are sorted,
+// so equal values always produce equal hashes.
+func contentHash(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return contentHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// toSnapshotMap converts a configuration value into a generic map keyed by its
+// configuration file property names, dropping unset properties and redacting
+// secret-looking values. Returns nil if v is nil or has no set properties.
+func toSnapshotMap(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	if redacted, ok := redact(m).(map[string]interface{}); ok && len(redacted) > 0 {
+		return redacted
+	}
+	return nil
+}
+
+// redact recursively removes unset properties (including empty lists and nested
+// objects left with no set properties) and replaces the values of secret-looking
+// properties with a placeholder.
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			switch {
+			case nested == nil:
+				delete(value, key)
+			case isSensitiveProperty(key):
+				value[key] = redactedValue
+			default:
+				if redacted := redact(nested); isEmptyCollection(redacted) {
+					delete(value, key)
+				} else {
+					value[key] = redacted
+				}
+			}
+		}
+		return value
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redact(nested)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+func isEmptyCollection(v interface{}) bool {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
+
+func isSensitiveProperty(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(sensitivePropertyMarkers, func(marker string) bool {
+		return strings.Contains(name, marker)
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSnapshotMap(t *testing.T) {
+	tests := []struct {
+		name string
+		in   interface{}
+		want map[string]interface{}
+	}{
+		{
+			name: "nil value",
+			in:   nil,
+			want: nil,
+		},
+		{
+			name: "no properties set",
+			in:   config.AnthropicModelParams{},
+			want: map[string]interface{}{"stream": false, "legacy-structured-output": false},
+		},
+		{
+			name: "unset properties dropped",
+			in: config.OpenAIModelParams{
+				ReasoningEffort: testutils.Ptr("high"),
+			},
+			want: map[string]interface{}{"reasoning-effort": "high", "text-response-format": false},
+		},
+		{
+			name: "secrets redacted at any depth",
+			in: config.OpenRouterModelParams{
+				Extra: map[string]any{
+					"Authorization": "Bearer abc",
+					"provider": map[string]any{
+						"order":   []any{"a", "b"},
+						"API-KEY": "xyz",
+					},
+					"max_tokens": 100,
+				},
+			},
+			want: map[string]interface{}{
+				"Authorization": "[REDACTED]",
+				"provider": map[string]interface{}{
+					"order":   []interface{}{"a", "b"},
+					"API-KEY": "[REDACTED]",
+				},
+				"max_tokens": 100,
+			},
+		},
+		{
+			name: "empty nested objects dropped",
+			in:   config.ValidationRules{CaseSensitive: testutils.Ptr(true)},
+			want: map[string]interface{}{"case-sensitive": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, toSnapshotMap(tt.in))
+		})
+	}
+}
+
+func TestNewTaskSnapshot(t *testing.T) {
+	newTask := func() config.Task {
+		task := config.Task{
+			Name:                 "task",
+			Prompt:               "prompt",
+			ResponseResultFormat: config.NewResponseFormat("format"),
+			ExpectedResult:       utils.NewValueSet("answer"),
+			MaxTurns:             testutils.Ptr(3),
+		}
+		task.ResolveMaxTurns(0)
+		task.ResolveTimeout(testutils.Ptr(90 * time.Second))
+		task.ResolveToolSelector(config.ToolSelector{
+			Tools: []config.ToolSelection{
+				{Name: "b", MaxCalls: testutils.Ptr(2)},
+				{Name: "a", Timeout: testutils.Ptr(time.Minute)},
+				{Name: "c", Disabled: testutils.Ptr(true)},
+			},
+		})
+		require.NoError(t, task.ResolveSystemPrompt(config.SystemPrompt{EnableFor: testutils.Ptr(config.EnableForAll)}))
+		return task
+	}
+
+	snapshot := newTaskSnapshot(newTask())
+	assert.Equal(t, "prompt", snapshot.Prompt)
+	assert.Equal(t, "Provide the final answer in exactly this format: format", snapshot.SystemPrompt)
+	assert.Equal(t, "format", snapshot.ResponseResultFormat)
+	assert.Equal(t, 3, snapshot.MaxTurns)
+	assert.Equal(t, 90*time.Second, snapshot.Timeout)
+	assert.Equal(t, map[string]interface{}{
+		"a": map[string]interface{}{"timeout": "1m0s"},
+		"b": map[string]interface{}{"max-calls": 2},
+	}, snapshot.Tools)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, snapshot.Hash)
+
+	assert.Equal(t, snapshot.Hash, newTaskSnapshot(newTask()).Hash, "hash must be stable")
+
+	changed := newTask()
+	changed.Prompt = "other prompt"
+	assert.NotEqual(t, snapshot.Hash, newTaskSnapshot(changed).Hash, "hash must change with the definition")
+}
+
+func TestNewRunSnapshotCoversRunConfig(t *testing.T) {
+	// Settings that identify or select the run but do not affect its results.
+	excluded := map[string]bool{
+		"Name":     true,
+		"Disabled": true,
+	}
+
+	baseline := contentHash(newRunSnapshot(config.RunConfig{}))
+	runType := reflect.TypeOf(config.RunConfig{})
+	for i := 0; i < runType.NumField(); i++ {
+		field := runType.Field(i)
+		t.Run(field.Name, func(t *testing.T) {
+			var run config.RunConfig
+			reflect.ValueOf(&run).Elem().Field(i).Set(nonZeroValue(t, field.Type))
+			snapshot := contentHash(newRunSnapshot(run))
+			if excluded[field.Name] {
+				assert.Equal(t, baseline, snapshot, "excluded field must not affect the run snapshot")
+			} else {
+				assert.NotEqual(t, baseline, snapshot, "field must be recorded in the run snapshot or explicitly excluded")
+			}
+		})
+	}
+}
+
+// nonZeroValue returns a non-zero value of the given type for use in reflective tests.
+func nonZeroValue(t *testing.T, typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString("value")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Ptr:
+		v.Set(reflect.New(typ.Elem()))
+		v.Elem().Set(nonZeroValue(t, typ.Elem()))
+	case reflect.Struct:
+		// A non-nil pointer to an empty struct is already distinguishable from nil.
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(map[string]interface{}{"value": 1}))
+	default:
+		require.Failf(t, "unsupported field type", "%s", typ)
+	}
+	return v
+}
@@ -23,11 +23,19 @@ import (
 // Failure indicates that task finished successfully but with incorrect result.
 // Error indicates that task failed to produce a result.
 // NotSupported indicates that task could not finish because the provider does not support the required features.
+// Cancelled indicates that task was not started or did not finish because the run was canceled.
+// ProviderUnavailable indicates that task was not sent because the circuit breaker gave up on the provider.
+// TimedOut indicates that task was stopped because it exceeded its timeout or the trial deadline.
+// ExtractionFailed indicates that the answer extractor configured for the task found no answer in the response.
 const (
 	Success ResultKind = iota
 	Failure
 	Error
 	NotSupported
+	Cancelled
+	ProviderUnavailable
+	TimedOut
+	ExtractionFailed
 )
 
 const runResultIDPrefix = "run"
@@ -37,6 +45,16 @@ var validIDCharMatcher = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
 var (
 	// ErrToolNotFound is returned when a required tool is not found in the available tools.
 	ErrToolNotFound = errors.New("required tool not found")
+	// ErrUnsupportedToolLimit is returned when a task sets a tool limit that cannot be enforced.
+	ErrUnsupportedToolLimit = errors.New("unsupported tool limit")
+	// ErrTaskTimeout is the cause of a task context canceled because the task exceeded its timeout.
+	ErrTaskTimeout = errors.New("task timed out")
+	// ErrTrialDeadline should be used as the cause of a runner context canceled because the
+	// trial exceeded its deadline, e.g. with context.WithTimeoutCause.
+	ErrTrialDeadline = errors.New("trial deadline exceeded")
+	// ErrAnswerNotExtracted is returned when the answer extractor configured for a task
+	// finds no answer in an unstructured response.
+	ErrAnswerNotExtracted = errors.New("answer could not be extracted from the response")
 )
 
 // ResultKind represents the task execution result status.
@@ -45,6 +63,8 @@ type ResultKind int
 // Runner executes tasks on configured AI providers.
 type Runner interface {
 	// Run executes all given tasks against all run configurations and returns when done.
+	// If the context is canceled, tasks that have not started yet and tasks that are
+	// stopped are reported as Cancelled.
 	Run(ctx context.Context, tasks []config.Task) (ResultSet, error)
 	// Start executes all given tasks against all run configurations asynchronously.
 	// It returns immediately and the execution continues in the background,
@@ -64,17 +84,19 @@ type ResultSet interface {
 // It offers channels for monitoring progress and receiving messages during execution,
 // as well as the ability to cancel the ongoing run.
 type AsyncResultSet interface {
-	// GetResults returns the task results for each provider.
+	// GetResults returns the task results for each provider and the error that stopped the run, if any.
 	// The call will block until the run is finished.
-	GetResults() Results
+	GetResults() (Results, error)
 	// ProgressEvents returns a channel that emits run progress as a value between 0 and 1.
 	// The channel will be closed when the run is finished.
 	ProgressEvents() <-chan float32
 	// MessageEvents returns a channel that emits run log messages.
 	// The channel will be closed when the run is finished.
 	MessageEvents() <-chan string
-	// Cancel stops the ongoing run execution.
-	Cancel()
+	// Cancel stops the ongoing run execution. Tasks that have not started yet are not
+	// started and are reported as Cancelled. Running tasks may finish within the grace
+	// period, after which they are stopped and reported as Cancelled as well.
+	Cancel(gracePeriod time.Duration)
 }
 
 // Results stores task results for each provider.
@@ -123,6 +145,66 @@ type RunResult struct {
 	// It excludes local tool execution time (see ToolCalls/ToolUsage) and any subsequent
 	// validation time, so it is not the total wall-clock time spent processing the task.
 	Duration time.Duration
+	// Timing breaks down the latency of the individual model requests and the time spent
+	// executing tools. It is nil if no timing information was recorded.
+	Timing *Timing
+	// Provenance records the configuration and task definition that produced this result.
+	// It is nil for results read from documents that predate provenance tracking.
+	Provenance *Provenance
+	// Workspaces contains the files left in the workspaces of the tool sessions configured
+	// to capture them, in order of tool names.
+	Workspaces []ToolWorkspace
+}
+
+// Timing records latency metrics of a single task execution.
+type Timing struct {
+	// Requests lists the timing of each model request in the order the requests were made.
+	Requests []RequestTiming
+	// ToolTime is the cumulative wall-clock time spent executing tool calls.
+	ToolTime time.Duration
+}
+
+// RequestTiming records the latency of a single model request.
+type RequestTiming struct {
+	// Duration is the time from sending the request until the complete response was received.
+	Duration time.Duration
+	// TimeToFirstToken is the time until the first output token was received.
+	// It is nil if the response was not streamed.
+	TimeToFirstToken *time.Duration
+	// OutputTokens is the number of tokens generated in response to the request, if known.
+	OutputTokens *int64
+}
+
+// TimeToFirstToken returns the time to first token of the first model request,
+// or nil if it was not measured.
+func (t Timing) TimeToFirstToken() *time.Duration {
+	if len(t.Requests) == 0 {
+		return nil
+	}
+	return t.Requests[0].TimeToFirstToken
+}
+
+// OutputTokensPerSecond returns the output token throughput across all model requests
+// with a known number of output tokens, or nil if it cannot be determined.
+// For streamed requests, the time to first token is excluded from the generation time.
+func (t Timing) OutputTokensPerSecond() *float64 {
+	var tokens int64
+	var generation time.Duration
+	for _, r := range t.Requests {
+		if r.OutputTokens == nil {
+			continue
+		}
+		tokens += *r.OutputTokens
+		generation += r.Duration
+		if r.TimeToFirstToken != nil {
+			generation -= *r.TimeToFirstToken
+		}
+	}
+	if generation <= 0 {
+		return nil
+	}
+	rate := float64(tokens) / generation.Seconds()
+	return &rate
 }
 
 // TaskMetadata carries optional descriptive labels from the originating task into the result.
@@ -137,6 +219,130 @@ type TaskMetadata struct {
 	Tags []string
 }
 
+// Provenance records the configuration and task definition that produced a result,
+// so that a result stays interpretable after the configuration files have changed.
+type Provenance struct {
+	// ConfigHash is a content hash identifying the combination of Run and Judge.
+	ConfigHash string
+	// Run is a redacted snapshot of the run configuration used.
+	Run RunSnapshot
+	// Judge is a redacted snapshot of the judge used to validate the answer,
+	// or nil if the answer was validated by value matching.
+	Judge *JudgeSnapshot
+	// Task is a snapshot of the task definition as resolved at execution time.
+	Task TaskSnapshot
+}
+
+// RunSnapshot is a redacted snapshot of a run configuration.
+type RunSnapshot struct {
+	// Model is the target model's identifier.
+	Model string
+	// MaxRequestsPerMinute is the per-run request rate limit, or 0 if not limited.
+	MaxRequestsPerMinute int
+	// MaxConcurrentTasks is the maximum number of tasks run concurrently, or 0 if the
+	// tasks were run one at a time.
+	MaxConcurrentTasks int `json:"MaxConcurrentTasks,omitempty"`
+	// Timeout is the maximum time each task could run, or 0 if not limited.
+	Timeout time.Duration `json:"Timeout,omitempty"`
+	// ExecutionMode is the execution mode of the run, or empty if the tasks were executed
+	// synchronously.
+	ExecutionMode string `json:"ExecutionMode,omitempty"`
+	// TextOnly indicates whether tasks with file attachments were skipped.
+	TextOnly bool
+	// DisableStructuredOutput indicates whether structured output was disabled.
+	DisableStructuredOutput bool
+	// RecordReasoning indicates whether the reasoning of the model was recorded.
+	RecordReasoning bool `json:"RecordReasoning,omitempty"`
+	// ModelParams holds the model-specific parameters keyed by their configuration file
+	// property names. Values of properties that look like secrets are redacted.
+	ModelParams map[string]interface{}
+	// RetryPolicy is the resolved retry policy, or nil if unknown.
+	RetryPolicy *RetryPolicySnapshot
+	// ResponseRecovery is the policy for recovering responses that could not be parsed,
+	// or nil if no recovery was configured.
+	ResponseRecovery *ResponseRecoverySnapshot `json:"ResponseRecovery,omitempty"`
+}
+
+// RetryPolicySnapshot is a snapshot of a resolved retry policy.
+type RetryPolicySnapshot struct {
+	// MaxRetryAttempts is the maximum number of retry attempts.
+	MaxRetryAttempts uint
+	// InitialDelaySeconds is the initial delay in seconds before the first retry attempt.
+	InitialDelaySeconds int
+	// MaxDelaySeconds caps the delay in seconds between retry attempts, or 0 if not capped.
+	MaxDelaySeconds int `json:"MaxDelaySeconds,omitempty"`
+	// Jitter is the randomization applied to retry delays, or empty if none.
+	Jitter string `json:"Jitter,omitempty"`
+	// MaxTotalSeconds limits the total time in seconds spent on all attempts, or 0 if not limited.
+	MaxTotalSeconds int `json:"MaxTotalSeconds,omitempty"`
+	// RetryOn lists the classes of errors that are retried, or is empty if all transient errors are retried.
+	RetryOn []string `json:"RetryOn,omitempty"`
+}
+
+// ResponseRecoverySnapshot is a snapshot of a response recovery policy.
+type ResponseRecoverySnapshot struct {
+	// Repair indicates whether responses were repaired locally.
+	Repair bool `json:"Repair,omitempty"`
+	// Coerce indicates whether responses were coerced to the result schema.
+	Coerce bool `json:"Coerce,omitempty"`
+	// MaxCorrectiveTurns is the number of times the model could be asked to correct its response.
+	MaxCorrectiveTurns int `json:"MaxCorrectiveTurns,omitempty"`
+}
+
+// JudgeSnapshot is a redacted snapshot of the judge configuration used for validation.
+type JudgeSnapshot struct {
+	// Name is the name of the judge configuration.
+	Name string
+	// Variant is the name of the judge's run variant.
+	Variant string
+	// Provider is the name of the judge's AI provider.
+	Provider string
+	// Run is a redacted snapshot of the judge's run variant configuration.
+	Run RunSnapshot
+}
+
+// TaskSnapshot is a snapshot of a task definition as resolved at execution time.
+type TaskSnapshot struct {
+	// Hash is a content hash of all the other snapshot fields.
+	Hash string
+	// Prompt is the prompt sent to the AI model.
+	Prompt string
+	// SystemPrompt is the resolved system prompt, or empty if none was used.
+	SystemPrompt string
+	// ResponseResultFormat is the plain text instruction or JSON schema object
+	// describing the expected answer format.
+	ResponseResultFormat interface{}
+	// ExpectedResult is the set of accepted valid answers, before canonicalization.
+	ExpectedResult utils.ValueSet
+	// ValidationRules holds the resolved validation rules keyed by their
+	// configuration file property names.
+	ValidationRules map[string]interface{}
+	// Tools holds the resolved settings of each enabled tool, keyed by tool name.
+	Tools map[string]interface{}
+	// Files lists the files attached to the prompt.
+	Files []TaskFileSnapshot
+	// MaxTurns is the resolved maximum number of conversation turns, or 0 if unlimited.
+	MaxTurns int
+	// Timeout is the resolved maximum time the task could run, or 0 if not limited.
+	Timeout time.Duration `json:"Timeout,omitempty"`
+	// AnswerExtractor holds the resolved answer extraction rule keyed by its configuration
+	// file property names, or nil if none was set.
+	AnswerExtractor map[string]interface{} `json:"AnswerExtractor,omitempty"`
+	// ToolExpectations holds the assertions on the tool calls keyed by their configuration
+	// file property names, or nil if none were set.
+	ToolExpectations map[string]interface{} `json:"ToolExpectations,omitempty"`
+}
+
+// TaskFileSnapshot identifies a file attached to a task prompt.
+type TaskFileSnapshot struct {
+	// Name is the file's name as referenced in the prompt.
+	Name string
+	// URI is the path or URL the file was loaded from.
+	URI string
+	// Type is the configured MIME type of the file, or empty if it was inferred.
+	Type string
+}
+
 // GetID generates a unique, sanitized identifier for the RunResult.
 // The ID must be non-empty, must not contain whitespace, must begin with a letter,
 // and must only include letters, digits, dashes (-), and underscores (_).
@@ -155,6 +361,23 @@ type Details struct {
 	Validation ValidationDetails
 	// Error contains details about any errors that occurred during task execution.
 	Error ErrorDetails
+	// Events lists what delayed or otherwise affected the execution of the task in the
+	// order it occurred, e.g. waits for rate limits or delays requested by the server.
+	Events []ExecutionEvent `json:"Events,omitempty"`
+}
+
+// ExecutionEvent records something that delayed or otherwise affected the execution of a
+// task without being an error of the task itself.
+type ExecutionEvent struct {
+	// Time is when the event occurred.
+	Time time.Time
+	// Source identifies the component that produced the event
+	// (e.g. rate-limit, retry or circuit-breaker).
+	Source string
+	// Message describes the event.
+	Message string
+	// Delay is how long the event delayed the task, or 0 if it did not delay it directly.
+	Delay time.Duration `json:"Delay,omitempty"`
 }
 
 // AnswerDetails defines structured information about the AI model's response to a task.
@@ -176,6 +399,16 @@ type AnswerDetails struct {
 	// the answer, including attempts that never actually ran. Tracked separately from
 	// ToolUsage, which only reflects invocations that actually ran.
 	ToolCalls []ToolCallSummary `json:"ToolCalls,omitempty"`
+	// Reasoning contains the reasoning text or summaries produced by the target AI model,
+	// one entry per model response. It is only recorded if enabled for the run.
+	Reasoning []string `json:"Reasoning,omitempty"`
+	// Recovery identifies the step that recovered a response that could not be parsed
+	// (e.g. "repair", "coercion" or "corrective-turn"), or is empty if the response
+	// was parsed on the first try.
+	Recovery string `json:"Recovery,omitempty"`
+	// RawAnswer is the complete unstructured response split into lines, recorded when
+	// ActualAnswer was extracted from it by the answer extractor configured for the task.
+	RawAnswer []string `json:"RawAnswer,omitempty"`
 }
 
 // ValidationDetails defines structured information about answer verification and correctness assessment.
@@ -218,6 +451,22 @@ type ErrorDetails struct {
 	// permanent/hard (false), or is unknown (nil). This is a best-effort classification,
 	// not a complete error taxonomy.
 	Transient *bool `json:"Transient,omitempty"`
+	// Attempts lists every attempt to execute the task in the order they were made.
+	// It is only populated when a retry policy is configured.
+	Attempts []AttemptDetails `json:"Attempts,omitempty"`
+}
+
+// AttemptDetails records a single attempt to execute a task.
+type AttemptDetails struct {
+	// StartedAt is when the attempt started.
+	StartedAt time.Time
+	// Duration is how long the attempt took.
+	Duration time.Duration
+	// Error is the error the attempt failed with, or empty if it succeeded.
+	Error string `json:"Error,omitempty"`
+	// ErrorClass is the class of the error used by retry rules (e.g. rate-limit),
+	// or empty if the error matches no class.
+	ErrorClass string `json:"ErrorClass,omitempty"`
 }
 
 // TokenUsage represents token usage consumed by an LLM request.
@@ -229,6 +478,10 @@ type TokenUsage struct {
 	// OutputTokens is the number of generated output tokens.
 	OutputTokens *int64 `json:"OutputTokens,omitempty" jsonschema:"title=Output Tokens" jsonschema_description:"The number of generated output tokens."`
 
+	// ReasoningTokens is the number of tokens spent on reasoning.
+	// Whether they are also counted in OutputTokens depends on the provider.
+	ReasoningTokens *int64 `json:"ReasoningTokens,omitempty" jsonschema:"title=Reasoning Tokens" jsonschema_description:"The number of tokens spent on reasoning. Whether they are also counted in OutputTokens depends on the provider."`
+
 	// InputCacheWriteTokens is the number of input tokens written
 	// into a provider prompt cache.
 	InputCacheWriteTokens *int64 `json:"InputCacheWriteTokens,omitempty" jsonschema:"title=Input Cache Write Tokens" jsonschema_description:"The number of input tokens written into a provider prompt cache."`
@@ -265,6 +518,15 @@ type ToolUsage struct {
 	CallCount *int64 `json:"CallCount,omitempty"`
 	// TotalDuration is the cumulative execution time for the tool's underlying process.
 	TotalDuration *time.Duration `json:"TotalDuration,omitempty"`
+	// PoolHits is the number of calls that used a pre-started container from the pool of
+	// the tool. Only set for tools with a container pool.
+	PoolHits *int64 `json:"PoolHits,omitempty"`
+	// PoolMisses is the number of calls that found no pre-started container ready in the
+	// pool of the tool. Only set for tools with a container pool.
+	PoolMisses *int64 `json:"PoolMisses,omitempty"`
+	// PoolSaved estimates the latency saved by the pool hits. Only set for tools with a
+	// container pool.
+	PoolSaved *time.Duration `json:"PoolSaved,omitempty"`
 }
 
 // ToolCallSummary records the outcome of a single tool invocation.
@@ -297,7 +559,8 @@ type ToolCallSummary struct {
 	// TimedOut indicates the call was aborted due to exceeding its configured timeout.
 	TimedOut bool
 	// Status is one of: "success", "nonzero_exit", "empty_output", "timeout",
-	// "invalid_arguments", "infrastructure_error".
+	// "invalid_arguments", "infrastructure_error", "tool_error" (an MCP tool reported an error or an
+	// HTTP tool service responded with an error).
 	Status string
 	// Stdout is a size-limited capture of the call's standard output, or nil if no output was
 	// ever captured.
@@ -307,6 +570,25 @@ type ToolCallSummary struct {
 	Stderr *ToolCallOutput
 	// ErrorMessage is a short explanation of the failure when Status is not "success".
 	ErrorMessage string
+	// Artifacts lists the files collected from the sandbox after the call, in lexical order
+	// of their paths, if the tool declares artifact paths.
+	Artifacts []ToolArtifact
+}
+
+// ToolArtifact is a file collected from the sandbox of a tool after a call.
+type ToolArtifact struct {
+	// Path is the absolute slash-separated path of the file in the sandbox.
+	Path string
+	// Bytes is the size of the file.
+	Bytes int64
+	// MediaType is the media type of the file.
+	MediaType string
+	// Content is the content of the file, or nil if it exceeded the size limit or has
+	// already been stored (see File).
+	Content []byte
+	// File is the slash-separated path of the stored file relative to the results,
+	// or empty if the artifact has not been stored.
+	File string
 }
 
 // ToolCallOutput holds a size-limited preview of a tool call's output stream.
@@ -319,6 +601,33 @@ type ToolCallOutput struct {
 	Truncated bool
 }
 
+// ToolWorkspace records the files left in the workspace directory of a tool session when
+// the task completed.
+type ToolWorkspace struct {
+	// Tool is the name of the tool that owned the session.
+	Tool string
+	// Dir is the path of the workspace directory in the sandbox.
+	Dir string
+	// Files lists the regular files found in the workspace, in lexical order of their paths.
+	Files []ToolWorkspaceFile
+	// Truncated indicates that the content of some files was omitted because the
+	// capture size limit was reached.
+	Truncated bool
+	// Error describes why the workspace could not be captured completely, if it could not.
+	Error string `json:"Error,omitempty"`
+}
+
+// ToolWorkspaceFile is a file captured from the workspace of a tool session.
+type ToolWorkspaceFile struct {
+	// Path is the slash-separated path of the file relative to the workspace directory.
+	Path string
+	// Bytes is the size of the file.
+	Bytes int64
+	// Content is the content of the file, or nil if it is not valid UTF-8 text or would
+	// exceed the capture size limit.
+	Content *string
+}
+
 // toLines converts an ExpectedResultSet to [][]string where each value is converted to string and split into lines.
 func toLines(expectedResult utils.ValueSet) [][]string {
 	expectedValues := expectedResult.Values()
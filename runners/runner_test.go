@@ -10,15 +10,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/logging"
 	"github.com/petmal/mindtrial/pkg/testutils"
 	"github.com/petmal/mindtrial/pkg/utils"
 	"github.com/petmal/mindtrial/providers"
+	"github.com/petmal/mindtrial/providers/execution"
+	providertools "github.com/petmal/mindtrial/providers/tools"
 	"github.com/petmal/mindtrial/validators"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -67,6 +74,16 @@ func TestPopulateErrorDetails(t *testing.T) {
 				"HTTP Response": {"{\"error\":\"invalid\"}"},
 			},
 		},
+		{
+			name: "provider unavailable includes circuit breaker transitions",
+			err: fmt.Errorf("aborted: %w", &execution.ErrProviderUnavailable{
+				Provider:    "mock",
+				Transitions: []string{"closed -> open", "open -> half-open", "half-open -> unavailable"},
+			}),
+			expects: map[string][]string{
+				"Circuit Breaker": {"closed -> open", "open -> half-open", "half-open -> unavailable"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1181,12 +1198,14 @@ func TestRunnerRun(t *testing.T) {
 			} else {
 				require.NoError(t, err)
 
-				// Clear random TraceID from results before comparison.
+				// Clear random TraceID and provenance (covered by TestRunTaskRecordsProvenance) from results before comparison.
 				results := got.GetResults()
 				for provider := range results {
 					for i := range results[provider] {
 						assert.NotEmpty(t, results[provider][i].TraceID, "TraceID should not be empty")
+						assert.NotNil(t, results[provider][i].Provenance, "Provenance should be recorded")
 						results[provider][i].TraceID = ""
+						results[provider][i].Provenance = nil
 					}
 				}
 
@@ -1687,6 +1706,300 @@ func TestRunTaskCopiesTaskMetadata(t *testing.T) {
 	}
 }
 
+func TestRunTaskRecordsReasoning(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:            "pass",
+					Model:           "with-reasoning",
+					RecordReasoning: true,
+				},
+			},
+		},
+		{
+			Name: "mock provider 2",
+			Runs: []config.RunConfig{
+				{
+					Name:  "pass",
+					Model: "without-reasoning",
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	results, err := r.Run(context.Background(), []config.Task{
+		{
+			Name:           "success",
+			ExpectedResult: utils.NewValueSet("corporis et ipsa"),
+		},
+	})
+	require.NoError(t, err)
+
+	withReasoning := results.GetResults()["mock provider 1"]
+	require.Len(t, withReasoning, 1)
+	assert.Equal(t, []string{"Quia voluptatem dolores sint."}, withReasoning[0].Details.Answer.Reasoning)
+
+	withoutReasoning := results.GetResults()["mock provider 2"]
+	require.Len(t, withoutReasoning, 1)
+	assert.Nil(t, withoutReasoning[0].Details.Answer.Reasoning)
+}
+
+func TestRunnerRunBatch(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:          "mock",
+					Model:         "batch",
+					ExecutionMode: config.ExecutionModeBatch,
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := []config.Task{
+		{
+			Name:           "success",
+			ExpectedResult: utils.NewValueSet("Provident quas tenetur repellat deserunt ut neque culpa."),
+		},
+		{
+			Name:           "failure",
+			ExpectedResult: utils.NewValueSet("Aperiam assumenda id provident ratione eos molestiae."),
+		},
+		{
+			Name:           "not_supported",
+			ExpectedResult: utils.NewValueSet("Unde accusantium sit et enim temporibus qui distinctio assumenda."),
+		},
+	}
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, len(tasks))
+	kinds := make(map[string]ResultKind, len(providerResults))
+	for _, result := range providerResults {
+		kinds[result.Task] = result.Kind
+	}
+	assert.Equal(t, map[string]ResultKind{
+		"success":       Success,
+		"failure":       Failure,
+		"not_supported": NotSupported,
+	}, kinds)
+}
+
+func TestRunnerRunConcurrentTasks(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:               "mock",
+					Model:              "concurrent",
+					MaxConcurrentTasks: 4,
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := make([]config.Task, 20)
+	for i := range tasks {
+		tasks[i] = config.Task{
+			Name:           fmt.Sprintf("parallel_%02d", i),
+			ExpectedResult: utils.NewValueSet("expected answer"),
+		}
+	}
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, len(tasks))
+	for i, result := range providerResults {
+		assert.Equal(t, tasks[i].Name, result.Task, "results must follow the task order")
+		assert.Equal(t, Success, result.Kind, result.Task)
+	}
+
+	for provider := range r.(*defaultRunner).targets {
+		peak := provider.(interface{ PeakConcurrency() int64 }).PeakConcurrency()
+		assert.Greater(t, peak, int64(1), "tasks must run concurrently")
+		assert.LessOrEqual(t, peak, int64(4), "at most max-concurrent-tasks tasks may run concurrently")
+	}
+}
+
+func TestRunnerRunRecordsExecutionEvents(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name:                         "mock provider 1",
+			MaxParallelRequestsPerMinute: 100,
+			AdaptiveRateLimit:            true,
+			Runs: []config.RunConfig{
+				{
+					Name:  "mock",
+					Model: "events",
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := []config.Task{
+		{Name: "low_quota", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+	}
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, 2)
+	assert.Equal(t, Success, providerResults[0].Kind)
+	require.Len(t, providerResults[0].Details.Events, 1)
+	event := providerResults[0].Details.Events[0]
+	assert.Equal(t, execution.EventSourceRateLimit, event.Source)
+	assert.Equal(t, "lowered the rate limit of mock provider 1 from 100.0 to 80.0 requests/min with 1% of the quota remaining", event.Message)
+	assert.Empty(t, providerResults[1].Details.Events)
+}
+
+func TestRunnerRunReportsUnavailableProvider(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			CircuitBreaker: &config.CircuitBreakerConfig{
+				FailureThreshold:     1,
+				ProbeIntervalSeconds: 1,
+				MaxProbeAttempts:     1,
+			},
+			Runs: []config.RunConfig{
+				{
+					Name:        "mock",
+					Model:       "unavailable",
+					RetryPolicy: &config.RetryPolicy{MaxRetryAttempts: 1, InitialDelaySeconds: 1},
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := []config.Task{
+		{Name: "retry_9: pending", ExpectedResult: utils.NewValueSet("irrelevant")},
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+	}
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, 2)
+	assert.Equal(t, Error, providerResults[0].Kind)
+	assert.Equal(t, ProviderUnavailable, providerResults[1].Kind)
+	assert.Equal(t, "Provider Unavailable", providerResults[1].Details.Error.Title)
+
+	sources := make([]string, 0, len(providerResults[0].Details.Events))
+	for _, event := range providerResults[0].Details.Events {
+		sources = append(sources, event.Source)
+	}
+	assert.Contains(t, sources, execution.EventSourceCircuitBreaker)
+}
+
+func TestOrderedResults(t *testing.T) {
+	rs := &resultSet{results: make(Results)}
+	results := newOrderedResults(rs, 3)
+
+	results.set(2, RunResult{Provider: "p", Task: "c"})
+	results.set(1, RunResult{Provider: "p", Task: "b"})
+	assert.Empty(t, rs.GetResults()["p"])
+
+	results.set(0, RunResult{Provider: "p", Task: "a"})
+	tasks := make([]string, 0, 3)
+	for _, result := range rs.GetResults()["p"] {
+		tasks = append(tasks, result.Task)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, tasks)
+}
+
+func TestRunTaskRecordsProvenance(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:                 "mock",
+					Model:                "microchip",
+					MaxRequestsPerMinute: 50,
+					ModelParams: config.OpenRouterModelParams{
+						Temperature: testutils.Ptr(float32(0.5)),
+						Extra: map[string]any{
+							"provider": map[string]any{"api_key": "secret-value"},
+						},
+					},
+					RetryPolicy: &config.RetryPolicy{MaxRetryAttempts: 2, InitialDelaySeconds: 5},
+				},
+				{
+					Name:  "pass",
+					Model: "parsing",
+				},
+			},
+		},
+	}, []config.JudgeConfig{
+		{
+			Name: "test-judge",
+			Provider: config.ProviderConfig{
+				Name: "mock",
+				Runs: []config.RunConfig{
+					{
+						Name:  "judge_evaluation",
+						Model: "judge-model-default",
+					},
+				},
+			},
+		},
+	}, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	judgedTask := config.Task{
+		Name:           "success",
+		Prompt:         "What is the answer?",
+		ExpectedResult: utils.NewValueSet("corporis et ipsa"),
+		ValidationRules: &config.ValidationRules{
+			Judge: config.JudgeSelector{
+				Enabled: testutils.Ptr(true),
+				Name:    testutils.Ptr("test-judge"),
+				Variant: testutils.Ptr("judge_evaluation"),
+			},
+		},
+	}
+	require.NoError(t, judgedTask.ResolveValidationRules(config.ValidationRules{}))
+
+	results, err := r.Run(context.Background(), []config.Task{judgedTask})
+	require.NoError(t, err)
+
+	provenanceByRun := make(map[string]*Provenance)
+	for _, result := range results.GetResults()["mock provider 1"] {
+		require.NotNil(t, result.Provenance, "run %s", result.Run)
+		provenanceByRun[result.Run] = result.Provenance
+	}
+	require.Len(t, provenanceByRun, 2)
+
+	mock := provenanceByRun["mock"]
+	assert.Equal(t, "microchip", mock.Run.Model)
+	assert.Equal(t, 50, mock.Run.MaxRequestsPerMinute)
+	assert.Equal(t, &RetryPolicySnapshot{MaxRetryAttempts: 2, InitialDelaySeconds: 5}, mock.Run.RetryPolicy)
+	assert.Equal(t, map[string]interface{}{
+		"temperature": 0.5,
+		"provider":    map[string]interface{}{"api_key": "[REDACTED]"},
+	}, mock.Run.ModelParams)
+	require.NotNil(t, mock.Judge)
+	assert.Equal(t, "test-judge", mock.Judge.Name)
+	assert.Equal(t, "judge_evaluation", mock.Judge.Variant)
+	assert.Equal(t, "mock", mock.Judge.Provider)
+	assert.Equal(t, "judge-model-default", mock.Judge.Run.Model)
+	assert.Equal(t, "What is the answer?", mock.Task.Prompt)
+	assert.Equal(t, utils.NewValueSet("corporis et ipsa"), mock.Task.ExpectedResult)
+
+	pass := provenanceByRun["pass"]
+	assert.Equal(t, "parsing", pass.Run.Model)
+	assert.NotEqual(t, mock.ConfigHash, pass.ConfigHash, "different run configurations")
+	assert.Equal(t, mock.Task.Hash, pass.Task.Hash, "same task definition")
+	assert.NotEmpty(t, mock.Task.Hash)
+}
+
 func TestRunTaskClassifiesTransientErrors(t *testing.T) {
 	// Configure a retry policy that is guaranteed to be exhausted before the mock
 	// provider's simulated transient error stops recurring, so the final result is
@@ -1843,7 +2156,7 @@ type stubToolValidator struct {
 	closed         bool
 }
 
-func (s *stubToolValidator) ValidateTool(ctx context.Context, cfg config.ToolConfig) error {
+func (s *stubToolValidator) ValidateTool(ctx context.Context, logger logging.Logger, cfg config.ToolConfig) error {
 	s.validatedTools = append(s.validatedTools, cfg.Name)
 	if s.validateErr != nil {
 		return s.validateErr
@@ -1858,13 +2171,16 @@ func (s *stubToolValidator) Close() error {
 
 func TestDefaultRunnerAssertCanRun(t *testing.T) {
 	tests := []struct {
-		name          string
-		tools         []config.ToolConfig
-		taskToolNames []string
-		validateErr   error
-		expectedTools []string
-		expectedError string
-		wantErr       bool
+		name             string
+		tools            []config.ToolConfig
+		taskToolNames    []string
+		cpuPercent       *int
+		validateErr      error
+		newValidatorErr  error
+		expectedTools    []string
+		expectedBackends []string
+		expectedError    string
+		wantErr          bool
 	}{
 		{
 			name: "validates single tool",
@@ -1872,9 +2188,10 @@ func TestDefaultRunnerAssertCanRun(t *testing.T) {
 				{Name: "echo", Image: "alpine:latest"},
 				{Name: "cat", Image: "linux:latest"},
 			},
-			taskToolNames: []string{"echo"},
-			expectedTools: []string{"echo"},
-			wantErr:       false,
+			taskToolNames:    []string{"echo"},
+			expectedTools:    []string{"echo"},
+			expectedBackends: []string{"docker"},
+			wantErr:          false,
 		},
 		{
 			name: "validates multiple tools",
@@ -1882,9 +2199,10 @@ func TestDefaultRunnerAssertCanRun(t *testing.T) {
 				{Name: "echo", Image: "alpine:latest"},
 				{Name: "cat", Image: "linux:latest"},
 			},
-			taskToolNames: []string{"echo", "cat"},
-			expectedTools: []string{"echo", "cat"},
-			wantErr:       false,
+			taskToolNames:    []string{"echo", "cat"},
+			expectedTools:    []string{"echo", "cat"},
+			expectedBackends: []string{"docker"},
+			wantErr:          false,
 		},
 		{
 			name: "deduplicates tools - validates each tool once",
@@ -1892,35 +2210,102 @@ func TestDefaultRunnerAssertCanRun(t *testing.T) {
 				{Name: "echo", Image: "alpine:latest"},
 				{Name: "cat", Image: "alpine:latest"},
 			},
-			taskToolNames: []string{"echo", "cat"},
-			expectedTools: []string{"echo", "cat"},
-			wantErr:       false,
+			taskToolNames:    []string{"echo", "cat"},
+			expectedTools:    []string{"echo", "cat"},
+			expectedBackends: []string{"docker"},
+			wantErr:          false,
 		},
 		{
 			name: "reports validation failure",
 			tools: []config.ToolConfig{
 				{Name: "echo", Image: "alpine:latest"},
 			},
-			taskToolNames: []string{"echo"},
-			validateErr:   errors.New("docker image missing"), //nolint:err113
-			expectedTools: []string{"echo"},
-			expectedError: "could not start because:\ntool 'echo' cannot be used: docker image missing",
+			taskToolNames:    []string{"echo"},
+			validateErr:      errors.New("docker image missing"), //nolint:err113
+			expectedTools:    []string{"echo"},
+			expectedBackends: []string{"docker"},
+			expectedError:    "could not start because:\ntool 'echo' cannot be used: docker image missing",
+			wantErr:          true,
+		},
+		{
+			name: "uses subprocess executor without docker tools",
+			tools: []config.ToolConfig{
+				{Name: "echo", Type: config.ToolTypeSubprocess, Command: []string{"echo"}},
+				{Name: "search", Type: config.ToolTypeMCP, MCP: &config.MCPServerConfig{Transport: config.MCPTransportStreamableHTTP, URL: "http://localhost:8080/mcp"}},
+			},
+			taskToolNames:    []string{"echo", "search"},
+			expectedTools:    []string{"echo", "search"},
+			expectedBackends: []string{"subprocess"},
+			wantErr:          false,
+		},
+		{
+			name: "reports cpu limit of subprocess tool",
+			tools: []config.ToolConfig{
+				{Name: "echo", Type: config.ToolTypeSubprocess, Command: []string{"echo"}},
+			},
+			taskToolNames:    []string{"echo"},
+			cpuPercent:       testutils.Ptr(50),
+			expectedTools:    []string{"echo"},
+			expectedBackends: []string{"subprocess"},
+			expectedError:    "could not start because:\nunsupported tool limit: task 'tool-task' sets cpu-percent for subprocess tool 'echo' that cannot be CPU limited",
+			wantErr:          true,
+		},
+		{
+			name: "does not create executor for task without tools",
+			tools: []config.ToolConfig{
+				{Name: "echo", Image: "alpine:latest"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "reports mixed executor backends",
+			tools: []config.ToolConfig{
+				{Name: "echo", Image: "alpine:latest"},
+				{Name: "cat", Type: config.ToolTypeSubprocess, Command: []string{"cat"}},
+			},
+			taskToolNames: []string{"echo", "cat"},
+			expectedError: "could not start because:\ntask 'tool-task' cannot use the selected tools: unsupported tool type: docker and subprocess tools cannot be used together",
 			wantErr:       true,
 		},
+		{
+			name: "reports executor initialization failure",
+			tools: []config.ToolConfig{
+				{Name: "echo", Image: "alpine:latest"},
+			},
+			taskToolNames:    []string{"echo"},
+			newValidatorErr:  errors.New("docker not running"), //nolint:err113
+			expectedBackends: []string{"docker"},
+			expectedError:    "could not start because:\ntask 'tool-task' requires docker tool executor that cannot be initialized: docker not running",
+			wantErr:          true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
 			stub := &stubToolValidator{validateErr: tt.validateErr}
+			var requestedBackends []string
 
 			runner := &defaultRunner{
 				validatorFactory: validators.NewFactory(nil),
 				tools:            tt.tools,
-				toolValidator:    stub,
+				toolResources:    providertools.NewExecutorResources(),
+				newToolValidator: func(_ context.Context, backend string) (toolValidator, error) {
+					requestedBackends = append(requestedBackends, backend)
+					if tt.newValidatorErr != nil {
+						return nil, tt.newValidatorErr
+					}
+					return stub, nil
+				},
 			}
 
 			task := newToolTask(t, tt.taskToolNames...)
+			if tt.cpuPercent != nil {
+				for i := range task.ToolSelector.Tools {
+					task.ToolSelector.Tools[i].CpuPercent = tt.cpuPercent
+				}
+				task.ResolveToolSelector(config.ToolSelector{})
+			}
 
 			err := runner.assertCanRun(ctx, []config.Task{task})
 			if tt.wantErr {
@@ -1930,10 +2315,11 @@ func TestDefaultRunnerAssertCanRun(t *testing.T) {
 				require.NoError(t, err)
 			}
 			assert.ElementsMatch(t, tt.expectedTools, stub.validatedTools)
+			assert.Equal(t, tt.expectedBackends, requestedBackends)
 			assert.False(t, stub.closed)
 
 			runner.Close(ctx)
-			assert.True(t, stub.closed)
+			assert.Equal(t, len(stub.validatedTools) > 0, stub.closed)
 		})
 	}
 }
@@ -2832,6 +3218,17 @@ func TestToTokenUsage(t *testing.T) {
 				InputCacheReadTokens:  testutils.Ptr(int64(1750409999982496)),
 			},
 		},
+		{
+			name: "reasoning tokens",
+			in: providers.Usage{
+				OutputTokens:    testutils.Ptr(int64(20)),
+				ReasoningTokens: testutils.Ptr(int64(15)),
+			},
+			want: TokenUsage{
+				OutputTokens:    testutils.Ptr(int64(20)),
+				ReasoningTokens: testutils.Ptr(int64(15)),
+			},
+		},
 		{
 			name: "only some fields populated",
 			in: providers.Usage{
@@ -2857,6 +3254,90 @@ func TestToTokenUsage(t *testing.T) {
 	}
 }
 
+func TestToTiming(t *testing.T) {
+	ttft := 200 * time.Millisecond
+	tests := []struct {
+		name      string
+		requests  []providers.RequestTiming
+		toolCalls []providertools.ToolCallSummary
+		want      *Timing
+	}{
+		{
+			name: "nothing recorded",
+			want: nil,
+		},
+		{
+			name: "requests and tool calls",
+			requests: []providers.RequestTiming{
+				{Duration: time.Second, TimeToFirstToken: &ttft, OutputTokens: testutils.Ptr(int64(100))},
+				{Duration: 2 * time.Second},
+			},
+			toolCalls: []providertools.ToolCallSummary{
+				{Tool: "python", WallTimeNs: int64(300 * time.Millisecond)},
+				{Tool: "python", WallTimeNs: int64(500 * time.Millisecond)},
+			},
+			want: &Timing{
+				Requests: []RequestTiming{
+					{Duration: time.Second, TimeToFirstToken: &ttft, OutputTokens: testutils.Ptr(int64(100))},
+					{Duration: 2 * time.Second},
+				},
+				ToolTime: 800 * time.Millisecond,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, toTiming(tt.requests, tt.toolCalls))
+		})
+	}
+}
+
+func TestTimingMetrics(t *testing.T) {
+	ttft := 500 * time.Millisecond
+	tests := []struct {
+		name                   string
+		timing                 Timing
+		wantTimeToFirstToken   *time.Duration
+		wantOutputTokensPerSec *float64
+	}{
+		{
+			name: "no requests",
+		},
+		{
+			name: "streamed request excludes time to first token",
+			timing: Timing{Requests: []RequestTiming{
+				{Duration: 2500 * time.Millisecond, TimeToFirstToken: &ttft, OutputTokens: testutils.Ptr(int64(100))},
+			}},
+			wantTimeToFirstToken:   &ttft,
+			wantOutputTokensPerSec: testutils.Ptr(50.0),
+		},
+		{
+			name: "requests without output tokens are ignored",
+			timing: Timing{Requests: []RequestTiming{
+				{Duration: time.Second},
+				{Duration: 2 * time.Second, OutputTokens: testutils.Ptr(int64(30))},
+				{Duration: 2 * time.Second, OutputTokens: testutils.Ptr(int64(10))},
+			}},
+			wantOutputTokensPerSec: testutils.Ptr(10.0),
+		},
+		{
+			name: "unknown output tokens",
+			timing: Timing{Requests: []RequestTiming{
+				{Duration: time.Second, TimeToFirstToken: &ttft},
+			}},
+			wantTimeToFirstToken: &ttft,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantTimeToFirstToken, tt.timing.TimeToFirstToken())
+			assert.Equal(t, tt.wantOutputTokensPerSec, tt.timing.OutputTokensPerSecond())
+		})
+	}
+}
+
 func TestTokenUsageJSONRoundTrip(t *testing.T) {
 	original := TokenUsage{
 		InputTokens:           testutils.Ptr(int64(4705009999952950)),
@@ -2919,3 +3400,302 @@ func TestMergeResultsPreservesTokenUsage(t *testing.T) {
 		InputCacheReadTokens:  testutils.Ptr(int64(40)),
 	}, merged["ProviderA"][0].Details.Answer.Usage)
 }
+
+func TestRunnerRunTaskTimeout(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:    "mock",
+					Model:   "slow",
+					Timeout: testutils.Ptr(50 * time.Millisecond),
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := []config.Task{
+		{Name: "hang", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+	}
+	start := time.Now()
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, 2)
+
+	timedOut := providerResults[0]
+	assert.Equal(t, TimedOut, timedOut.Kind)
+	assert.Equal(t, "Timed Out", timedOut.Details.Error.Title)
+	assert.Equal(t, "task timed out after 50ms", timedOut.Details.Error.Message)
+	assert.Equal(t, testutils.Ptr(int64(8200209999917998)), timedOut.Details.Error.Usage.InputTokens, "partial usage must be kept")
+
+	assert.Equal(t, Success, providerResults[1].Kind)
+}
+
+func TestRunnerRunTrialDeadline(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:  "mock",
+					Model: "slow",
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	tasks := []config.Task{
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "hang", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "failure", ExpectedResult: utils.NewValueSet("expected answer")},
+	}
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, ErrTrialDeadline)
+	defer cancel()
+	results, err := r.Run(ctx, tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, 3)
+	assert.Equal(t, Success, providerResults[0].Kind)
+	for _, result := range providerResults[1:] {
+		assert.Equal(t, TimedOut, result.Kind, result.Task)
+		assert.Equal(t, "Timed Out", result.Details.Error.Title, result.Task)
+		assert.Equal(t, ErrTrialDeadline.Error(), result.Details.Error.Message, result.Task)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	withTimeout := func(timeout *time.Duration) config.Task {
+		task := config.Task{Timeout: timeout}
+		task.ResolveTimeout(nil)
+		return task
+	}
+	tests := []struct {
+		name string
+		run  config.RunConfig
+		task config.Task
+		want time.Duration
+	}{
+		{name: "no timeout", task: withTimeout(nil), want: 0},
+		{name: "task timeout", task: withTimeout(testutils.Ptr(time.Minute)), want: time.Minute},
+		{name: "run timeout", run: config.RunConfig{Timeout: testutils.Ptr(time.Minute)}, task: withTimeout(nil), want: time.Minute},
+		{name: "shorter task timeout", run: config.RunConfig{Timeout: testutils.Ptr(time.Hour)}, task: withTimeout(testutils.Ptr(time.Minute)), want: time.Minute},
+		{name: "shorter run timeout", run: config.RunConfig{Timeout: testutils.Ptr(time.Minute)}, task: withTimeout(testutils.Ptr(time.Hour)), want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, taskTimeout(tt.run, tt.task))
+		})
+	}
+}
+
+func TestRunnerStartCancel(t *testing.T) {
+	started := &signalWriter{match: "mock: hang: starting task", signal: make(chan struct{})}
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:  "mock",
+					Model: "slow",
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(io.MultiWriter(zerolog.NewTestWriter(t), started)))
+
+	tasks := []config.Task{
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "hang", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "failure", ExpectedResult: utils.NewValueSet("expected answer")},
+	}
+	rs, err := r.Start(context.Background(), tasks)
+	require.NoError(t, err)
+
+	// Wait until the hanging task is running.
+	select {
+	case <-started.signal:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hanging task did not start")
+	}
+	start := time.Now()
+	rs.Cancel(50 * time.Millisecond)
+
+	results, err := rs.GetResults()
+	require.NoError(t, err)
+	providerResults := results["mock provider 1"]
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "running tasks must be given the grace period")
+	require.Len(t, providerResults, 3, "cancelled tasks must be reported")
+	assert.Equal(t, Success, providerResults[0].Kind)
+
+	stopped := providerResults[1]
+	assert.Equal(t, Cancelled, stopped.Kind)
+	assert.Equal(t, "Cancelled", stopped.Details.Error.Title)
+	assert.Equal(t, "task was stopped because the run was canceled", stopped.Details.Error.Message)
+	assert.Equal(t, testutils.Ptr(int64(8200209999917998)), stopped.Details.Error.Usage.InputTokens, "partial usage must be kept")
+
+	notStarted := providerResults[2]
+	assert.Equal(t, Cancelled, notStarted.Kind)
+	assert.Equal(t, "failure", notStarted.Task)
+	assert.Equal(t, "mock", notStarted.Run)
+	assert.NotNil(t, notStarted.Provenance)
+	assert.Equal(t, "task was not started because the run was canceled", notStarted.Details.Error.Message)
+}
+
+func TestRunnerRunCanceledContext(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:  "mock",
+					Model: "canceled",
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := r.Run(ctx, []config.Task{
+		{Name: "success", ExpectedResult: utils.NewValueSet("expected answer")},
+		{Name: "failure", ExpectedResult: utils.NewValueSet("expected answer")},
+	})
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, 2)
+	for _, result := range providerResults {
+		assert.Equal(t, Cancelled, result.Kind, result.Task)
+	}
+}
+
+// signalWriter closes the signal channel once a written log entry contains the match string.
+type signalWriter struct {
+	match  string
+	signal chan struct{}
+	once   sync.Once
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), w.match) {
+		w.once.Do(func() { close(w.signal) })
+	}
+	return len(p), nil
+}
+
+func TestRunnerRunResponseRecovery(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       *config.ResponseRecoveryPolicy
+		wantKind     []ResultKind
+		wantRecovery []string
+	}{
+		{
+			name:         "no recovery",
+			wantKind:     []ResultKind{Error, Error},
+			wantRecovery: []string{"", ""},
+		},
+		{
+			name:         "repair only",
+			policy:       &config.ResponseRecoveryPolicy{Repair: true},
+			wantKind:     []ResultKind{Success, Error},
+			wantRecovery: []string{"repair", ""},
+		},
+		{
+			name:         "repair and corrective turn",
+			policy:       &config.ResponseRecoveryPolicy{Repair: true, MaxCorrectiveTurns: 1},
+			wantKind:     []ResultKind{Success, Success},
+			wantRecovery: []string{"repair", "corrective-turn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+				{
+					Name: "mock provider 1",
+					Runs: []config.RunConfig{
+						{
+							Name:             "mock",
+							Model:            "parser",
+							ResponseRecovery: tt.policy,
+						},
+					},
+				},
+			}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+			tasks := []config.Task{
+				{Name: "malformed", ResponseResultFormat: config.NewResponseFormat("Answer in plain text."), ExpectedResult: utils.NewValueSet("expected answer")},
+				{Name: "unparsable", ResponseResultFormat: config.NewResponseFormat("Answer in plain text."), ExpectedResult: utils.NewValueSet("expected answer")},
+			}
+			results, err := r.Run(context.Background(), tasks)
+			require.NoError(t, err)
+
+			providerResults := results.GetResults()["mock provider 1"]
+			require.Len(t, providerResults, len(tasks))
+			for i, result := range providerResults {
+				assert.Equal(t, tt.wantKind[i], result.Kind, result.Task)
+				assert.Equal(t, tt.wantRecovery[i], result.Details.Answer.Recovery, result.Task)
+				if result.Kind == Error {
+					assert.Equal(t, "Response Parsing Error", result.Details.Error.Title, result.Task)
+				}
+			}
+			if tt.wantRecovery[1] == "corrective-turn" {
+				assert.Equal(t, testutils.Ptr(int64(2*8200209999917998)), providerResults[1].Details.Answer.Usage.InputTokens, "usage of the corrective turn must be added")
+			}
+		})
+	}
+}
+
+func TestRunnerRunAnswerExtraction(t *testing.T) {
+	r := createMockRunnerFromConfig(t, []config.ProviderConfig{
+		{
+			Name: "mock provider 1",
+			Runs: []config.RunConfig{
+				{
+					Name:                    "echo",
+					Model:                   "unstructured",
+					DisableStructuredOutput: true,
+				},
+			},
+		},
+	}, nil, nil, zerolog.New(zerolog.NewTestWriter(t)))
+
+	marker := &config.AnswerExtractor{Type: config.AnswerExtractorMarker}
+	tasks := []config.Task{
+		{Name: "Let me work it out.\n**Answer:** 42", ExpectedResult: utils.NewValueSet("42")},
+		{Name: "I am not sure.", ExpectedResult: utils.NewValueSet("42")},
+		{Name: "Plain 42", ExpectedResult: utils.NewValueSet("Plain 42")},
+	}
+	tasks[0].ResolveAnswerExtractor(marker)
+	tasks[1].ResolveAnswerExtractor(marker)
+	tasks[2].ResolveAnswerExtractor(nil)
+
+	results, err := r.Run(context.Background(), tasks)
+	require.NoError(t, err)
+
+	providerResults := results.GetResults()["mock provider 1"]
+	require.Len(t, providerResults, len(tasks))
+
+	extracted := providerResults[0]
+	assert.Equal(t, Success, extracted.Kind)
+	assert.Equal(t, "42", extracted.Got)
+	assert.Equal(t, []string{"42"}, extracted.Details.Answer.ActualAnswer)
+	assert.Equal(t, []string{"Let me work it out.", "**Answer:** 42"}, extracted.Details.Answer.RawAnswer)
+
+	failed := providerResults[1]
+	assert.Equal(t, ExtractionFailed, failed.Kind)
+	assert.Equal(t, "Answer Extraction Failed", failed.Details.Error.Title)
+	assert.Equal(t, testutils.Ptr(false), failed.Details.Error.Transient)
+	assert.Equal(t, []string{"I am not sure."}, failed.Details.Error.Details["Raw Answer"])
+	assert.Contains(t, failed.Got, ErrAnswerNotExtracted.Error())
+
+	unextracted := providerResults[2]
+	assert.Equal(t, Success, unextracted.Kind)
+	assert.Empty(t, unextracted.Details.Answer.RawAnswer)
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/petmal/mindtrial/config"
+	providertools "github.com/petmal/mindtrial/providers/tools"
+)
+
+// evaluateToolExpectations checks the tool calls made while producing the answer against
+// the tool expectations of a task and returns a description of each expectation not met.
+func evaluateToolExpectations(expectations config.ToolExpectations, calls []providertools.ToolCallSummary) (violations []string) {
+	counts := make(map[string]int)
+	for _, call := range calls {
+		counts[call.Tool]++
+	}
+
+	for _, tool := range expectations.Required {
+		if counts[tool] == 0 {
+			violations = append(violations, fmt.Sprintf("required tool '%s' was not called", tool))
+		}
+	}
+	for _, tool := range expectations.Forbidden {
+		if counts[tool] > 0 {
+			violations = append(violations, fmt.Sprintf("forbidden tool '%s' was called %d time(s)", tool, counts[tool]))
+		}
+	}
+	for _, expected := range expectations.Calls {
+		count := counts[expected.Tool]
+		if expected.MinCalls != nil && count < *expected.MinCalls {
+			violations = append(violations, fmt.Sprintf("tool '%s' was called %d time(s), expected at least %d", expected.Tool, count, *expected.MinCalls))
+		}
+		if expected.MaxCalls != nil && count > *expected.MaxCalls {
+			violations = append(violations, fmt.Sprintf("tool '%s' was called %d time(s), expected at most %d", expected.Tool, count, *expected.MaxCalls))
+		}
+	}
+	for _, expected := range expectations.Arguments {
+		if violation := checkArgumentExpectation(expected, calls); violation != "" {
+			violations = append(violations, violation)
+		}
+	}
+	if len(expectations.Order) > 0 && !isCalledInOrder(expectations.Order, calls) {
+		sequence := make([]string, len(calls))
+		for i, call := range calls {
+			sequence[i] = call.Tool
+		}
+		violations = append(violations, fmt.Sprintf("tools were not called in the expected order [%s], actual calls: [%s]", strings.Join(expectations.Order, ", "), strings.Join(sequence, ", ")))
+	}
+	return
+}
+
+// checkArgumentExpectation checks the arguments of the calls of a tool against an expectation
+// and returns a description of the violation, or an empty string if it is met.
+func checkArgumentExpectation(expected config.ToolArgumentExpectation, calls []providertools.ToolCallSummary) string {
+	pattern, err := expected.CompilePattern()
+	if err != nil {
+		return fmt.Sprintf("invalid argument pattern for tool '%s': %v", expected.Tool, err)
+	}
+	matches := func(value interface{}) bool {
+		switch {
+		case expected.Equals != nil && !isEqualJSONValue(expected.Equals, value):
+			return false
+		case pattern != nil:
+			text, ok := value.(string)
+			if !ok {
+				text = toCompactJSON(value)
+			}
+			return pattern.MatchString(text)
+		}
+		return true
+	}
+
+	var called, matched int
+	for _, call := range calls {
+		if call.Tool != expected.Tool {
+			continue
+		}
+		called++
+		var args interface{}
+		if err := json.Unmarshal(call.Arguments, &args); err != nil {
+			continue
+		}
+		for _, value := range expected.Path.Select(args) {
+			if matches(value) {
+				matched++
+				break
+			}
+		}
+	}
+
+	constraint := "a value"
+	switch {
+	case expected.Equals != nil:
+		constraint = fmt.Sprintf("value %s", toCompactJSON(expected.Equals))
+	case pattern != nil:
+		constraint = fmt.Sprintf("value matching %q", expected.Pattern)
+	}
+	switch {
+	case called == 0:
+		return fmt.Sprintf("tool '%s' was not called, expected %s at %s in its arguments", expected.Tool, constraint, expected.Path)
+	case expected.All && matched < called:
+		return fmt.Sprintf("%d of %d call(s) of tool '%s' did not have %s at %s in their arguments", called-matched, called, expected.Tool, constraint, expected.Path)
+	case !expected.All && matched == 0:
+		return fmt.Sprintf("no call of tool '%s' had %s at %s in its arguments", expected.Tool, constraint, expected.Path)
+	}
+	return ""
+}
+
+// isEqualJSONValue reports whether a configured value equals a value decoded from JSON,
+// comparing both in their JSON representation so that e.g. integers match numbers.
+func isEqualJSONValue(expected interface{}, actual interface{}) bool {
+	data, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalized, actual)
+}
+
+// toCompactJSON returns the compact JSON encoding of a value, or its default format if it
+// cannot be encoded.
+func toCompactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// isCalledInOrder reports whether the tools were called in the given order, allowing
+// other calls in between.
+func isCalledInOrder(order []string, calls []providertools.ToolCallSummary) bool {
+	next := 0
+	for _, call := range calls {
+		if next < len(order) && call.Tool == order[next] {
+			next++
+		}
+	}
+	return next == len(order)
+}
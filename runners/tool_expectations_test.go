@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/petmal/mindtrial/config"
+	"github.com/petmal/mindtrial/pkg/testutils"
+	"github.com/petmal/mindtrial/pkg/utils"
+	providertools "github.com/petmal/mindtrial/providers/tools"
+)
+
+func mustParseJSONPath(t *testing.T, expr string) utils.JSONPath {
+	path, err := utils.ParseJSONPath(expr)
+	require.NoError(t, err)
+	return path
+}
+
+func TestEvaluateToolExpectations(t *testing.T) {
+	calls := []providertools.ToolCallSummary{
+		{Tool: "search", Arguments: json.RawMessage(`{"query":"capital of France"}`)},
+		{Tool: "search", Arguments: json.RawMessage(`{"query":"population of Paris","limit":5}`)},
+		{Tool: "calculator", Arguments: json.RawMessage(`{"expression":"2161000*2"}`)},
+	}
+
+	tests := []struct {
+		name         string
+		expectations config.ToolExpectations
+		want         []string
+	}{
+		{
+			name: "all met",
+			expectations: config.ToolExpectations{
+				Required:  []string{"calculator"},
+				Forbidden: []string{"python"},
+				Calls:     []config.ToolCallCountExpectation{{Tool: "search", MinCalls: testutils.Ptr(1), MaxCalls: testutils.Ptr(3)}},
+				Arguments: []config.ToolArgumentExpectation{
+					{Tool: "search", Path: mustParseJSONPath(t, "$.limit"), Equals: 5},
+					{Tool: "search", Path: mustParseJSONPath(t, "$.query"), Pattern: "(?i)paris|france", All: true},
+					{Tool: "calculator", Path: mustParseJSONPath(t, "$.expression")},
+				},
+				Order: []string{"search", "calculator"},
+			},
+		},
+		{
+			name: "required and forbidden tools",
+			expectations: config.ToolExpectations{
+				Required:  []string{"python"},
+				Forbidden: []string{"search"},
+			},
+			want: []string{
+				"required tool 'python' was not called",
+				"forbidden tool 'search' was called 2 time(s)",
+			},
+		},
+		{
+			name: "call counts",
+			expectations: config.ToolExpectations{
+				Calls: []config.ToolCallCountExpectation{
+					{Tool: "search", MaxCalls: testutils.Ptr(1)},
+					{Tool: "calculator", MinCalls: testutils.Ptr(2)},
+				},
+			},
+			want: []string{
+				"tool 'search' was called 2 time(s), expected at most 1",
+				"tool 'calculator' was called 1 time(s), expected at least 2",
+			},
+		},
+		{
+			name: "arguments",
+			expectations: config.ToolExpectations{
+				Arguments: []config.ToolArgumentExpectation{
+					{Tool: "search", Path: mustParseJSONPath(t, "$.query"), Pattern: "France", All: true},
+					{Tool: "calculator", Path: mustParseJSONPath(t, "$.expression"), Equals: "2+2"},
+					{Tool: "python", Path: mustParseJSONPath(t, "$.code")},
+					{Tool: "search", Path: mustParseJSONPath(t, "$.limit"), Pattern: "^[0-9]$"},
+				},
+			},
+			want: []string{
+				`1 of 2 call(s) of tool 'search' did not have value matching "France" at $.query in their arguments`,
+				`no call of tool 'calculator' had value "2+2" at $.expression in its arguments`,
+				"tool 'python' was not called, expected a value at $.code in its arguments",
+			},
+		},
+		{
+			name: "order",
+			expectations: config.ToolExpectations{
+				Order: []string{"calculator", "search"},
+			},
+			want: []string{
+				"tools were not called in the expected order [calculator, search], actual calls: [search, search, calculator]",
+			},
+		},
+		{
+			name: "repeated tool in order",
+			expectations: config.ToolExpectations{
+				Order: []string{"search", "search", "calculator"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, evaluateToolExpectations(tt.expectations, calls))
+		})
+	}
+}
+
+func TestEvaluateToolExpectations_NoCalls(t *testing.T) {
+	violations := evaluateToolExpectations(config.ToolExpectations{
+		Forbidden: []string{"search"},
+		Calls:     []config.ToolCallCountExpectation{{Tool: "search", MaxCalls: testutils.Ptr(0)}},
+		Order:     []string{"search"},
+	}, nil)
+	assert.Equal(t, []string{"tools were not called in the expected order [search], actual calls: []"}, violations)
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/petmal/mindtrial/pkg/utils"
+)
+
+const (
+	// providerPseudonymPrefix prefixes the pseudonyms that replace provider names.
+	providerPseudonymPrefix = "provider-"
+	// runPseudonymPrefix prefixes the pseudonyms that replace run names.
+	runPseudonymPrefix = "run-"
+	// pseudonymHashLength is the number of hexadecimal digits of the keyed hash used in pseudonyms.
+	pseudonymHashLength = 12
+)
+
+// ErrPseudonymCollision is returned when two different identities map to the same pseudonym.
+var ErrPseudonymCollision = errors.New("pseudonym collision")
+
+// ResultFilter selects results by provider, run, task, and result kind.
+// For each dimension, an empty include list matches everything, and the exclude list
+// takes precedence over the include list. A result is selected only if it matches
+// in every dimension.
+type ResultFilter struct {
+	IncludeProviders []string
+	ExcludeProviders []string
+	IncludeRuns      []string
+	ExcludeRuns      []string
+	IncludeTasks     []string
+	ExcludeTasks     []string
+	IncludeKinds     []ResultKind
+	ExcludeKinds     []ResultKind
+}
+
+// Matches returns true if the result is selected by this filter.
+func (f ResultFilter) Matches(r RunResult) bool {
+	return isIncluded(r.Provider, f.IncludeProviders, f.ExcludeProviders) &&
+		isIncluded(r.Run, f.IncludeRuns, f.ExcludeRuns) &&
+		isIncluded(r.Task, f.IncludeTasks, f.ExcludeTasks) &&
+		isIncluded(r.Kind, f.IncludeKinds, f.ExcludeKinds)
+}
+
+// Apply returns a copy of results containing only the selected results.
+// Providers left without any results are omitted.
+func (f ResultFilter) Apply(results Results) Results {
+	filtered := make(Results, len(results))
+	for provider, runResults := range results {
+		for _, r := range runResults {
+			if f.Matches(r) {
+				filtered[provider] = append(filtered[provider], r)
+			}
+		}
+	}
+	return filtered
+}
+
+func isIncluded[T comparable](value T, include []T, exclude []T) bool {
+	return (len(include) == 0 || slices.Contains(include, value)) && !slices.Contains(exclude, value)
+}
+
+// RenameMap renames providers and runs.
+// Run renames apply to runs with a matching name under any provider.
+type RenameMap struct {
+	// Providers maps original provider names to new names.
+	Providers map[string]string
+	// Runs maps original run names to new names.
+	Runs map[string]string
+}
+
+// Apply returns a copy of results with providers and runs renamed.
+// If several providers are renamed to the same name, their results are combined
+// under that name in ascending order of the original provider names.
+// Renaming does not resolve duplicate tasks within a run; pass each renamed
+// result set through MergeResults to do that.
+func (m RenameMap) Apply(results Results) Results {
+	renamed := make(Results, len(results))
+	for _, provider := range utils.SortedKeys(results) {
+		newProvider := renameOrKeep(m.Providers, provider)
+		for _, r := range results[provider] {
+			r.Provider = newProvider
+			r.Run = renameOrKeep(m.Runs, r.Run)
+			renamed[newProvider] = append(renamed[newProvider], r)
+		}
+	}
+	return renamed
+}
+
+func renameOrKeep(renames map[string]string, name string) string {
+	if newName, ok := renames[name]; ok {
+		return newName
+	}
+	return name
+}
+
+// Identity identifies the provider and run configuration that produced a result.
+type Identity struct {
+	Provider string
+	Run      string
+}
+
+// PseudonymMapping records the original identity replaced by a pseudonymous one.
+type PseudonymMapping struct {
+	Pseudonym Identity
+	Original  Identity
+}
+
+// Anonymize replaces the provider and run names in results and stats with pseudonyms
+// derived from a keyed hash of the original names, so that the same key always
+// produces the same pseudonyms. The run configuration snapshots, which reveal the
+// model, are removed from the result provenance.
+// Returns the anonymized results and stats, and the mapping from pseudonymous to
+// original identities ordered by pseudonym.
+func Anonymize(results Results, stats MergeStats, key []byte) (Results, MergeStats, []PseudonymMapping, error) {
+	pseudonyms := make(map[Identity]Identity)
+	originals := make(map[Identity]Identity)
+	providerPseudonyms := make(map[string]string)
+	pseudonymFor := func(original Identity) (Identity, error) {
+		if pseudonym, ok := pseudonyms[original]; ok {
+			return pseudonym, nil
+		}
+		pseudonym := Identity{
+			Provider: providerPseudonymPrefix + keyedHash(key, original.Provider),
+			Run:      runPseudonymPrefix + keyedHash(key, original.Provider, original.Run),
+		}
+		if previous, ok := providerPseudonyms[pseudonym.Provider]; ok && previous != original.Provider {
+			return Identity{}, fmt.Errorf("%w: providers %q and %q", ErrPseudonymCollision, previous, original.Provider)
+		}
+		if previous, ok := originals[pseudonym]; ok {
+			return Identity{}, fmt.Errorf("%w: runs %q and %q", ErrPseudonymCollision, previous.Run, original.Run)
+		}
+		providerPseudonyms[pseudonym.Provider] = original.Provider
+		pseudonyms[original] = pseudonym
+		originals[pseudonym] = original
+		return pseudonym, nil
+	}
+
+	anonymized := make(Results, len(results))
+	for _, provider := range utils.SortedKeys(results) {
+		for _, r := range results[provider] {
+			pseudonym, err := pseudonymFor(Identity{Provider: provider, Run: r.Run})
+			if err != nil {
+				return nil, MergeStats{}, nil, err
+			}
+			r.Provider = pseudonym.Provider
+			r.Run = pseudonym.Run
+			if r.Provenance != nil {
+				provenance := *r.Provenance
+				provenance.Run = RunSnapshot{}
+				provenance.ConfigHash = configHash(provenance.Run, provenance.Judge)
+				r.Provenance = &provenance
+			}
+			anonymized[pseudonym.Provider] = append(anonymized[pseudonym.Provider], r)
+		}
+	}
+
+	anonymizedStats := MergeStats{Runs: make(map[string]map[string]RunMergeStats, len(stats.Runs))}
+	for provider, runs := range stats.Runs {
+		for run, runStats := range runs {
+			pseudonym, err := pseudonymFor(Identity{Provider: provider, Run: run})
+			if err != nil {
+				return nil, MergeStats{}, nil, err
+			}
+			if anonymizedStats.Runs[pseudonym.Provider] == nil {
+				anonymizedStats.Runs[pseudonym.Provider] = make(map[string]RunMergeStats)
+			}
+			anonymizedStats.Runs[pseudonym.Provider][pseudonym.Run] = runStats
+		}
+	}
+
+	mapping := make([]PseudonymMapping, 0, len(originals))
+	for pseudonym, original := range originals {
+		mapping = append(mapping, PseudonymMapping{Pseudonym: pseudonym, Original: original})
+	}
+	slices.SortFunc(mapping, func(a, b PseudonymMapping) int {
+		if c := strings.Compare(a.Pseudonym.Provider, b.Pseudonym.Provider); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Pseudonym.Run, b.Pseudonym.Run)
+	})
+
+	return anonymized, anonymizedStats, mapping, nil
+}
+
+// keyedHash returns a truncated HMAC-SHA256 of the given names.
+// Names are length-prefixed so that different sequences never share an input.
+func keyedHash(key []byte, names ...string) string {
+	mac := hmac.New(sha256.New, key)
+	for _, name := range names {
+		fmt.Fprintf(mac, "%d:%s", len(name), name)
+	}
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymHashLength]
+}
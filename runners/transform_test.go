@@ -0,0 +1,199 @@
+// Copyright (C) 2026 Petr Malik
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at <https://mozilla.org/MPL/2.0/>.
+
+package runners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransformTestResults() Results {
+	return Results{
+		"openai": {
+			{Provider: "openai", Run: "fast", Task: "t1", Kind: Success},
+			{Provider: "openai", Run: "fast", Task: "t2", Kind: Failure},
+			{Provider: "openai", Run: "internal", Task: "t1", Kind: Error},
+		},
+		"anthropic": {
+			{Provider: "anthropic", Run: "fast", Task: "t1", Kind: NotSupported},
+		},
+	}
+}
+
+func TestResultFilterApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ResultFilter
+		want   Results
+	}{
+		{
+			name:   "empty filter selects everything",
+			filter: ResultFilter{},
+			want:   newTransformTestResults(),
+		},
+		{
+			name:   "include providers",
+			filter: ResultFilter{IncludeProviders: []string{"anthropic"}},
+			want: Results{
+				"anthropic": {
+					{Provider: "anthropic", Run: "fast", Task: "t1", Kind: NotSupported},
+				},
+			},
+		},
+		{
+			name:   "exclude runs",
+			filter: ResultFilter{ExcludeRuns: []string{"internal"}},
+			want: Results{
+				"openai": {
+					{Provider: "openai", Run: "fast", Task: "t1", Kind: Success},
+					{Provider: "openai", Run: "fast", Task: "t2", Kind: Failure},
+				},
+				"anthropic": {
+					{Provider: "anthropic", Run: "fast", Task: "t1", Kind: NotSupported},
+				},
+			},
+		},
+		{
+			name:   "exclude takes precedence over include",
+			filter: ResultFilter{IncludeTasks: []string{"t1", "t2"}, ExcludeTasks: []string{"t1"}},
+			want: Results{
+				"openai": {
+					{Provider: "openai", Run: "fast", Task: "t2", Kind: Failure},
+				},
+			},
+		},
+		{
+			name: "all dimensions must match",
+			filter: ResultFilter{
+				IncludeProviders: []string{"openai"},
+				IncludeKinds:     []ResultKind{Success, Error},
+				ExcludeKinds:     []ResultKind{Error},
+			},
+			want: Results{
+				"openai": {
+					{Provider: "openai", Run: "fast", Task: "t1", Kind: Success},
+				},
+			},
+		},
+		{
+			name:   "nothing selected",
+			filter: ResultFilter{IncludeRuns: []string{"missing"}},
+			want:   Results{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Apply(newTransformTestResults()))
+		})
+	}
+}
+
+func TestRenameMapApply(t *testing.T) {
+	t.Run("renames providers and runs", func(t *testing.T) {
+		renames := RenameMap{
+			Providers: map[string]string{"openai": "OpenAI"},
+			Runs:      map[string]string{"fast": "GPT Fast"},
+		}
+		assert.Equal(t, Results{
+			"OpenAI": {
+				{Provider: "OpenAI", Run: "GPT Fast", Task: "t1", Kind: Success},
+				{Provider: "OpenAI", Run: "GPT Fast", Task: "t2", Kind: Failure},
+				{Provider: "OpenAI", Run: "internal", Task: "t1", Kind: Error},
+			},
+			"anthropic": {
+				{Provider: "anthropic", Run: "GPT Fast", Task: "t1", Kind: NotSupported},
+			},
+		}, renames.Apply(newTransformTestResults()))
+	})
+
+	t.Run("combines providers renamed to the same name", func(t *testing.T) {
+		renames := RenameMap{
+			Providers: map[string]string{"openai": "vendor", "anthropic": "vendor"},
+		}
+		assert.Equal(t, Results{
+			"vendor": {
+				{Provider: "vendor", Run: "fast", Task: "t1", Kind: NotSupported},
+				{Provider: "vendor", Run: "fast", Task: "t1", Kind: Success},
+				{Provider: "vendor", Run: "fast", Task: "t2", Kind: Failure},
+				{Provider: "vendor", Run: "internal", Task: "t1", Kind: Error},
+			},
+		}, renames.Apply(newTransformTestResults()))
+	})
+
+	t.Run("does not modify input", func(t *testing.T) {
+		input := newTransformTestResults()
+		RenameMap{Providers: map[string]string{"openai": "OpenAI"}, Runs: map[string]string{"fast": "x"}}.Apply(input)
+		assert.Equal(t, newTransformTestResults(), input)
+	})
+}
+
+func TestAnonymize(t *testing.T) {
+	key := []byte("secret")
+	provenance := &Provenance{
+		ConfigHash: "sha256:original",
+		Run:        RunSnapshot{Model: "gpt-fast"},
+		Judge:      &JudgeSnapshot{Name: "judge"},
+		Task:       TaskSnapshot{Hash: "sha256:task", Prompt: "prompt"},
+	}
+	input := newTransformTestResults()
+	input["openai"][0].Provenance = provenance
+	merged, stats := MergeResults(input)
+
+	results, anonymizedStats, mapping, err := Anonymize(merged, stats, key)
+	require.NoError(t, err)
+
+	require.Len(t, mapping, 3)
+	pseudonyms := make(map[Identity]Identity, len(mapping))
+	for _, m := range mapping {
+		assert.Regexp(t, `^provider-[0-9a-f]{12}$`, m.Pseudonym.Provider)
+		assert.Regexp(t, `^run-[0-9a-f]{12}$`, m.Pseudonym.Run)
+		pseudonyms[m.Original] = m.Pseudonym
+	}
+	openAIFast := pseudonyms[Identity{Provider: "openai", Run: "fast"}]
+	openAIInternal := pseudonyms[Identity{Provider: "openai", Run: "internal"}]
+	anthropicFast := pseudonyms[Identity{Provider: "anthropic", Run: "fast"}]
+	assert.Equal(t, openAIFast.Provider, openAIInternal.Provider, "same provider, same pseudonym")
+	assert.NotEqual(t, openAIFast.Provider, anthropicFast.Provider)
+	assert.NotEqual(t, openAIFast.Run, anthropicFast.Run, "runs of different providers must not share pseudonyms")
+
+	require.Len(t, results, 2)
+	require.Len(t, results[openAIFast.Provider], 3)
+	first := results[openAIFast.Provider][0]
+	assert.Equal(t, openAIFast.Provider, first.Provider)
+	assert.Equal(t, openAIFast.Run, first.Run)
+	assert.Equal(t, "t1", first.Task)
+	require.NotNil(t, first.Provenance)
+	assert.Equal(t, RunSnapshot{}, first.Provenance.Run)
+	assert.Equal(t, provenance.Judge, first.Provenance.Judge)
+	assert.Equal(t, provenance.Task, first.Provenance.Task)
+	assert.NotEqual(t, "sha256:original", first.Provenance.ConfigHash)
+	assert.Equal(t, "gpt-fast", provenance.Run.Model, "input provenance must not be modified")
+
+	assert.Equal(t, MergeStats{Runs: map[string]map[string]RunMergeStats{
+		openAIFast.Provider: {
+			openAIFast.Run:     {Total: 2},
+			openAIInternal.Run: {Total: 1},
+		},
+		anthropicFast.Provider: {
+			anthropicFast.Run: {Total: 1},
+		},
+	}}, anonymizedStats)
+
+	t.Run("stable for the same key", func(t *testing.T) {
+		_, _, again, err := Anonymize(merged, stats, key)
+		require.NoError(t, err)
+		assert.Equal(t, mapping, again)
+	})
+
+	t.Run("different for another key", func(t *testing.T) {
+		_, _, other, err := Anonymize(merged, stats, []byte("other"))
+		require.NoError(t, err)
+		assert.NotEqual(t, mapping, other)
+	})
+}
@@ -83,6 +83,16 @@ func (f *Factory) AssertExists(judge config.JudgeSelector) error {
 	return err
 }
 
+// LookupJudge returns copies of the judge configuration and run variant configuration
+// selected by the given judge selector.
+func (f *Factory) LookupJudge(judge config.JudgeSelector) (config.JudgeConfig, config.RunConfig, error) {
+	judgeConfig, runConfig, err := f.lookupJudgeConfig(judge)
+	if err != nil {
+		return config.JudgeConfig{}, config.RunConfig{}, err
+	}
+	return *judgeConfig, *runConfig, nil
+}
+
 func (f *Factory) getValueMatchValidator() Validator {
 	if validator, exists := f.cache.Load(valueMatchValidatorCacheKey); exists {
 		return validator.(Validator)
@@ -1082,6 +1082,41 @@ func TestFactoryAssertExists(t *testing.T) {
 	assert.Contains(t, err.Error(), "run variant not found: non-existing for judge test-judge")
 }
 
+func TestFactoryLookupJudge(t *testing.T) {
+	judgeConfigs := []config.JudgeConfig{
+		{
+			Name: "test-judge",
+			Provider: config.ProviderConfig{
+				Name: "mock",
+				Runs: []config.RunConfig{
+					{
+						Name:  "default",
+						Model: "mock-model",
+					},
+				},
+			},
+		},
+	}
+	factory := NewFactory(judgeConfigs)
+
+	judgeConfig, runConfig, err := factory.LookupJudge(config.JudgeSelector{
+		Enabled: testutils.Ptr(true),
+		Name:    testutils.Ptr("test-judge"),
+		Variant: testutils.Ptr("default"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test-judge", judgeConfig.Name)
+	assert.Equal(t, "mock", judgeConfig.Provider.Name)
+	assert.Equal(t, "mock-model", runConfig.Model)
+
+	_, _, err = factory.LookupJudge(config.JudgeSelector{
+		Enabled: testutils.Ptr(true),
+		Name:    testutils.Ptr("test-judge"),
+		Variant: testutils.Ptr("non-existing"),
+	})
+	require.ErrorIs(t, err, ErrJudgeVariantNotFound)
+}
+
 func TestValidatorGetName(t *testing.T) {
 	// Test value match validator.
 	valueMatchValidator := NewValueMatchValidator()